@@ -2,23 +2,76 @@ package cmd
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
 
 	appContext "github.com/kumojin/repo-backup-cli/context"
 	"github.com/kumojin/repo-backup-cli/pkg/config"
+	"github.com/kumojin/repo-backup-cli/pkg/download"
 	"github.com/kumojin/repo-backup-cli/pkg/github"
 	"github.com/kumojin/repo-backup-cli/pkg/logging"
+	"github.com/kumojin/repo-backup-cli/pkg/metrics"
+	"github.com/kumojin/repo-backup-cli/pkg/storage"
+	"github.com/kumojin/repo-backup-cli/pkg/storage/filesystem"
 	"github.com/kumojin/repo-backup-cli/pkg/uc"
 
+	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
 )
 
+const (
+	gitDataFlag          = "git-data"
+	metadataFlag         = "metadata"
+	attachmentsFlag      = "attachments"
+	releasesFlag         = "releases"
+	ownerProjectsFlag    = "owner-projects"
+	lockRepositoriesFlag = "lock-repositories"
+	batchMaxReposFlag    = "batch-max-repositories"
+	batchMaxSizeFlag     = "batch-max-size"
+	modeFlag             = "mode"
+	pruneFlag            = "prune"
+	resumeFlag           = "resume"
+	concurrencyFlag      = "concurrency"
+	dirFlag              = "dir"
+	splitReposFlag       = "split-repositories"
+	fullIntervalFlag     = "full-interval-days"
+	reportFlag           = "report"
+	uploadReportFlag     = "upload-report"
+	pushgatewayFlag      = "metrics-pushgateway"
+	metricsTextfileFlag  = "metrics-textfile"
+)
+
 func BackupCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "backup",
 		Short: "Commands to backup repositories from an organization",
 	}
 
+	cmd.PersistentFlags().String(modeFlag, config.BackupModeMigration, fmt.Sprintf("Backup mode: %s uses the GitHub migrations API, %s clones the repositories with git", config.BackupModeMigration, config.BackupModeMirror))
+
+	defaults := github.DefaultMigrationContents()
+	cmd.PersistentFlags().Bool(gitDataFlag, defaults.GitData, "Include git data in the migration archive")
+	cmd.PersistentFlags().Bool(metadataFlag, defaults.Metadata, "Include metadata (issues, pull requests, ...) in the migration archive")
+	cmd.PersistentFlags().Bool(attachmentsFlag, defaults.Attachments, "Include attachments in the migration archive")
+	cmd.PersistentFlags().Bool(releasesFlag, defaults.Releases, "Include release assets in the migration archive")
+	cmd.PersistentFlags().Bool(ownerProjectsFlag, defaults.OwnerProjects, "Include projects owned by the organization in the migration archive")
+	cmd.PersistentFlags().Bool(lockRepositoriesFlag, defaults.LockRepositories, "Lock the repositories while the migration is running")
+	cmd.PersistentFlags().Int(batchMaxReposFlag, 0, "Maximum number of repositories per migration, splits the backup in several archives")
+	cmd.PersistentFlags().String(batchMaxSizeFlag, "", "Maximum total size of the repositories per migration (e.g. 10GB), splits the backup in several archives")
+	cmd.PersistentFlags().Bool(splitReposFlag, false, "Also save an archive per repository, so that a repository can be restored without downloading the whole backup")
+	cmd.PersistentFlags().Int(fullIntervalFlag, 0, "Days between full backups, the backups in between only save the repositories changed since the full backup (0 makes every backup full)")
+	cmd.PersistentFlags().String(reportFlag, "", "Write a JSON report of the run to this file (e.g. report.json)")
+	cmd.PersistentFlags().Bool(uploadReportFlag, false, "Also save the report of each organization next to its backup")
+	cmd.PersistentFlags().String(pushgatewayFlag, "", "Push the Prometheus metrics of the run to this Pushgateway (e.g. http://pushgateway:9091)")
+	cmd.PersistentFlags().String(metricsTextfileFlag, "", "Write the Prometheus metrics of the run to this file for the textfile collector of the node exporter")
+	cmd.PersistentFlags().Bool(resumeFlag, false, "Resume the migrations of an interrupted backup instead of starting new ones")
+	cmd.PersistentFlags().Int(concurrencyFlag, uc.DefaultConcurrency, "Number of organizations backed up at the same time")
+	addRepositoryFilterFlags(cmd.PersistentFlags())
+
 	cmd.AddCommand(LocalBackupCommand())
 	cmd.AddCommand(RemoteBackupCommand())
 
@@ -32,6 +85,9 @@ func LocalBackupCommand() *cobra.Command {
 		RunE:  runLocalBackupCommand,
 	}
 
+	cmd.Flags().String(dirFlag, "", "Directory the backups are written to (defaults to FILESYSTEM_STORAGE_ROOT or the current directory)")
+	cmd.Flags().Bool(pruneFlag, false, "Delete the backups which are not kept by the retention policy once the backup is completed")
+
 	return cmd
 }
 
@@ -42,13 +98,39 @@ func RemoteBackupCommand() *cobra.Command {
 		RunE:  runRemoteBackupCommand,
 	}
 
+	cmd.Flags().Bool(pruneFlag, false, "Delete the backups which are not kept by the retention policy once the backup is completed")
+
 	return cmd
 }
 
-func runLocalBackupCommand(_ *cobra.Command, _ []string) error {
+func runLocalBackupCommand(cmd *cobra.Command, _ []string) error {
+	return runBackup(cmd, "local", func(cfg *config.Config) (storage.BlobRepository, error) {
+		dir, err := cmd.Flags().GetString(dirFlag)
+		if err != nil {
+			return nil, err
+		}
+
+		if dir == "" {
+			dir = cfg.FilesystemStorageConfig.Root
+		}
+		if dir == "" {
+			dir = "."
+		}
+
+		return filesystem.NewBlobRepository(dir), nil
+	})
+}
+
+func runRemoteBackupCommand(cmd *cobra.Command, _ []string) error {
+	return runBackup(cmd, "remote", appContext.NewBlobRepository)
+}
+
+// runBackup backs up every organization to the blob repository, local backups are written to the filesystem backend
+// so that they share the naming, the manifests and the retention of remote backups
+func runBackup(cmd *cobra.Command, backupType string, newBlobRepository func(cfg *config.Config) (storage.BlobRepository, error)) error {
 	ctx := context.Background()
 	logger := logging.NewLogger(ctx).With(
-		slog.String("backupType", "local"),
+		slog.String("backupType", backupType),
 	)
 
 	cfg, err := getConfig()
@@ -57,78 +139,401 @@ func runLocalBackupCommand(_ *cobra.Command, _ []string) error {
 		return err
 	}
 
-	logger = logger.With(slog.String("organization", cfg.Organization))
-
-	createBackupUseCase, err := getCreateBackupUseCase(cfg)
+	contents, err := getMigrationContents(cmd, cfg)
 	if err != nil {
-		logger.Error("could not create backup use case", slog.Any("error", err))
+		logger.Error("invalid migration contents", slog.Any("error", err))
 		return err
 	}
 
-	usecase := uc.NewCreateLocalBackupUseCase(createBackupUseCase)
+	logger = logger.With(
+		slog.Any("organizations", cfg.Organizations),
+		slog.Any("migrationContents", contents),
+	)
 
-	archivePath, err := usecase.Do(ctx, cfg.Organization, "archive.tar.gz")
+	blobRepository, err := newBlobRepository(cfg)
 	if err != nil {
-		logger.Error("could not create local backup", slog.Any("error", err))
+		logger.Error("could not get blob repository", slog.Any("error", err))
 		return err
 	}
 
-	logger.With(slog.String("backupURL", archivePath)).Info("backup completed successfully")
-
-	return nil
-}
-
-func runRemoteBackupCommand(_ *cobra.Command, _ []string) error {
-	ctx := context.Background()
-	logger := logging.NewLogger(ctx).With(
-		slog.String("backupType", "remote"),
-	)
-
-	cfg, err := getConfig()
+	reportPath, err := cmd.Flags().GetString(reportFlag)
+	if err != nil {
+		return err
+	}
+	uploadReport, err := cmd.Flags().GetBool(uploadReportFlag)
 	if err != nil {
-		logger.Error("could not get config", slog.Any("error", err))
 		return err
 	}
 
-	logger = logger.With(slog.String("organization", cfg.Organization))
+	destinations := cfg.StorageBackends
+	if backupType == "local" {
+		destinations = []string{config.StorageBackendFilesystem}
+	}
+	recorder := uc.NewRunReportRecorder(destinations)
 
-	blobRepository, err := appContext.NewBlobRepository(cfg)
+	createBackupUseCase, err := getCreateBackupUseCase(cmd, cfg, uc.NewBlobMigrationStateStore(blobRepository), recorder)
 	if err != nil {
-		logger.Error("could not get blob repository", slog.Any("error", err))
+		logger.Error("could not create backup use case", slog.Any("error", err))
 		return err
 	}
 
-	createBackupUseCase, err := getCreateBackupUseCase(cfg)
+	encryptor, err := appContext.NewEncryptor(cfg)
 	if err != nil {
-		logger.Error("could not create backup use case", slog.Any("error", err))
+		logger.Error("could not create encryptor", slog.Any("error", err))
 		return err
 	}
 
-	usecase := uc.NewCreateRemoteBackupUseCase(blobRepository, createBackupUseCase)
+	splitRepositories := cfg.SplitRepositories
+	if cmd.Flags().Changed(splitReposFlag) {
+		splitRepositories, err = cmd.Flags().GetBool(splitReposFlag)
+		if err != nil {
+			return err
+		}
+	}
+
+	fullIntervalDays := cfg.FullBackupIntervalDays
+	if cmd.Flags().Changed(fullIntervalFlag) {
+		fullIntervalDays, err = cmd.Flags().GetInt(fullIntervalFlag)
+		if err != nil {
+			return err
+		}
+		if fullIntervalDays < 0 {
+			return fmt.Errorf("invalid --%s %d, it must be positive", fullIntervalFlag, fullIntervalDays)
+		}
+	}
+
+	catalog := uc.NewBlobCatalogStore(blobRepository)
+	usecase := uc.NewCreateRemoteBackupUseCase(blobRepository, createBackupUseCase).
+		WithEncryptor(encryptor).
+		WithCatalog(catalog).
+		WithSplitRepositories(splitRepositories).
+		WithIncremental(fullIntervalDays).
+		WithRecorder(recorder)
+
+	pruneAfterBackup := cfg.RetentionConfig.PruneAfterBackup
+	if cmd.Flags().Changed(pruneFlag) {
+		pruneAfterBackup, err = cmd.Flags().GetBool(pruneFlag)
+		if err != nil {
+			return err
+		}
+	}
+
+	var policy uc.RetentionPolicy
+	if pruneAfterBackup {
+		policy, err = getRetentionPolicy(cmd, cfg)
+		if err != nil {
+			logger.Error("invalid retention policy", slog.Any("error", err))
+			return err
+		}
+	}
+
+	backup := func(ctx context.Context, organization string) (string, error) {
+		logger := logger.With(slog.String("organization", organization))
+
+		backupUrl, err := usecase.Do(ctx, organization, contents)
+		if err != nil {
+			logger.Error("could not create backup", slog.Any("error", err))
+			return "", err
+		}
+
+		logger.With(
+			slog.String("backupURL", backupUrl),
+		).Info("backup completed successfully")
+
+		if !pruneAfterBackup {
+			return backupUrl, nil
+		}
+
+		report, err := uc.NewPruneBackupsUseCase(blobRepository).WithCatalog(catalog).Do(ctx, organization, policy, false)
+		if err != nil {
+			logger.Error("could not prune backups", slog.Any("error", err))
+			return backupUrl, err
+		}
+
+		logger.With(
+			slog.Int("kept", len(report.Kept)),
+			slog.Int("deleted", len(report.Deleted)),
+		).Info("prune completed successfully")
+
+		return backupUrl, nil
+	}
+
+	err = backupOrganizations(ctx, cmd, cfg, func(ctx context.Context, organization string) (string, error) {
+		recorder.StartOrganization(organization)
+		backupUrl, err := backup(ctx, organization)
+		report := recorder.FinishOrganization(organization, backupUrl, err)
+
+		if uploadReport {
+			if _, uploadErr := uc.SaveOrganizationReport(ctx, blobRepository, report); uploadErr != nil {
+				logger.Error("could not save run report", slog.String("organization", organization), slog.Any("error", uploadErr))
+				if err == nil {
+					err = uploadErr
+				}
+			}
+		}
 
-	remoteUrl, err := usecase.Do(ctx, cfg.Organization)
+		return backupUrl, err
+	})
+
+	runReport := recorder.Report(cfg.Organizations)
+
+	if reportPath != "" {
+		if writeErr := writeRunReport(reportPath, runReport); writeErr != nil {
+			logger.Error("could not write run report", slog.Any("error", writeErr))
+			if err == nil {
+				err = writeErr
+			}
+		}
+	}
+
+	if metricsErr := exportMetrics(ctx, cmd, cfg, runReport); metricsErr != nil {
+		logger.Error("could not export metrics", slog.Any("error", metricsErr))
+		if err == nil {
+			err = metricsErr
+		}
+	}
+
+	return err
+}
+
+// exportMetrics pushes the Prometheus metrics of the run to the Pushgateway and writes them to the textfile, when
+// they are configured
+func exportMetrics(ctx context.Context, cmd *cobra.Command, cfg *config.Config, report uc.RunReport) error {
+	metricsConfig := cfg.MetricsConfig
+
+	var err error
+	if cmd.Flags().Changed(pushgatewayFlag) {
+		metricsConfig.PushgatewayURL, err = cmd.Flags().GetString(pushgatewayFlag)
+		if err != nil {
+			return err
+		}
+	}
+	if cmd.Flags().Changed(metricsTextfileFlag) {
+		metricsConfig.TextfilePath, err = cmd.Flags().GetString(metricsTextfileFlag)
+		if err != nil {
+			return err
+		}
+	}
+
+	job := metricsConfig.Job
+	if job == "" {
+		job = metrics.DefaultJob
+	}
+
+	run := newMetricsRun(report)
+
+	var errs []error
+	if metricsConfig.PushgatewayURL != "" {
+		errs = append(errs, metrics.Push(ctx, metricsConfig.PushgatewayURL, job, run))
+	}
+	if metricsConfig.TextfilePath != "" {
+		errs = append(errs, metrics.WriteTextfile(metricsConfig.TextfilePath, run))
+	}
+
+	return errors.Join(errs...)
+}
+
+// newMetricsRun returns the metrics of the run from its report
+func newMetricsRun(report uc.RunReport) metrics.Run {
+	phases := []string{uc.PhaseList, uc.PhaseMigrate, uc.PhaseDownload, uc.PhaseUpload}
+	run := metrics.Run{Stages: append(phases, uc.PhaseOther)}
+
+	for _, organization := range report.Organizations {
+		backup := metrics.Backup{
+			Organization:         organization.Organization,
+			Succeeded:            organization.Status != uc.ReportStatusFailed,
+			FailedStage:          organization.FailedStage,
+			StartedAt:            organization.StartedAt,
+			FinishedAt:           organization.FinishedAt,
+			PhaseDurations:       map[string]float64{},
+			Repositories:         len(organization.Repositories),
+			ExcludedRepositories: len(organization.ExcludedRepositories),
+		}
+		for _, phase := range phases {
+			backup.PhaseDurations[phase] = organization.PhaseDurations[phase]
+		}
+		for _, archive := range organization.Archives {
+			backup.TransferredBytes += archive.Size
+		}
+
+		run.Backups = append(run.Backups, backup)
+	}
+
+	return run
+}
+
+// writeRunReport writes the report of the run to the file as indented JSON
+func writeRunReport(path string, report uc.RunReport) error {
+	file, err := os.Create(path)
 	if err != nil {
-		logger.Error("could not create remote backup", slog.Any("error", err))
-		return err
+		return fmt.Errorf("failed to create run report: %w", err)
 	}
 
-	logger.With(
-		slog.String("backupURL", remoteUrl),
-	).Info("backup completed successfully")
+	if err := writeJSON(file, report); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write run report: %w", err)
+	}
 
-	return nil
+	return file.Close()
 }
 
-func getCreateBackupUseCase(cfg *config.Config) (uc.CreateBackupUseCase, error) {
+// backupOrganizations runs the backup of every organization and prints a summary of the results
+func backupOrganizations(ctx context.Context, cmd *cobra.Command, cfg *config.Config, backup uc.OrganizationBackupFunc) error {
+	concurrency := cfg.BackupConcurrency
+	if cmd.Flags().Changed(concurrencyFlag) {
+		var err error
+		concurrency, err = cmd.Flags().GetInt(concurrencyFlag)
+		if err != nil {
+			return err
+		}
+		if concurrency < 1 {
+			return fmt.Errorf("--%s must be at least 1", concurrencyFlag)
+		}
+	}
+
+	results, err := uc.NewBackupOrganizationsUseCase(concurrency).Do(ctx, cfg.Organizations, backup)
+
+	writer := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "ORGANIZATION\tSTATUS\tDURATION\tBACKUP")
+	for _, result := range results {
+		status, detail := "ok", result.Location
+		if result.Err != nil {
+			status, detail = "failed", result.Err.Error()
+		}
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", result.Organization, status, result.Duration.Round(time.Second), detail)
+	}
+	_ = writer.Flush()
+
+	return err
+}
+
+func getCreateBackupUseCase(cmd *cobra.Command, cfg *config.Config, stateStore uc.MigrationStateStore, recorder uc.RunRecorder) (uc.CreateBackupUseCase, error) {
+	backupMode, err := getBackupMode(cmd, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	resume, err := cmd.Flags().GetBool(resumeFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	batchOptions, err := getBatchOptions(cmd, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := getRepositoryFilter(cmd, cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	ghClient, err := appContext.GetGithubClient(cfg)
 	if err != nil {
 		return nil, err
 	}
-	githubClient := github.NewClient(ghClient)
+	githubClient := github.NewRateLimitedClient(github.NewClient(ghClient), github.RateLimitOptions{})
+	listReposUseCase := uc.NewListRepositoriesUseCase(githubClient).WithFilter(filter).WithRecorder(recorder)
+
+	if backupMode == config.BackupModeMirror {
+		gitClient, err := appContext.GetGitClient(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		return uc.NewCreateMirrorBackupUseCase(
+			gitClient,
+			listReposUseCase,
+		).WithBatchOptions(batchOptions).
+			WithRecorder(recorder), nil
+	}
+
+	// The archives are downloaded from the GitHub server, which may be behind an internal CA
+	httpClient, err := appContext.GetGithubHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	return uc.NewCreateBackupUseCase(
 		githubClient,
-		uc.NewListPrivateReposUseCase(githubClient),
+		listReposUseCase,
 		uc.NewGetOrganizationArchiveUrlUseCase(githubClient),
-	), nil
+	).WithBatchOptions(batchOptions).
+		WithMigrationState(stateStore, resume).
+		WithDownloader(download.NewDownloader(httpClient, download.Options{})).
+		WithRecorder(recorder), nil
+}
+
+// getBackupMode returns the configured backup mode, overridden by the flag set on the command line
+func getBackupMode(cmd *cobra.Command, cfg *config.Config) (string, error) {
+	if !cmd.Flags().Changed(modeFlag) {
+		return cfg.BackupMode, nil
+	}
+
+	backupMode, err := cmd.Flags().GetString(modeFlag)
+	if err != nil {
+		return "", err
+	}
+
+	return backupMode, config.ValidateBackupMode(backupMode)
+}
+
+// getMigrationContents returns the configured migration contents, overridden by the flags set on the command line
+func getMigrationContents(cmd *cobra.Command, cfg *config.Config) (github.MigrationContents, error) {
+	contents := cfg.MigrationContents
+
+	flags := map[string]*bool{
+		gitDataFlag:          &contents.GitData,
+		metadataFlag:         &contents.Metadata,
+		attachmentsFlag:      &contents.Attachments,
+		releasesFlag:         &contents.Releases,
+		ownerProjectsFlag:    &contents.OwnerProjects,
+		lockRepositoriesFlag: &contents.LockRepositories,
+	}
+
+	for name, value := range flags {
+		if !cmd.Flags().Changed(name) {
+			continue
+		}
+
+		flagValue, err := cmd.Flags().GetBool(name)
+		if err != nil {
+			return contents, err
+		}
+		*value = flagValue
+	}
+
+	return contents, contents.Validate()
+}
+
+// getBatchOptions returns the configured batch options, overridden by the flags set on the command line
+func getBatchOptions(cmd *cobra.Command, cfg *config.Config) (uc.BatchOptions, error) {
+	options := uc.BatchOptions{
+		MaxRepositories: cfg.BatchConfig.MaxRepositories,
+		MaxSize:         cfg.BatchConfig.MaxSize,
+	}
+
+	if cmd.Flags().Changed(batchMaxReposFlag) {
+		maxRepositories, err := cmd.Flags().GetInt(batchMaxReposFlag)
+		if err != nil {
+			return options, err
+		}
+		if maxRepositories < 0 {
+			return options, fmt.Errorf("--%s must be positive", batchMaxReposFlag)
+		}
+		options.MaxRepositories = maxRepositories
+	}
+
+	if cmd.Flags().Changed(batchMaxSizeFlag) {
+		value, err := cmd.Flags().GetString(batchMaxSizeFlag)
+		if err != nil {
+			return options, err
+		}
+		options.MaxSize, err = humanize.ParseBytes(value)
+		if err != nil {
+			return options, fmt.Errorf("invalid --%s: %w", batchMaxSizeFlag, err)
+		}
+	}
+
+	return options, nil
 }
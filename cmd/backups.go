@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	appContext "github.com/kumojin/repo-backup-cli/context"
+	"github.com/kumojin/repo-backup-cli/pkg/logging"
+	"github.com/kumojin/repo-backup-cli/pkg/uc"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+const (
+	formatFlag = "format"
+
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+func BackupsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backups",
+		Short: "Commands to read the catalog of the backups saved in the storage backend",
+	}
+
+	cmd.PersistentFlags().String(formatFlag, formatTable, fmt.Sprintf("Output format: %s or %s", formatTable, formatJSON))
+
+	cmd.AddCommand(ListBackupsCommand())
+	cmd.AddCommand(ShowBackupCommand())
+
+	return cmd
+}
+
+func ListBackupsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the backups of the organizations recorded in the catalog, oldest first",
+		Args:  cobra.NoArgs,
+		RunE:  runListBackupsCommand,
+	}
+}
+
+func ShowBackupCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <id>",
+		Short: "Show a backup recorded in the catalog, its ID is the name of the archive",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runShowBackupCommand,
+	}
+}
+
+func runListBackupsCommand(cmd *cobra.Command, _ []string) error {
+	ctx := context.Background()
+	logger := logging.NewLogger(ctx)
+
+	format, err := getFormat(cmd)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := getConfig()
+	if err != nil {
+		logger.Error("could not get config", slog.Any("error", err))
+		return err
+	}
+
+	catalog, err := getCatalogStore()
+	if err != nil {
+		logger.Error("could not get catalog", slog.Any("error", err))
+		return err
+	}
+
+	entries, err := catalog.List(ctx)
+	if err != nil {
+		logger.Error("could not list backups", slog.Any("error", err))
+		return err
+	}
+
+	entries = slices.DeleteFunc(entries, func(entry uc.CatalogEntry) bool {
+		return !slices.Contains(cfg.Organizations, entry.Organization)
+	})
+
+	if format == formatJSON {
+		// An empty catalog is printed as an empty list rather than null
+		if entries == nil {
+			entries = []uc.CatalogEntry{}
+		}
+		return writeJSON(cmd.OutOrStdout(), entries)
+	}
+
+	writer := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "ID\tORGANIZATION\tCREATED\tREPOSITORIES\tSIZE")
+	for _, entry := range entries {
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t%d\t%s\n",
+			entry.ID,
+			entry.Organization,
+			entry.CreatedAt.Format(time.RFC3339),
+			len(entry.Repositories),
+			humanize.Bytes(uint64(entry.Size)),
+		)
+	}
+
+	return writer.Flush()
+}
+
+func runShowBackupCommand(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	logger := logging.NewLogger(ctx).With(slog.String("id", args[0]))
+
+	format, err := getFormat(cmd)
+	if err != nil {
+		return err
+	}
+
+	catalog, err := getCatalogStore()
+	if err != nil {
+		logger.Error("could not get catalog", slog.Any("error", err))
+		return err
+	}
+
+	entry, err := catalog.Get(ctx, args[0])
+	if err != nil {
+		logger.Error("could not get backup", slog.Any("error", err))
+		return err
+	}
+
+	if format == formatJSON {
+		return writeJSON(cmd.OutOrStdout(), entry)
+	}
+
+	writer := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(writer, "ID:\t%s\n", entry.ID)
+	_, _ = fmt.Fprintf(writer, "Organization:\t%s\n", entry.Organization)
+	_, _ = fmt.Fprintf(writer, "Created:\t%s\n", entry.CreatedAt.Format(time.RFC3339))
+	if entry.MigrationID != 0 {
+		_, _ = fmt.Fprintf(writer, "Migration ID:\t%d\n", entry.MigrationID)
+	}
+	if entry.Type != "" {
+		_, _ = fmt.Fprintf(writer, "Type:\t%s\n", entry.Type)
+	}
+	if entry.Base != "" {
+		_, _ = fmt.Fprintf(writer, "Base:\t%s\n", entry.Base)
+	}
+	_, _ = fmt.Fprintf(writer, "Size:\t%s (%d bytes)\n", humanize.Bytes(uint64(entry.Size)), entry.Size)
+	_, _ = fmt.Fprintf(writer, "SHA-256:\t%s\n", entry.SHA256)
+	_, _ = fmt.Fprintf(writer, "Location:\t%s\n", entry.Location)
+	if entry.ToolVersion != "" {
+		_, _ = fmt.Fprintf(writer, "Tool version:\t%s\n", entry.ToolVersion)
+	}
+	_, _ = fmt.Fprintf(writer, "Repositories:\t%s\n", strings.Join(entry.Repositories, ", "))
+
+	return writer.Flush()
+}
+
+func getFormat(cmd *cobra.Command) (string, error) {
+	format, err := cmd.Flags().GetString(formatFlag)
+	if err != nil {
+		return "", err
+	}
+
+	if format != formatTable && format != formatJSON {
+		return "", fmt.Errorf("invalid --%s %q (supported: %s, %s)", formatFlag, format, formatTable, formatJSON)
+	}
+
+	return format, nil
+}
+
+func getCatalogStore() (uc.CatalogStore, error) {
+	cfg, err := getConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	blobRepository, err := appContext.NewBlobRepository(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.NewBlobCatalogStore(blobRepository), nil
+}
+
+func writeJSON(out io.Writer, value any) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(value)
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kumojin/repo-backup-cli/pkg/encryption"
+	"github.com/kumojin/repo-backup-cli/pkg/logging"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	identityFlag = "identity"
+	outputFlag   = "output"
+)
+
+func DecryptCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "decrypt",
+		Short: "Decrypt a backup archive encrypted with age (.age) or OpenPGP (.gpg)",
+		RunE:  runDecryptCommand,
+	}
+
+	cmd.Flags().String(archiveFlag, "", "Path of the encrypted backup archive")
+	cmd.Flags().String(blobFlag, "", "Name of the encrypted backup archive in the configured storage backend")
+	cmd.Flags().String(identityFlag, "", "Path of the age identity file or of the armored OpenPGP private key")
+	cmd.Flags().String(outputFlag, "", "Path of the decrypted archive, defaults to the name of the archive without its encryption extension")
+	cmd.MarkFlagsOneRequired(archiveFlag, blobFlag)
+	cmd.MarkFlagsMutuallyExclusive(archiveFlag, blobFlag)
+	_ = cmd.MarkFlagRequired(identityFlag)
+
+	return cmd
+}
+
+func runDecryptCommand(cmd *cobra.Command, _ []string) error {
+	ctx := context.Background()
+	logger := logging.NewLogger(ctx)
+
+	cfg, err := getConfig()
+	if err != nil {
+		logger.Error("could not get config", slog.Any("error", err))
+		return err
+	}
+
+	archiveName, err := getArchiveName(cmd)
+	if err != nil {
+		return err
+	}
+
+	format, err := encryption.Format(archiveName)
+	if err != nil {
+		logger.Error("could not detect encryption format", slog.String("archive", archiveName), slog.Any("error", err))
+		return err
+	}
+
+	outputPath, err := cmd.Flags().GetString(outputFlag)
+	if err != nil {
+		return err
+	}
+	if outputPath == "" {
+		outputPath = filepath.Base(strings.TrimSuffix(archiveName, format))
+	}
+
+	logger = logger.With(
+		slog.String("archive", archiveName),
+		slog.String("output", outputPath),
+	)
+
+	identityPath, err := cmd.Flags().GetString(identityFlag)
+	if err != nil {
+		return err
+	}
+
+	identity, err := os.Open(identityPath)
+	if err != nil {
+		logger.Error("could not open identity", slog.Any("error", err))
+		return err
+	}
+	defer func() { _ = identity.Close() }()
+
+	decryptor, err := encryption.NewDecryptor(format, identity, []byte(cfg.EncryptionConfig.PGPPassphrase))
+	if err != nil {
+		logger.Error("could not read identity", slog.Any("error", err))
+		return err
+	}
+
+	reader, err := openArchive(ctx, cmd, cfg)
+	if err != nil {
+		logger.Error("could not open archive", slog.Any("error", err))
+		return err
+	}
+	defer func() { _ = reader.Close() }()
+
+	if err := decryptToFile(decryptor, reader, outputPath); err != nil {
+		logger.Error("could not decrypt archive", slog.Any("error", err))
+		return err
+	}
+
+	logger.Info("archive decrypted successfully")
+
+	return nil
+}
+
+// getArchiveName returns the path or the blob name of the archive given on the command line
+func getArchiveName(cmd *cobra.Command) (string, error) {
+	archivePath, err := cmd.Flags().GetString(archiveFlag)
+	if err != nil || archivePath != "" {
+		return archivePath, err
+	}
+
+	return cmd.Flags().GetString(blobFlag)
+}
+
+// decryptToFile writes the decrypted archive to a temporary file renamed to outputPath once complete, so that a
+// failed decryption never leaves a truncated archive behind
+func decryptToFile(decryptor encryption.Decryptor, reader io.Reader, outputPath string) error {
+	decrypted, err := decryptor.Decrypt(reader)
+	if err != nil {
+		return err
+	}
+
+	out, err := os.CreateTemp(filepath.Dir(outputPath), filepath.Base(outputPath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(out.Name()) }()
+
+	if _, err := io.Copy(out, decrypted); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("failed to decrypt archive: %w", err)
+	}
+
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(out.Name(), outputPath)
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/kumojin/repo-backup-cli/pkg/config"
+	"github.com/kumojin/repo-backup-cli/pkg/github"
+	"github.com/kumojin/repo-backup-cli/pkg/uc"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	includeFlag         = "include"
+	excludeFlag         = "exclude"
+	includeRegexFlag    = "include-regex"
+	excludeRegexFlag    = "exclude-regex"
+	includeTopicFlag    = "include-topic"
+	excludeTopicFlag    = "exclude-topic"
+	visibilityFlag      = "visibility"
+	includeArchivedFlag = "include-archived"
+	excludeForksFlag    = "exclude-forks"
+	maxSizeFlag         = "max-size"
+	pushedSinceFlag     = "pushed-since"
+)
+
+func addRepositoryFilterFlags(flags *pflag.FlagSet) {
+	flags.StringSlice(includeFlag, nil, "Only select the repositories whose name matches one of the globs (e.g. api-*)")
+	flags.StringSlice(excludeFlag, nil, "Skip the repositories whose name matches one of the globs")
+	flags.String(includeRegexFlag, "", "Only select the repositories whose name matches the regular expression, in addition to --include")
+	flags.String(excludeRegexFlag, "", "Skip the repositories whose name matches the regular expression")
+	flags.StringSlice(includeTopicFlag, nil, "Only select the repositories having one of the topics")
+	flags.StringSlice(excludeTopicFlag, nil, "Skip the repositories having one of the topics")
+	flags.String(visibilityFlag, github.VisibilityPrivate, fmt.Sprintf("Visibility of the repositories: %s, %s, %s or %s", github.VisibilityPrivate, github.VisibilityInternal, github.VisibilityPublic, github.VisibilityAll))
+	flags.Bool(includeArchivedFlag, false, "Also select the archived repositories")
+	flags.Bool(excludeForksFlag, false, "Skip the forks")
+	flags.String(maxSizeFlag, "", "Skip the repositories larger than this size (e.g. 5GB), based on the size reported by GitHub")
+	flags.String(pushedSinceFlag, "", "Skip the repositories not pushed to since a date (2006-01-02) or for an age (90d, 12h)")
+}
+
+// getRepositoryFilter returns the configured repository filter, overridden by the flags set on the command line
+func getRepositoryFilter(cmd *cobra.Command, cfg *config.Config) (uc.RepositoryFilter, error) {
+	filterConfig := cfg.FilterConfig
+	flags := cmd.Flags()
+
+	stringSliceFlags := map[string]*[]string{
+		includeFlag:      &filterConfig.Include,
+		excludeFlag:      &filterConfig.Exclude,
+		includeTopicFlag: &filterConfig.IncludeTopics,
+		excludeTopicFlag: &filterConfig.ExcludeTopics,
+	}
+	for name, value := range stringSliceFlags {
+		if !flags.Changed(name) {
+			continue
+		}
+		flagValue, err := flags.GetStringSlice(name)
+		if err != nil {
+			return uc.RepositoryFilter{}, err
+		}
+		*value = flagValue
+	}
+
+	stringFlags := map[string]*string{
+		includeRegexFlag: &filterConfig.IncludeRegex,
+		excludeRegexFlag: &filterConfig.ExcludeRegex,
+		visibilityFlag:   &filterConfig.Visibility,
+		pushedSinceFlag:  &filterConfig.PushedSince,
+	}
+	for name, value := range stringFlags {
+		if !flags.Changed(name) {
+			continue
+		}
+		flagValue, err := flags.GetString(name)
+		if err != nil {
+			return uc.RepositoryFilter{}, err
+		}
+		*value = flagValue
+	}
+
+	boolFlags := map[string]*bool{
+		includeArchivedFlag: &filterConfig.IncludeArchived,
+		excludeForksFlag:    &filterConfig.ExcludeForks,
+	}
+	for name, value := range boolFlags {
+		if !flags.Changed(name) {
+			continue
+		}
+		flagValue, err := flags.GetBool(name)
+		if err != nil {
+			return uc.RepositoryFilter{}, err
+		}
+		*value = flagValue
+	}
+
+	if flags.Changed(maxSizeFlag) {
+		value, err := flags.GetString(maxSizeFlag)
+		if err != nil {
+			return uc.RepositoryFilter{}, err
+		}
+		filterConfig.MaxSize, err = humanize.ParseBytes(value)
+		if err != nil {
+			return uc.RepositoryFilter{}, fmt.Errorf("invalid --%s: %w", maxSizeFlag, err)
+		}
+	}
+
+	includeRegex, err := compileOptionalRegex(filterConfig.IncludeRegex)
+	if err != nil {
+		return uc.RepositoryFilter{}, fmt.Errorf("invalid include regular expression: %w", err)
+	}
+	excludeRegex, err := compileOptionalRegex(filterConfig.ExcludeRegex)
+	if err != nil {
+		return uc.RepositoryFilter{}, fmt.Errorf("invalid exclude regular expression: %w", err)
+	}
+
+	pushedSince, err := config.ParsePushedSince(filterConfig.PushedSince, time.Now())
+	if err != nil {
+		return uc.RepositoryFilter{}, fmt.Errorf("invalid pushed since: %w", err)
+	}
+
+	filter := uc.RepositoryFilter{
+		Include:         filterConfig.Include,
+		IncludeRegex:    includeRegex,
+		Exclude:         filterConfig.Exclude,
+		ExcludeRegex:    excludeRegex,
+		IncludeTopics:   filterConfig.IncludeTopics,
+		ExcludeTopics:   filterConfig.ExcludeTopics,
+		Visibility:      filterConfig.Visibility,
+		IncludeArchived: filterConfig.IncludeArchived,
+		ExcludeForks:    filterConfig.ExcludeForks,
+		MaxSize:         filterConfig.MaxSize,
+		PushedSince:     pushedSince,
+	}
+
+	return filter, filter.Validate()
+}
+
+func compileOptionalRegex(value string) (*regexp.Regexp, error) {
+	if value == "" {
+		return nil, nil
+	}
+	return regexp.Compile(value)
+}
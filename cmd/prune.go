@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	appContext "github.com/kumojin/repo-backup-cli/context"
+	"github.com/kumojin/repo-backup-cli/pkg/config"
+	"github.com/kumojin/repo-backup-cli/pkg/logging"
+	"github.com/kumojin/repo-backup-cli/pkg/uc"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	keepDailyFlag   = "keep-daily"
+	keepWeeklyFlag  = "keep-weekly"
+	keepMonthlyFlag = "keep-monthly"
+	keepYearlyFlag  = "keep-yearly"
+)
+
+func PruneCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Delete the remote backups of the organizations which are not kept by the retention policy",
+		RunE:  runPruneCommand,
+	}
+
+	cmd.Flags().Int(keepDailyFlag, 0, "Number of daily backups to keep")
+	cmd.Flags().Int(keepWeeklyFlag, 0, "Number of weekly backups to keep")
+	cmd.Flags().Int(keepMonthlyFlag, 0, "Number of monthly backups to keep")
+	cmd.Flags().Int(keepYearlyFlag, 0, "Number of yearly backups to keep")
+	cmd.Flags().Bool(dryRunFlag, false, "Print the backups which would be deleted without deleting them")
+
+	return cmd
+}
+
+func runPruneCommand(cmd *cobra.Command, _ []string) error {
+	ctx := context.Background()
+	logger := logging.NewLogger(ctx)
+
+	cfg, err := getConfig()
+	if err != nil {
+		logger.Error("could not get config", slog.Any("error", err))
+		return err
+	}
+
+	policy, err := getRetentionPolicy(cmd, cfg)
+	if err != nil {
+		logger.Error("invalid retention policy", slog.Any("error", err))
+		return err
+	}
+
+	dryRun, err := cmd.Flags().GetBool(dryRunFlag)
+	if err != nil {
+		return err
+	}
+
+	logger = logger.With(
+		slog.Bool("dryRun", dryRun),
+	)
+
+	blobRepository, err := appContext.NewBlobRepository(cfg)
+	if err != nil {
+		logger.Error("could not get blob repository", slog.Any("error", err))
+		return err
+	}
+
+	usecase := uc.NewPruneBackupsUseCase(blobRepository).WithCatalog(uc.NewBlobCatalogStore(blobRepository))
+
+	for _, organization := range cfg.Organizations {
+		logger := logger.With(slog.String("organization", organization))
+
+		report, err := usecase.Do(ctx, organization, policy, dryRun)
+		if err != nil {
+			logger.Error("could not prune backups", slog.Any("error", err))
+			return err
+		}
+
+		if dryRun {
+			for _, blobName := range report.Kept {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "keep   %s\n", blobName)
+			}
+			for _, blobName := range report.Deleted {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "delete %s\n", blobName)
+			}
+			continue
+		}
+
+		logger.With(
+			slog.Int("kept", len(report.Kept)),
+			slog.Int("deleted", len(report.Deleted)),
+		).Info("prune completed successfully")
+	}
+
+	return nil
+}
+
+// getRetentionPolicy returns the configured retention policy, overridden by the flags set on the command line
+func getRetentionPolicy(cmd *cobra.Command, cfg *config.Config) (uc.RetentionPolicy, error) {
+	policy := uc.RetentionPolicy{
+		Daily:   cfg.RetentionConfig.Daily,
+		Weekly:  cfg.RetentionConfig.Weekly,
+		Monthly: cfg.RetentionConfig.Monthly,
+		Yearly:  cfg.RetentionConfig.Yearly,
+	}
+
+	flags := map[string]*int{
+		keepDailyFlag:   &policy.Daily,
+		keepWeeklyFlag:  &policy.Weekly,
+		keepMonthlyFlag: &policy.Monthly,
+		keepYearlyFlag:  &policy.Yearly,
+	}
+
+	for name, value := range flags {
+		if !cmd.Flags().Changed(name) {
+			continue
+		}
+
+		flagValue, err := cmd.Flags().GetInt(name)
+		if err != nil {
+			return policy, err
+		}
+		if flagValue < 0 {
+			return policy, fmt.Errorf("--%s must be positive", name)
+		}
+		*value = flagValue
+	}
+
+	return policy, nil
+}
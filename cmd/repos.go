@@ -15,10 +15,12 @@ import (
 func ReposCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "repos",
-		Short: "List all repositories in an organization (defaults to `Kumojin` organization)",
+		Short: "List the repositories of an organization selected by the filters (defaults to `Kumojin` organization)",
 		RunE:  runReposCommand,
 	}
 
+	addRepositoryFilterFlags(cmd.Flags())
+
 	return cmd
 }
 
@@ -32,27 +34,36 @@ func runReposCommand(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	logger = logger.With(
-		slog.String("organization", cfg.Organization),
-	)
+	filter, err := getRepositoryFilter(cmd, cfg)
+	if err != nil {
+		logger.Error("invalid repository filter", slog.Any("error", err))
+		return err
+	}
 
 	ghClient, err := appContext.GetGithubClient(cfg)
 	if err != nil {
 		logger.Error("could not get github client", slog.Any("error", err))
 		return err
 	}
-	githubClient := github.NewClient(ghClient)
+	githubClient := github.NewRateLimitedClient(github.NewClient(ghClient), github.RateLimitOptions{})
 
-	usecase := uc.NewListPrivateReposUseCase(githubClient)
+	usecase := uc.NewListRepositoriesUseCase(githubClient).WithFilter(filter)
 
-	repos, err := usecase.Do(ctx, cfg.Organization)
-	if err != nil {
-		logger.Error("could not list repositories", slog.Any("error", err))
-		return err
-	}
+	for _, organization := range cfg.Organizations {
+		repos, err := usecase.Do(ctx, organization)
+		if err != nil {
+			logger.Error("could not list repositories", slog.String("organization", organization), slog.Any("error", err))
+			return err
+		}
 
-	for _, repo := range repos {
-		println(*repo.Name)
+		for _, repo := range repos {
+			// The organization is only printed when several are listed, to keep the output of one organization unchanged
+			if len(cfg.Organizations) > 1 {
+				println(organization + "/" + *repo.Name)
+				continue
+			}
+			println(*repo.Name)
+		}
 	}
 
 	return nil
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	appContext "github.com/kumojin/repo-backup-cli/context"
+	"github.com/kumojin/repo-backup-cli/pkg/config"
+	"github.com/kumojin/repo-backup-cli/pkg/github"
+	"github.com/kumojin/repo-backup-cli/pkg/logging"
+	"github.com/kumojin/repo-backup-cli/pkg/uc"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	archiveFlag = "archive"
+	blobFlag    = "blob"
+	dryRunFlag  = "dry-run"
+)
+
+func RestoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Restore the repositories, labels, milestones and issues of a backup archive into the organization",
+		RunE:  runRestoreCommand,
+	}
+
+	cmd.Flags().String(archiveFlag, "", "Path of the backup archive to restore")
+	cmd.Flags().String(blobFlag, "", "Name of the backup archive to restore from the configured storage backend")
+	cmd.Flags().Bool(dryRunFlag, false, "Print the actions of the restore without performing them")
+	cmd.MarkFlagsOneRequired(archiveFlag, blobFlag)
+	cmd.MarkFlagsMutuallyExclusive(archiveFlag, blobFlag)
+
+	return cmd
+}
+
+func runRestoreCommand(cmd *cobra.Command, _ []string) error {
+	ctx := context.Background()
+	logger := logging.NewLogger(ctx)
+
+	cfg, err := getConfig()
+	if err != nil {
+		logger.Error("could not get config", slog.Any("error", err))
+		return err
+	}
+
+	if len(cfg.Organizations) > 1 {
+		err := errors.New("restore targets a single organization")
+		logger.Error("too many organizations", slog.Any("organizations", cfg.Organizations), slog.Any("error", err))
+		return err
+	}
+
+	dryRun, err := cmd.Flags().GetBool(dryRunFlag)
+	if err != nil {
+		return err
+	}
+
+	logger = logger.With(
+		slog.String("organization", cfg.Organization),
+		slog.Bool("dryRun", dryRun),
+	)
+
+	ghClient, err := appContext.GetGithubClient(cfg)
+	if err != nil {
+		logger.Error("could not get github client", slog.Any("error", err))
+		return err
+	}
+
+	gitClient, err := appContext.GetGitClient(cfg)
+	if err != nil {
+		logger.Error("could not get git client", slog.Any("error", err))
+		return err
+	}
+
+	reader, err := openArchive(ctx, cmd, cfg)
+	if err != nil {
+		logger.Error("could not open archive", slog.Any("error", err))
+		return err
+	}
+	defer func() { _ = reader.Close() }()
+
+	usecase := uc.NewRestoreBackupUseCase(github.NewRateLimitedClient(github.NewClient(ghClient), github.RateLimitOptions{}), gitClient)
+
+	actions, err := usecase.Do(ctx, reader, cfg.Organization, dryRun)
+	if err != nil {
+		logger.Error("could not restore backup", slog.Any("error", err))
+		return err
+	}
+
+	if dryRun {
+		for _, action := range actions {
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), action)
+		}
+		return nil
+	}
+
+	logger.With(slog.Int("actions", len(actions))).Info("restore completed successfully")
+
+	return nil
+}
+
+// openArchive opens the archive given either as a local path or as a blob of the storage backend
+func openArchive(ctx context.Context, cmd *cobra.Command, cfg *config.Config) (io.ReadCloser, error) {
+	return openArchiveFile(ctx, cmd, cfg, "")
+}
+
+// openArchiveFile opens the file stored next to the archive given on the command line, named after it with suffix
+func openArchiveFile(ctx context.Context, cmd *cobra.Command, cfg *config.Config, suffix string) (io.ReadCloser, error) {
+	archivePath, err := cmd.Flags().GetString(archiveFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	if archivePath != "" {
+		return os.Open(archivePath + suffix)
+	}
+
+	blobName, err := cmd.Flags().GetString(blobFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	blobRepository, err := appContext.NewBlobRepository(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return blobRepository.Download(ctx, blobName+suffix)
+}
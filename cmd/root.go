@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"errors"
+
 	"github.com/getsentry/sentry-go"
 	"github.com/kumojin/repo-backup-cli/pkg/config"
 	"github.com/spf13/cobra"
@@ -8,29 +10,29 @@ import (
 
 var (
 	rootConfig     *config.Config
-	organization   string
+	organizations  []string
 	configFilepath string
 )
 
 func RootCommand() (*cobra.Command, error) {
 	cmd := &cobra.Command{
 		Use:               "rbk",
-		Short:             "CLI tool to backup private repositories from one a github organization to a remote file storage service",
+		Short:             "CLI tool to backup private repositories from GitHub organizations to a remote file storage service",
 		SilenceUsage:      true,
 		SilenceErrors:     true,
 		PersistentPreRunE: preRun,
 	}
 
 	cmd.PersistentFlags().StringVarP(&configFilepath, "config", "c", ".env", "Path to environment configuration file")
-	cmd.PersistentFlags().StringVarP(&organization, "organization", "o", "", "GitHub organization to use")
-
-	err := cmd.MarkPersistentFlagRequired("organization")
-	if err != nil {
-		return nil, err
-	}
+	cmd.PersistentFlags().StringSliceVarP(&organizations, "organization", "o", nil, "GitHub organization to use, repeat it to backup several organizations (defaults to ORGANIZATIONS)")
 
 	cmd.AddCommand(ReposCommand())
 	cmd.AddCommand(BackupCommand())
+	cmd.AddCommand(BackupsCommand())
+	cmd.AddCommand(RestoreCommand())
+	cmd.AddCommand(PruneCommand())
+	cmd.AddCommand(DecryptCommand())
+	cmd.AddCommand(VerifyCommand())
 
 	return cmd, nil
 }
@@ -57,11 +59,16 @@ func getConfig() (*config.Config, error) {
 		return rootConfig, nil
 	}
 
-	var err error
-	rootConfig, err = config.New(configFilepath)
+	cfg, err := config.New(configFilepath)
 	if err != nil {
 		return nil, err
 	}
 
-	return rootConfig.WithOrganization(organization), nil
+	cfg = cfg.WithOrganizations(organizations)
+	if len(cfg.Organizations) == 0 {
+		return nil, errors.New("an organization must be set with --organization or ORGANIZATIONS")
+	}
+
+	rootConfig = cfg
+	return rootConfig, nil
 }
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/kumojin/repo-backup-cli/pkg/logging"
+	"github.com/kumojin/repo-backup-cli/pkg/uc"
+
+	"github.com/spf13/cobra"
+)
+
+func VerifyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Check a backup archive against the integrity manifest stored next to it",
+		RunE:  runVerifyCommand,
+	}
+
+	cmd.Flags().String(archiveFlag, "", "Path of the backup archive to verify")
+	cmd.Flags().String(blobFlag, "", "Name of the backup archive to verify in the configured storage backend")
+	cmd.MarkFlagsOneRequired(archiveFlag, blobFlag)
+	cmd.MarkFlagsMutuallyExclusive(archiveFlag, blobFlag)
+
+	return cmd
+}
+
+func runVerifyCommand(cmd *cobra.Command, _ []string) error {
+	ctx := context.Background()
+	logger := logging.NewLogger(ctx)
+
+	cfg, err := getConfig()
+	if err != nil {
+		logger.Error("could not get config", slog.Any("error", err))
+		return err
+	}
+
+	archiveName, err := getArchiveName(cmd)
+	if err != nil {
+		return err
+	}
+
+	logger = logger.With(slog.String("archive", archiveName))
+
+	manifest, err := openArchiveFile(ctx, cmd, cfg, uc.IntegrityManifestExtension)
+	if err != nil {
+		logger.Error("could not open integrity manifest", slog.Any("error", err))
+		return err
+	}
+	defer func() { _ = manifest.Close() }()
+
+	archive, err := openArchive(ctx, cmd, cfg)
+	if err != nil {
+		logger.Error("could not open archive", slog.Any("error", err))
+		return err
+	}
+	defer func() { _ = archive.Close() }()
+
+	usecase := uc.NewVerifyBackupUseCase()
+
+	result, err := usecase.Do(ctx, manifest, archive)
+	if err != nil {
+		logger.Error("could not verify archive", slog.Any("error", err))
+		return err
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s: OK (sha256 %s, %d bytes, %d repositories)\n", archiveName, result.SHA256, result.Size, len(result.Repositories))
+
+	return nil
+}
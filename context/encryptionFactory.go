@@ -0,0 +1,28 @@
+package context
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kumojin/repo-backup-cli/pkg/config"
+	"github.com/kumojin/repo-backup-cli/pkg/encryption"
+)
+
+// NewEncryptor returns the encryptor of the configured recipients, or nil when encryption is disabled
+func NewEncryptor(cfg *config.Config) (encryption.Encryptor, error) {
+	if len(cfg.EncryptionConfig.AgeRecipients) > 0 {
+		return encryption.NewAgeEncryptor(cfg.EncryptionConfig.AgeRecipients)
+	}
+
+	if cfg.EncryptionConfig.PGPPublicKeyFile != "" {
+		publicKeys, err := os.Open(cfg.EncryptionConfig.PGPPublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open OpenPGP public keys: %w", err)
+		}
+		defer func() { _ = publicKeys.Close() }()
+
+		return encryption.NewPGPEncryptor(publicKeys)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,34 @@
+package context
+
+import (
+	"context"
+	"fmt"
+
+	cloudstorage "cloud.google.com/go/storage"
+	"github.com/kumojin/repo-backup-cli/pkg/config"
+	"google.golang.org/api/option"
+)
+
+var (
+	gcsClient *cloudstorage.Client
+)
+
+// GetGCSClient authenticates with the service account key when one is configured, otherwise with the application
+// default credentials
+func GetGCSClient(cfg *config.Config) (*cloudstorage.Client, error) {
+	if gcsClient == nil {
+		var options []option.ClientOption
+		if cfg.GCSStorageConfig.CredentialsFile != "" {
+			options = append(options, option.WithAuthCredentialsFile(option.ServiceAccount, cfg.GCSStorageConfig.CredentialsFile))
+		}
+
+		client, err := cloudstorage.NewClient(context.Background(), options...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GCS client: %w", err)
+		}
+
+		gcsClient = client
+	}
+
+	return gcsClient, nil
+}
@@ -0,0 +1,20 @@
+package context
+
+import (
+	"github.com/kumojin/repo-backup-cli/pkg/config"
+	"github.com/kumojin/repo-backup-cli/pkg/git"
+)
+
+// GetGitClient returns a git client authenticated like the GitHub client and trusting the same CA bundle
+func GetGitClient(cfg *config.Config) (git.Client, error) {
+	tokenSource, err := GetGithubTokenSource(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return git.NewClientWithTokenFunc(
+		tokenSource.Token,
+		git.WithCABundleFile(cfg.GitHubServerConfig.CABundleFile),
+		git.WithInsecureSkipVerify(cfg.GitHubServerConfig.InsecureSkipVerify),
+	), nil
+}
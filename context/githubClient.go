@@ -1,17 +1,37 @@
 package context
 
 import (
-	"github.com/google/go-github/v90/github"
+	"net/http"
+
+	gh "github.com/google/go-github/v90/github"
 	"github.com/kumojin/repo-backup-cli/pkg/config"
+	"github.com/kumojin/repo-backup-cli/pkg/github"
 )
 
 var (
-	githubClient *github.Client
+	githubClient      *gh.Client
+	githubTokenSource github.TokenSource
+	githubHTTPClient  *http.Client
 )
 
-func GetGithubClient(cfg *config.Config) (*github.Client, error) {
+func GetGithubClient(cfg *config.Config) (*gh.Client, error) {
 	if githubClient == nil {
-		client, err := github.NewClient(github.WithAuthToken(cfg.GitHubToken))
+		server, err := getGithubServer(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		httpClient, err := GetGithubHTTPClient(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		tokenSource, err := GetGithubTokenSource(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		client, err := github.NewServerClient(server, httpClient, tokenSource)
 		if err != nil {
 			return nil, err
 		}
@@ -20,3 +40,74 @@ func GetGithubClient(cfg *config.Config) (*github.Client, error) {
 
 	return githubClient, nil
 }
+
+// GetGithubTokenSource returns the installation tokens of the GitHub App when one is configured, otherwise the
+// personal access token
+func GetGithubTokenSource(cfg *config.Config) (github.TokenSource, error) {
+	if githubTokenSource == nil {
+		if !cfg.GitHubAppConfig.IsEnabled() {
+			githubTokenSource = github.NewStaticTokenSource(cfg.GitHubToken)
+			return githubTokenSource, nil
+		}
+
+		privateKey, err := cfg.GitHubAppConfig.LoadPrivateKey()
+		if err != nil {
+			return nil, err
+		}
+
+		server, err := getGithubServer(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		httpClient, err := GetGithubHTTPClient(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		tokenSource, err := github.NewAppTokenSource(github.App{
+			ID:             cfg.GitHubAppConfig.AppID,
+			InstallationID: cfg.GitHubAppConfig.InstallationID,
+			PrivateKey:     privateKey,
+		}, server.ClientOptions(httpClient)...)
+		if err != nil {
+			return nil, err
+		}
+		githubTokenSource = tokenSource
+	}
+
+	return githubTokenSource, nil
+}
+
+// GetGithubHTTPClient returns the HTTP client trusting the CA bundle of the GitHub server, it also downloads the
+// migration archives
+func GetGithubHTTPClient(cfg *config.Config) (*http.Client, error) {
+	if githubHTTPClient == nil {
+		server, err := getGithubServer(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		httpClient, err := server.HTTPClient()
+		if err != nil {
+			return nil, err
+		}
+		githubHTTPClient = httpClient
+	}
+
+	return githubHTTPClient, nil
+}
+
+func getGithubServer(cfg *config.Config) (github.Server, error) {
+	caBundle, err := cfg.GitHubServerConfig.LoadCABundle()
+	if err != nil {
+		return github.Server{}, err
+	}
+
+	return github.Server{
+		BaseURL:            cfg.GitHubServerConfig.BaseURL,
+		UploadURL:          cfg.GitHubServerConfig.UploadURL,
+		CABundle:           caBundle,
+		InsecureSkipVerify: cfg.GitHubServerConfig.InsecureSkipVerify,
+	}, nil
+}
@@ -4,19 +4,20 @@ import (
 	"fmt"
 
 	"github.com/kumojin/repo-backup-cli/pkg/config"
-	"github.com/minio/minio-go/v7"
-	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/kumojin/repo-backup-cli/pkg/storage/minio"
+	miniogo "github.com/minio/minio-go/v7"
 )
 
 var (
-	minioClient *minio.Client
+	minioClient *miniogo.Client
 )
 
-func GetMinioClient(cfg *config.Config) (*minio.Client, error) {
+func GetMinioClient(cfg *config.Config) (*miniogo.Client, error) {
 	if minioClient == nil {
-		client, err := minio.New(cfg.ObjectStorageConfig.Endpoint, &minio.Options{
-			Creds:  credentials.NewStaticV4(cfg.ObjectStorageConfig.AccessKey, cfg.ObjectStorageConfig.SecretKey, ""),
+		client, err := miniogo.New(cfg.ObjectStorageConfig.Endpoint, &miniogo.Options{
+			Creds:  minio.NewCredentials(cfg.ObjectStorageConfig.AccessKey, cfg.ObjectStorageConfig.SecretKey),
 			Secure: cfg.ObjectStorageConfig.UseSSL,
+			Region: cfg.ObjectStorageConfig.Region,
 		})
 		if err != nil {
 			return nil, fmt.Errorf("failed to create MinIO client: %w", err)
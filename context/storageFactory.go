@@ -6,11 +6,35 @@ import (
 	"github.com/kumojin/repo-backup-cli/pkg/config"
 	"github.com/kumojin/repo-backup-cli/pkg/storage"
 	"github.com/kumojin/repo-backup-cli/pkg/storage/azure"
+	"github.com/kumojin/repo-backup-cli/pkg/storage/filesystem"
+	"github.com/kumojin/repo-backup-cli/pkg/storage/gcs"
 	"github.com/kumojin/repo-backup-cli/pkg/storage/minio"
+	"github.com/kumojin/repo-backup-cli/pkg/storage/replicated"
+	"github.com/kumojin/repo-backup-cli/pkg/storage/sftp"
 )
 
+// NewBlobRepository returns the repository of the storage backend, the blobs are replicated to every backend when
+// several are configured
 func NewBlobRepository(cfg *config.Config) (storage.BlobRepository, error) {
-	switch cfg.StorageBackend {
+	if len(cfg.StorageBackends) == 1 {
+		return newBackendBlobRepository(cfg, cfg.StorageBackends[0])
+	}
+
+	destinations := make([]replicated.Destination, 0, len(cfg.StorageBackends))
+	for _, storageBackend := range cfg.StorageBackends {
+		repository, err := newBackendBlobRepository(cfg, storageBackend)
+		if err != nil {
+			return nil, err
+		}
+
+		destinations = append(destinations, replicated.Destination{Name: storageBackend, Repository: repository})
+	}
+
+	return replicated.NewBlobRepository(destinations, replicated.Policy(cfg.StorageReplicationPolicy)), nil
+}
+
+func newBackendBlobRepository(cfg *config.Config, storageBackend string) (storage.BlobRepository, error) {
+	switch storageBackend {
 	case config.StorageBackendObject:
 		minioClient, err := GetMinioClient(cfg)
 		if err != nil {
@@ -25,7 +49,41 @@ func NewBlobRepository(cfg *config.Config) (storage.BlobRepository, error) {
 		}
 		return azure.NewBlobRepository(cfg, azureClient), nil
 
+	case config.StorageBackendFilesystem:
+		return filesystem.NewBlobRepository(cfg.FilesystemStorageConfig.Root), nil
+
+	case config.StorageBackendGCS:
+		gcsClient, err := GetGCSClient(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get GCS client: %w", err)
+		}
+		return gcs.NewBlobRepository(cfg, gcsClient), nil
+
+	case config.StorageBackendSFTP:
+		server, err := getSFTPServer(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return sftp.NewBlobRepository(server, cfg.SFTPStorageConfig.Root), nil
+
 	default:
-		return nil, fmt.Errorf("unsupported storage backend: %s (supported: %s, %s)", cfg.StorageBackend, config.StorageBackendAzure, config.StorageBackendObject)
+		return nil, fmt.Errorf("unsupported storage backend: %s (supported: %s, %s, %s, %s, %s)", storageBackend, config.StorageBackendAzure, config.StorageBackendObject, config.StorageBackendFilesystem, config.StorageBackendGCS, config.StorageBackendSFTP)
 	}
 }
+
+func getSFTPServer(cfg *config.Config) (sftp.Server, error) {
+	privateKey, err := cfg.SFTPStorageConfig.LoadPrivateKey()
+	if err != nil {
+		return sftp.Server{}, err
+	}
+
+	return sftp.Server{
+		Host:                 cfg.SFTPStorageConfig.Host,
+		Port:                 cfg.SFTPStorageConfig.Port,
+		User:                 cfg.SFTPStorageConfig.User,
+		Password:             cfg.SFTPStorageConfig.Password,
+		PrivateKey:           privateKey,
+		PrivateKeyPassphrase: cfg.SFTPStorageConfig.PrivateKeyPassphrase,
+		KnownHostsFile:       cfg.SFTPStorageConfig.KnownHostsFile,
+	}, nil
+}
@@ -0,0 +1,184 @@
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrInvalidPath = errors.New("archive entry escapes the destination directory")
+
+// Entry is a file or directory written by WriteTarGzEntries under Name, a directory is written with its content
+type Entry struct {
+	Name string
+	// Path is the file or directory to write, Content is written instead when Path is empty
+	Path    string
+	Content []byte
+}
+
+// WriteTarGz writes the content of the root directory as a gzipped tarball, paths are relative to root
+func WriteTarGz(w io.Writer, root string) error {
+	return writeTarGz(w, func(tarWriter *tar.Writer) error {
+		return writePath(tarWriter, "", root)
+	})
+}
+
+// WriteTarGzEntries writes the entries as a gzipped tarball
+func WriteTarGzEntries(w io.Writer, entries []Entry) error {
+	return writeTarGz(w, func(tarWriter *tar.Writer) error {
+		for _, entry := range entries {
+			if entry.Path != "" {
+				if err := writePath(tarWriter, entry.Name, entry.Path); err != nil {
+					return err
+				}
+				continue
+			}
+
+			header := &tar.Header{
+				Name:     entry.Name,
+				Typeflag: tar.TypeReg,
+				Mode:     0o644,
+				Size:     int64(len(entry.Content)),
+			}
+			if err := tarWriter.WriteHeader(header); err != nil {
+				return err
+			}
+			if _, err := tarWriter.Write(entry.Content); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func writeTarGz(w io.Writer, write func(tarWriter *tar.Writer) error) error {
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	if err := write(tarWriter); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return fmt.Errorf("failed to close archive: %w", err)
+	}
+
+	return gzipWriter.Close()
+}
+
+// writePath writes the file or directory at root under the name prefix, an empty prefix only writes the content
+// of the root directory
+func writePath(tarWriter *tar.Writer, prefix string, root string) error {
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		name, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		name = filepath.ToSlash(name)
+		if prefix != "" {
+			name = strings.TrimSuffix(prefix+"/"+name, "/.")
+		}
+		if name == "." {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = name
+		if entry.IsDir() {
+			header.Name += "/"
+		}
+
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		return copyFile(tarWriter, path)
+	})
+}
+
+func copyFile(w io.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	_, err = io.Copy(w, file)
+	return err
+}
+
+// ExtractTarGz extracts a gzipped tarball in the dir directory. Only directories and regular files are extracted.
+func ExtractTarGz(r io.Reader, dir string) error {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+	defer func() { _ = gzipReader.Close() }()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		path := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if path != filepath.Clean(dir) && !strings.HasPrefix(path, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("%w: %s", ErrInvalidPath, header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return err
+			}
+			if err := writeFile(path, tarReader, header.FileInfo().Mode().Perm()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func writeFile(path string, r io.Reader, perm fs.FileMode) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm|0o600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(file, r); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
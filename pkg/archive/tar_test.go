@@ -0,0 +1,98 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTarGz_RoundTrip(t *testing.T) {
+	// Given
+	source := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(source, "repositories", "kumojin"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(source, "schema.json"), []byte(`{"version":"1.0.1"}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(source, "repositories", "kumojin", "repo1.bundle"), []byte("bundle"), 0o644))
+
+	var buffer bytes.Buffer
+	require.NoError(t, WriteTarGz(&buffer, source))
+
+	destination := t.TempDir()
+
+	// When
+	err := ExtractTarGz(&buffer, destination)
+
+	// Then
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(destination, "schema.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"version":"1.0.1"}`, string(content))
+
+	content, err = os.ReadFile(filepath.Join(destination, "repositories", "kumojin", "repo1.bundle"))
+	assert.NoError(t, err)
+	assert.Equal(t, "bundle", string(content))
+}
+
+func TestExtractTarGz_RejectsPathTraversal(t *testing.T) {
+	// Given
+	var buffer bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buffer)
+	tarWriter := tar.NewWriter(gzipWriter)
+	require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: "../evil", Typeflag: tar.TypeReg, Mode: 0o644, Size: 4}))
+	_, err := tarWriter.Write([]byte("evil"))
+	require.NoError(t, err)
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, gzipWriter.Close())
+
+	destination := t.TempDir()
+
+	// When
+	err = ExtractTarGz(&buffer, destination)
+
+	// Then
+	assert.ErrorIs(t, err, ErrInvalidPath)
+	assert.NoFileExists(t, filepath.Join(filepath.Dir(destination), "evil"))
+}
+
+func TestWriteTarGzEntries(t *testing.T) {
+	// Given
+	source := t.TempDir()
+	gitDir := filepath.Join(source, "repo1.git")
+	require.NoError(t, os.MkdirAll(filepath.Join(gitDir, "refs", "heads"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(gitDir, "HEAD"), []byte("ref: refs/heads/main"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(source, "schema.json"), []byte(`{"version":"1.0.1"}`), 0o644))
+
+	var buffer bytes.Buffer
+
+	// When
+	err := WriteTarGzEntries(&buffer, []Entry{
+		{Name: "repositories/kumojin/repo1.git", Path: gitDir},
+		{Name: "schema.json", Path: filepath.Join(source, "schema.json")},
+		{Name: "repositories_000001.json", Content: []byte(`[]`)},
+	})
+
+	// Then
+	require.NoError(t, err)
+
+	destination := t.TempDir()
+	require.NoError(t, ExtractTarGz(&buffer, destination))
+
+	content, err := os.ReadFile(filepath.Join(destination, "repositories", "kumojin", "repo1.git", "HEAD"))
+	require.NoError(t, err)
+	assert.Equal(t, "ref: refs/heads/main", string(content))
+	assert.DirExists(t, filepath.Join(destination, "repositories", "kumojin", "repo1.git", "refs", "heads"), "empty directories are kept")
+
+	content, err = os.ReadFile(filepath.Join(destination, "schema.json"))
+	require.NoError(t, err)
+	assert.Equal(t, `{"version":"1.0.1"}`, string(content))
+
+	content, err = os.ReadFile(filepath.Join(destination, "repositories_000001.json"))
+	require.NoError(t, err)
+	assert.Equal(t, `[]`, string(content))
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// BackupModeMigration exports the repositories with the GitHub migrations API
+	BackupModeMigration = "migration"
+	// BackupModeMirror clones the repositories with git
+	BackupModeMirror = "mirror"
+)
+
+func newBackupMode() (string, error) {
+	backupMode := viper.GetString(backupModeKey)
+	if backupMode == "" {
+		return BackupModeMigration, nil
+	}
+
+	if err := ValidateBackupMode(backupMode); err != nil {
+		return "", err
+	}
+
+	return backupMode, nil
+}
+
+func ValidateBackupMode(backupMode string) error {
+	switch backupMode {
+	case BackupModeMigration, BackupModeMirror:
+		return nil
+	default:
+		return fmt.Errorf("unsupported backup mode: %s (supported: %s, %s)", backupMode, BackupModeMigration, BackupModeMirror)
+	}
+}
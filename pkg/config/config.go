@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 
+	"github.com/kumojin/repo-backup-cli/pkg/github"
 	"github.com/spf13/viper"
 )
 
@@ -16,9 +17,70 @@ const (
 	objectStorageSecretKeyKey    = "OBJECT_STORAGE_SECRET_KEY"
 	objectStorageBucketNameKey   = "OBJECT_STORAGE_BUCKET_NAME"
 	objectStorageUseSSLKey       = "OBJECT_STORAGE_USE_SSL"
+	objectStorageRegionKey       = "OBJECT_STORAGE_REGION"
+	objectStorageSSEKey          = "OBJECT_STORAGE_SSE"
+	objectStorageSSEKMSKeyIDKey  = "OBJECT_STORAGE_SSE_KMS_KEY_ID"
+	objectStorageStorageClassKey = "OBJECT_STORAGE_STORAGE_CLASS"
+	objectStorageLockModeKey     = "OBJECT_STORAGE_OBJECT_LOCK_MODE"
+	objectStorageLockDaysKey     = "OBJECT_STORAGE_OBJECT_LOCK_RETENTION_DAYS"
 	storageBackendKey            = "STORAGE_BACKEND"
+	storageReplicationPolicyKey  = "STORAGE_REPLICATION_POLICY"
+	filesystemStorageRootKey     = "FILESYSTEM_STORAGE_ROOT"
+	gcsStorageBucketNameKey      = "GCS_STORAGE_BUCKET_NAME"
+	gcsStorageCredentialsFileKey = "GCS_STORAGE_CREDENTIALS_FILE"
+	sftpStorageHostKey           = "SFTP_STORAGE_HOST"
+	sftpStoragePortKey           = "SFTP_STORAGE_PORT"
+	sftpStorageUserKey           = "SFTP_STORAGE_USER"
+	sftpStoragePasswordKey       = "SFTP_STORAGE_PASSWORD"
+	sftpStoragePrivateKeyFileKey = "SFTP_STORAGE_PRIVATE_KEY_FILE"
+	sftpStoragePassphraseKey     = "SFTP_STORAGE_PRIVATE_KEY_PASSPHRASE"
+	sftpStorageKnownHostsKey     = "SFTP_STORAGE_KNOWN_HOSTS_FILE"
+	sftpStorageRootKey           = "SFTP_STORAGE_ROOT"
 	githubTokenKey               = "CLI_GITHUB_TOKEN"
+	githubAppIDKey               = "GITHUB_APP_ID"
+	githubAppInstallationIDKey   = "GITHUB_APP_INSTALLATION_ID"
+	githubAppPrivateKeyFileKey   = "GITHUB_APP_PRIVATE_KEY_FILE"
+	githubAppPrivateKeyKey       = "GITHUB_APP_PRIVATE_KEY"
+	githubBaseURLKey             = "GITHUB_BASE_URL"
+	githubUploadURLKey           = "GITHUB_UPLOAD_URL"
+	githubCABundleFileKey        = "GITHUB_CA_BUNDLE_FILE"
+	githubInsecureSkipVerifyKey  = "GITHUB_INSECURE_SKIP_VERIFY"
 	sentryDsnKey                 = "SENTRY_DSN"
+	migrationGitDataKey          = "MIGRATION_GIT_DATA"
+	migrationMetadataKey         = "MIGRATION_METADATA"
+	migrationAttachmentsKey      = "MIGRATION_ATTACHMENTS"
+	migrationReleasesKey         = "MIGRATION_RELEASES"
+	migrationOwnerProjectsKey    = "MIGRATION_OWNER_PROJECTS"
+	migrationLockRepositoriesKey = "MIGRATION_LOCK_REPOSITORIES"
+	batchMaxRepositoriesKey      = "BATCH_MAX_REPOSITORIES"
+	batchMaxSizeKey              = "BATCH_MAX_SIZE"
+	backupModeKey                = "BACKUP_MODE"
+	splitRepositoriesKey         = "SPLIT_REPOSITORIES"
+	fullBackupIntervalDaysKey    = "FULL_BACKUP_INTERVAL_DAYS"
+	retentionDailyKey            = "RETENTION_DAILY"
+	retentionWeeklyKey           = "RETENTION_WEEKLY"
+	retentionMonthlyKey          = "RETENTION_MONTHLY"
+	retentionYearlyKey           = "RETENTION_YEARLY"
+	retentionPruneAfterBackupKey = "RETENTION_PRUNE_AFTER_BACKUP"
+	encryptionAgeRecipientsKey   = "ENCRYPTION_AGE_RECIPIENTS"
+	encryptionPGPPublicKeysKey   = "ENCRYPTION_PGP_PUBLIC_KEY_FILE"
+	encryptionPGPPassphraseKey   = "ENCRYPTION_PGP_PASSPHRASE"
+	filterIncludeKey             = "FILTER_INCLUDE"
+	filterExcludeKey             = "FILTER_EXCLUDE"
+	filterIncludeRegexKey        = "FILTER_INCLUDE_REGEX"
+	filterExcludeRegexKey        = "FILTER_EXCLUDE_REGEX"
+	filterIncludeTopicsKey       = "FILTER_INCLUDE_TOPICS"
+	filterExcludeTopicsKey       = "FILTER_EXCLUDE_TOPICS"
+	filterVisibilityKey          = "FILTER_VISIBILITY"
+	filterIncludeArchivedKey     = "FILTER_INCLUDE_ARCHIVED"
+	filterExcludeForksKey        = "FILTER_EXCLUDE_FORKS"
+	filterMaxSizeKey             = "FILTER_MAX_SIZE"
+	filterPushedSinceKey         = "FILTER_PUSHED_SINCE"
+	organizationsKey             = "ORGANIZATIONS"
+	backupConcurrencyKey         = "BACKUP_CONCURRENCY"
+	metricsPushgatewayURLKey     = "METRICS_PUSHGATEWAY_URL"
+	metricsJobKey                = "METRICS_JOB"
+	metricsTextfileKey           = "METRICS_TEXTFILE"
 )
 
 type SentryConfig struct {
@@ -32,12 +94,29 @@ func NewSentryConfig() SentryConfig {
 }
 
 type Config struct {
-	AzureStorageConfig  AzureStorageConfig
-	ObjectStorageConfig ObjectStorageConfig
-	SentryConfig        SentryConfig
-	GitHubToken         string
-	Organization        string
-	StorageBackend      string
+	AzureStorageConfig       AzureStorageConfig
+	ObjectStorageConfig      ObjectStorageConfig
+	FilesystemStorageConfig  FilesystemStorageConfig
+	GCSStorageConfig         GCSStorageConfig
+	SFTPStorageConfig        SFTPStorageConfig
+	SentryConfig             SentryConfig
+	MigrationContents        github.MigrationContents
+	BatchConfig              BatchConfig
+	BackupMode               string
+	SplitRepositories        bool
+	FullBackupIntervalDays   int
+	RetentionConfig          RetentionConfig
+	EncryptionConfig         EncryptionConfig
+	FilterConfig             FilterConfig
+	MetricsConfig            MetricsConfig
+	GitHubToken              string
+	GitHubAppConfig          GitHubAppConfig
+	GitHubServerConfig       GitHubServerConfig
+	Organization             string
+	Organizations            []string
+	BackupConcurrency        int
+	StorageBackends          []string
+	StorageReplicationPolicy string
 }
 
 func New(filepath string) (*Config, error) {
@@ -54,32 +133,112 @@ func New(filepath string) (*Config, error) {
 		}
 	}
 
+	gitHubAppConfig, err := newGitHubAppConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	gitHubServerConfig, err := newGitHubServerConfig()
+	if err != nil {
+		return nil, err
+	}
+
 	token := viper.GetString(githubTokenKey)
-	if token == "" {
-		return nil, fmt.Errorf("github token is not set in the configuration file")
+	if token == "" && !gitHubAppConfig.IsEnabled() {
+		return nil, fmt.Errorf("neither a github token nor a github app is set in the configuration file")
+	}
+
+	storageBackends := splitList(viper.GetString(storageBackendKey))
+	if len(storageBackends) == 0 {
+		storageBackends = []string{StorageBackendAzure} // Defaults to Azure blob storage
+	}
+
+	storageConfigs, err := createStorageConfigs(storageBackends)
+	if err != nil {
+		return nil, err
+	}
+
+	storageReplicationPolicy, err := newStorageReplicationPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	migrationContents, err := newMigrationContents()
+	if err != nil {
+		return nil, err
 	}
 
-	storageBackend := viper.GetString(storageBackendKey)
-	if storageBackend == "" {
-		storageBackend = StorageBackendAzure // Defaults to Azure blob storage
+	batchConfig, err := newBatchConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	backupMode, err := newBackupMode()
+	if err != nil {
+		return nil, err
+	}
+
+	retentionConfig, err := newRetentionConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	encryptionConfig, err := newEncryptionConfig()
+	if err != nil {
+		return nil, err
 	}
 
-	azureStorageConfig, objectStorageConfig, err := createStorageConfigs(storageBackend)
+	filterConfig, err := newFilterConfig()
 	if err != nil {
 		return nil, err
 	}
 
-	return &Config{
-		AzureStorageConfig:  azureStorageConfig,
-		ObjectStorageConfig: objectStorageConfig,
-		GitHubToken:         token,
-		SentryConfig:        NewSentryConfig(),
-		StorageBackend:      storageBackend,
-	}, nil
+	backupConcurrency := viper.GetInt(backupConcurrencyKey)
+	if backupConcurrency < 0 {
+		return nil, fmt.Errorf("invalid backup configuration: %s must be positive", backupConcurrencyKey)
+	}
+
+	fullBackupIntervalDays := viper.GetInt(fullBackupIntervalDaysKey)
+	if fullBackupIntervalDays < 0 {
+		return nil, fmt.Errorf("invalid backup configuration: %s must be positive", fullBackupIntervalDaysKey)
+	}
+
+	cfg := &Config{
+		AzureStorageConfig:       storageConfigs.azure,
+		ObjectStorageConfig:      storageConfigs.object,
+		FilesystemStorageConfig:  storageConfigs.filesystem,
+		GCSStorageConfig:         storageConfigs.gcs,
+		SFTPStorageConfig:        storageConfigs.sftp,
+		MigrationContents:        migrationContents,
+		BatchConfig:              batchConfig,
+		BackupMode:               backupMode,
+		SplitRepositories:        viper.GetBool(splitRepositoriesKey),
+		FullBackupIntervalDays:   fullBackupIntervalDays,
+		RetentionConfig:          retentionConfig,
+		EncryptionConfig:         encryptionConfig,
+		FilterConfig:             filterConfig,
+		MetricsConfig:            newMetricsConfig(),
+		BackupConcurrency:        backupConcurrency,
+		GitHubToken:              token,
+		GitHubAppConfig:          gitHubAppConfig,
+		GitHubServerConfig:       gitHubServerConfig,
+		SentryConfig:             NewSentryConfig(),
+		StorageBackends:          storageBackends,
+		StorageReplicationPolicy: storageReplicationPolicy,
+	}
+
+	return cfg.WithOrganizations(splitList(viper.GetString(organizationsKey))), nil
 }
 
-func (c *Config) WithOrganization(organization string) *Config {
-	c.Organization = organization
+// WithOrganizations replaces the configured organizations, an empty list keeps them.
+// Organization is set to the first one for the commands working on a single organization.
+func (c *Config) WithOrganizations(organizations []string) *Config {
+	if len(organizations) == 0 {
+		return c
+	}
+
+	c.Organizations = organizations
+	c.Organization = organizations[0]
 
 	return c
 }
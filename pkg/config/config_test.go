@@ -0,0 +1,84 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setEnv sets the environment read by New and resets viper once the test is done
+func setEnv(t *testing.T, env map[string]string) {
+	t.Cleanup(viper.Reset)
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+}
+
+// defaultBackendEnv is a complete configuration of the default Azure storage backend
+var defaultBackendEnv = map[string]string{
+	githubTokenKey:               "token",
+	azureStorageAccountNameKey:   "account",
+	azureStorageApiKeyKey:        "key",
+	azureStorageAccountUrlKey:    "https://account.blob.core.windows.net",
+	azureStorageContainerNameKey: "backups",
+}
+
+func TestNew_ReadsFilesystemRootWithDefaultBackend(t *testing.T) {
+	// Given
+	root := t.TempDir()
+	setEnv(t, defaultBackendEnv)
+	t.Setenv(filesystemStorageRootKey, root)
+
+	// When
+	cfg, err := New("missing.env")
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []string{StorageBackendAzure}, cfg.StorageBackends)
+	assert.Equal(t, root, cfg.FilesystemStorageConfig.Root)
+}
+
+func TestNew_FilesystemRootIsOptionalWithDefaultBackend(t *testing.T) {
+	// Given
+	setEnv(t, defaultBackendEnv)
+	t.Setenv(filesystemStorageRootKey, "")
+
+	// When
+	cfg, err := New("missing.env")
+
+	// Then
+	require.NoError(t, err)
+	assert.Empty(t, cfg.FilesystemStorageConfig.Root)
+}
+
+func TestNew_RejectsFilesystemRootWhichIsNotADirectory(t *testing.T) {
+	// Given
+	root := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(root, nil, 0o600))
+	setEnv(t, defaultBackendEnv)
+	t.Setenv(filesystemStorageRootKey, root)
+
+	// When
+	_, err := New("missing.env")
+
+	// Then
+	assert.ErrorContains(t, err, "FILESYSTEM_STORAGE_ROOT is not a directory")
+}
+
+func TestNew_RequiresFilesystemRootWithFilesystemBackend(t *testing.T) {
+	// Given
+	setEnv(t, map[string]string{
+		githubTokenKey:    "token",
+		storageBackendKey: StorageBackendFilesystem,
+	})
+
+	// When
+	_, err := New("missing.env")
+
+	// Then
+	assert.ErrorContains(t, err, "FILESYSTEM_STORAGE_ROOT is not set")
+}
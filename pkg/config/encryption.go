@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+// EncryptionConfig holds the public-key recipients the archives are encrypted for, encryption is disabled when none is set
+type EncryptionConfig struct {
+	// AgeRecipients are age X25519 public keys (age1...)
+	AgeRecipients []string
+	// PGPPublicKeyFile is the path of an armored keyring holding the OpenPGP public keys of the recipients
+	PGPPublicKeyFile string
+	// PGPPassphrase unlocks protected OpenPGP private keys when decrypting
+	PGPPassphrase string
+}
+
+func (c EncryptionConfig) IsEnabled() bool {
+	return len(c.AgeRecipients) > 0 || c.PGPPublicKeyFile != ""
+}
+
+func newEncryptionConfig() (EncryptionConfig, error) {
+	encryptionConfig := EncryptionConfig{
+		AgeRecipients:    splitList(viper.GetString(encryptionAgeRecipientsKey)),
+		PGPPublicKeyFile: viper.GetString(encryptionPGPPublicKeysKey),
+		PGPPassphrase:    viper.GetString(encryptionPGPPassphraseKey),
+	}
+
+	if len(encryptionConfig.AgeRecipients) > 0 && encryptionConfig.PGPPublicKeyFile != "" {
+		return EncryptionConfig{}, fmt.Errorf("invalid encryption configuration: only one of %s and %s can be set", encryptionAgeRecipientsKey, encryptionPGPPublicKeysKey)
+	}
+
+	return encryptionConfig, nil
+}
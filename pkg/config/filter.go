@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/kumojin/repo-backup-cli/pkg/github"
+	"github.com/spf13/viper"
+)
+
+// FilterConfig selects the repositories to list and back up, the zero value selects the private repositories which are not archived
+type FilterConfig struct {
+	// Include and Exclude are globs matched against the repository names
+	Include       []string
+	Exclude       []string
+	IncludeRegex  string
+	ExcludeRegex  string
+	IncludeTopics []string
+	ExcludeTopics []string
+	Visibility    string
+	// IncludeArchived also selects the archived repositories
+	IncludeArchived bool
+	ExcludeForks    bool
+	// MaxSize is the size in bytes above which repositories are skipped, zero disables the limit
+	MaxSize uint64
+	// PushedSince is a date (2006-01-02) or an age (90d, 12h) before which repositories which were not pushed to are skipped
+	PushedSince string
+}
+
+func newFilterConfig() (FilterConfig, error) {
+	filterConfig := FilterConfig{
+		Include:         splitList(viper.GetString(filterIncludeKey)),
+		Exclude:         splitList(viper.GetString(filterExcludeKey)),
+		IncludeRegex:    viper.GetString(filterIncludeRegexKey),
+		ExcludeRegex:    viper.GetString(filterExcludeRegexKey),
+		IncludeTopics:   splitList(viper.GetString(filterIncludeTopicsKey)),
+		ExcludeTopics:   splitList(viper.GetString(filterExcludeTopicsKey)),
+		Visibility:      viper.GetString(filterVisibilityKey),
+		IncludeArchived: viper.GetBool(filterIncludeArchivedKey),
+		ExcludeForks:    viper.GetBool(filterExcludeForksKey),
+		PushedSince:     viper.GetString(filterPushedSinceKey),
+	}
+
+	if filterConfig.Visibility == "" {
+		filterConfig.Visibility = github.VisibilityPrivate
+	}
+	if err := github.ValidateVisibility(filterConfig.Visibility); err != nil {
+		return FilterConfig{}, fmt.Errorf("invalid filter configuration: %s: %w", filterVisibilityKey, err)
+	}
+
+	for key, value := range map[string]string{filterIncludeRegexKey: filterConfig.IncludeRegex, filterExcludeRegexKey: filterConfig.ExcludeRegex} {
+		if _, err := regexp.Compile(value); err != nil {
+			return FilterConfig{}, fmt.Errorf("invalid filter configuration: %s: %w", key, err)
+		}
+	}
+
+	if value := viper.GetString(filterMaxSizeKey); value != "" {
+		var err error
+		filterConfig.MaxSize, err = humanize.ParseBytes(value)
+		if err != nil {
+			return FilterConfig{}, fmt.Errorf("invalid filter configuration: %s: %w", filterMaxSizeKey, err)
+		}
+	}
+
+	if _, err := ParsePushedSince(filterConfig.PushedSince, time.Now()); err != nil {
+		return FilterConfig{}, fmt.Errorf("invalid filter configuration: %s: %w", filterPushedSinceKey, err)
+	}
+
+	return filterConfig, nil
+}
+
+// ParsePushedSince returns the time of a date (2006-01-02 or RFC 3339) or of an age (90d, 12h) before now,
+// an empty value returns the zero time
+func ParsePushedSince(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if date, err := time.Parse(time.DateOnly, value); err == nil {
+		return date, nil
+	}
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return date, nil
+	}
+
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		count, err := strconv.Atoi(days)
+		if err != nil || count < 0 {
+			return time.Time{}, fmt.Errorf("invalid age %q", value)
+		}
+		return now.AddDate(0, 0, -count), nil
+	}
+
+	age, err := time.ParseDuration(value)
+	if err != nil || age < 0 {
+		return time.Time{}, fmt.Errorf("invalid date or age %q (expected 2006-01-02, 90d or 12h)", value)
+	}
+
+	return now.Add(-age), nil
+}
+
+// splitList returns the trimmed, non empty values of a comma separated list
+func splitList(value string) []string {
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/viper"
+)
+
+// GitHubAppConfig authenticates as the installation of a GitHub App instead of with a personal access token
+type GitHubAppConfig struct {
+	AppID          int64
+	InstallationID int64
+	// PrivateKeyFile is the path of the PEM private key of the app
+	PrivateKeyFile string
+	// PrivateKey is the PEM private key itself, for environments where secrets are only passed as variables
+	PrivateKey string
+}
+
+func (c GitHubAppConfig) IsEnabled() bool {
+	return c.AppID != 0
+}
+
+// LoadPrivateKey returns the PEM private key of the app
+func (c GitHubAppConfig) LoadPrivateKey() ([]byte, error) {
+	if c.PrivateKey != "" {
+		return []byte(c.PrivateKey), nil
+	}
+
+	privateKey, err := os.ReadFile(c.PrivateKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read github app private key: %w", err)
+	}
+
+	return privateKey, nil
+}
+
+func newGitHubAppConfig() (GitHubAppConfig, error) {
+	appConfig := GitHubAppConfig{
+		AppID:          viper.GetInt64(githubAppIDKey),
+		InstallationID: viper.GetInt64(githubAppInstallationIDKey),
+		PrivateKeyFile: viper.GetString(githubAppPrivateKeyFileKey),
+		PrivateKey:     viper.GetString(githubAppPrivateKeyKey),
+	}
+
+	if appConfig.AppID == 0 && appConfig.InstallationID == 0 && appConfig.PrivateKeyFile == "" && appConfig.PrivateKey == "" {
+		return GitHubAppConfig{}, nil
+	}
+
+	if appConfig.AppID <= 0 || appConfig.InstallationID <= 0 {
+		return GitHubAppConfig{}, fmt.Errorf("invalid github app configuration: %s and %s must be set", githubAppIDKey, githubAppInstallationIDKey)
+	}
+
+	if (appConfig.PrivateKeyFile == "") == (appConfig.PrivateKey == "") {
+		return GitHubAppConfig{}, fmt.Errorf("invalid github app configuration: one of %s and %s must be set", githubAppPrivateKeyFileKey, githubAppPrivateKeyKey)
+	}
+
+	return appConfig, nil
+}
+
+// GitHubServerConfig points the CLI to a GitHub Enterprise Server, github.com is used when BaseURL is empty
+type GitHubServerConfig struct {
+	BaseURL   string
+	UploadURL string
+	// CABundleFile is the path of PEM certificates trusted in addition to the system ones, for servers behind an internal CA
+	CABundleFile       string
+	InsecureSkipVerify bool
+}
+
+// LoadCABundle returns the PEM certificates of the CA bundle, nil when none is configured
+func (c GitHubServerConfig) LoadCABundle() ([]byte, error) {
+	if c.CABundleFile == "" {
+		return nil, nil
+	}
+
+	caBundle, err := os.ReadFile(c.CABundleFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read github CA bundle: %w", err)
+	}
+
+	return caBundle, nil
+}
+
+func newGitHubServerConfig() (GitHubServerConfig, error) {
+	serverConfig := GitHubServerConfig{
+		BaseURL:            viper.GetString(githubBaseURLKey),
+		UploadURL:          viper.GetString(githubUploadURLKey),
+		CABundleFile:       viper.GetString(githubCABundleFileKey),
+		InsecureSkipVerify: viper.GetBool(githubInsecureSkipVerifyKey),
+	}
+
+	if serverConfig.UploadURL != "" && serverConfig.BaseURL == "" {
+		return GitHubServerConfig{}, fmt.Errorf("invalid github configuration: %s requires %s", githubUploadURLKey, githubBaseURLKey)
+	}
+
+	return serverConfig, nil
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+// MetricsConfig tells where the Prometheus metrics of the backup runs are exported, an empty PushgatewayURL or
+// TextfilePath disables the export
+type MetricsConfig struct {
+	PushgatewayURL string
+	Job            string
+	// TextfilePath is a file read by the textfile collector of the node exporter, such as
+	// /var/lib/node_exporter/textfile_collector/rbk.prom
+	TextfilePath string
+}
+
+func newMetricsConfig() MetricsConfig {
+	return MetricsConfig{
+		PushgatewayURL: viper.GetString(metricsPushgatewayURLKey),
+		Job:            viper.GetString(metricsJobKey),
+		TextfilePath:   viper.GetString(metricsTextfileKey),
+	}
+}
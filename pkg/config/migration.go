@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/dustin/go-humanize"
+	"github.com/kumojin/repo-backup-cli/pkg/github"
+	"github.com/spf13/viper"
+)
+
+func newMigrationContents() (github.MigrationContents, error) {
+	defaults := github.DefaultMigrationContents()
+
+	viper.SetDefault(migrationGitDataKey, defaults.GitData)
+	viper.SetDefault(migrationMetadataKey, defaults.Metadata)
+	viper.SetDefault(migrationAttachmentsKey, defaults.Attachments)
+	viper.SetDefault(migrationReleasesKey, defaults.Releases)
+	viper.SetDefault(migrationOwnerProjectsKey, defaults.OwnerProjects)
+	viper.SetDefault(migrationLockRepositoriesKey, defaults.LockRepositories)
+
+	contents := github.MigrationContents{
+		GitData:          viper.GetBool(migrationGitDataKey),
+		Metadata:         viper.GetBool(migrationMetadataKey),
+		Attachments:      viper.GetBool(migrationAttachmentsKey),
+		Releases:         viper.GetBool(migrationReleasesKey),
+		OwnerProjects:    viper.GetBool(migrationOwnerProjectsKey),
+		LockRepositories: viper.GetBool(migrationLockRepositoriesKey),
+	}
+
+	if err := contents.Validate(); err != nil {
+		return github.MigrationContents{}, fmt.Errorf("invalid migration configuration: %w", err)
+	}
+
+	return contents, nil
+}
+
+// BatchConfig controls how an organization is split into several migrations, zero values disable batching
+type BatchConfig struct {
+	MaxRepositories int
+	// MaxSize is the maximum total size of the repositories of a batch, in bytes
+	MaxSize uint64
+}
+
+func newBatchConfig() (BatchConfig, error) {
+	maxRepositories := viper.GetInt(batchMaxRepositoriesKey)
+	if maxRepositories < 0 {
+		return BatchConfig{}, fmt.Errorf("invalid batch configuration: %s must be positive", batchMaxRepositoriesKey)
+	}
+
+	var maxSize uint64
+	if value := viper.GetString(batchMaxSizeKey); value != "" {
+		var err error
+		maxSize, err = humanize.ParseBytes(value)
+		if err != nil {
+			return BatchConfig{}, fmt.Errorf("invalid batch configuration: %s: %w", batchMaxSizeKey, err)
+		}
+	}
+
+	return BatchConfig{
+		MaxRepositories: maxRepositories,
+		MaxSize:         maxSize,
+	}, nil
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+// RetentionConfig is the grandfather-father-son policy applied to remote backups, zero values keep no backup for the period
+type RetentionConfig struct {
+	Daily   int
+	Weekly  int
+	Monthly int
+	Yearly  int
+	// PruneAfterBackup applies the policy after every successful remote backup
+	PruneAfterBackup bool
+}
+
+func newRetentionConfig() (RetentionConfig, error) {
+	retentionConfig := RetentionConfig{
+		Daily:            viper.GetInt(retentionDailyKey),
+		Weekly:           viper.GetInt(retentionWeeklyKey),
+		Monthly:          viper.GetInt(retentionMonthlyKey),
+		Yearly:           viper.GetInt(retentionYearlyKey),
+		PruneAfterBackup: viper.GetBool(retentionPruneAfterBackupKey),
+	}
+
+	counts := map[string]int{
+		retentionDailyKey:   retentionConfig.Daily,
+		retentionWeeklyKey:  retentionConfig.Weekly,
+		retentionMonthlyKey: retentionConfig.Monthly,
+		retentionYearlyKey:  retentionConfig.Yearly,
+	}
+	for key, count := range counts {
+		if count < 0 {
+			return RetentionConfig{}, fmt.Errorf("invalid retention configuration: %s must be positive", key)
+		}
+	}
+
+	return retentionConfig, nil
+}
@@ -2,13 +2,24 @@ package config
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/spf13/viper"
 )
 
 const (
-	StorageBackendAzure  = "azure"
-	StorageBackendObject = "object"
+	StorageBackendAzure      = "azure"
+	StorageBackendObject     = "object"
+	StorageBackendFilesystem = "filesystem"
+	StorageBackendGCS        = "gcs"
+	StorageBackendSFTP       = "sftp"
+
+	// StorageReplicationAll requires every storage backend to succeed
+	StorageReplicationAll = "all"
+	// StorageReplicationQuorum requires a majority of the storage backends to succeed
+	StorageReplicationQuorum = "quorum"
 )
 
 type AzureStorageConfig struct {
@@ -36,53 +47,293 @@ func newAzureStorageConfig() (AzureStorageConfig, error) {
 	}, nil
 }
 
+const (
+	// ObjectStorageSSES3 encrypts the objects with keys managed by the object storage
+	ObjectStorageSSES3 = "s3"
+	// ObjectStorageSSEKMS encrypts the objects with a key of the key management service
+	ObjectStorageSSEKMS = "kms"
+
+	ObjectLockGovernance = "GOVERNANCE"
+	ObjectLockCompliance = "COMPLIANCE"
+
+	// defaultObjectStorageEndpoint is used when no endpoint is set, for the buckets of AWS S3
+	defaultObjectStorageEndpoint = "s3.amazonaws.com"
+)
+
 type ObjectStorageConfig struct {
-	Endpoint   string
+	Endpoint string
+	// AccessKey and SecretKey are optional, the credentials are otherwise taken from the environment, the AWS
+	// credentials file or the IAM role of the instance, the task or the pod
 	AccessKey  string
 	SecretKey  string
 	BucketName string
 	UseSSL     bool
+	Region     string
+	// ServerSideEncryption is empty, ObjectStorageSSES3 or ObjectStorageSSEKMS
+	ServerSideEncryption string
+	// SSEKMSKeyID is the KMS key encrypting the objects, the default key of the bucket is used when empty
+	SSEKMSKeyID string
+	// StorageClass of the uploaded objects, such as STANDARD_IA or GLACIER_IR, the default of the bucket when empty
+	StorageClass string
+	// ObjectLockMode retains the uploaded objects for ObjectLockRetentionDays, it requires a bucket with object lock
+	// enabled
+	ObjectLockMode          string
+	ObjectLockRetentionDays int
 }
 
 func newObjectStorageConfig() (ObjectStorageConfig, error) {
-	endpoint := viper.GetString(objectStorageEndpointKey)
-	accessKey := viper.GetString(objectStorageAccessKeyKey)
-	secretKey := viper.GetString(objectStorageSecretKeyKey)
-	bucketName := viper.GetString(objectStorageBucketNameKey)
-	useSSL := viper.GetBool(objectStorageUseSSLKey)
+	viper.SetDefault(objectStorageEndpointKey, defaultObjectStorageEndpoint)
+	viper.SetDefault(objectStorageUseSSLKey, true)
 
-	if endpoint == "" || accessKey == "" || secretKey == "" || bucketName == "" {
+	objectConfig := ObjectStorageConfig{
+		Endpoint:                viper.GetString(objectStorageEndpointKey),
+		AccessKey:               viper.GetString(objectStorageAccessKeyKey),
+		SecretKey:               viper.GetString(objectStorageSecretKeyKey),
+		BucketName:              viper.GetString(objectStorageBucketNameKey),
+		UseSSL:                  viper.GetBool(objectStorageUseSSLKey),
+		Region:                  viper.GetString(objectStorageRegionKey),
+		ServerSideEncryption:    strings.ToLower(viper.GetString(objectStorageSSEKey)),
+		SSEKMSKeyID:             viper.GetString(objectStorageSSEKMSKeyIDKey),
+		StorageClass:            strings.ToUpper(viper.GetString(objectStorageStorageClassKey)),
+		ObjectLockMode:          strings.ToUpper(viper.GetString(objectStorageLockModeKey)),
+		ObjectLockRetentionDays: viper.GetInt(objectStorageLockDaysKey),
+	}
+
+	if objectConfig.Endpoint == "" || objectConfig.BucketName == "" {
 		return ObjectStorageConfig{}, fmt.Errorf("object storage configuration is incomplete")
 	}
 
-	return ObjectStorageConfig{
-		Endpoint:   endpoint,
-		AccessKey:  accessKey,
-		SecretKey:  secretKey,
-		BucketName: bucketName,
-		UseSSL:     useSSL,
+	if (objectConfig.AccessKey == "") != (objectConfig.SecretKey == "") {
+		return ObjectStorageConfig{}, fmt.Errorf("invalid object storage configuration: %s and %s must be set together", objectStorageAccessKeyKey, objectStorageSecretKeyKey)
+	}
+
+	// A KMS key implies SSE-KMS
+	if objectConfig.SSEKMSKeyID != "" && objectConfig.ServerSideEncryption == "" {
+		objectConfig.ServerSideEncryption = ObjectStorageSSEKMS
+	}
+
+	switch objectConfig.ServerSideEncryption {
+	case "", ObjectStorageSSEKMS:
+	case ObjectStorageSSES3:
+		if objectConfig.SSEKMSKeyID != "" {
+			return ObjectStorageConfig{}, fmt.Errorf("invalid object storage configuration: %s requires %s=%s", objectStorageSSEKMSKeyIDKey, objectStorageSSEKey, ObjectStorageSSEKMS)
+		}
+	default:
+		return ObjectStorageConfig{}, fmt.Errorf("invalid object storage configuration: %s must be %s or %s", objectStorageSSEKey, ObjectStorageSSES3, ObjectStorageSSEKMS)
+	}
+
+	switch objectConfig.ObjectLockMode {
+	case "":
+		if objectConfig.ObjectLockRetentionDays != 0 {
+			return ObjectStorageConfig{}, fmt.Errorf("invalid object storage configuration: %s requires %s", objectStorageLockDaysKey, objectStorageLockModeKey)
+		}
+	case ObjectLockGovernance, ObjectLockCompliance:
+		if objectConfig.ObjectLockRetentionDays <= 0 {
+			return ObjectStorageConfig{}, fmt.Errorf("invalid object storage configuration: %s must be positive", objectStorageLockDaysKey)
+		}
+	default:
+		return ObjectStorageConfig{}, fmt.Errorf("invalid object storage configuration: %s must be %s or %s", objectStorageLockModeKey, ObjectLockGovernance, ObjectLockCompliance)
+	}
+
+	return objectConfig, nil
+}
+
+type FilesystemStorageConfig struct {
+	// Root is the directory holding the backups
+	Root string
+}
+
+// newFilesystemStorageConfig reads the root of the filesystem backend, it is only required when the backend is
+// selected since local backups also write to it
+func newFilesystemStorageConfig(required bool) (FilesystemStorageConfig, error) {
+	root := viper.GetString(filesystemStorageRootKey)
+	if root == "" {
+		if required {
+			return FilesystemStorageConfig{}, fmt.Errorf("filesystem storage configuration is incomplete: %s is not set", filesystemStorageRootKey)
+		}
+		return FilesystemStorageConfig{}, nil
+	}
+
+	if info, err := os.Stat(root); err == nil && !info.IsDir() {
+		return FilesystemStorageConfig{}, fmt.Errorf("invalid filesystem storage configuration: %s is not a directory", filesystemStorageRootKey)
+	}
+
+	return FilesystemStorageConfig{
+		Root: root,
 	}, nil
 }
 
-func createStorageConfigs(storageBackend string) (AzureStorageConfig, ObjectStorageConfig, error) {
-	var azureStorageConfig AzureStorageConfig
-	var objectStorageConfig ObjectStorageConfig
+type GCSStorageConfig struct {
+	BucketName string
+	// CredentialsFile is the JSON key of a service account, the application default credentials are used when empty
+	CredentialsFile string
+}
+
+func newGCSStorageConfig() (GCSStorageConfig, error) {
+	gcsConfig := GCSStorageConfig{
+		BucketName:      viper.GetString(gcsStorageBucketNameKey),
+		CredentialsFile: viper.GetString(gcsStorageCredentialsFileKey),
+	}
+
+	if gcsConfig.BucketName == "" {
+		return GCSStorageConfig{}, fmt.Errorf("gcs storage configuration is incomplete: %s is not set", gcsStorageBucketNameKey)
+	}
+
+	if gcsConfig.CredentialsFile != "" {
+		if _, err := os.Stat(gcsConfig.CredentialsFile); err != nil {
+			return GCSStorageConfig{}, fmt.Errorf("invalid gcs storage configuration: %s: %w", gcsStorageCredentialsFileKey, err)
+		}
+	}
+
+	return gcsConfig, nil
+}
+
+type SFTPStorageConfig struct {
+	Host string
+	Port int
+	User string
+	// Password and PrivateKeyFile authenticate the user, at least one of them is set
+	Password             string
+	PrivateKeyFile       string
+	PrivateKeyPassphrase string
+	// KnownHostsFile verifies the host key of the server, it defaults to ~/.ssh/known_hosts
+	KnownHostsFile string
+	// Root is the directory holding the backups, relative to the home directory of the user unless absolute
+	Root string
+}
+
+// LoadPrivateKey returns the PEM private key of the user, nil when none is configured
+func (c SFTPStorageConfig) LoadPrivateKey() ([]byte, error) {
+	if c.PrivateKeyFile == "" {
+		return nil, nil
+	}
+
+	privateKey, err := os.ReadFile(c.PrivateKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sftp private key: %w", err)
+	}
+
+	return privateKey, nil
+}
+
+func newSFTPStorageConfig() (SFTPStorageConfig, error) {
+	viper.SetDefault(sftpStoragePortKey, 22)
+
+	sftpConfig := SFTPStorageConfig{
+		Host:                 viper.GetString(sftpStorageHostKey),
+		Port:                 viper.GetInt(sftpStoragePortKey),
+		User:                 viper.GetString(sftpStorageUserKey),
+		Password:             viper.GetString(sftpStoragePasswordKey),
+		PrivateKeyFile:       viper.GetString(sftpStoragePrivateKeyFileKey),
+		PrivateKeyPassphrase: viper.GetString(sftpStoragePassphraseKey),
+		KnownHostsFile:       viper.GetString(sftpStorageKnownHostsKey),
+		Root:                 viper.GetString(sftpStorageRootKey),
+	}
+
+	if sftpConfig.Host == "" || sftpConfig.User == "" || sftpConfig.Root == "" {
+		return SFTPStorageConfig{}, fmt.Errorf("sftp storage configuration is incomplete: %s, %s and %s must be set", sftpStorageHostKey, sftpStorageUserKey, sftpStorageRootKey)
+	}
+
+	if sftpConfig.Port <= 0 || sftpConfig.Port > 65535 {
+		return SFTPStorageConfig{}, fmt.Errorf("invalid sftp storage configuration: %s must be a valid port", sftpStoragePortKey)
+	}
+
+	if sftpConfig.Password == "" && sftpConfig.PrivateKeyFile == "" {
+		return SFTPStorageConfig{}, fmt.Errorf("invalid sftp storage configuration: one of %s and %s must be set", sftpStoragePasswordKey, sftpStoragePrivateKeyFileKey)
+	}
+
+	if sftpConfig.KnownHostsFile == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return SFTPStorageConfig{}, fmt.Errorf("invalid sftp storage configuration: %s is not set: %w", sftpStorageKnownHostsKey, err)
+		}
+		sftpConfig.KnownHostsFile = filepath.Join(home, ".ssh", "known_hosts")
+	}
+
+	return sftpConfig, nil
+}
+
+// newStorageReplicationPolicy returns the number of storage backends which must succeed when several are set
+func newStorageReplicationPolicy() (string, error) {
+	policy := strings.ToLower(viper.GetString(storageReplicationPolicyKey))
+	switch policy {
+	case "":
+		return StorageReplicationAll, nil
+	case StorageReplicationAll, StorageReplicationQuorum:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid storage configuration: %s must be %s or %s", storageReplicationPolicyKey, StorageReplicationAll, StorageReplicationQuorum)
+	}
+}
+
+// storageConfigs holds the configuration of the selected storage backends, the others are left empty
+type storageConfigs struct {
+	azure      AzureStorageConfig
+	object     ObjectStorageConfig
+	filesystem FilesystemStorageConfig
+	gcs        GCSStorageConfig
+	sftp       SFTPStorageConfig
+}
+
+func createStorageConfigs(storageBackends []string) (storageConfigs, error) {
+	var configs storageConfigs
+
+	seen := map[string]bool{}
+	for _, storageBackend := range storageBackends {
+		if seen[storageBackend] {
+			return configs, fmt.Errorf("invalid storage configuration: %s is listed twice in %s", storageBackend, storageBackendKey)
+		}
+		seen[storageBackend] = true
+
+		if err := configs.add(storageBackend); err != nil {
+			return configs, err
+		}
+	}
+
+	// Local backups are written to the filesystem root whatever the storage backends
+	if !seen[StorageBackendFilesystem] {
+		var err error
+		configs.filesystem, err = newFilesystemStorageConfig(false)
+		if err != nil {
+			return configs, fmt.Errorf("failed to create filesystem storage config: %w", err)
+		}
+	}
+
+	return configs, nil
+}
+
+func (configs *storageConfigs) add(storageBackend string) error {
 	var err error
 
 	switch storageBackend {
 	case StorageBackendAzure:
-		azureStorageConfig, err = newAzureStorageConfig()
+		configs.azure, err = newAzureStorageConfig()
 		if err != nil {
-			return azureStorageConfig, objectStorageConfig, fmt.Errorf("failed to create Azure storage config: %w", err)
+			return fmt.Errorf("failed to create Azure storage config: %w", err)
 		}
 	case StorageBackendObject:
-		objectStorageConfig, err = newObjectStorageConfig()
+		configs.object, err = newObjectStorageConfig()
+		if err != nil {
+			return fmt.Errorf("failed to create object storage config: %w", err)
+		}
+	case StorageBackendFilesystem:
+		configs.filesystem, err = newFilesystemStorageConfig(true)
+		if err != nil {
+			return fmt.Errorf("failed to create filesystem storage config: %w", err)
+		}
+	case StorageBackendGCS:
+		configs.gcs, err = newGCSStorageConfig()
+		if err != nil {
+			return fmt.Errorf("failed to create gcs storage config: %w", err)
+		}
+	case StorageBackendSFTP:
+		configs.sftp, err = newSFTPStorageConfig()
 		if err != nil {
-			return azureStorageConfig, objectStorageConfig, fmt.Errorf("failed to create object storage config: %w", err)
+			return fmt.Errorf("failed to create sftp storage config: %w", err)
 		}
 	default:
-		return azureStorageConfig, objectStorageConfig, fmt.Errorf("unsupported storage backend: %s (supported: %s, %s)", storageBackend, StorageBackendAzure, StorageBackendObject)
+		return fmt.Errorf("unsupported storage backend: %s (supported: %s, %s, %s, %s, %s)", storageBackend, StorageBackendAzure, StorageBackendObject, StorageBackendFilesystem, StorageBackendGCS, StorageBackendSFTP)
 	}
 
-	return azureStorageConfig, objectStorageConfig, nil
+	return nil
 }
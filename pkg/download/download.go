@@ -0,0 +1,126 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrIncompleteDownload is returned when the connection kept dropping before the whole content was received
+	ErrIncompleteDownload = errors.New("incomplete download")
+	// ErrContentChanged is returned when the content changed on the server while resuming a download
+	ErrContentChanged = errors.New("content changed during download")
+)
+
+const (
+	DefaultRequestTimeout = 1 * time.Minute
+	DefaultReadTimeout    = 2 * time.Minute
+	DefaultMaxRetries     = 5
+	DefaultInitialBackoff = 1 * time.Second
+	DefaultMaxBackoff     = 1 * time.Minute
+)
+
+// Options configures the timeouts and retries of the downloads, zero values use the defaults
+type Options struct {
+	// RequestTimeout is the time to wait for the response headers of each request
+	RequestTimeout time.Duration
+	// ReadTimeout is the time the body may stall before the connection is considered dropped
+	ReadTimeout time.Duration
+	// MaxRetries is the number of consecutive failed attempts after which the download is abandoned
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+func (o Options) withDefaults() Options {
+	if o.RequestTimeout <= 0 {
+		o.RequestTimeout = DefaultRequestTimeout
+	}
+	if o.ReadTimeout <= 0 {
+		o.ReadTimeout = DefaultReadTimeout
+	}
+	if o.MaxRetries <= 0 {
+		o.MaxRetries = DefaultMaxRetries
+	}
+	if o.InitialBackoff <= 0 {
+		o.InitialBackoff = DefaultInitialBackoff
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = DefaultMaxBackoff
+	}
+	return o
+}
+
+// backoff returns the time to wait before the retry following the given number of consecutive failures
+func (o Options) backoff(failures int) time.Duration {
+	backoff := o.InitialBackoff
+	for i := 1; i < failures && backoff < o.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, o.MaxBackoff)
+}
+
+// URLFunc returns the URL to download, it is called again when the server rejects a signed URL which expired
+type URLFunc func(ctx context.Context) (string, error)
+
+// StaticURL returns a URLFunc which always returns url
+func StaticURL(url string) URLFunc {
+	return func(context.Context) (string, error) {
+		return url, nil
+	}
+}
+
+// StatusError is returned when the server answers with an unexpected status
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("got status: %s", e.Status)
+}
+
+// retryable tells whether the request may succeed when sent again
+func (e *StatusError) retryable() bool {
+	return e.StatusCode >= http.StatusInternalServerError ||
+		e.StatusCode == http.StatusTooManyRequests ||
+		e.StatusCode == http.StatusRequestTimeout
+}
+
+type Downloader interface {
+	// Open starts the download, the returned reader resumes it with Range requests when the connection drops
+	Open(ctx context.Context, getURL URLFunc) (io.ReadCloser, error)
+}
+
+type downloader struct {
+	client  *http.Client
+	options Options
+}
+
+// NewDownloader returns a Downloader sending its requests with client, which should not set a Timeout
+// since it would bound the whole transfer, Options.RequestTimeout and Options.ReadTimeout are used instead
+func NewDownloader(client *http.Client, options Options) Downloader {
+	return &downloader{
+		client:  client,
+		options: options.withDefaults(),
+	}
+}
+
+func (d *downloader) Open(ctx context.Context, getURL URLFunc) (io.ReadCloser, error) {
+	reader := &resumingReader{
+		ctx:        ctx,
+		downloader: d,
+		getURL:     getURL,
+		size:       -1,
+	}
+
+	if err := reader.connect(); err != nil {
+		return nil, err
+	}
+
+	return reader, nil
+}
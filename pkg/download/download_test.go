@@ -0,0 +1,358 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testOptions = Options{
+	RequestTimeout: time.Second,
+	ReadTimeout:    time.Second,
+	MaxRetries:     3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     10 * time.Millisecond,
+}
+
+// testServer serves content with Range support and records the Range header of each request
+type testServer struct {
+	*httptest.Server
+	mu     sync.Mutex
+	ranges []string
+}
+
+// newTestServer starts a server whose handle is called with the index of each request
+func newTestServer(t *testing.T, handle func(request int, w http.ResponseWriter, r *http.Request)) *testServer {
+	t.Helper()
+
+	server := &testServer{}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.mu.Lock()
+		request := len(server.ranges)
+		server.ranges = append(server.ranges, r.Header.Get("Range"))
+		server.mu.Unlock()
+
+		handle(request, w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func (s *testServer) requestedRanges() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.ranges...)
+}
+
+func serveContent(w http.ResponseWriter, r *http.Request, etag string, content []byte) {
+	w.Header().Set("ETag", etag)
+	http.ServeContent(w, r, "archive.tar.gz", time.Time{}, bytes.NewReader(content))
+}
+
+// cuttingWriter closes the connection once limit bytes of the body were written
+type cuttingWriter struct {
+	http.ResponseWriter
+	remaining int
+}
+
+func cutAfter(w http.ResponseWriter, limit int) http.ResponseWriter {
+	return &cuttingWriter{ResponseWriter: w, remaining: limit}
+}
+
+func (w *cuttingWriter) Write(p []byte) (int, error) {
+	if len(p) < w.remaining {
+		n, err := w.ResponseWriter.Write(p)
+		w.remaining -= n
+		return n, err
+	}
+
+	n, _ := w.ResponseWriter.Write(p[:w.remaining])
+	w.remaining = 0
+
+	controller := http.NewResponseController(w.ResponseWriter)
+	_ = controller.Flush()
+	conn, _, err := controller.Hijack()
+	if err == nil {
+		_ = conn.Close()
+	}
+
+	return n, errors.New("connection cut")
+}
+
+func randomContent(t *testing.T, size int) []byte {
+	t.Helper()
+
+	content := make([]byte, size)
+	_, err := rand.Read(content)
+	require.NoError(t, err)
+
+	return content
+}
+
+func download(t *testing.T, url string) ([]byte, error) {
+	t.Helper()
+
+	reader, err := NewDownloader(http.DefaultClient, testOptions).Open(context.Background(), StaticURL(url))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = reader.Close() }()
+
+	return io.ReadAll(reader)
+}
+
+func TestDownloader_Success(t *testing.T) {
+	// Given
+	content := randomContent(t, 1<<20)
+	server := newTestServer(t, func(_ int, w http.ResponseWriter, r *http.Request) {
+		serveContent(w, r, `"v1"`, content)
+	})
+
+	// When
+	result, err := download(t, server.URL)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, content, result)
+	assert.Equal(t, []string{""}, server.requestedRanges())
+}
+
+func TestDownloader_ResumesDroppedConnections(t *testing.T) {
+	// Given
+	content := randomContent(t, 1<<20)
+	server := newTestServer(t, func(request int, w http.ResponseWriter, r *http.Request) {
+		if request < 3 {
+			w = cutAfter(w, 100_000)
+		}
+		serveContent(w, r, `"v1"`, content)
+	})
+
+	// When
+	result, err := download(t, server.URL)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, content, result)
+	assert.Equal(t, []string{"", "bytes=100000-", "bytes=200000-", "bytes=300000-"}, server.requestedRanges())
+}
+
+func TestDownloader_ResumesWhenRangeIsIgnored(t *testing.T) {
+	// Given
+	content := randomContent(t, 1<<20)
+	server := newTestServer(t, func(request int, w http.ResponseWriter, _ *http.Request) {
+		if request == 0 {
+			w = cutAfter(w, 100_000)
+		}
+		w.Header().Set("Content-Type", "application/gzip")
+		_, _ = w.Write(content)
+	})
+
+	// When
+	result, err := download(t, server.URL)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, content, result)
+	assert.Equal(t, []string{"", "bytes=100000-"}, server.requestedRanges())
+}
+
+func TestDownloader_ResumesStalledConnection(t *testing.T) {
+	// Given
+	content := randomContent(t, 1<<20)
+	server := newTestServer(t, func(request int, w http.ResponseWriter, r *http.Request) {
+		if request == 0 {
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Content-Length", "1048576")
+			_, _ = w.Write(content[:1000])
+			_ = http.NewResponseController(w).Flush()
+			<-r.Context().Done()
+			return
+		}
+		serveContent(w, r, `"v1"`, content)
+	})
+
+	options := testOptions
+	options.ReadTimeout = 50 * time.Millisecond
+
+	// When
+	reader, err := NewDownloader(http.DefaultClient, options).Open(context.Background(), StaticURL(server.URL))
+	require.NoError(t, err)
+	defer func() { _ = reader.Close() }()
+	result, err := io.ReadAll(reader)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, content, result)
+	assert.Equal(t, []string{"", "bytes=1000-"}, server.requestedRanges())
+}
+
+func TestDownloader_ContentChanged(t *testing.T) {
+	// Given
+	content := randomContent(t, 1<<20)
+	server := newTestServer(t, func(request int, w http.ResponseWriter, r *http.Request) {
+		if request == 0 {
+			serveContent(cutAfter(w, 100_000), r, `"v1"`, content)
+			return
+		}
+		serveContent(w, r, `"v2"`, content)
+	})
+
+	// When
+	_, err := download(t, server.URL)
+
+	// Then
+	assert.ErrorIs(t, err, ErrContentChanged)
+}
+
+func TestDownloader_SizeChanged(t *testing.T) {
+	// Given
+	content := randomContent(t, 1<<20)
+	server := newTestServer(t, func(request int, w http.ResponseWriter, r *http.Request) {
+		if request == 0 {
+			serveContent(cutAfter(w, 100_000), r, "", content)
+			return
+		}
+		serveContent(w, r, "", content[:500_000])
+	})
+
+	// When
+	_, err := download(t, server.URL)
+
+	// Then
+	assert.ErrorIs(t, err, ErrContentChanged)
+}
+
+func TestDownloader_RetriesServerErrors(t *testing.T) {
+	// Given
+	content := randomContent(t, 1024)
+	server := newTestServer(t, func(request int, w http.ResponseWriter, r *http.Request) {
+		if request < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		serveContent(w, r, `"v1"`, content)
+	})
+
+	// When
+	result, err := download(t, server.URL)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, content, result)
+	assert.Len(t, server.requestedRanges(), 3)
+}
+
+func TestDownloader_ClientErrorIsNotRetried(t *testing.T) {
+	// Given
+	server := newTestServer(t, func(_ int, w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	// When
+	_, err := download(t, server.URL)
+
+	// Then
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+	assert.EqualError(t, err, "got status: 404 Not Found")
+	assert.Len(t, server.requestedRanges(), 1)
+}
+
+func TestDownloader_GivesUpWhenConnectionKeepsDropping(t *testing.T) {
+	// Given
+	content := randomContent(t, 1<<20)
+	// Failures are only counted while no byte is received
+	server := newTestServer(t, func(request int, w http.ResponseWriter, r *http.Request) {
+		if request == 0 {
+			serveContent(cutAfter(w, 10), r, `"v1"`, content)
+			return
+		}
+		serveContent(cutAfter(w, 0), r, `"v1"`, content)
+	})
+	options := testOptions
+	options.MaxRetries = 2
+
+	// When
+	reader, err := NewDownloader(http.DefaultClient, options).Open(context.Background(), StaticURL(server.URL))
+	require.NoError(t, err)
+	defer func() { _ = reader.Close() }()
+	_, err = io.ReadAll(reader)
+
+	// Then
+	assert.ErrorIs(t, err, ErrIncompleteDownload)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Len(t, server.requestedRanges(), 3)
+}
+
+func TestDownloader_RefreshesExpiredURL(t *testing.T) {
+	// Given
+	content := randomContent(t, 1<<20)
+	server := newTestServer(t, func(request int, w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/expired" && request == 0:
+			serveContent(cutAfter(w, 100_000), r, `"v1"`, content)
+		case r.URL.Path == "/expired":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			serveContent(w, r, `"v1"`, content)
+		}
+	})
+
+	calls := 0
+	getURL := func(context.Context) (string, error) {
+		calls++
+		if calls == 1 {
+			return server.URL + "/expired", nil
+		}
+		return server.URL + "/refreshed", nil
+	}
+
+	// When
+	reader, err := NewDownloader(http.DefaultClient, testOptions).Open(context.Background(), getURL)
+	require.NoError(t, err)
+	defer func() { _ = reader.Close() }()
+	result, err := io.ReadAll(reader)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, content, result)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, []string{"", "bytes=100000-", "bytes=100000-"}, server.requestedRanges())
+}
+
+func TestDownloader_ContextCancellation(t *testing.T) {
+	// Given
+	server := newTestServer(t, func(_ int, w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// When
+	_, err := NewDownloader(http.DefaultClient, testOptions).Open(ctx, StaticURL(server.URL))
+
+	// Then
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestOptions_Backoff(t *testing.T) {
+	options := Options{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	assert.Equal(t, time.Second, options.backoff(1))
+	assert.Equal(t, 2*time.Second, options.backoff(2))
+	assert.Equal(t, 4*time.Second, options.backoff(3))
+	assert.Equal(t, 5*time.Second, options.backoff(4))
+	assert.Equal(t, 5*time.Second, options.backoff(60))
+}
@@ -0,0 +1,315 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kumojin/repo-backup-cli/pkg/logging"
+)
+
+// resumingReader reads the body of a download and reconnects from the last received byte when the connection drops
+type resumingReader struct {
+	ctx        context.Context
+	downloader *downloader
+	getURL     URLFunc
+	url        string
+
+	body   io.ReadCloser
+	cancel context.CancelFunc
+	// timer cancels the current request when the server stops answering or sending data
+	timer *time.Timer
+
+	// offset is the number of bytes returned to the caller
+	offset int64
+	// size is the length of the whole content, or -1 when the server did not send it
+	size int64
+	// etag identifies the content, it is sent with If-Range so that a changed content is not stitched
+	etag string
+	// skip is the number of bytes to discard when the server ignored the Range header and sent the whole content
+	skip    int64
+	started bool
+
+	failures int
+	lastErr  error
+	// err is returned by every Read once the download is over
+	err error
+}
+
+func (r *resumingReader) Read(p []byte) (int, error) {
+	for {
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		if r.body == nil {
+			if err := r.connect(); err != nil {
+				r.err = err
+				return 0, err
+			}
+		}
+
+		n, err := r.body.Read(p)
+		if n > 0 {
+			r.timer.Reset(r.downloader.options.ReadTimeout)
+			n = r.discardSkipped(p, n)
+			r.offset += int64(n)
+			r.failures = 0
+		}
+
+		if err == nil {
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+
+		r.close()
+
+		if errors.Is(err, io.EOF) {
+			if r.size < 0 || r.offset == r.size {
+				r.err = io.EOF
+				return n, io.EOF
+			}
+			err = io.ErrUnexpectedEOF
+		}
+
+		if r.ctx.Err() != nil {
+			r.err = r.ctx.Err()
+			return n, r.err
+		}
+
+		r.failures++
+		r.lastErr = err
+		logging.NewLogger(r.ctx).Warn("download interrupted, resuming",
+			slog.Int64("offset", r.offset),
+			slog.Int64("size", r.size),
+			slog.Any("error", err),
+		)
+
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+func (r *resumingReader) Close() error {
+	r.close()
+	if r.err == nil {
+		r.err = http.ErrBodyReadAfterClose
+	}
+	return nil
+}
+
+func (r *resumingReader) close() {
+	if r.body == nil {
+		return
+	}
+
+	r.timer.Stop()
+	_ = r.body.Close()
+	r.cancel()
+	r.body = nil
+}
+
+// discardSkipped drops the bytes of p which were already returned and returns the number of bytes left at its start
+func (r *resumingReader) discardSkipped(p []byte, n int) int {
+	if r.skip == 0 {
+		return n
+	}
+
+	skipped := int(min(int64(n), r.skip))
+	r.skip -= int64(skipped)
+	return copy(p, p[skipped:n])
+}
+
+// connect sends requests until one is accepted, waiting longer after each consecutive failure
+func (r *resumingReader) connect() error {
+	options := r.downloader.options
+
+	for {
+		if r.failures > 0 {
+			if r.failures > options.MaxRetries {
+				return r.giveUp()
+			}
+
+			if err := sleep(r.ctx, options.backoff(r.failures)); err != nil {
+				return err
+			}
+		}
+
+		retry, err := r.request()
+		if err == nil {
+			return nil
+		}
+		if !retry {
+			return err
+		}
+		if r.ctx.Err() != nil {
+			return r.ctx.Err()
+		}
+
+		r.failures++
+		r.lastErr = err
+		logging.NewLogger(r.ctx).Warn("download request failed, retrying",
+			slog.Int("failures", r.failures),
+			slog.Any("error", err),
+		)
+	}
+}
+
+func (r *resumingReader) giveUp() error {
+	if r.started {
+		return fmt.Errorf("%w: received %d of %d bytes after %d retries: %w", ErrIncompleteDownload, r.offset, r.size, r.downloader.options.MaxRetries, r.lastErr)
+	}
+
+	return fmt.Errorf("giving up after %d retries: %w", r.downloader.options.MaxRetries, r.lastErr)
+}
+
+// request sends a request from the current offset and tells whether it may be retried when it fails
+func (r *resumingReader) request() (bool, error) {
+	options := r.downloader.options
+
+	if r.url == "" {
+		url, err := r.getURL(r.ctx)
+		if err != nil {
+			return false, fmt.Errorf("failed to get download URL: %w", err)
+		}
+		r.url = url
+	}
+
+	ctx, cancel := context.WithCancel(r.ctx)
+	timer := time.AfterFunc(options.RequestTimeout, cancel)
+	release := func() {
+		timer.Stop()
+		cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.url, nil)
+	if err != nil {
+		release()
+		return false, err
+	}
+
+	if r.offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", r.offset))
+		if r.etag != "" {
+			req.Header.Set("If-Range", r.etag)
+		}
+	}
+
+	resp, err := r.downloader.client.Do(req)
+	if err != nil {
+		release()
+		return true, err
+	}
+
+	retry, err := r.accept(resp)
+	if err != nil {
+		_ = resp.Body.Close()
+		release()
+		return retry, err
+	}
+
+	r.body = resp.Body
+	r.cancel = cancel
+	r.timer = timer
+	r.timer.Reset(options.ReadTimeout)
+	r.started = true
+
+	return false, nil
+}
+
+// accept checks that the response continues the content from the current offset
+func (r *resumingReader) accept(resp *http.Response) (bool, error) {
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return false, r.acceptFullContent(resp)
+
+	case resp.StatusCode == http.StatusPartialContent && r.offset > 0:
+		return false, r.acceptPartialContent(resp)
+
+	case r.started && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden):
+		// The signed URL expired while resuming, a new one is requested
+		r.url = ""
+		return true, &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+
+	default:
+		statusErr := &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+		return statusErr.retryable(), statusErr
+	}
+}
+
+func (r *resumingReader) acceptFullContent(resp *http.Response) error {
+	etag := strongETag(resp)
+
+	if !r.started {
+		r.size = resp.ContentLength
+		r.etag = etag
+		return nil
+	}
+
+	if r.etag != "" && etag != r.etag {
+		return ErrContentChanged
+	}
+	if r.size >= 0 && resp.ContentLength != r.size {
+		return fmt.Errorf("%w: Content-Length changed from %d to %d", ErrContentChanged, r.size, resp.ContentLength)
+	}
+
+	r.skip = r.offset
+	return nil
+}
+
+func (r *resumingReader) acceptPartialContent(resp *http.Response) error {
+	contentRange := resp.Header.Get("Content-Range")
+
+	var start, end int64
+	var total string
+	if _, err := fmt.Sscanf(contentRange, "bytes %d-%d/%s", &start, &end, &total); err != nil {
+		return fmt.Errorf("invalid Content-Range %q: %w", contentRange, err)
+	}
+
+	if start != r.offset {
+		return fmt.Errorf("got Content-Range %q when resuming from byte %d", contentRange, r.offset)
+	}
+
+	if total != "*" {
+		size, err := strconv.ParseInt(total, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid Content-Range %q: %w", contentRange, err)
+		}
+		if r.size >= 0 && size != r.size {
+			return fmt.Errorf("%w: size changed from %d to %d", ErrContentChanged, r.size, size)
+		}
+		r.size = size
+	}
+
+	return nil
+}
+
+// strongETag returns the ETag of the response when it can be used with If-Range
+func strongETag(resp *http.Response) string {
+	etag := resp.Header.Get("ETag")
+	if strings.HasPrefix(etag, "W/") {
+		return ""
+	}
+	return etag
+}
+
+func sleep(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,58 @@
+package encryption
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+)
+
+type ageEncryptor struct {
+	recipients []age.Recipient
+}
+
+// NewAgeEncryptor creates an encryptor for age X25519 recipients, given as age1... public keys
+func NewAgeEncryptor(recipients []string) (Encryptor, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("at least one age recipient is required")
+	}
+
+	parsed := make([]age.Recipient, 0, len(recipients))
+	for _, recipient := range recipients {
+		x25519Recipient, err := age.ParseX25519Recipient(strings.TrimSpace(recipient))
+		if err != nil {
+			return nil, fmt.Errorf("invalid age recipient %q: %w", recipient, err)
+		}
+		parsed = append(parsed, x25519Recipient)
+	}
+
+	return &ageEncryptor{recipients: parsed}, nil
+}
+
+func (e *ageEncryptor) Encrypt(w io.Writer) (io.WriteCloser, error) {
+	return age.Encrypt(w, e.recipients...)
+}
+
+func (e *ageEncryptor) Extension() string {
+	return AgeExtension
+}
+
+type ageDecryptor struct {
+	identities []age.Identity
+}
+
+// NewAgeDecryptor creates a decryptor from an age identity file, as written by age-keygen
+func NewAgeDecryptor(identityFile io.Reader) (Decryptor, error) {
+	identities, err := age.ParseIdentities(identityFile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid age identity file: %w", err)
+	}
+
+	return &ageDecryptor{identities: identities}, nil
+}
+
+func (d *ageDecryptor) Decrypt(r io.Reader) (io.Reader, error) {
+	return age.Decrypt(r, d.identities...)
+}
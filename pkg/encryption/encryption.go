@@ -0,0 +1,74 @@
+package encryption
+
+import (
+	"errors"
+	"io"
+	"strings"
+)
+
+const (
+	AgeExtension = ".age"
+	PGPExtension = ".gpg"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported encryption format")
+
+// Encryptor encrypts archives for a set of public-key recipients
+type Encryptor interface {
+	// Encrypt returns a writer encrypting what is written to it into w, the output is only complete once it is closed
+	Encrypt(w io.Writer) (io.WriteCloser, error)
+	// Extension is appended to the name of the encrypted archives
+	Extension() string
+}
+
+// Decryptor decrypts archives with private keys
+type Decryptor interface {
+	Decrypt(r io.Reader) (io.Reader, error)
+}
+
+// EncryptReader returns a reader streaming the encrypted content of reader, so that large archives are never held
+// in memory. It must be closed to stop the encryption when the content is not read until the end.
+func EncryptReader(encryptor Encryptor, reader io.Reader) io.ReadCloser {
+	pipeReader, pipeWriter := io.Pipe()
+
+	go func() {
+		writer, err := encryptor.Encrypt(pipeWriter)
+		if err != nil {
+			_ = pipeWriter.CloseWithError(err)
+			return
+		}
+
+		_, err = io.Copy(writer, reader)
+		if closeErr := writer.Close(); err == nil {
+			err = closeErr
+		}
+
+		_ = pipeWriter.CloseWithError(err)
+	}()
+
+	return pipeReader
+}
+
+// Format returns the extension of the encryption format of the file, or ErrUnsupportedFormat
+func Format(fileName string) (string, error) {
+	for _, extension := range []string{AgeExtension, PGPExtension} {
+		if strings.HasSuffix(fileName, extension) {
+			return extension, nil
+		}
+	}
+
+	return "", ErrUnsupportedFormat
+}
+
+// NewDecryptor creates a decryptor for the format, given by its extension, from an age identity file or an armored
+// OpenPGP private key
+func NewDecryptor(format string, privateKey io.Reader, passphrase []byte) (Decryptor, error) {
+	switch format {
+	case AgeExtension:
+		return NewAgeDecryptor(privateKey)
+	case PGPExtension:
+		return NewPGPDecryptor(privateKey, passphrase)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
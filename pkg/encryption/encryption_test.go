@@ -0,0 +1,180 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAgeKeys(t *testing.T) (Encryptor, Decryptor) {
+	t.Helper()
+
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	encryptor, err := NewAgeEncryptor([]string{identity.Recipient().String()})
+	require.NoError(t, err)
+
+	decryptor, err := NewAgeDecryptor(strings.NewReader(identity.String() + "\n"))
+	require.NoError(t, err)
+
+	return encryptor, decryptor
+}
+
+func newPGPKeys(t *testing.T, passphrase []byte) (Encryptor, Decryptor) {
+	t.Helper()
+
+	entity, err := openpgp.NewEntity("rbk", "", "rbk@example.com", nil)
+	require.NoError(t, err)
+
+	publicKey := &bytes.Buffer{}
+	writer, err := armor.Encode(publicKey, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.Serialize(writer))
+	require.NoError(t, writer.Close())
+
+	if passphrase != nil {
+		require.NoError(t, entity.EncryptPrivateKeys(passphrase, nil))
+	}
+
+	privateKey := &bytes.Buffer{}
+	writer, err = armor.Encode(privateKey, openpgp.PrivateKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.SerializePrivateWithoutSigning(writer, nil))
+	require.NoError(t, writer.Close())
+
+	encryptor, err := NewPGPEncryptor(publicKey)
+	require.NoError(t, err)
+
+	decryptor, err := NewPGPDecryptor(privateKey, passphrase)
+	require.NoError(t, err)
+
+	return encryptor, decryptor
+}
+
+func TestEncryption_RoundTrip(t *testing.T) {
+	ageEncryptor, ageDecryptor := newAgeKeys(t)
+	pgpEncryptor, pgpDecryptor := newPGPKeys(t, nil)
+	protectedPGPEncryptor, protectedPGPDecryptor := newPGPKeys(t, []byte("secret"))
+
+	tests := []struct {
+		name              string
+		encryptor         Encryptor
+		decryptor         Decryptor
+		expectedExtension string
+	}{
+		{name: "age", encryptor: ageEncryptor, decryptor: ageDecryptor, expectedExtension: ".age"},
+		{name: "OpenPGP", encryptor: pgpEncryptor, decryptor: pgpDecryptor, expectedExtension: ".gpg"},
+		{name: "OpenPGP with passphrase", encryptor: protectedPGPEncryptor, decryptor: protectedPGPDecryptor, expectedExtension: ".gpg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			plaintext := make([]byte, 3<<20)
+			_, err := rand.Read(plaintext)
+			require.NoError(t, err)
+
+			// When
+			encrypted, err := io.ReadAll(EncryptReader(tt.encryptor, bytes.NewReader(plaintext)))
+			require.NoError(t, err)
+
+			decrypted, err := tt.decryptor.Decrypt(bytes.NewReader(encrypted))
+			require.NoError(t, err)
+			result, err := io.ReadAll(decrypted)
+
+			// Then
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedExtension, tt.encryptor.Extension())
+			assert.False(t, bytes.Contains(encrypted, plaintext[:64]))
+			assert.Equal(t, sha256.Sum256(plaintext), sha256.Sum256(result))
+		})
+	}
+}
+
+func TestEncryptReader_Streams(t *testing.T) {
+	// Given
+	encryptor, decryptor := newAgeKeys(t)
+	// Far more than the pipe ever holds, the source is only read as the output is consumed
+	source := &countingReader{reader: io.LimitReader(zeroReader{}, 1<<30)}
+
+	// When
+	reader := EncryptReader(encryptor, source)
+	decrypted, err := decryptor.Decrypt(reader)
+	require.NoError(t, err)
+
+	_, err = io.CopyN(io.Discard, decrypted, 1<<20)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+
+	// Then
+	assert.Less(t, source.count.Load(), int64(8<<20))
+}
+
+func TestEncryptReader_SourceError(t *testing.T) {
+	// Given
+	encryptor, _ := newAgeKeys(t)
+	expectedError := io.ErrUnexpectedEOF
+
+	// When
+	_, err := io.ReadAll(EncryptReader(encryptor, io.MultiReader(strings.NewReader("partial"), errorReader{err: expectedError})))
+
+	// Then
+	assert.ErrorIs(t, err, expectedError)
+}
+
+func TestNewAgeEncryptor_InvalidRecipient(t *testing.T) {
+	_, err := NewAgeEncryptor([]string{"not-a-key"})
+
+	assert.Error(t, err)
+}
+
+func TestFormat(t *testing.T) {
+	format, err := Format("2025-07-23-kumojin-migration.tar.gz.age")
+	assert.NoError(t, err)
+	assert.Equal(t, AgeExtension, format)
+
+	format, err = Format("2025-07-23-kumojin-migration.tar.gz.gpg")
+	assert.NoError(t, err)
+	assert.Equal(t, PGPExtension, format)
+
+	_, err = Format("2025-07-23-kumojin-migration.tar.gz")
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+type countingReader struct {
+	reader io.Reader
+	// count is read while the encryption goroutine may still be reading
+	count atomic.Int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count.Add(int64(n))
+	return n, err
+}
+
+type errorReader struct {
+	err error
+}
+
+func (r errorReader) Read(_ []byte) (int, error) {
+	return 0, r.err
+}
@@ -0,0 +1,70 @@
+package encryption
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+type pgpEncryptor struct {
+	recipients openpgp.EntityList
+}
+
+// NewPGPEncryptor creates an encryptor for the OpenPGP public keys of an armored keyring
+func NewPGPEncryptor(publicKeys io.Reader) (Encryptor, error) {
+	recipients, err := openpgp.ReadArmoredKeyRing(publicKeys)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OpenPGP public keys: %w", err)
+	}
+
+	if len(recipients) == 0 {
+		return nil, errors.New("at least one OpenPGP public key is required")
+	}
+
+	return &pgpEncryptor{recipients: recipients}, nil
+}
+
+func (e *pgpEncryptor) Encrypt(w io.Writer) (io.WriteCloser, error) {
+	return openpgp.Encrypt(w, e.recipients, nil, &openpgp.FileHints{IsBinary: true}, nil)
+}
+
+func (e *pgpEncryptor) Extension() string {
+	return PGPExtension
+}
+
+type pgpDecryptor struct {
+	keyring openpgp.EntityList
+}
+
+// NewPGPDecryptor creates a decryptor from an armored OpenPGP private key, protected keys are unlocked with passphrase
+func NewPGPDecryptor(privateKeys io.Reader, passphrase []byte) (Decryptor, error) {
+	keyring, err := openpgp.ReadArmoredKeyRing(privateKeys)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OpenPGP private key: %w", err)
+	}
+
+	for _, entity := range keyring {
+		if entity.PrivateKey == nil {
+			return nil, errors.New("OpenPGP key has no private key")
+		}
+
+		if entity.PrivateKey.Encrypted {
+			if err := entity.DecryptPrivateKeys(passphrase); err != nil {
+				return nil, fmt.Errorf("failed to unlock OpenPGP private key: %w", err)
+			}
+		}
+	}
+
+	return &pgpDecryptor{keyring: keyring}, nil
+}
+
+func (d *pgpDecryptor) Decrypt(r io.Reader) (io.Reader, error) {
+	message, err := openpgp.ReadMessage(r, d.keyring, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return message.UnverifiedBody, nil
+}
@@ -0,0 +1,207 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+var ErrEmptyRepository = errors.New("repository is empty")
+
+// systemCertFiles are the usual locations of the system CA certificates, the ones searched by crypto/x509
+var systemCertFiles = []string{
+	"/etc/ssl/certs/ca-certificates.crt",                // Debian/Ubuntu/Gentoo etc.
+	"/etc/pki/tls/certs/ca-bundle.crt",                  // Fedora/RHEL 6
+	"/etc/ssl/ca-bundle.pem",                            // OpenSUSE
+	"/etc/pki/tls/cacert.pem",                           // OpenELEC
+	"/etc/pki/ca-trust/extracted/pem/tls-ca-bundle.pem", // CentOS/RHEL 7
+	"/etc/ssl/cert.pem",                                 // Alpine Linux, macOS, FreeBSD
+}
+
+type Client interface {
+	// MirrorClone creates a bare mirror of the repository at url in path
+	MirrorClone(ctx context.Context, url string, path string) error
+	// CreateBundle writes every ref of the repository at repositoryPath to a bundle file
+	CreateBundle(ctx context.Context, repositoryPath string, bundlePath string) error
+	// Push pushes every branch and tag of the repository at repositoryPath to url
+	Push(ctx context.Context, repositoryPath string, url string) error
+}
+
+// TokenFunc returns the GitHub token sent to HTTP remotes, it is called for every git command so that a short-lived
+// token such as a GitHub App installation token is refreshed during a long backup
+type TokenFunc func(ctx context.Context) (string, error)
+
+type defaultClient struct {
+	token              TokenFunc
+	caBundleFile       string
+	insecureSkipVerify bool
+}
+
+// ClientOption configures the HTTPS remotes of a git client
+type ClientOption func(*defaultClient)
+
+// WithCABundleFile trusts the PEM certificates of the file in addition to the system ones, for servers behind an
+// internal CA
+func WithCABundleFile(path string) ClientOption {
+	return func(c *defaultClient) {
+		c.caBundleFile = path
+	}
+}
+
+// WithInsecureSkipVerify disables the verification of the server certificates
+func WithInsecureSkipVerify(insecureSkipVerify bool) ClientOption {
+	return func(c *defaultClient) {
+		c.insecureSkipVerify = insecureSkipVerify
+	}
+}
+
+// NewClient creates a git client authenticating HTTP remotes with the given GitHub token
+func NewClient(token string) Client {
+	return NewClientWithTokenFunc(func(context.Context) (string, error) {
+		return token, nil
+	})
+}
+
+// NewClientWithTokenFunc creates a git client authenticating HTTP remotes with the tokens returned by token
+func NewClientWithTokenFunc(token TokenFunc, options ...ClientOption) Client {
+	client := &defaultClient{
+		token: token,
+	}
+	for _, option := range options {
+		option(client)
+	}
+
+	return client
+}
+
+func (c *defaultClient) MirrorClone(ctx context.Context, url string, path string) error {
+	_, err := c.run(ctx, "", "clone", "--mirror", "--quiet", url, path)
+	return err
+}
+
+func (c *defaultClient) CreateBundle(ctx context.Context, repositoryPath string, bundlePath string) error {
+	refs, err := c.run(ctx, repositoryPath, "for-each-ref", "--count=1")
+	if err != nil {
+		return err
+	}
+
+	// git refuses to create a bundle without any ref
+	if strings.TrimSpace(refs) == "" {
+		return ErrEmptyRepository
+	}
+
+	_, err = c.run(ctx, repositoryPath, "bundle", "create", "--quiet", bundlePath, "--all")
+	return err
+}
+
+func (c *defaultClient) run(ctx context.Context, dir string, args ...string) (string, error) {
+	env, err := c.env(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if c.caBundleFile != "" {
+		caInfoFile, err := c.writeCAInfo()
+		if err != nil {
+			return "", err
+		}
+		defer func() { _ = os.Remove(caInfoFile) }()
+
+		env = append(env, "GIT_SSL_CAINFO="+caInfoFile)
+	}
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
+
+// env returns the environment passed to git. The token is sent through an extra header configured
+// with environment variables so that it never shows up in the process arguments or in remote URLs.
+func (c *defaultClient) env(ctx context.Context) ([]string, error) {
+	env := []string{"GIT_TERMINAL_PROMPT=0"}
+	if c.insecureSkipVerify {
+		env = append(env, "GIT_SSL_NO_VERIFY=true")
+	}
+
+	token, err := c.token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get github token: %w", err)
+	}
+	if token == "" {
+		return env, nil
+	}
+
+	credentials := base64.StdEncoding.EncodeToString([]byte("x-access-token:" + token))
+
+	return append(env,
+		"GIT_CONFIG_COUNT=1",
+		"GIT_CONFIG_KEY_0=http.extraHeader",
+		"GIT_CONFIG_VALUE_0=Authorization: Basic "+credentials,
+	), nil
+}
+
+// writeCAInfo writes the system CA certificates followed by the CA bundle to a temporary file for GIT_SSL_CAINFO,
+// which replaces the system certificates trusted by git
+func (c *defaultClient) writeCAInfo() (string, error) {
+	caBundle, err := os.ReadFile(c.caBundleFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+
+	file, err := os.CreateTemp("", "rbk-ca-*.pem")
+	if err != nil {
+		return "", fmt.Errorf("failed to create CA file: %w", err)
+	}
+
+	caInfo := append(systemCertificates(), '\n')
+	caInfo = append(caInfo, caBundle...)
+	_, err = file.Write(caInfo)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write CA file: %w", err)
+	}
+
+	return file.Name(), nil
+}
+
+// systemCertificates returns the PEM certificates of the system, from SSL_CERT_FILE when set like crypto/x509. It
+// returns none when they are not found, git then only trusts the CA bundle.
+func systemCertificates() []byte {
+	files := systemCertFiles
+	if file := os.Getenv("SSL_CERT_FILE"); file != "" {
+		files = []string{file}
+	}
+
+	for _, file := range files {
+		certificates, err := os.ReadFile(file)
+		if err == nil {
+			return certificates
+		}
+	}
+
+	return nil
+}
+
+func (c *defaultClient) Push(ctx context.Context, repositoryPath string, url string) error {
+	// Hidden refs such as refs/pull/* are rejected by GitHub, so only branches and tags are pushed
+	_, err := c.run(ctx, repositoryPath, "push", "--quiet", url, "refs/heads/*:refs/heads/*", "refs/tags/*:refs/tags/*")
+	return err
+}
@@ -0,0 +1,45 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCAInfo_TrustsSystemCertificatesAndCABundle(t *testing.T) {
+	// Given
+	dir := t.TempDir()
+	systemFile := filepath.Join(dir, "system.pem")
+	require.NoError(t, os.WriteFile(systemFile, []byte("system certificates"), 0o600))
+	t.Setenv("SSL_CERT_FILE", systemFile)
+
+	bundleFile := filepath.Join(dir, "bundle.pem")
+	require.NoError(t, os.WriteFile(bundleFile, []byte("internal CA"), 0o600))
+
+	client := &defaultClient{caBundleFile: bundleFile}
+
+	// When
+	caInfoFile, err := client.writeCAInfo()
+
+	// Then
+	require.NoError(t, err)
+	defer func() { _ = os.Remove(caInfoFile) }()
+
+	caInfo, err := os.ReadFile(caInfoFile)
+	require.NoError(t, err)
+	assert.Equal(t, "system certificates\ninternal CA", string(caInfo))
+}
+
+func TestWriteCAInfo_MissingCABundle(t *testing.T) {
+	// Given
+	client := &defaultClient{caBundleFile: filepath.Join(t.TempDir(), "missing.pem")}
+
+	// When
+	_, err := client.writeCAInfo()
+
+	// Then
+	assert.ErrorContains(t, err, "failed to read CA bundle")
+}
@@ -0,0 +1,156 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	gh "github.com/google/go-github/v90/github"
+)
+
+const (
+	// appJWTLifetime stays under the 10 minutes accepted by GitHub
+	appJWTLifetime = 9 * time.Minute
+	// appJWTClockDrift backdates the JWT to tolerate a clock running ahead of GitHub's
+	appJWTClockDrift = time.Minute
+	// installationTokenRefreshMargin is how long before its expiry an installation token is replaced, so that a
+	// request started with the token does not fail half way
+	installationTokenRefreshMargin = 5 * time.Minute
+)
+
+// TokenSource returns the token authenticating the requests to GitHub
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+type staticTokenSource struct {
+	token string
+}
+
+// NewStaticTokenSource always returns the same token, such as a personal access token
+func NewStaticTokenSource(token string) TokenSource {
+	return &staticTokenSource{
+		token: token,
+	}
+}
+
+func (s *staticTokenSource) Token(context.Context) (string, error) {
+	return s.token, nil
+}
+
+// App identifies the installation of a GitHub App in an organization
+type App struct {
+	ID             int64
+	InstallationID int64
+	// PrivateKey is the PEM encoded private key generated in the settings of the app
+	PrivateKey []byte
+}
+
+type appTokenSource struct {
+	app        App
+	privateKey any
+	options    []gh.ClientOptionsFunc
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewAppTokenSource returns installation tokens of the app. An installation token is valid for one hour, a new one
+// is requested with a JWT signed by the private key of the app when it is about to expire. The options configure the
+// client requesting the tokens, such as the URLs of the API.
+func NewAppTokenSource(app App, options ...gh.ClientOptionsFunc) (TokenSource, error) {
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(app.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse github app private key: %w", err)
+	}
+
+	return &appTokenSource{
+		app:        app,
+		privateKey: privateKey,
+		options:    options,
+	}, nil
+}
+
+func (s *appTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Until(s.expiresAt) > installationTokenRefreshMargin {
+		return s.token, nil
+	}
+
+	signedJWT, err := s.signJWT(time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	client, err := gh.NewClient(append(s.options, gh.WithAuthToken(signedJWT))...)
+	if err != nil {
+		return "", fmt.Errorf("failed to create github app client: %w", err)
+	}
+
+	installationToken, _, err := client.Apps.CreateInstallationToken(ctx, s.app.InstallationID, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create github app installation token: %w", err)
+	}
+
+	s.token = installationToken.GetToken()
+	s.expiresAt = installationToken.GetExpiresAt().Time
+
+	return s.token, nil
+}
+
+// signJWT creates the JWT authenticating as the app itself, it only allows requesting installation tokens
+func (s *appTokenSource) signJWT(now time.Time) (string, error) {
+	claims := jwt.RegisteredClaims{
+		Issuer:    strconv.FormatInt(s.app.ID, 10),
+		IssuedAt:  jwt.NewNumericDate(now.Add(-appJWTClockDrift)),
+		ExpiresAt: jwt.NewNumericDate(now.Add(appJWTLifetime)),
+	}
+
+	signedJWT, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(s.privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign github app JWT: %w", err)
+	}
+
+	return signedJWT, nil
+}
+
+type tokenTransport struct {
+	tokenSource TokenSource
+	base        http.RoundTripper
+}
+
+// NewTokenTransport authenticates every request with the current token of the token source, so that a long running
+// command such as the polling of a migration keeps working after an installation token expired
+func NewTokenTransport(tokenSource TokenSource, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &tokenTransport{
+		tokenSource: tokenSource,
+		base:        base,
+	}
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.tokenSource.Token(req.Context())
+	if err != nil {
+		// A RoundTripper must always close the body
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, err
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,197 @@
+package github
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	gh "github.com/google/go-github/v90/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// appServer issues installation tokens to the test app and serves the migration status with them
+type appServer struct {
+	*httptest.Server
+	publicKey *rsa.PublicKey
+	// lifetimes are the lifetimes of the issued tokens, the last one is reused
+	lifetimes []time.Duration
+
+	mu     sync.Mutex
+	issued []string
+	used   []string
+}
+
+func newAppServer(t *testing.T, publicKey *rsa.PublicKey, lifetimes ...time.Duration) *appServer {
+	t.Helper()
+
+	server := &appServer{publicKey: publicKey, lifetimes: lifetimes}
+	server.Server = httptest.NewServer(http.HandlerFunc(server.handle))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func (s *appServer) handle(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/app/installations/42/access_tokens":
+		if err := s.verifyJWT(token); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		lifetime := s.lifetimes[min(len(s.issued), len(s.lifetimes)-1)]
+		issued := fmt.Sprintf("ghs_%d", len(s.issued)+1)
+		s.issued = append(s.issued, issued)
+
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"token":      issued,
+			"expires_at": time.Now().Add(lifetime).UTC().Format(time.RFC3339),
+		})
+	case r.URL.Path == "/orgs/kumojin/migrations/1":
+		s.used = append(s.used, token)
+		_, _ = w.Write([]byte(`{"id": 1, "state": "exporting"}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *appServer) verifyJWT(token string) error {
+	claims := jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (any, error) {
+		return s.publicKey, nil
+	}, jwt.WithValidMethods([]string{"RS256"}), jwt.WithIssuer("123"), jwt.WithIssuedAt())
+	if err != nil {
+		return err
+	}
+
+	if claims.ExpiresAt.Sub(claims.IssuedAt.Time) > 10*time.Minute {
+		return fmt.Errorf("JWT is valid for more than 10 minutes")
+	}
+
+	return nil
+}
+
+func (s *appServer) tokens() ([]string, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.issued...), append([]string(nil), s.used...)
+}
+
+func (s *appServer) options() []gh.ClientOptionsFunc {
+	baseURL := s.URL + "/"
+	return []gh.ClientOptionsFunc{gh.WithURLs(&baseURL, &baseURL)}
+}
+
+func newTestApp(t *testing.T) (App, *rsa.PublicKey) {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+
+	return App{ID: 123, InstallationID: 42, PrivateKey: privateKeyPEM}, &privateKey.PublicKey
+}
+
+// newAppClient creates a Client authenticated as the installation of the app
+func newAppClient(t *testing.T, tokenSource TokenSource, server *appServer) Client {
+	t.Helper()
+
+	ghClient, err := gh.NewClient(append(server.options(), gh.WithTransport(NewTokenTransport(tokenSource, nil)))...)
+	require.NoError(t, err)
+
+	return NewClient(ghClient)
+}
+
+func TestAppTokenSource_ReusesValidToken(t *testing.T) {
+	// Given
+	app, publicKey := newTestApp(t)
+	server := newAppServer(t, publicKey, time.Hour)
+
+	tokenSource, err := NewAppTokenSource(app, server.options()...)
+	require.NoError(t, err)
+	client := newAppClient(t, tokenSource, server)
+
+	// When
+	for range 3 {
+		_, err = client.GetMigrationStatus(context.Background(), "kumojin", 1)
+		require.NoError(t, err)
+	}
+
+	// Then
+	issued, used := server.tokens()
+	assert.Equal(t, []string{"ghs_1"}, issued)
+	assert.Equal(t, []string{"ghs_1", "ghs_1", "ghs_1"}, used)
+}
+
+func TestAppTokenSource_RefreshesExpiringToken(t *testing.T) {
+	// Given
+	app, publicKey := newTestApp(t)
+	// The first token expires within the refresh margin, as it would after polling a migration for an hour
+	server := newAppServer(t, publicKey, time.Minute, time.Hour)
+
+	tokenSource, err := NewAppTokenSource(app, server.options()...)
+	require.NoError(t, err)
+	client := newAppClient(t, tokenSource, server)
+
+	// When
+	for range 3 {
+		_, err = client.GetMigrationStatus(context.Background(), "kumojin", 1)
+		require.NoError(t, err)
+	}
+
+	// Then
+	issued, used := server.tokens()
+	assert.Equal(t, []string{"ghs_1", "ghs_2"}, issued)
+	assert.Equal(t, []string{"ghs_1", "ghs_2", "ghs_2"}, used)
+}
+
+func TestAppTokenSource_RejectedJWT(t *testing.T) {
+	// Given
+	_, publicKey := newTestApp(t)
+	server := newAppServer(t, publicKey, time.Hour)
+
+	// The key of another app
+	otherApp, _ := newTestApp(t)
+	tokenSource, err := NewAppTokenSource(otherApp, server.options()...)
+	require.NoError(t, err)
+
+	// When
+	_, err = tokenSource.Token(context.Background())
+
+	// Then
+	assert.ErrorContains(t, err, "failed to create github app installation token")
+	issued, _ := server.tokens()
+	assert.Empty(t, issued)
+}
+
+func TestNewAppTokenSource_InvalidPrivateKey(t *testing.T) {
+	_, err := NewAppTokenSource(App{ID: 123, InstallationID: 42, PrivateKey: []byte("not a key")})
+
+	assert.ErrorContains(t, err, "failed to parse github app private key")
+}
+
+func TestStaticTokenSource(t *testing.T) {
+	token, err := NewStaticTokenSource("ghp_token").Token(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ghp_token", token)
+}
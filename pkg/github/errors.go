@@ -0,0 +1,28 @@
+package github
+
+import (
+	"errors"
+	"net/http"
+
+	gh "github.com/google/go-github/v90/github"
+)
+
+// IsAlreadyExists tells whether the error is a validation error reporting that the resource already exists
+func IsAlreadyExists(err error) bool {
+	var errorResponse *gh.ErrorResponse
+	if !errors.As(err, &errorResponse) || errorResponse.Response == nil {
+		return false
+	}
+
+	if errorResponse.Response.StatusCode != http.StatusUnprocessableEntity {
+		return false
+	}
+
+	for _, e := range errorResponse.Errors {
+		if e.Code == "already_exists" {
+			return true
+		}
+	}
+
+	return false
+}
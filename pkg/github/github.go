@@ -2,24 +2,39 @@ package github
 
 import (
 	"context"
+	"fmt"
+	"sync"
 
 	gh "github.com/google/go-github/v90/github"
 )
 
-const maxPerPage = 100
+const (
+	maxPerPage                 = 100
+	mediaTypeMigrationsPreview = "application/vnd.github.wyandotte-preview+json"
+)
 
 type Client interface {
 	// Migrations
 	GetMigrationArchiveURL(ctx context.Context, organization string, organizationID int64) (string, error)
 	GetMigrationStatus(ctx context.Context, organization string, migrationID int64) (*gh.Migration, error)
-	StartMigration(ctx context.Context, organization string, repoNames []string) (*gh.Migration, error)
+	StartMigration(ctx context.Context, organization string, repoNames []string, contents MigrationContents) (*gh.Migration, error)
 
 	// Repositories
 	ListOrgRepos(ctx context.Context, organization string, visibility string) ([]*gh.Repository, error)
+	CreateRepository(ctx context.Context, organization string, repository *gh.Repository) (*gh.Repository, error)
+
+	// Issues
+	CreateLabel(ctx context.Context, owner string, repo string, label gh.CreateIssueLabelRequest) error
+	CreateMilestone(ctx context.Context, owner string, repo string, milestone *gh.Milestone) (*gh.Milestone, error)
+	CreateIssue(ctx context.Context, owner string, repo string, issue gh.CreateIssueRequest) (*gh.Issue, error)
+	CloseIssue(ctx context.Context, owner string, repo string, number int) error
 }
 
 type defaultClient struct {
 	githubClient *gh.Client
+
+	mu   sync.Mutex
+	rate gh.Rate
 }
 
 func NewClient(gitHubClient *gh.Client) Client {
@@ -28,12 +43,31 @@ func NewClient(gitHubClient *gh.Client) Client {
 	}
 }
 
+// observe records the rate limit returned with the response
+func (c *defaultClient) observe(resp *gh.Response) {
+	if resp == nil || resp.Rate.Limit == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rate = resp.Rate
+}
+
+// lastRate returns the rate limit returned with the last response
+func (c *defaultClient) lastRate() (gh.Rate, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rate, c.rate.Limit != 0
+}
+
 func (c *defaultClient) GetMigrationArchiveURL(ctx context.Context, organization string, organizationID int64) (string, error) {
 	return c.githubClient.Migrations.MigrationArchiveURL(ctx, organization, organizationID)
 }
 
 func (c *defaultClient) GetMigrationStatus(ctx context.Context, organization string, migrationID int64) (*gh.Migration, error) {
-	migration, _, err := c.githubClient.Migrations.MigrationStatus(ctx, organization, migrationID)
+	migration, resp, err := c.githubClient.Migrations.MigrationStatus(ctx, organization, migrationID)
+	c.observe(resp)
 	if err != nil {
 		return nil, err
 	}
@@ -41,12 +75,18 @@ func (c *defaultClient) GetMigrationStatus(ctx context.Context, organization str
 	return migration, nil
 }
 
-func (c *defaultClient) StartMigration(ctx context.Context, organization string, repoNames []string) (*gh.Migration, error) {
-	migration, _, err := c.githubClient.Migrations.StartMigration(ctx, organization, repoNames, &gh.MigrationOptions{
-		ExcludeAttachments: true,
-		ExcludeReleases:    true,
-		Exclude:            []string{"repositories"},
-	})
+func (c *defaultClient) StartMigration(ctx context.Context, organization string, repoNames []string, contents MigrationContents) (*gh.Migration, error) {
+	url := fmt.Sprintf("orgs/%s/migrations", organization)
+
+	req, err := c.githubClient.NewRequest(ctx, "POST", url, newStartMigrationRequest(repoNames, contents))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", mediaTypeMigrationsPreview)
+
+	var migration *gh.Migration
+	resp, err := c.githubClient.Do(req, &migration)
+	c.observe(resp)
 	if err != nil {
 		return nil, err
 	}
@@ -55,29 +95,87 @@ func (c *defaultClient) StartMigration(ctx context.Context, organization string,
 }
 
 func (c *defaultClient) ListOrgRepos(ctx context.Context, organization string, visibility string) ([]*gh.Repository, error) {
+	return listPages(func(page int) ([]*gh.Repository, int, error) {
+		return c.listOrgReposPage(ctx, organization, visibility, page)
+	})
+}
+
+// listOrgReposPage returns a page of the repositories of the organization and the number of the next page, 0 after
+// the last one
+func (c *defaultClient) listOrgReposPage(ctx context.Context, organization string, visibility string, page int) ([]*gh.Repository, int, error) {
 	opts := &gh.RepositoryListByOrgOptions{
 		Type: visibility,
 		ListOptions: gh.ListOptions{
 			PerPage: maxPerPage,
-			Page:    1,
+			Page:    page,
 		},
 	}
 
+	repos, resp, err := c.githubClient.Repositories.ListByOrg(ctx, organization, opts)
+	c.observe(resp)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return repos, resp.NextPage, nil
+}
+
+// listPages collects the repositories of every page returned by listPage, from the first one
+func listPages(listPage func(page int) ([]*gh.Repository, int, error)) ([]*gh.Repository, error) {
 	var allRepos []*gh.Repository
-	for {
-		repos, resp, err := c.githubClient.Repositories.ListByOrg(ctx, organization, opts)
+	for page := 1; page != 0; {
+		repos, nextPage, err := listPage(page)
 		if err != nil {
 			return nil, err
 		}
 
 		allRepos = append(allRepos, repos...)
+		page = nextPage
+	}
 
-		if resp.NextPage == 0 {
-			break
-		}
+	return allRepos, nil
+}
 
-		opts.Page = resp.NextPage
+func (c *defaultClient) CreateRepository(ctx context.Context, organization string, repository *gh.Repository) (*gh.Repository, error) {
+	created, resp, err := c.githubClient.Repositories.Create(ctx, organization, repository)
+	c.observe(resp)
+	if err != nil {
+		return nil, err
 	}
 
-	return allRepos, nil
+	return created, nil
+}
+
+func (c *defaultClient) CreateLabel(ctx context.Context, owner string, repo string, label gh.CreateIssueLabelRequest) error {
+	_, resp, err := c.githubClient.Issues.CreateLabel(ctx, owner, repo, label)
+	c.observe(resp)
+	return err
+}
+
+func (c *defaultClient) CreateMilestone(ctx context.Context, owner string, repo string, milestone *gh.Milestone) (*gh.Milestone, error) {
+	created, resp, err := c.githubClient.Issues.CreateMilestone(ctx, owner, repo, milestone)
+	c.observe(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (c *defaultClient) CreateIssue(ctx context.Context, owner string, repo string, issue gh.CreateIssueRequest) (*gh.Issue, error) {
+	created, resp, err := c.githubClient.Issues.Create(ctx, owner, repo, issue)
+	c.observe(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (c *defaultClient) CloseIssue(ctx context.Context, owner string, repo string, number int) error {
+	_, resp, err := c.githubClient.Issues.Update(ctx, owner, repo, number, gh.UpdateIssueRequest{
+		State: gh.Ptr("closed"),
+	})
+	c.observe(resp)
+	return err
 }
@@ -38,6 +38,378 @@ func (_m *MockClient) EXPECT() *MockClient_Expecter {
 	return &MockClient_Expecter{mock: &_m.Mock}
 }
 
+// CloseIssue provides a mock function for the type MockClient
+func (_mock *MockClient) CloseIssue(ctx context.Context, owner string, repo string, number int) error {
+	ret := _mock.Called(ctx, owner, repo, number)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CloseIssue")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, int) error); ok {
+		r0 = returnFunc(ctx, owner, repo, number)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockClient_CloseIssue_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CloseIssue'
+type MockClient_CloseIssue_Call struct {
+	*mock.Call
+}
+
+// CloseIssue is a helper method to define mock.On call
+//   - ctx context.Context
+//   - owner string
+//   - repo string
+//   - number int
+func (_e *MockClient_Expecter) CloseIssue(ctx interface{}, owner interface{}, repo interface{}, number interface{}) *MockClient_CloseIssue_Call {
+	return &MockClient_CloseIssue_Call{Call: _e.mock.On("CloseIssue", ctx, owner, repo, number)}
+}
+
+func (_c *MockClient_CloseIssue_Call) Run(run func(ctx context.Context, owner string, repo string, number int)) *MockClient_CloseIssue_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 int
+		if args[3] != nil {
+			arg3 = args[3].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockClient_CloseIssue_Call) Return(err error) *MockClient_CloseIssue_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockClient_CloseIssue_Call) RunAndReturn(run func(ctx context.Context, owner string, repo string, number int) error) *MockClient_CloseIssue_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateIssue provides a mock function for the type MockClient
+func (_mock *MockClient) CreateIssue(ctx context.Context, owner string, repo string, issue github.CreateIssueRequest) (*github.Issue, error) {
+	ret := _mock.Called(ctx, owner, repo, issue)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateIssue")
+	}
+
+	var r0 *github.Issue
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, github.CreateIssueRequest) (*github.Issue, error)); ok {
+		return returnFunc(ctx, owner, repo, issue)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, github.CreateIssueRequest) *github.Issue); ok {
+		r0 = returnFunc(ctx, owner, repo, issue)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*github.Issue)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string, github.CreateIssueRequest) error); ok {
+		r1 = returnFunc(ctx, owner, repo, issue)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockClient_CreateIssue_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateIssue'
+type MockClient_CreateIssue_Call struct {
+	*mock.Call
+}
+
+// CreateIssue is a helper method to define mock.On call
+//   - ctx context.Context
+//   - owner string
+//   - repo string
+//   - issue github.CreateIssueRequest
+func (_e *MockClient_Expecter) CreateIssue(ctx interface{}, owner interface{}, repo interface{}, issue interface{}) *MockClient_CreateIssue_Call {
+	return &MockClient_CreateIssue_Call{Call: _e.mock.On("CreateIssue", ctx, owner, repo, issue)}
+}
+
+func (_c *MockClient_CreateIssue_Call) Run(run func(ctx context.Context, owner string, repo string, issue github.CreateIssueRequest)) *MockClient_CreateIssue_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 github.CreateIssueRequest
+		if args[3] != nil {
+			arg3 = args[3].(github.CreateIssueRequest)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockClient_CreateIssue_Call) Return(issue1 *github.Issue, err error) *MockClient_CreateIssue_Call {
+	_c.Call.Return(issue1, err)
+	return _c
+}
+
+func (_c *MockClient_CreateIssue_Call) RunAndReturn(run func(ctx context.Context, owner string, repo string, issue github.CreateIssueRequest) (*github.Issue, error)) *MockClient_CreateIssue_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateLabel provides a mock function for the type MockClient
+func (_mock *MockClient) CreateLabel(ctx context.Context, owner string, repo string, label github.CreateIssueLabelRequest) error {
+	ret := _mock.Called(ctx, owner, repo, label)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateLabel")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, github.CreateIssueLabelRequest) error); ok {
+		r0 = returnFunc(ctx, owner, repo, label)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockClient_CreateLabel_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateLabel'
+type MockClient_CreateLabel_Call struct {
+	*mock.Call
+}
+
+// CreateLabel is a helper method to define mock.On call
+//   - ctx context.Context
+//   - owner string
+//   - repo string
+//   - label github.CreateIssueLabelRequest
+func (_e *MockClient_Expecter) CreateLabel(ctx interface{}, owner interface{}, repo interface{}, label interface{}) *MockClient_CreateLabel_Call {
+	return &MockClient_CreateLabel_Call{Call: _e.mock.On("CreateLabel", ctx, owner, repo, label)}
+}
+
+func (_c *MockClient_CreateLabel_Call) Run(run func(ctx context.Context, owner string, repo string, label github.CreateIssueLabelRequest)) *MockClient_CreateLabel_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 github.CreateIssueLabelRequest
+		if args[3] != nil {
+			arg3 = args[3].(github.CreateIssueLabelRequest)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockClient_CreateLabel_Call) Return(err error) *MockClient_CreateLabel_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockClient_CreateLabel_Call) RunAndReturn(run func(ctx context.Context, owner string, repo string, label github.CreateIssueLabelRequest) error) *MockClient_CreateLabel_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateMilestone provides a mock function for the type MockClient
+func (_mock *MockClient) CreateMilestone(ctx context.Context, owner string, repo string, milestone *github.Milestone) (*github.Milestone, error) {
+	ret := _mock.Called(ctx, owner, repo, milestone)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateMilestone")
+	}
+
+	var r0 *github.Milestone
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, *github.Milestone) (*github.Milestone, error)); ok {
+		return returnFunc(ctx, owner, repo, milestone)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, *github.Milestone) *github.Milestone); ok {
+		r0 = returnFunc(ctx, owner, repo, milestone)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*github.Milestone)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string, *github.Milestone) error); ok {
+		r1 = returnFunc(ctx, owner, repo, milestone)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockClient_CreateMilestone_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateMilestone'
+type MockClient_CreateMilestone_Call struct {
+	*mock.Call
+}
+
+// CreateMilestone is a helper method to define mock.On call
+//   - ctx context.Context
+//   - owner string
+//   - repo string
+//   - milestone *github.Milestone
+func (_e *MockClient_Expecter) CreateMilestone(ctx interface{}, owner interface{}, repo interface{}, milestone interface{}) *MockClient_CreateMilestone_Call {
+	return &MockClient_CreateMilestone_Call{Call: _e.mock.On("CreateMilestone", ctx, owner, repo, milestone)}
+}
+
+func (_c *MockClient_CreateMilestone_Call) Run(run func(ctx context.Context, owner string, repo string, milestone *github.Milestone)) *MockClient_CreateMilestone_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 *github.Milestone
+		if args[3] != nil {
+			arg3 = args[3].(*github.Milestone)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockClient_CreateMilestone_Call) Return(milestone1 *github.Milestone, err error) *MockClient_CreateMilestone_Call {
+	_c.Call.Return(milestone1, err)
+	return _c
+}
+
+func (_c *MockClient_CreateMilestone_Call) RunAndReturn(run func(ctx context.Context, owner string, repo string, milestone *github.Milestone) (*github.Milestone, error)) *MockClient_CreateMilestone_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateRepository provides a mock function for the type MockClient
+func (_mock *MockClient) CreateRepository(ctx context.Context, organization string, repository *github.Repository) (*github.Repository, error) {
+	ret := _mock.Called(ctx, organization, repository)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateRepository")
+	}
+
+	var r0 *github.Repository
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, *github.Repository) (*github.Repository, error)); ok {
+		return returnFunc(ctx, organization, repository)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, *github.Repository) *github.Repository); ok {
+		r0 = returnFunc(ctx, organization, repository)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*github.Repository)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, *github.Repository) error); ok {
+		r1 = returnFunc(ctx, organization, repository)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockClient_CreateRepository_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateRepository'
+type MockClient_CreateRepository_Call struct {
+	*mock.Call
+}
+
+// CreateRepository is a helper method to define mock.On call
+//   - ctx context.Context
+//   - organization string
+//   - repository *github.Repository
+func (_e *MockClient_Expecter) CreateRepository(ctx interface{}, organization interface{}, repository interface{}) *MockClient_CreateRepository_Call {
+	return &MockClient_CreateRepository_Call{Call: _e.mock.On("CreateRepository", ctx, organization, repository)}
+}
+
+func (_c *MockClient_CreateRepository_Call) Run(run func(ctx context.Context, organization string, repository *github.Repository)) *MockClient_CreateRepository_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 *github.Repository
+		if args[2] != nil {
+			arg2 = args[2].(*github.Repository)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockClient_CreateRepository_Call) Return(repository1 *github.Repository, err error) *MockClient_CreateRepository_Call {
+	_c.Call.Return(repository1, err)
+	return _c
+}
+
+func (_c *MockClient_CreateRepository_Call) RunAndReturn(run func(ctx context.Context, organization string, repository *github.Repository) (*github.Repository, error)) *MockClient_CreateRepository_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetMigrationArchiveURL provides a mock function for the type MockClient
 func (_mock *MockClient) GetMigrationArchiveURL(ctx context.Context, organization string, organizationID int64) (string, error) {
 	ret := _mock.Called(ctx, organization, organizationID)
@@ -259,8 +631,8 @@ func (_c *MockClient_ListOrgRepos_Call) RunAndReturn(run func(ctx context.Contex
 }
 
 // StartMigration provides a mock function for the type MockClient
-func (_mock *MockClient) StartMigration(ctx context.Context, organization string, repoNames []string) (*github.Migration, error) {
-	ret := _mock.Called(ctx, organization, repoNames)
+func (_mock *MockClient) StartMigration(ctx context.Context, organization string, repoNames []string, contents MigrationContents) (*github.Migration, error) {
+	ret := _mock.Called(ctx, organization, repoNames, contents)
 
 	if len(ret) == 0 {
 		panic("no return value specified for StartMigration")
@@ -268,18 +640,18 @@ func (_mock *MockClient) StartMigration(ctx context.Context, organization string
 
 	var r0 *github.Migration
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string, []string) (*github.Migration, error)); ok {
-		return returnFunc(ctx, organization, repoNames)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, []string, MigrationContents) (*github.Migration, error)); ok {
+		return returnFunc(ctx, organization, repoNames, contents)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string, []string) *github.Migration); ok {
-		r0 = returnFunc(ctx, organization, repoNames)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, []string, MigrationContents) *github.Migration); ok {
+		r0 = returnFunc(ctx, organization, repoNames, contents)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*github.Migration)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, string, []string) error); ok {
-		r1 = returnFunc(ctx, organization, repoNames)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, []string, MigrationContents) error); ok {
+		r1 = returnFunc(ctx, organization, repoNames, contents)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -295,11 +667,12 @@ type MockClient_StartMigration_Call struct {
 //   - ctx context.Context
 //   - organization string
 //   - repoNames []string
-func (_e *MockClient_Expecter) StartMigration(ctx interface{}, organization interface{}, repoNames interface{}) *MockClient_StartMigration_Call {
-	return &MockClient_StartMigration_Call{Call: _e.mock.On("StartMigration", ctx, organization, repoNames)}
+//   - contents MigrationContents
+func (_e *MockClient_Expecter) StartMigration(ctx interface{}, organization interface{}, repoNames interface{}, contents interface{}) *MockClient_StartMigration_Call {
+	return &MockClient_StartMigration_Call{Call: _e.mock.On("StartMigration", ctx, organization, repoNames, contents)}
 }
 
-func (_c *MockClient_StartMigration_Call) Run(run func(ctx context.Context, organization string, repoNames []string)) *MockClient_StartMigration_Call {
+func (_c *MockClient_StartMigration_Call) Run(run func(ctx context.Context, organization string, repoNames []string, contents MigrationContents)) *MockClient_StartMigration_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -313,10 +686,15 @@ func (_c *MockClient_StartMigration_Call) Run(run func(ctx context.Context, orga
 		if args[2] != nil {
 			arg2 = args[2].([]string)
 		}
+		var arg3 MigrationContents
+		if args[3] != nil {
+			arg3 = args[3].(MigrationContents)
+		}
 		run(
 			arg0,
 			arg1,
 			arg2,
+			arg3,
 		)
 	})
 	return _c
@@ -327,7 +705,94 @@ func (_c *MockClient_StartMigration_Call) Return(migration *github.Migration, er
 	return _c
 }
 
-func (_c *MockClient_StartMigration_Call) RunAndReturn(run func(ctx context.Context, organization string, repoNames []string) (*github.Migration, error)) *MockClient_StartMigration_Call {
+func (_c *MockClient_StartMigration_Call) RunAndReturn(run func(ctx context.Context, organization string, repoNames []string, contents MigrationContents) (*github.Migration, error)) *MockClient_StartMigration_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockTokenSource creates a new instance of MockTokenSource. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockTokenSource(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockTokenSource {
+	mock := &MockTokenSource{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockTokenSource is an autogenerated mock type for the TokenSource type
+type MockTokenSource struct {
+	mock.Mock
+}
+
+type MockTokenSource_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockTokenSource) EXPECT() *MockTokenSource_Expecter {
+	return &MockTokenSource_Expecter{mock: &_m.Mock}
+}
+
+// Token provides a mock function for the type MockTokenSource
+func (_mock *MockTokenSource) Token(ctx context.Context) (string, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Token")
+	}
+
+	var r0 string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) (string, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) string); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockTokenSource_Token_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Token'
+type MockTokenSource_Token_Call struct {
+	*mock.Call
+}
+
+// Token is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockTokenSource_Expecter) Token(ctx interface{}) *MockTokenSource_Token_Call {
+	return &MockTokenSource_Token_Call{Call: _e.mock.On("Token", ctx)}
+}
+
+func (_c *MockTokenSource_Token_Call) Run(run func(ctx context.Context)) *MockTokenSource_Token_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockTokenSource_Token_Call) Return(s string, err error) *MockTokenSource_Token_Call {
+	_c.Call.Return(s, err)
+	return _c
+}
+
+func (_c *MockTokenSource_Token_Call) RunAndReturn(run func(ctx context.Context) (string, error)) *MockTokenSource_Token_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -0,0 +1,68 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gh "github.com/google/go-github/v90/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient creates a Client talking to the given test server
+func newTestClient(t *testing.T, server *httptest.Server) Client {
+	baseURL := server.URL + "/"
+	ghClient, err := gh.NewClient(gh.WithURLs(&baseURL, &baseURL))
+	require.NoError(t, err)
+
+	return NewClient(ghClient)
+}
+
+func TestClient_StartMigrationSendsContents(t *testing.T) {
+	// Given
+	var body map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/orgs/kumojin/migrations", r.URL.Path)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id": 12345, "state": "pending"}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	contents := MigrationContents{
+		GitData:          true,
+		Metadata:         false,
+		Attachments:      true,
+		Releases:         true,
+		OwnerProjects:    false,
+		LockRepositories: true,
+	}
+
+	// When
+	migration, err := client.StartMigration(context.Background(), "kumojin", []string{"repo1", "repo2"}, contents)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12345), migration.GetID())
+	assert.Equal(t, []any{"repo1", "repo2"}, body["repositories"])
+	assert.Equal(t, true, body["lock_repositories"])
+	assert.Equal(t, false, body["exclude_git_data"])
+	assert.Equal(t, true, body["exclude_metadata"])
+	assert.Equal(t, false, body["exclude_attachments"])
+	assert.Equal(t, false, body["exclude_releases"])
+	assert.Equal(t, true, body["exclude_owner_projects"])
+}
+
+func TestMigrationContents_Validate(t *testing.T) {
+	assert.NoError(t, DefaultMigrationContents().Validate())
+	assert.NoError(t, MigrationContents{Metadata: true}.Validate())
+	assert.NoError(t, MigrationContents{GitData: true}.Validate())
+	assert.ErrorIs(t, MigrationContents{Attachments: true, Releases: true}.Validate(), ErrEmptyMigrationContents)
+}
@@ -0,0 +1,73 @@
+package github
+
+import (
+	"errors"
+	"log/slog"
+)
+
+var ErrEmptyMigrationContents = errors.New("migration contents must include git data or metadata")
+
+// MigrationContents selects what GitHub includes in a migration archive
+type MigrationContents struct {
+	GitData          bool `json:"gitData"`
+	Metadata         bool `json:"metadata"`
+	Attachments      bool `json:"attachments"`
+	Releases         bool `json:"releases"`
+	OwnerProjects    bool `json:"ownerProjects"`
+	LockRepositories bool `json:"lockRepositories"`
+}
+
+// DefaultMigrationContents returns the contents of a migration when nothing is configured
+func DefaultMigrationContents() MigrationContents {
+	return MigrationContents{
+		GitData:       true,
+		Metadata:      true,
+		OwnerProjects: true,
+	}
+}
+
+func (c MigrationContents) Validate() error {
+	if !c.GitData && !c.Metadata {
+		return ErrEmptyMigrationContents
+	}
+
+	return nil
+}
+
+func (c MigrationContents) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Bool("gitData", c.GitData),
+		slog.Bool("metadata", c.Metadata),
+		slog.Bool("attachments", c.Attachments),
+		slog.Bool("releases", c.Releases),
+		slog.Bool("ownerProjects", c.OwnerProjects),
+		slog.Bool("lockRepositories", c.LockRepositories),
+	)
+}
+
+// startMigrationRequest is the body of a start migration request. go-github does not expose
+// every exclusion supported by the API, so the request is built here.
+type startMigrationRequest struct {
+	Repositories         []string `json:"repositories"`
+	LockRepositories     bool     `json:"lock_repositories"`
+	ExcludeGitData       bool     `json:"exclude_git_data"`
+	ExcludeMetadata      bool     `json:"exclude_metadata"`
+	ExcludeAttachments   bool     `json:"exclude_attachments"`
+	ExcludeReleases      bool     `json:"exclude_releases"`
+	ExcludeOwnerProjects bool     `json:"exclude_owner_projects"`
+	Exclude              []string `json:"exclude,omitempty"`
+}
+
+func newStartMigrationRequest(repoNames []string, contents MigrationContents) startMigrationRequest {
+	return startMigrationRequest{
+		Repositories:         repoNames,
+		LockRepositories:     contents.LockRepositories,
+		ExcludeGitData:       !contents.GitData,
+		ExcludeMetadata:      !contents.Metadata,
+		ExcludeAttachments:   !contents.Attachments,
+		ExcludeReleases:      !contents.Releases,
+		ExcludeOwnerProjects: !contents.OwnerProjects,
+		// Only trims the repositories from the API response, the archive is not affected
+		Exclude: []string{"repositories"},
+	}
+}
@@ -0,0 +1,295 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	gh "github.com/google/go-github/v90/github"
+	"github.com/kumojin/repo-backup-cli/pkg/logging"
+)
+
+const (
+	DefaultRateLimitMaxRetries     = 5
+	DefaultRateLimitInitialBackoff = time.Second
+	DefaultRateLimitMaxBackoff     = time.Minute
+	DefaultRateLimitMaxWait        = time.Hour
+
+	// secondaryRateLimitWait is the wait GitHub asks for when a secondary rate limit gives no Retry-After
+	secondaryRateLimitWait = time.Minute
+	// lowRateBudget is the share of the primary rate limit under which the budget left is reported as a warning
+	lowRateBudget = 0.1
+)
+
+// RateLimitOptions configures how the calls limited by GitHub are retried, zero values use the defaults
+type RateLimitOptions struct {
+	// MaxRetries is the number of times a call is retried before its error is returned
+	MaxRetries int
+	// InitialBackoff is the wait before retrying a call which failed for another reason than a rate limit, it doubles
+	// with every retry up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// MaxWait is the longest wait for a rate limit to reset, the error is returned when the reset is later
+	MaxWait time.Duration
+}
+
+func (o RateLimitOptions) withDefaults() RateLimitOptions {
+	if o.MaxRetries == 0 {
+		o.MaxRetries = DefaultRateLimitMaxRetries
+	}
+	if o.InitialBackoff == 0 {
+		o.InitialBackoff = DefaultRateLimitInitialBackoff
+	}
+	if o.MaxBackoff == 0 {
+		o.MaxBackoff = DefaultRateLimitMaxBackoff
+	}
+	if o.MaxWait == 0 {
+		o.MaxWait = DefaultRateLimitMaxWait
+	}
+
+	return o
+}
+
+// backoff returns the wait before the given retry
+func (o RateLimitOptions) backoff(retry int) time.Duration {
+	backoff := o.InitialBackoff
+	for i := 1; i < retry && backoff < o.MaxBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, o.MaxBackoff)
+}
+
+// jitter spreads the retries of concurrent backups which hit the same limit
+func jitter(wait time.Duration) time.Duration {
+	return wait + rand.N(wait/10+time.Second)
+}
+
+// rateReporter is implemented by the clients which know the rate limit returned with their last response
+type rateReporter interface {
+	lastRate() (gh.Rate, bool)
+}
+
+// orgReposPager is implemented by the clients which list the repositories of an organization page by page
+type orgReposPager interface {
+	listOrgReposPage(ctx context.Context, organization string, visibility string, page int) ([]*gh.Repository, int, error)
+}
+
+// repositoryPage is a page of repositories and the number of the next page
+type repositoryPage struct {
+	repos    []*gh.Repository
+	nextPage int
+}
+
+type rateLimitedClient struct {
+	client  Client
+	options RateLimitOptions
+	sleep   func(ctx context.Context, wait time.Duration) error
+
+	mu sync.Mutex
+	// warnedReset is the reset of the rate limit window for which the budget was reported as low
+	warnedReset time.Time
+}
+
+// NewRateLimitedClient wraps the client so that the calls rejected by a primary or secondary rate limit wait for the
+// limit to reset and are retried. The calls which only read are also retried with a backoff after server and network
+// errors. The budget left is logged after every call.
+func NewRateLimitedClient(client Client, options RateLimitOptions) Client {
+	return &rateLimitedClient{
+		client:  client,
+		options: options.withDefaults(),
+		sleep:   sleep,
+	}
+}
+
+func sleep(ctx context.Context, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// withRetries calls call until it succeeds, fails with an error which cannot be retried, or runs out of retries.
+// An idempotent call is also retried after server and network errors.
+func withRetries[T any](ctx context.Context, c *rateLimitedClient, name string, idempotent bool, call func() (T, error)) (T, error) {
+	logger := logging.NewLogger(ctx).With(slog.String("call", name))
+
+	for retry := 1; ; retry++ {
+		result, err := call()
+		c.logRate(logger)
+		if err == nil {
+			return result, nil
+		}
+
+		wait, ok := c.retryWait(err, idempotent, retry)
+		if !ok || retry > c.options.MaxRetries || wait > c.options.MaxWait {
+			return result, err
+		}
+
+		logger.Warn("github call failed, retrying", slog.Int("retry", retry), slog.Duration("wait", wait), slog.Any("error", err))
+		if err := c.sleep(ctx, wait); err != nil {
+			return result, err
+		}
+	}
+}
+
+// retryWait tells whether the call which failed with err can be retried and how long to wait before
+func (c *rateLimitedClient) retryWait(err error, idempotent bool, retry int) (time.Duration, bool) {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return 0, false
+	}
+
+	// A call rejected by a rate limit was not processed, so any call can be retried
+	var rateLimitErr *gh.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return jitter(max(time.Until(rateLimitErr.Rate.Reset.Time), 0)), true
+	}
+
+	var abuseRateLimitErr *gh.AbuseRateLimitError
+	if errors.As(err, &abuseRateLimitErr) {
+		if abuseRateLimitErr.RetryAfter != nil {
+			return jitter(*abuseRateLimitErr.RetryAfter), true
+		}
+		return jitter(max(secondaryRateLimitWait, c.options.backoff(retry))), true
+	}
+
+	var errorResponse *gh.ErrorResponse
+	if errors.As(err, &errorResponse) && errorResponse.Response != nil {
+		switch status := errorResponse.Response.StatusCode; {
+		case status == http.StatusTooManyRequests:
+			if retryAfter, ok := parseRetryAfter(errorResponse.Response); ok {
+				return jitter(retryAfter), true
+			}
+			return jitter(max(secondaryRateLimitWait, c.options.backoff(retry))), true
+		case status >= http.StatusInternalServerError && idempotent:
+			return jitter(c.options.backoff(retry)), true
+		default:
+			return 0, false
+		}
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && idempotent {
+		return jitter(c.options.backoff(retry)), true
+	}
+
+	return 0, false
+}
+
+func parseRetryAfter(resp *http.Response) (time.Duration, bool) {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+
+	return time.Duration(seconds) * time.Second, true
+}
+
+// logRate logs the budget left in the primary rate limit, as a warning once per window when it runs low
+func (c *rateLimitedClient) logRate(logger *slog.Logger) {
+	reporter, ok := c.client.(rateReporter)
+	if !ok {
+		return
+	}
+
+	rate, ok := reporter.lastRate()
+	if !ok {
+		return
+	}
+
+	attrs := []any{slog.Int("remaining", rate.Remaining), slog.Int("limit", rate.Limit), slog.Time("reset", rate.Reset.Time)}
+
+	c.mu.Lock()
+	low := float64(rate.Remaining) < float64(rate.Limit)*lowRateBudget && !c.warnedReset.Equal(rate.Reset.Time)
+	if low {
+		c.warnedReset = rate.Reset.Time
+	}
+	c.mu.Unlock()
+
+	if low {
+		logger.Warn("github rate limit budget is running low", attrs...)
+		return
+	}
+	logger.Debug("github rate limit budget", attrs...)
+}
+
+func (c *rateLimitedClient) GetMigrationArchiveURL(ctx context.Context, organization string, organizationID int64) (string, error) {
+	return withRetries(ctx, c, "GetMigrationArchiveURL", true, func() (string, error) {
+		return c.client.GetMigrationArchiveURL(ctx, organization, organizationID)
+	})
+}
+
+func (c *rateLimitedClient) GetMigrationStatus(ctx context.Context, organization string, migrationID int64) (*gh.Migration, error) {
+	return withRetries(ctx, c, "GetMigrationStatus", true, func() (*gh.Migration, error) {
+		return c.client.GetMigrationStatus(ctx, organization, migrationID)
+	})
+}
+
+func (c *rateLimitedClient) StartMigration(ctx context.Context, organization string, repoNames []string, contents MigrationContents) (*gh.Migration, error) {
+	return withRetries(ctx, c, "StartMigration", false, func() (*gh.Migration, error) {
+		return c.client.StartMigration(ctx, organization, repoNames, contents)
+	})
+}
+
+// ListOrgRepos retries each page on its own when the client lists the repositories page by page, so that a page
+// rejected by a rate limit does not list the previous pages again
+func (c *rateLimitedClient) ListOrgRepos(ctx context.Context, organization string, visibility string) ([]*gh.Repository, error) {
+	pager, ok := c.client.(orgReposPager)
+	if !ok {
+		return withRetries(ctx, c, "ListOrgRepos", true, func() ([]*gh.Repository, error) {
+			return c.client.ListOrgRepos(ctx, organization, visibility)
+		})
+	}
+
+	return listPages(func(page int) ([]*gh.Repository, int, error) {
+		result, err := withRetries(ctx, c, "ListOrgRepos", true, func() (repositoryPage, error) {
+			repos, nextPage, err := pager.listOrgReposPage(ctx, organization, visibility, page)
+			return repositoryPage{repos: repos, nextPage: nextPage}, err
+		})
+		return result.repos, result.nextPage, err
+	})
+}
+
+func (c *rateLimitedClient) CreateRepository(ctx context.Context, organization string, repository *gh.Repository) (*gh.Repository, error) {
+	return withRetries(ctx, c, "CreateRepository", false, func() (*gh.Repository, error) {
+		return c.client.CreateRepository(ctx, organization, repository)
+	})
+}
+
+func (c *rateLimitedClient) CreateLabel(ctx context.Context, owner string, repo string, label gh.CreateIssueLabelRequest) error {
+	_, err := withRetries(ctx, c, "CreateLabel", false, func() (struct{}, error) {
+		return struct{}{}, c.client.CreateLabel(ctx, owner, repo, label)
+	})
+	return err
+}
+
+func (c *rateLimitedClient) CreateMilestone(ctx context.Context, owner string, repo string, milestone *gh.Milestone) (*gh.Milestone, error) {
+	return withRetries(ctx, c, "CreateMilestone", false, func() (*gh.Milestone, error) {
+		return c.client.CreateMilestone(ctx, owner, repo, milestone)
+	})
+}
+
+func (c *rateLimitedClient) CreateIssue(ctx context.Context, owner string, repo string, issue gh.CreateIssueRequest) (*gh.Issue, error) {
+	return withRetries(ctx, c, "CreateIssue", false, func() (*gh.Issue, error) {
+		return c.client.CreateIssue(ctx, owner, repo, issue)
+	})
+}
+
+// CloseIssue is idempotent, closing a closed issue changes nothing
+func (c *rateLimitedClient) CloseIssue(ctx context.Context, owner string, repo string, number int) error {
+	_, err := withRetries(ctx, c, "CloseIssue", true, func() (struct{}, error) {
+		return struct{}{}, c.client.CloseIssue(ctx, owner, repo, number)
+	})
+	return err
+}
@@ -0,0 +1,301 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gh "github.com/google/go-github/v90/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRateLimitedTestClient creates a rate limited Client talking to the given test server, its waits are recorded
+// instead of slept
+func newRateLimitedTestClient(t *testing.T, server *httptest.Server, waits *[]time.Duration) Client {
+	t.Helper()
+
+	baseURL := server.URL + "/"
+	// go-github would otherwise reject the retries itself until the reset announced by the test server
+	ghClient, err := gh.NewClient(gh.WithURLs(&baseURL, &baseURL), gh.WithDisableRateLimitCheck())
+	require.NoError(t, err)
+
+	client := NewRateLimitedClient(NewClient(ghClient), RateLimitOptions{MaxRetries: 2}).(*rateLimitedClient)
+	client.sleep = func(_ context.Context, wait time.Duration) error {
+		*waits = append(*waits, wait)
+		return nil
+	}
+
+	return client
+}
+
+func writeRateHeaders(w http.ResponseWriter, remaining int, reset time.Time) {
+	w.Header().Set("X-RateLimit-Limit", "5000")
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	w.Header().Set("X-RateLimit-Used", strconv.Itoa(5000-remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+}
+
+func TestRateLimitedClient_WaitsForPrimaryRateLimitReset(t *testing.T) {
+	// Given
+	reset := time.Now().Add(30 * time.Second)
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) == 1 {
+			writeRateHeaders(w, 0, reset)
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message": "API rate limit exceeded"}`))
+			return
+		}
+		writeRateHeaders(w, 4999, reset.Add(time.Hour))
+		_, _ = w.Write([]byte(`{"id": 1, "state": "exported"}`))
+	}))
+	defer server.Close()
+
+	var waits []time.Duration
+	client := newRateLimitedTestClient(t, server, &waits)
+
+	// When
+	migration, err := client.GetMigrationStatus(context.Background(), "kumojin", 1)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, "exported", migration.GetState())
+	assert.Equal(t, int32(2), requests.Load())
+	require.Len(t, waits, 1)
+	assert.InDelta(t, 30*time.Second, waits[0], float64(5*time.Second))
+
+	rate, ok := client.(*rateLimitedClient).client.(rateReporter).lastRate()
+	assert.True(t, ok)
+	assert.Equal(t, 4999, rate.Remaining)
+}
+
+func TestRateLimitedClient_WaitsForSecondaryRateLimit(t *testing.T) {
+	// Given
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"message": "You have exceeded a secondary rate limit", "documentation_url": "https://docs.github.com/rest/overview/rate-limits-for-the-rest-api#about-secondary-rate-limits"}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id": 12345, "state": "pending"}`))
+	}))
+	defer server.Close()
+
+	var waits []time.Duration
+	client := newRateLimitedTestClient(t, server, &waits)
+
+	// When
+	migration, err := client.StartMigration(context.Background(), "kumojin", []string{"repo1"}, DefaultMigrationContents())
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, int64(12345), migration.GetID())
+	assert.Equal(t, int32(2), requests.Load())
+	require.Len(t, waits, 1)
+	assert.GreaterOrEqual(t, waits[0], 3*time.Second)
+	assert.Less(t, waits[0], 5*time.Second)
+}
+
+func TestRateLimitedClient_WaitsAtLeastAMinuteWithoutRetryAfter(t *testing.T) {
+	// Given
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message": "You have exceeded a secondary rate limit", "documentation_url": "https://docs.github.com/rest/overview/rate-limits-for-the-rest-api#about-secondary-rate-limits"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"name": "repo1"}]`))
+	}))
+	defer server.Close()
+
+	var waits []time.Duration
+	client := newRateLimitedTestClient(t, server, &waits)
+
+	// When
+	repos, err := client.ListOrgRepos(context.Background(), "kumojin", "private")
+
+	// Then
+	require.NoError(t, err)
+	assert.Len(t, repos, 1)
+	require.Len(t, waits, 1)
+	assert.GreaterOrEqual(t, waits[0], time.Minute)
+}
+
+func TestRateLimitedClient_RetriesIdempotentCallsAfterServerErrors(t *testing.T) {
+	// Given
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) <= 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": 1, "state": "exported"}`))
+	}))
+	defer server.Close()
+
+	var waits []time.Duration
+	client := newRateLimitedTestClient(t, server, &waits)
+
+	// When
+	migration, err := client.GetMigrationStatus(context.Background(), "kumojin", 1)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, "exported", migration.GetState())
+	assert.Equal(t, int32(3), requests.Load())
+	require.Len(t, waits, 2)
+	assert.GreaterOrEqual(t, waits[1], 2*time.Second, "the backoff doubles")
+}
+
+func TestRateLimitedClient_RetriesFailedPageOnly(t *testing.T) {
+	// Given
+	var firstPageRequests, secondPageRequests atomic.Int32
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "1" {
+			firstPageRequests.Add(1)
+			w.Header().Set("Link", `<`+server.URL+`/orgs/kumojin/repos?per_page=100&page=2>; rel="next"`)
+			_, _ = w.Write([]byte(`[{"name": "repo1"}]`))
+			return
+		}
+
+		if secondPageRequests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"name": "repo2"}]`))
+	}))
+	defer server.Close()
+
+	var waits []time.Duration
+	client := newRateLimitedTestClient(t, server, &waits)
+
+	// When
+	repos, err := client.ListOrgRepos(context.Background(), "kumojin", "private")
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, repos, 2)
+	assert.Equal(t, "repo2", repos[1].GetName())
+	assert.Equal(t, int32(1), firstPageRequests.Load(), "the first page is not listed again")
+	assert.Equal(t, int32(2), secondPageRequests.Load())
+	assert.Len(t, waits, 1)
+}
+
+func TestRateLimitedClient_DoesNotRetryOtherCallsAfterServerErrors(t *testing.T) {
+	// Given
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	var waits []time.Duration
+	client := newRateLimitedTestClient(t, server, &waits)
+
+	// When
+	_, err := client.CreateRepository(context.Background(), "kumojin", &gh.Repository{Name: gh.Ptr("repo1")})
+
+	// Then
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), requests.Load())
+	assert.Empty(t, waits)
+}
+
+func TestRateLimitedClient_GivesUpAfterMaxRetries(t *testing.T) {
+	// Given
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		writeRateHeaders(w, 0, time.Now().Add(time.Minute))
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	var waits []time.Duration
+	client := newRateLimitedTestClient(t, server, &waits)
+
+	// When
+	_, err := client.GetMigrationStatus(context.Background(), "kumojin", 1)
+
+	// Then
+	var rateLimitErr *gh.RateLimitError
+	assert.ErrorAs(t, err, &rateLimitErr)
+	assert.Equal(t, int32(3), requests.Load())
+	assert.Len(t, waits, 2)
+}
+
+func TestRateLimitedClient_DoesNotWaitPastMaxWait(t *testing.T) {
+	// Given
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		writeRateHeaders(w, 0, time.Now().Add(2*time.Hour))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	var waits []time.Duration
+	client := newRateLimitedTestClient(t, server, &waits)
+
+	// When
+	_, err := client.GetMigrationStatus(context.Background(), "kumojin", 1)
+
+	// Then
+	var rateLimitErr *gh.RateLimitError
+	assert.ErrorAs(t, err, &rateLimitErr)
+	assert.Equal(t, int32(1), requests.Load())
+	assert.Empty(t, waits)
+}
+
+func TestRateLimitedClient_ContextCancellation(t *testing.T) {
+	// Given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeRateHeaders(w, 0, time.Now().Add(time.Minute))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	baseURL := server.URL + "/"
+	ghClient, err := gh.NewClient(gh.WithURLs(&baseURL, &baseURL))
+	require.NoError(t, err)
+	client := NewRateLimitedClient(NewClient(ghClient), RateLimitOptions{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// When
+	_, err = client.GetMigrationStatus(ctx, "kumojin", 1)
+
+	// Then
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRateLimitOptions_Backoff(t *testing.T) {
+	options := RateLimitOptions{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	assert.Equal(t, time.Second, options.backoff(1))
+	assert.Equal(t, 2*time.Second, options.backoff(2))
+	assert.Equal(t, 4*time.Second, options.backoff(3))
+	assert.Equal(t, 5*time.Second, options.backoff(4))
+	assert.Equal(t, 5*time.Second, options.backoff(60))
+}
@@ -0,0 +1,77 @@
+package github
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+
+	gh "github.com/google/go-github/v90/github"
+)
+
+// Server locates the GitHub API, github.com when BaseURL is empty, otherwise a GitHub Enterprise Server
+type Server struct {
+	// BaseURL is the URL of the GitHub Enterprise Server, /api/v3/ is appended when it is missing
+	BaseURL string
+	// UploadURL defaults to BaseURL, /api/uploads/ is appended when it is missing
+	UploadURL string
+	// CABundle holds the PEM certificates of the authorities trusted in addition to the system ones
+	CABundle []byte
+	// InsecureSkipVerify disables the verification of the server certificate
+	InsecureSkipVerify bool
+}
+
+func (s Server) IsEnterprise() bool {
+	return s.BaseURL != ""
+}
+
+// HTTPClient returns the client for the API and for the migration archives of the server, it trusts the CA bundle
+func (s Server) HTTPClient() (*http.Client, error) {
+	if len(s.CABundle) == 0 && !s.InsecureSkipVerify {
+		return http.DefaultClient, nil
+	}
+
+	rootCAs, err := x509.SystemCertPool()
+	if err != nil {
+		rootCAs = x509.NewCertPool()
+	}
+	if len(s.CABundle) > 0 && !rootCAs.AppendCertsFromPEM(s.CABundle) {
+		return nil, errors.New("failed to parse CA bundle: no PEM certificate found")
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		RootCAs:            rootCAs,
+		InsecureSkipVerify: s.InsecureSkipVerify,
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// ClientOptions returns the options creating a go-github client for the server with the given HTTP client
+func (s Server) ClientOptions(httpClient *http.Client) []gh.ClientOptionsFunc {
+	options := []gh.ClientOptionsFunc{gh.WithHTTPClient(httpClient)}
+	if !s.IsEnterprise() {
+		return options
+	}
+
+	uploadURL := s.UploadURL
+	if uploadURL == "" {
+		uploadURL = s.BaseURL
+	}
+
+	return append(options, gh.WithEnterpriseURLs(s.BaseURL, uploadURL))
+}
+
+// NewServerClient creates a go-github client for the server using the HTTP client returned by Server.HTTPClient,
+// every request is authenticated with the token source
+func NewServerClient(server Server, httpClient *http.Client, tokenSource TokenSource) (*gh.Client, error) {
+	client, err := gh.NewClient(append(server.ClientOptions(httpClient), gh.WithTransport(NewTokenTransport(tokenSource, httpClient.Transport)))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create github client: %w", err)
+	}
+
+	return client, nil
+}
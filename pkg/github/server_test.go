@@ -0,0 +1,191 @@
+package github
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kumojin/repo-backup-cli/pkg/download"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestCA generates a certificate authority and a certificate it signed for 127.0.0.1, it returns the PEM
+// certificate of the authority
+func newTestCA(t *testing.T) ([]byte, tls.Certificate) {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Internal CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	serverKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	serverTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "github.internal"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	serverDER, err := x509.CreateCertificate(rand.Reader, serverTemplate, caCert, &serverKey.PublicKey, caKey)
+	require.NoError(t, err)
+
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+	return caPEM, tls.Certificate{Certificate: [][]byte{serverDER}, PrivateKey: serverKey}
+}
+
+// newEnterpriseServer starts a GitHub Enterprise Server behind the test CA, it serves the API under /api/v3/ and
+// the migration archive under /storage/
+func newEnterpriseServer(t *testing.T, certificate tls.Certificate) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/app/installations/42/access_tokens":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"token": "ghs_enterprise", "expires_at": "` + time.Now().Add(time.Hour).UTC().Format(time.RFC3339) + `"}`))
+		case "/api/v3/orgs/kumojin/repos":
+			if r.Header.Get("Authorization") != "Bearer ghs_enterprise" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`[{"name": "repo1"}]`))
+		case "/storage/archive.tar.gz":
+			_, _ = w.Write([]byte("archive"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{certificate}}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestServer_TrustsCABundle(t *testing.T) {
+	// Given
+	caPEM, certificate := newTestCA(t)
+	enterpriseServer := newEnterpriseServer(t, certificate)
+	server := Server{BaseURL: enterpriseServer.URL, CABundle: caPEM}
+
+	httpClient, err := server.HTTPClient()
+	require.NoError(t, err)
+
+	app, _ := newTestApp(t)
+	tokenSource, err := NewAppTokenSource(app, server.ClientOptions(httpClient)...)
+	require.NoError(t, err)
+
+	ghClient, err := NewServerClient(server, httpClient, tokenSource)
+	require.NoError(t, err)
+
+	// When
+	repos, listErr := NewClient(ghClient).ListOrgRepos(context.Background(), "kumojin", "private")
+
+	reader, downloadErr := download.NewDownloader(httpClient, download.Options{}).
+		Open(context.Background(), download.StaticURL(enterpriseServer.URL+"/storage/archive.tar.gz"))
+	require.NoError(t, downloadErr)
+	defer func() { _ = reader.Close() }()
+	archive, readErr := io.ReadAll(reader)
+
+	// Then
+	require.NoError(t, listErr)
+	require.Len(t, repos, 1)
+	assert.Equal(t, "repo1", repos[0].GetName())
+	assert.Equal(t, enterpriseServer.URL+"/api/v3/", ghClient.BaseURL())
+	assert.Equal(t, enterpriseServer.URL+"/api/uploads/", ghClient.UploadURL())
+	require.NoError(t, readErr)
+	assert.Equal(t, "archive", string(archive))
+}
+
+func TestServer_RejectsUnknownCA(t *testing.T) {
+	// Given
+	_, certificate := newTestCA(t)
+	enterpriseServer := newEnterpriseServer(t, certificate)
+	otherCAPEM, _ := newTestCA(t)
+	server := Server{BaseURL: enterpriseServer.URL, CABundle: otherCAPEM}
+
+	httpClient, err := server.HTTPClient()
+	require.NoError(t, err)
+	ghClient, err := NewServerClient(server, httpClient, NewStaticTokenSource("ghs_enterprise"))
+	require.NoError(t, err)
+
+	// When
+	_, err = NewClient(ghClient).ListOrgRepos(context.Background(), "kumojin", "private")
+
+	// Then
+	assert.ErrorContains(t, err, "certificate signed by unknown authority")
+}
+
+func TestServer_InsecureSkipVerify(t *testing.T) {
+	// Given
+	_, certificate := newTestCA(t)
+	enterpriseServer := newEnterpriseServer(t, certificate)
+	server := Server{BaseURL: enterpriseServer.URL, InsecureSkipVerify: true}
+
+	httpClient, err := server.HTTPClient()
+	require.NoError(t, err)
+	ghClient, err := NewServerClient(server, httpClient, NewStaticTokenSource("ghs_enterprise"))
+	require.NoError(t, err)
+
+	// When
+	repos, err := NewClient(ghClient).ListOrgRepos(context.Background(), "kumojin", "private")
+
+	// Then
+	require.NoError(t, err)
+	assert.Len(t, repos, 1)
+}
+
+func TestServer_HTTPClient(t *testing.T) {
+	t.Run("default client without TLS settings", func(t *testing.T) {
+		httpClient, err := Server{BaseURL: "https://github.internal"}.HTTPClient()
+
+		require.NoError(t, err)
+		assert.Same(t, http.DefaultClient, httpClient)
+	})
+
+	t.Run("invalid CA bundle", func(t *testing.T) {
+		_, err := Server{CABundle: []byte("not a certificate")}.HTTPClient()
+
+		assert.EqualError(t, err, "failed to parse CA bundle: no PEM certificate found")
+	})
+}
+
+func TestServer_ClientOptionsForGithubCom(t *testing.T) {
+	// Given
+	server := Server{}
+
+	// When
+	ghClient, err := NewServerClient(server, http.DefaultClient, NewStaticTokenSource("ghp_token"))
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.github.com/", ghClient.BaseURL())
+}
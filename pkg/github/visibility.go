@@ -0,0 +1,19 @@
+package github
+
+import "fmt"
+
+const (
+	VisibilityPrivate  = "private"
+	VisibilityInternal = "internal"
+	VisibilityPublic   = "public"
+	VisibilityAll      = "all"
+)
+
+func ValidateVisibility(visibility string) error {
+	switch visibility {
+	case VisibilityPrivate, VisibilityInternal, VisibilityPublic, VisibilityAll:
+		return nil
+	default:
+		return fmt.Errorf("unsupported visibility: %s (supported: %s, %s, %s, %s)", visibility, VisibilityPrivate, VisibilityInternal, VisibilityPublic, VisibilityAll)
+	}
+}
@@ -0,0 +1,214 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+)
+
+// DefaultJob is the job of the metrics pushed to a Pushgateway
+const DefaultJob = "rbk"
+
+const (
+	namespace       = "rbk_backup"
+	lastSuccessName = "last_success_timestamp_seconds"
+)
+
+// Run is the outcome of a backup run
+type Run struct {
+	// Stages are the stages a backup can fail at, the failed stage gauge is set for every stage so that a success
+	// replaces the failure of the previous backup
+	Stages  []string
+	Backups []Backup
+}
+
+// Backup is the outcome of the backup of an organization
+type Backup struct {
+	Organization string
+	Succeeded    bool
+	// FailedStage is the stage the backup failed at
+	FailedStage string
+	StartedAt   time.Time
+	FinishedAt  time.Time
+	// PhaseDurations are the seconds spent in each phase of the backup
+	PhaseDurations       map[string]float64
+	TransferredBytes     int64
+	Repositories         int
+	ExcludedRepositories int
+}
+
+// backupMetrics are the metrics of the last backup of the organizations. They have an organization label, except
+// when pushed to a Pushgateway where the organization is a grouping label.
+type backupMetrics struct {
+	organizationLabel bool
+	stages            []string
+	// lastSuccesses are the last success timestamps of the organizations written before, the last success of a failed
+	// backup is kept from them when set. It is nil when pushing, the Pushgateway keeps the metrics which are not pushed.
+	lastSuccesses map[string]float64
+
+	lastSuccess   *prometheus.GaugeVec
+	success       *prometheus.GaugeVec
+	duration      *prometheus.GaugeVec
+	phaseDuration *prometheus.GaugeVec
+	bytes         *prometheus.GaugeVec
+	repositories  *prometheus.GaugeVec
+	excluded      *prometheus.GaugeVec
+	lastRunFailed *prometheus.GaugeVec
+}
+
+func newBackupMetrics(registry *prometheus.Registry, organizationLabel bool, stages []string) *backupMetrics {
+	gaugeVec := func(name string, help string, labels ...string) *prometheus.GaugeVec {
+		if organizationLabel {
+			labels = append([]string{"organization"}, labels...)
+		}
+		gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      name,
+			Help:      help,
+		}, labels)
+		registry.MustRegister(gauge)
+
+		return gauge
+	}
+
+	return &backupMetrics{
+		organizationLabel: organizationLabel,
+		stages:            stages,
+		lastSuccess:       gaugeVec(lastSuccessName, "Time the last successful backup of the organization finished."),
+		success:           gaugeVec("success", "Whether the last backup of the organization succeeded."),
+		duration:          gaugeVec("duration_seconds", "Duration of the last backup of the organization."),
+		phaseDuration:     gaugeVec("phase_duration_seconds", "Seconds spent in each phase of the last backup, summed over the batches and repositories.", "phase"),
+		bytes:             gaugeVec("transferred_bytes", "Bytes of the archives saved by the last backup, as stored."),
+		repositories:      gaugeVec("repositories", "Repositories saved by the last backup."),
+		excluded:          gaugeVec("excluded_repositories", "Repositories skipped by the last backup."),
+		lastRunFailed:     gaugeVec("last_run_failed", "Whether the last backup failed at the stage.", "stage"),
+	}
+}
+
+// labels returns the values of the labels of a metric of the organization
+func (m *backupMetrics) labels(organization string, values ...string) []string {
+	if m.organizationLabel {
+		return append([]string{organization}, values...)
+	}
+
+	return values
+}
+
+// record sets the metrics of the organization from its backup
+func (m *backupMetrics) record(backup Backup) {
+	organization := m.labels(backup.Organization)
+
+	switch {
+	case backup.Succeeded:
+		m.lastSuccess.WithLabelValues(organization...).Set(float64(backup.FinishedAt.Unix()))
+	case m.lastSuccesses != nil:
+		// Zero when the organization never succeeded, so that an alert on the age of the last success fires
+		m.lastSuccess.WithLabelValues(organization...).Set(m.lastSuccesses[backup.Organization])
+	}
+
+	succeeded := 0.0
+	if backup.Succeeded {
+		succeeded = 1
+	}
+	m.success.WithLabelValues(organization...).Set(succeeded)
+
+	for _, stage := range m.stages {
+		failed := 0.0
+		if !backup.Succeeded && backup.FailedStage == stage {
+			failed = 1
+		}
+		m.lastRunFailed.WithLabelValues(m.labels(backup.Organization, stage)...).Set(failed)
+	}
+
+	m.duration.WithLabelValues(organization...).Set(backup.FinishedAt.Sub(backup.StartedAt).Seconds())
+	for _, phase := range slices.Sorted(maps.Keys(backup.PhaseDurations)) {
+		m.phaseDuration.WithLabelValues(m.labels(backup.Organization, phase)...).Set(backup.PhaseDurations[phase])
+	}
+
+	m.bytes.WithLabelValues(organization...).Set(float64(backup.TransferredBytes))
+	m.repositories.WithLabelValues(organization...).Set(float64(backup.Repositories))
+	m.excluded.WithLabelValues(organization...).Set(float64(backup.ExcludedRepositories))
+}
+
+// Push pushes the metrics of the run to a Pushgateway, in a group per organization. The metrics are added to the
+// group, so that the last success timestamp of an organization is kept when its backup fails.
+func Push(ctx context.Context, url string, job string, run Run) error {
+	for _, backup := range run.Backups {
+		registry := prometheus.NewRegistry()
+		newBackupMetrics(registry, false, run.Stages).record(backup)
+
+		err := push.New(url, job).
+			Grouping("organization", backup.Organization).
+			Gatherer(registry).
+			Format(expfmt.NewFormat(expfmt.TypeTextPlain)).
+			AddContext(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to push metrics of %s: %w", backup.Organization, err)
+		}
+	}
+
+	return nil
+}
+
+// WriteTextfile writes the metrics of the run to a file read by the textfile collector of the node exporter, the
+// file is replaced atomically. The last success timestamp of a failed backup is kept from the file.
+func WriteTextfile(path string, run Run) error {
+	lastSuccesses, err := readLastSuccesses(path)
+	if err != nil {
+		return err
+	}
+
+	registry := prometheus.NewRegistry()
+	metrics := newBackupMetrics(registry, true, run.Stages)
+	metrics.lastSuccesses = lastSuccesses
+	for _, backup := range run.Backups {
+		metrics.record(backup)
+	}
+
+	if err := prometheus.WriteToTextfile(path, registry); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+
+	return nil
+}
+
+// readLastSuccesses returns the last success timestamps of the organizations written to the textfile by the previous
+// run, an unreadable file is replaced and keeps none
+func readLastSuccesses(path string) (map[string]float64, error) {
+	lastSuccesses := map[string]float64{}
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return lastSuccesses, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metrics: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	parser := expfmt.NewTextParser(model.LegacyValidation)
+	families, err := parser.TextToMetricFamilies(file)
+	if err != nil {
+		return lastSuccesses, nil
+	}
+
+	for _, metric := range families[namespace+"_"+lastSuccessName].GetMetric() {
+		for _, label := range metric.GetLabel() {
+			if label.GetName() == "organization" {
+				lastSuccesses[label.GetValue()] = metric.GetGauge().GetValue()
+			}
+		}
+	}
+
+	return lastSuccesses, nil
+}
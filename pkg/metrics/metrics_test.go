@@ -0,0 +1,132 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var startedAt = time.Date(2025, 7, 23, 2, 0, 0, 0, time.UTC)
+
+func newRun() Run {
+	return Run{
+		Stages: []string{"list", "migrate", "download", "upload", "other"},
+		Backups: []Backup{
+			{
+				Organization:     "kumojin",
+				Succeeded:        true,
+				StartedAt:        startedAt,
+				FinishedAt:       startedAt.Add(90 * time.Second),
+				PhaseDurations:   map[string]float64{"list": 1.5, "migrate": 60, "download": 20, "upload": 25},
+				TransferredBytes: 3072,
+				Repositories:     2,
+			},
+			{
+				Organization:   "broken",
+				FailedStage:    "migrate",
+				StartedAt:      startedAt,
+				FinishedAt:     startedAt.Add(10 * time.Second),
+				PhaseDurations: map[string]float64{"list": 0.5, "migrate": 9.5, "download": 0, "upload": 0},
+			},
+		},
+	}
+}
+
+func TestPush(t *testing.T) {
+	// Given
+	bodies := map[string]string{}
+	pushgateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method, "metrics are added to the group")
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		bodies[r.URL.Path] = string(body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer pushgateway.Close()
+
+	// When
+	err := Push(context.Background(), pushgateway.URL, DefaultJob, newRun())
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, bodies, 2)
+
+	kumojin := bodies["/metrics/job/rbk/organization/kumojin"]
+	assert.Contains(t, kumojin, `rbk_backup_last_success_timestamp_seconds 1.75323609e+09`)
+	assert.Contains(t, kumojin, `rbk_backup_success 1`)
+	assert.Contains(t, kumojin, `rbk_backup_duration_seconds 90`)
+	assert.Contains(t, kumojin, `rbk_backup_phase_duration_seconds{phase="migrate"} 60`)
+	assert.Contains(t, kumojin, `rbk_backup_transferred_bytes 3072`)
+	assert.Contains(t, kumojin, `rbk_backup_repositories 2`)
+	assert.Contains(t, kumojin, `rbk_backup_last_run_failed{stage="migrate"} 0`, "a success clears the failure pushed before")
+
+	broken := bodies["/metrics/job/rbk/organization/broken"]
+	assert.Contains(t, broken, `rbk_backup_success 0`)
+	assert.Contains(t, broken, `rbk_backup_last_run_failed{stage="migrate"} 1`)
+	assert.Contains(t, broken, `rbk_backup_phase_duration_seconds{phase="upload"} 0`)
+	assert.NotContains(t, broken, "rbk_backup_last_success_timestamp_seconds", "the last success of a failed organization is kept by the pushgateway")
+}
+
+func TestPush_Error(t *testing.T) {
+	// Given
+	pushgateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer pushgateway.Close()
+
+	// When
+	err := Push(context.Background(), pushgateway.URL, DefaultJob, newRun())
+
+	// Then
+	assert.ErrorContains(t, err, "failed to push metrics of kumojin")
+}
+
+func TestWriteTextfile(t *testing.T) {
+	// Given
+	path := filepath.Join(t.TempDir(), "rbk.prom")
+
+	// When
+	err := WriteTextfile(path, newRun())
+
+	// Then
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "# TYPE rbk_backup_last_success_timestamp_seconds gauge")
+	assert.Contains(t, string(content), `rbk_backup_last_success_timestamp_seconds{organization="kumojin"} 1.75323609e+09`)
+	assert.Contains(t, string(content), `rbk_backup_last_success_timestamp_seconds{organization="broken"} 0`, "an organization which never succeeded is written")
+	assert.Contains(t, string(content), `rbk_backup_success{organization="kumojin"} 1`)
+	assert.Contains(t, string(content), `rbk_backup_last_run_failed{organization="broken",stage="migrate"} 1`)
+}
+
+func TestWriteTextfile_KeepsLastSuccessOfFailedBackup(t *testing.T) {
+	// Given
+	path := filepath.Join(t.TempDir(), "rbk.prom")
+	require.NoError(t, WriteTextfile(path, newRun()))
+
+	failed := newRun()
+	failed.Backups[0].Succeeded = false
+	failed.Backups[0].FailedStage = "upload"
+	failed.Backups[0].FinishedAt = startedAt.Add(24 * time.Hour)
+
+	// When
+	err := WriteTextfile(path, failed)
+
+	// Then
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `rbk_backup_last_success_timestamp_seconds{organization="kumojin"} 1.75323609e+09`)
+	assert.Contains(t, string(content), `rbk_backup_success{organization="kumojin"} 0`)
+	assert.Contains(t, string(content), `rbk_backup_last_run_failed{organization="kumojin",stage="upload"} 1`)
+}
@@ -0,0 +1,234 @@
+package migration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	gitDirExtension = ".git"
+	bundleExtension = ".bundle"
+	wikiSuffix      = ".wiki"
+)
+
+type Label struct {
+	URL         string `json:"url"`
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+}
+
+type Milestone struct {
+	URL         string     `json:"url"`
+	Repository  string     `json:"repository"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	State       string     `json:"state"`
+	DueOn       *time.Time `json:"due_on"`
+}
+
+type Issue struct {
+	URL        string     `json:"url"`
+	Repository string     `json:"repository"`
+	User       string     `json:"user"`
+	Title      string     `json:"title"`
+	Body       string     `json:"body"`
+	Milestone  string     `json:"milestone"`
+	Labels     []string   `json:"labels"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ClosedAt   *time.Time `json:"closed_at"`
+}
+
+// Author returns the login of the user who opened the issue
+func (i Issue) Author() string {
+	return lastPathSegment(i.User)
+}
+
+// LabelNames returns the names of the labels of the issue, which are referenced by URL in the archive
+func (i Issue) LabelNames() []string {
+	names := make([]string, 0, len(i.Labels))
+	for _, label := range i.Labels {
+		names = append(names, lastPathSegment(label))
+	}
+
+	return names
+}
+
+func (i Issue) IsClosed() bool {
+	return i.ClosedAt != nil
+}
+
+type Repository struct {
+	URL           string  `json:"url"`
+	Name          string  `json:"name"`
+	Description   string  `json:"description"`
+	Website       *string `json:"website"`
+	Private       bool    `json:"private"`
+	HasIssues     bool    `json:"has_issues"`
+	HasWiki       bool    `json:"has_wiki"`
+	DefaultBranch string  `json:"default_branch"`
+	Labels        []Label `json:"labels"`
+
+	Milestones []Milestone `json:"-"`
+	Issues     []Issue     `json:"-"`
+	// GitPath is the path of the bare repository or of the bundle holding the git data, empty when absent
+	GitPath string `json:"-"`
+}
+
+// HasGitBundle tells whether the git data of the repository is stored as a bundle instead of a bare repository
+func (r Repository) HasGitBundle() bool {
+	return strings.HasSuffix(r.GitPath, bundleExtension)
+}
+
+// Archive is the content of an extracted backup archive, produced either by the GitHub migrations API or by a mirror backup
+type Archive struct {
+	Dir          string
+	Repositories []Repository
+}
+
+// Open reads the repositories, milestones and issues of an archive extracted in dir
+func Open(dir string) (*Archive, error) {
+	var repositories []Repository
+	if err := readRecords(dir, "repositories", &repositories); err != nil {
+		return nil, err
+	}
+
+	var milestones []Milestone
+	if err := readRecords(dir, "milestones", &milestones); err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	if err := readRecords(dir, "issues", &issues); err != nil {
+		return nil, err
+	}
+
+	gitPaths, err := findGitData(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byURL := make(map[string]*Repository, len(repositories))
+	for i := range repositories {
+		byURL[repositories[i].URL] = &repositories[i]
+	}
+
+	for _, milestone := range milestones {
+		if repository, ok := byURL[milestone.Repository]; ok {
+			repository.Milestones = append(repository.Milestones, milestone)
+		}
+	}
+
+	for _, issue := range issues {
+		if repository, ok := byURL[issue.Repository]; ok {
+			repository.Issues = append(repository.Issues, issue)
+		}
+	}
+
+	// Mirror backups only contain bundles, repositories are then only known by their git data
+	known := make(map[string]bool, len(repositories))
+	for i := range repositories {
+		known[repositories[i].Name] = true
+		repositories[i].GitPath = gitPaths[repositories[i].Name]
+	}
+
+	var names []string
+	for name := range gitPaths {
+		if !known[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		repositories = append(repositories, Repository{
+			Name:      name,
+			Private:   true,
+			HasIssues: true,
+			GitPath:   gitPaths[name],
+		})
+	}
+
+	return &Archive{
+		Dir:          dir,
+		Repositories: repositories,
+	}, nil
+}
+
+// readRecords decodes every <kind>_NNNNNN.json file of the archive into records
+func readRecords[T any](dir string, kind string, records *[]T) error {
+	files, err := filepath.Glob(filepath.Join(dir, kind+"_*.json"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", filepath.Base(file), err)
+		}
+
+		var page []T
+		if err := json.Unmarshal(content, &page); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", filepath.Base(file), err)
+		}
+
+		*records = append(*records, page...)
+	}
+
+	return nil
+}
+
+// findGitData returns the path of the git data of every repository found in repositories/<organization>/
+func findGitData(dir string) (map[string]string, error) {
+	entries, err := filepath.Glob(filepath.Join(dir, "repositories", "*", "*"))
+	if err != nil {
+		return nil, err
+	}
+
+	gitPaths := make(map[string]string)
+	for _, entry := range entries {
+		base := filepath.Base(entry)
+
+		var name string
+		switch {
+		case strings.HasSuffix(base, bundleExtension):
+			name = strings.TrimSuffix(base, bundleExtension)
+		case strings.HasSuffix(base, gitDirExtension):
+			name = strings.TrimSuffix(base, gitDirExtension)
+		default:
+			continue
+		}
+
+		// Wikis are stored next to their repository and are not restored
+		if strings.HasSuffix(name, wikiSuffix) {
+			continue
+		}
+
+		gitPaths[name] = entry
+	}
+
+	return gitPaths, nil
+}
+
+func lastPathSegment(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return path.Base(rawURL)
+	}
+
+	segment, err := url.PathUnescape(path.Base(parsed.Path))
+	if err != nil {
+		return path.Base(parsed.Path)
+	}
+
+	return segment
+}
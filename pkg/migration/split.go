@@ -0,0 +1,211 @@
+package migration
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kumojin/repo-backup-cli/pkg/archive"
+)
+
+// recordFilePattern matches the <kind>_NNNNNN.json files holding the records of an archive
+var recordFilePattern = regexp.MustCompile(`^([a-z_]+)_\d+\.json$`)
+
+// Part is a piece of an archive split by repository
+type Part struct {
+	// Repository is the name of the repository of the part, empty for the part shared by every repository
+	Repository string
+	entries    []archive.Entry
+}
+
+// IsShared tells whether the part holds what is not specific to a repository, such as the users and teams
+func (p Part) IsShared() bool {
+	return p.Repository == ""
+}
+
+// WriteTarGz writes the part as a gzipped tarball, with the same layout as the archive it was split from
+func (p Part) WriteTarGz(w io.Writer) error {
+	return archive.WriteTarGzEntries(w, p.entries)
+}
+
+// record holds the fields telling which repository a record belongs to
+type record struct {
+	URL        string `json:"url"`
+	Repository string `json:"repository"`
+}
+
+// Split splits an archive extracted in dir into a part per repository, restorable on its own, and a shared part.
+// A repository part holds the git data of the repository and its wiki, and the records referencing it, such as
+// its issues and milestones. The shared part holds every other file, such as the users, the teams and the
+// attachments.
+func Split(dir string) ([]Part, error) {
+	repositoryNames, err := readRepositoryNames(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make(map[string]*Part)
+	partOf := func(repository string) *Part {
+		part, ok := parts[repository]
+		if !ok {
+			part = &Part{Repository: repository}
+			parts[repository] = part
+		}
+		return part
+	}
+	// The shared part is written even when every file belongs to a repository
+	shared := partOf("")
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		switch {
+		case name == "repositories" && entry.IsDir():
+			if err := splitGitData(dir, partOf); err != nil {
+				return nil, err
+			}
+
+		case !entry.IsDir() && recordFilePattern.MatchString(name):
+			if err := splitRecords(dir, name, repositoryNames, partOf); err != nil {
+				return nil, err
+			}
+
+		default:
+			addEntry(shared, dir, name)
+		}
+	}
+
+	names := make([]string, 0, len(parts))
+	for name := range parts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]Part, 0, len(names))
+	for _, name := range names {
+		result = append(result, *parts[name])
+	}
+
+	return result, nil
+}
+
+// readRepositoryNames returns the names of the repositories of the archive by URL
+func readRepositoryNames(dir string) (map[string]string, error) {
+	var repositories []Repository
+	if err := readRecords(dir, "repositories", &repositories); err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string, len(repositories))
+	for _, repository := range repositories {
+		names[repository.URL] = repository.Name
+	}
+
+	return names, nil
+}
+
+// splitGitData adds the git data found in repositories/<organization>/ to the part of its repository, the wikis
+// go with their repository and anything else is shared
+func splitGitData(dir string, partOf func(repository string) *Part) error {
+	organizations, err := os.ReadDir(filepath.Join(dir, "repositories"))
+	if err != nil {
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	for _, organization := range organizations {
+		organizationPath := path.Join("repositories", organization.Name())
+		if !organization.IsDir() {
+			addEntry(partOf(""), dir, organizationPath)
+			continue
+		}
+
+		entries, err := os.ReadDir(filepath.Join(dir, filepath.FromSlash(organizationPath)))
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		for _, entry := range entries {
+			addEntry(partOf(gitDataRepository(entry.Name())), dir, path.Join(organizationPath, entry.Name()))
+		}
+	}
+
+	return nil
+}
+
+// addEntry adds the file or directory of the archive extracted in dir to the part
+func addEntry(part *Part, dir string, name string) {
+	part.entries = append(part.entries, archive.Entry{Name: name, Path: filepath.Join(dir, filepath.FromSlash(name))})
+}
+
+// gitDataRepository returns the repository of a bare repository or bundle, empty when the name is neither
+func gitDataRepository(name string) string {
+	var repository string
+	switch {
+	case strings.HasSuffix(name, bundleExtension):
+		repository = strings.TrimSuffix(name, bundleExtension)
+	case strings.HasSuffix(name, gitDirExtension):
+		repository = strings.TrimSuffix(name, gitDirExtension)
+	default:
+		return ""
+	}
+
+	return strings.TrimSuffix(repository, wikiSuffix)
+}
+
+// splitRecords writes the records of a record file referencing a known repository to the part of the repository,
+// under the same file name. The other records are shared.
+func splitRecords(dir string, fileName string, repositoryNames map[string]string, partOf func(repository string) *Part) error {
+	content, err := os.ReadFile(filepath.Join(dir, fileName))
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", fileName, err)
+	}
+
+	var page []json.RawMessage
+	if err := json.Unmarshal(content, &page); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", fileName, err)
+	}
+
+	isRepositories := recordFilePattern.FindStringSubmatch(fileName)[1] == "repositories"
+
+	byRepository := make(map[string][]json.RawMessage)
+	var order []string
+	for _, raw := range page {
+		var r record
+		if err := json.Unmarshal(raw, &r); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", fileName, err)
+		}
+
+		repositoryURL := r.Repository
+		if isRepositories {
+			repositoryURL = r.URL
+		}
+
+		repository := repositoryNames[repositoryURL]
+		if _, ok := byRepository[repository]; !ok {
+			order = append(order, repository)
+		}
+		byRepository[repository] = append(byRepository[repository], raw)
+	}
+
+	for _, repository := range order {
+		records, err := json.Marshal(byRepository[repository])
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", fileName, err)
+		}
+
+		part := partOf(repository)
+		part.entries = append(part.entries, archive.Entry{Name: fileName, Content: records})
+	}
+
+	return nil
+}
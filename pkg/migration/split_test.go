@@ -0,0 +1,159 @@
+package migration
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kumojin/repo-backup-cli/pkg/archive"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// migrationArchiveFiles is a synthetic migration archive of two repositories, one of them with a wiki
+var migrationArchiveFiles = map[string]string{
+	"schema.json":           `{"version":"1.0.1"}`,
+	"users_000001.json":     `[{"url":"https://github.com/octocat","login":"octocat"}]`,
+	"attachments/1/log.txt": "attachment",
+	"repositories_000001.json": `[
+		{"url":"https://github.com/kumojin/repo1","name":"repo1"},
+		{"url":"https://github.com/kumojin/repo2","name":"repo2"}
+	]`,
+	"issues_000001.json": `[
+		{"url":"https://github.com/kumojin/repo1/issues/1","repository":"https://github.com/kumojin/repo1","title":"First"},
+		{"url":"https://github.com/kumojin/repo2/issues/1","repository":"https://github.com/kumojin/repo2","title":"Second"},
+		{"url":"https://github.com/kumojin/repo1/issues/2","repository":"https://github.com/kumojin/repo1","title":"Third"}
+	]`,
+	"milestones_000001.json":                         `[{"url":"https://github.com/kumojin/repo2/milestones/1","repository":"https://github.com/kumojin/repo2","title":"v1"}]`,
+	"repositories/kumojin/repo1.git/HEAD":            "ref: refs/heads/main",
+	"repositories/kumojin/repo1.git/objects/pack/p1": "pack",
+	"repositories/kumojin/repo1.wiki.git/HEAD":       "ref: refs/heads/master",
+	"repositories/kumojin/repo2.git/HEAD":            "ref: refs/heads/main",
+}
+
+// extractMigrationArchive writes the synthetic migration archive as a tarball and extracts it, like a downloaded
+// archive would be
+func extractMigrationArchive(t *testing.T) string {
+	t.Helper()
+
+	source := t.TempDir()
+	for name, content := range migrationArchiveFiles {
+		path := filepath.Join(source, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(source, "repositories", "kumojin", "repo1.git", "refs", "heads"), 0o755))
+
+	var tarball bytes.Buffer
+	require.NoError(t, archive.WriteTarGz(&tarball, source))
+
+	dir := t.TempDir()
+	require.NoError(t, archive.ExtractTarGz(&tarball, dir))
+
+	return dir
+}
+
+// extractPart writes the part as a tarball and extracts it
+func extractPart(t *testing.T, part Part) string {
+	t.Helper()
+
+	var tarball bytes.Buffer
+	require.NoError(t, part.WriteTarGz(&tarball))
+
+	dir := t.TempDir()
+	require.NoError(t, archive.ExtractTarGz(&tarball, dir))
+
+	return dir
+}
+
+func readTitles(t *testing.T, path string) []string {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var records []struct {
+		Title string `json:"title"`
+	}
+	require.NoError(t, json.Unmarshal(content, &records))
+
+	titles := make([]string, len(records))
+	for i, record := range records {
+		titles[i] = record.Title
+	}
+
+	return titles
+}
+
+func TestSplit(t *testing.T) {
+	// Given
+	dir := extractMigrationArchive(t)
+
+	// When
+	parts, err := Split(dir)
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, parts, 3)
+	assert.True(t, parts[0].IsShared())
+	assert.Equal(t, "repo1", parts[1].Repository)
+	assert.Equal(t, "repo2", parts[2].Repository)
+
+	shared := extractPart(t, parts[0])
+	assert.FileExists(t, filepath.Join(shared, "schema.json"))
+	assert.FileExists(t, filepath.Join(shared, "users_000001.json"))
+	assert.FileExists(t, filepath.Join(shared, "attachments", "1", "log.txt"))
+	assert.NoDirExists(t, filepath.Join(shared, "repositories"))
+	assert.NoFileExists(t, filepath.Join(shared, "issues_000001.json"))
+
+	repo1 := extractPart(t, parts[1])
+	assert.Equal(t, []string{"First", "Third"}, readTitles(t, filepath.Join(repo1, "issues_000001.json")))
+	assert.NoFileExists(t, filepath.Join(repo1, "milestones_000001.json"))
+	assert.FileExists(t, filepath.Join(repo1, "repositories", "kumojin", "repo1.git", "objects", "pack", "p1"))
+	assert.DirExists(t, filepath.Join(repo1, "repositories", "kumojin", "repo1.git", "refs", "heads"))
+	assert.FileExists(t, filepath.Join(repo1, "repositories", "kumojin", "repo1.wiki.git", "HEAD"))
+	assert.NoDirExists(t, filepath.Join(repo1, "repositories", "kumojin", "repo2.git"))
+	assert.NoFileExists(t, filepath.Join(repo1, "schema.json"))
+
+	repo2 := extractPart(t, parts[2])
+	assert.Equal(t, []string{"Second"}, readTitles(t, filepath.Join(repo2, "issues_000001.json")))
+	assert.Equal(t, []string{"v1"}, readTitles(t, filepath.Join(repo2, "milestones_000001.json")))
+}
+
+func TestSplit_PartsCanBeOpened(t *testing.T) {
+	// Given
+	parts, err := Split(extractMigrationArchive(t))
+	require.NoError(t, err)
+
+	// When
+	opened, err := Open(extractPart(t, parts[1]))
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, opened.Repositories, 1)
+	assert.Equal(t, "repo1", opened.Repositories[0].Name)
+	assert.Len(t, opened.Repositories[0].Issues, 2)
+	assert.NotEmpty(t, opened.Repositories[0].GitPath)
+}
+
+func TestSplit_MirrorBackup(t *testing.T) {
+	// Given
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "repositories", "kumojin"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "repositories", "kumojin", "repo1.bundle"), []byte("bundle"), 0o644))
+
+	// When
+	parts, err := Split(dir)
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, parts, 2)
+	assert.True(t, parts[0].IsShared())
+
+	repo1 := extractPart(t, parts[1])
+	content, err := os.ReadFile(filepath.Join(repo1, "repositories", "kumojin", "repo1.bundle"))
+	require.NoError(t, err)
+	assert.Equal(t, "bundle", string(content))
+}
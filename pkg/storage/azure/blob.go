@@ -7,6 +7,8 @@ import (
 	"net/url"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
 	"github.com/kumojin/repo-backup-cli/pkg/config"
 	"github.com/kumojin/repo-backup-cli/pkg/storage"
 )
@@ -29,6 +31,76 @@ func (r defaultBlobRepository) Upload(ctx context.Context, blobName string, in i
 	return r.getUrl(blobName)
 }
 
+func (r defaultBlobRepository) List(ctx context.Context, prefix string) ([]storage.BlobInfo, error) {
+	pager := r.client.NewListBlobsFlatPager(r.cfg.AzureStorageConfig.ContainerName, &azblob.ListBlobsFlatOptions{
+		Prefix:  &prefix,
+		Include: container.ListBlobsInclude{Metadata: true},
+	})
+
+	var blobs []storage.BlobInfo
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list blobs: %w", err)
+		}
+
+		for _, item := range page.Segment.BlobItems {
+			info := storage.BlobInfo{
+				Name:     deref(item.Name),
+				Metadata: toMetadata(item.Metadata),
+			}
+			if item.Properties != nil {
+				info.Size = deref(item.Properties.ContentLength)
+				info.LastModified = deref(item.Properties.LastModified)
+				info.ContentType = deref(item.Properties.ContentType)
+			}
+
+			blobs = append(blobs, info)
+		}
+	}
+
+	return blobs, nil
+}
+
+func (r defaultBlobRepository) Download(ctx context.Context, blobName string) (io.ReadCloser, error) {
+	response, err := r.client.DownloadStream(ctx, r.cfg.AzureStorageConfig.ContainerName, blobName, nil)
+	if err != nil {
+		return nil, wrapError(blobName, err)
+	}
+
+	return response.Body, nil
+}
+
+func (r defaultBlobRepository) Delete(ctx context.Context, blobName string) error {
+	_, err := r.client.DeleteBlob(ctx, r.cfg.AzureStorageConfig.ContainerName, blobName, nil)
+	if bloberror.HasCode(err, bloberror.BlobNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete blob %s: %w", blobName, err)
+	}
+
+	return nil
+}
+
+func (r defaultBlobRepository) Stat(ctx context.Context, blobName string) (storage.BlobInfo, error) {
+	properties, err := r.client.ServiceClient().
+		NewContainerClient(r.cfg.AzureStorageConfig.ContainerName).
+		NewBlobClient(blobName).
+		GetProperties(ctx, nil)
+	if err != nil {
+		return storage.BlobInfo{}, wrapError(blobName, err)
+	}
+
+	return storage.BlobInfo{
+		Name:         blobName,
+		Size:         deref(properties.ContentLength),
+		LastModified: deref(properties.LastModified),
+		ContentType:  deref(properties.ContentType),
+		Metadata:     toMetadata(properties.Metadata),
+	}, nil
+}
+
 func (r defaultBlobRepository) getUrl(blobName string) (string, error) {
 	url, err := url.JoinPath(r.cfg.AzureStorageConfig.AccountUrl, r.cfg.AzureStorageConfig.ContainerName, blobName)
 	if err != nil {
@@ -37,3 +109,34 @@ func (r defaultBlobRepository) getUrl(blobName string) (string, error) {
 
 	return url, nil
 }
+
+// wrapError maps the not found errors of Azure to storage.ErrBlobNotFound
+func wrapError(blobName string, err error) error {
+	if bloberror.HasCode(err, bloberror.BlobNotFound) {
+		return fmt.Errorf("%w: %s", storage.ErrBlobNotFound, blobName)
+	}
+
+	return fmt.Errorf("failed to access blob %s: %w", blobName, err)
+}
+
+func toMetadata(metadata map[string]*string) map[string]string {
+	if len(metadata) == 0 {
+		return nil
+	}
+
+	result := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		result[key] = deref(value)
+	}
+
+	return result
+}
+
+func deref[T any](value *T) T {
+	if value == nil {
+		var zero T
+		return zero
+	}
+
+	return *value
+}
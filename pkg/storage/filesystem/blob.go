@@ -0,0 +1,217 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kumojin/repo-backup-cli/pkg/storage"
+)
+
+const (
+	contentType = "application/octet-stream"
+	// tempFilePattern names the files being written, they are renamed to the blob once complete
+	tempFilePattern = ".*.tmp"
+)
+
+type defaultBlobRepository struct {
+	root string
+}
+
+// NewBlobRepository stores the blobs as files under root, the slashes of the blob names are directories
+func NewBlobRepository(root string) storage.BlobRepository {
+	return defaultBlobRepository{root: root}
+}
+
+// path returns the path of the blob, blob names leaving the root are rejected
+func (r defaultBlobRepository) path(blobName string) (string, error) {
+	name := filepath.FromSlash(blobName)
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("invalid blob name: %s", blobName)
+	}
+
+	return filepath.Join(r.root, name), nil
+}
+
+// Upload writes the blob to a temporary file renamed once complete, so that an interrupted upload never leaves a
+// partial blob behind
+func (r defaultBlobRepository) Upload(_ context.Context, blobName string, in io.Reader) (string, error) {
+	path, err := r.path(blobName)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create directory of blob %s: %w", blobName, err)
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), tempFilePattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create blob %s: %w", blobName, err)
+	}
+	defer func() { _ = os.Remove(temp.Name()) }()
+
+	if err := writeFile(temp, in); err != nil {
+		return "", fmt.Errorf("failed to write blob %s: %w", blobName, err)
+	}
+
+	// CreateTemp only gives access to the owner
+	if err := os.Chmod(temp.Name(), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write blob %s: %w", blobName, err)
+	}
+
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to write blob %s: %w", blobName, err)
+	}
+
+	location, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	return location, nil
+}
+
+// writeFile copies in to the file and flushes it to the disk before closing it
+func writeFile(file *os.File, in io.Reader) error {
+	if _, err := io.Copy(file, in); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+func (r defaultBlobRepository) List(_ context.Context, prefix string) ([]storage.BlobInfo, error) {
+	var blobs []storage.BlobInfo
+
+	err := filepath.WalkDir(r.root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// The root is only created by the first upload
+			if path == r.root && errors.Is(err, fs.ErrNotExist) {
+				return filepath.SkipAll
+			}
+			return err
+		}
+
+		// Hidden directories, such as the .git of a working directory, never hold blobs
+		if entry.IsDir() && path != r.root && strings.HasPrefix(entry.Name(), ".") {
+			return filepath.SkipDir
+		}
+
+		if entry.IsDir() || isTempFile(entry.Name()) {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(r.root, path)
+		if err != nil {
+			return err
+		}
+
+		blobName := filepath.ToSlash(relativePath)
+		if !strings.HasPrefix(blobName, prefix) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		blobs = append(blobs, toBlobInfo(blobName, info))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list blobs: %w", err)
+	}
+
+	sort.Slice(blobs, func(i, j int) bool {
+		return blobs[i].Name < blobs[j].Name
+	})
+
+	return blobs, nil
+}
+
+func (r defaultBlobRepository) Download(_ context.Context, blobName string) (io.ReadCloser, error) {
+	path, err := r.path(blobName)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, wrapError(blobName, err)
+	}
+
+	return file, nil
+}
+
+// Delete removes the blob and the directories it leaves empty
+func (r defaultBlobRepository) Delete(_ context.Context, blobName string) error {
+	path, err := r.path(blobName)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete blob %s: %w", blobName, err)
+	}
+
+	for dir := filepath.Dir(path); dir != filepath.Clean(r.root); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (r defaultBlobRepository) Stat(_ context.Context, blobName string) (storage.BlobInfo, error) {
+	path, err := r.path(blobName)
+	if err != nil {
+		return storage.BlobInfo{}, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return storage.BlobInfo{}, wrapError(blobName, err)
+	}
+	if info.IsDir() {
+		return storage.BlobInfo{}, fmt.Errorf("%w: %s", storage.ErrBlobNotFound, blobName)
+	}
+
+	return toBlobInfo(blobName, info), nil
+}
+
+func isTempFile(name string) bool {
+	return strings.HasPrefix(name, ".") && strings.HasSuffix(name, ".tmp")
+}
+
+// wrapError maps the missing files to storage.ErrBlobNotFound
+func wrapError(blobName string, err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %s", storage.ErrBlobNotFound, blobName)
+	}
+
+	return fmt.Errorf("failed to access blob %s: %w", blobName, err)
+}
+
+func toBlobInfo(blobName string, info fs.FileInfo) storage.BlobInfo {
+	return storage.BlobInfo{
+		Name:         blobName,
+		Size:         info.Size(),
+		LastModified: info.ModTime(),
+		ContentType:  contentType,
+	}
+}
@@ -0,0 +1,185 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kumojin/repo-backup-cli/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readBlob(t *testing.T, repository storage.BlobRepository, blobName string) string {
+	t.Helper()
+
+	reader, err := repository.Download(context.Background(), blobName)
+	require.NoError(t, err)
+	defer func() { _ = reader.Close() }()
+
+	content, err := io.ReadAll(reader)
+	require.NoError(t, err)
+
+	return string(content)
+}
+
+func TestBlobRepository_UploadAndDownload(t *testing.T) {
+	// Given
+	root := t.TempDir()
+	repository := NewBlobRepository(root)
+
+	// When
+	location, err := repository.Upload(context.Background(), "2025-07-23/kumojin/repo1.tar.gz", strings.NewReader("archive"))
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "2025-07-23", "kumojin", "repo1.tar.gz"), location)
+	assert.Equal(t, "archive", readBlob(t, repository, "2025-07-23/kumojin/repo1.tar.gz"))
+
+	info, err := os.Stat(location)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+}
+
+func TestBlobRepository_UploadReplacesBlob(t *testing.T) {
+	// Given
+	repository := NewBlobRepository(t.TempDir())
+	_, err := repository.Upload(context.Background(), "archive.tar.gz", strings.NewReader("first"))
+	require.NoError(t, err)
+
+	// When
+	_, err = repository.Upload(context.Background(), "archive.tar.gz", strings.NewReader("second"))
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, "second", readBlob(t, repository, "archive.tar.gz"))
+}
+
+// failingReader fails after returning its content, like a download dropping half way
+type failingReader struct {
+	content io.Reader
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	n, err := r.content.Read(p)
+	if errors.Is(err, io.EOF) {
+		return n, errors.New("connection reset")
+	}
+	return n, err
+}
+
+func TestBlobRepository_FailedUploadLeavesNoPartialBlob(t *testing.T) {
+	// Given
+	root := t.TempDir()
+	repository := NewBlobRepository(root)
+	_, err := repository.Upload(context.Background(), "archive.tar.gz", strings.NewReader("previous"))
+	require.NoError(t, err)
+
+	// When
+	_, err = repository.Upload(context.Background(), "archive.tar.gz", &failingReader{content: strings.NewReader("partial")})
+
+	// Then
+	assert.ErrorContains(t, err, "connection reset")
+	assert.Equal(t, "previous", readBlob(t, repository, "archive.tar.gz"))
+
+	entries, err := os.ReadDir(root)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "the temporary file is removed")
+}
+
+func TestBlobRepository_List(t *testing.T) {
+	// Given
+	root := t.TempDir()
+	repository := NewBlobRepository(root)
+	for _, blobName := range []string{
+		"2025-07-23-kumojin-migration.tar.gz",
+		"2025-07-22-kumojin-migration.tar.gz",
+		"2025-07-23-other-migration.tar.gz",
+		"2025-07-23/kumojin/repo1.tar.gz",
+	} {
+		_, err := repository.Upload(context.Background(), blobName, strings.NewReader(blobName))
+		require.NoError(t, err)
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".upload.tmp"), []byte("partial"), 0o644))
+
+	// When
+	blobs, err := repository.List(context.Background(), "2025-07-2")
+
+	// Then
+	require.NoError(t, err)
+	names := make([]string, len(blobs))
+	for i, blob := range blobs {
+		names[i] = blob.Name
+	}
+	assert.Equal(t, []string{
+		"2025-07-22-kumojin-migration.tar.gz",
+		"2025-07-23-kumojin-migration.tar.gz",
+		"2025-07-23-other-migration.tar.gz",
+		"2025-07-23/kumojin/repo1.tar.gz",
+	}, names)
+	assert.Equal(t, int64(len("2025-07-22-kumojin-migration.tar.gz")), blobs[0].Size)
+	assert.Equal(t, "application/octet-stream", blobs[0].ContentType)
+	assert.False(t, blobs[0].LastModified.IsZero())
+}
+
+func TestBlobRepository_ListMissingRoot(t *testing.T) {
+	repository := NewBlobRepository(filepath.Join(t.TempDir(), "missing"))
+
+	blobs, err := repository.List(context.Background(), "")
+
+	assert.NoError(t, err)
+	assert.Empty(t, blobs)
+}
+
+func TestBlobRepository_Stat(t *testing.T) {
+	// Given
+	repository := NewBlobRepository(t.TempDir())
+	_, err := repository.Upload(context.Background(), "2025-07-23/kumojin/repo1.tar.gz", strings.NewReader("archive"))
+	require.NoError(t, err)
+
+	// When
+	info, statErr := repository.Stat(context.Background(), "2025-07-23/kumojin/repo1.tar.gz")
+	_, notFoundErr := repository.Stat(context.Background(), "missing.tar.gz")
+	_, dirErr := repository.Stat(context.Background(), "2025-07-23")
+
+	// Then
+	require.NoError(t, statErr)
+	assert.Equal(t, "2025-07-23/kumojin/repo1.tar.gz", info.Name)
+	assert.Equal(t, int64(7), info.Size)
+	assert.ErrorIs(t, notFoundErr, storage.ErrBlobNotFound)
+	assert.ErrorIs(t, dirErr, storage.ErrBlobNotFound)
+}
+
+func TestBlobRepository_Delete(t *testing.T) {
+	// Given
+	root := t.TempDir()
+	repository := NewBlobRepository(root)
+	_, err := repository.Upload(context.Background(), "2025-07-23/kumojin/repo1.tar.gz", strings.NewReader("archive"))
+	require.NoError(t, err)
+
+	// When
+	deleteErr := repository.Delete(context.Background(), "2025-07-23/kumojin/repo1.tar.gz")
+	missingErr := repository.Delete(context.Background(), "2025-07-23/kumojin/repo1.tar.gz")
+	_, downloadErr := repository.Download(context.Background(), "2025-07-23/kumojin/repo1.tar.gz")
+
+	// Then
+	assert.NoError(t, deleteErr)
+	assert.NoError(t, missingErr)
+	assert.ErrorIs(t, downloadErr, storage.ErrBlobNotFound)
+
+	entries, err := os.ReadDir(root)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "the empty directories are removed")
+}
+
+func TestBlobRepository_RejectsNamesOutsideRoot(t *testing.T) {
+	repository := NewBlobRepository(t.TempDir())
+
+	_, err := repository.Upload(context.Background(), "../escape.tar.gz", strings.NewReader("archive"))
+
+	assert.EqualError(t, err, "invalid blob name: ../escape.tar.gz")
+}
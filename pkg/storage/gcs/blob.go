@@ -0,0 +1,130 @@
+package gcs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	cloudstorage "cloud.google.com/go/storage"
+	"github.com/kumojin/repo-backup-cli/pkg/config"
+	"github.com/kumojin/repo-backup-cli/pkg/storage"
+	"google.golang.org/api/iterator"
+)
+
+const (
+	contentType = "application/octet-stream"
+	// defaultChunkSize is the size of the chunks of the resumable uploads, a failed chunk is retried without
+	// uploading the archive again
+	defaultChunkSize = 16 * 1024 * 1024
+)
+
+type defaultBlobRepository struct {
+	cfg       *config.Config
+	client    *cloudstorage.Client
+	chunkSize int
+}
+
+func NewBlobRepository(cfg *config.Config, client *cloudstorage.Client) storage.BlobRepository {
+	return defaultBlobRepository{cfg: cfg, client: client, chunkSize: defaultChunkSize}
+}
+
+func (r defaultBlobRepository) bucket() *cloudstorage.BucketHandle {
+	return r.client.Bucket(r.cfg.GCSStorageConfig.BucketName)
+}
+
+// Upload streams the blob with a resumable upload, the object is only created once the upload completes
+func (r defaultBlobRepository) Upload(ctx context.Context, blobName string, in io.Reader) (string, error) {
+	// Cancelling the context is the only way to abort the upload without creating the object
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	writer := r.bucket().Object(blobName).NewWriter(ctx)
+	writer.ContentType = contentType
+	writer.ChunkSize = r.chunkSize
+
+	if _, err := io.Copy(writer, in); err != nil {
+		cancel()
+		_ = writer.Close()
+		return "", fmt.Errorf("failed to upload object to gcs: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("failed to upload object to gcs: %w", err)
+	}
+
+	return fmt.Sprintf("gs://%s/%s", r.cfg.GCSStorageConfig.BucketName, blobName), nil
+}
+
+func (r defaultBlobRepository) List(ctx context.Context, prefix string) ([]storage.BlobInfo, error) {
+	var blobs []storage.BlobInfo
+
+	objects := r.bucket().Objects(ctx, &cloudstorage.Query{Prefix: prefix})
+	for {
+		attrs, err := objects.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects from gcs: %w", err)
+		}
+
+		blobs = append(blobs, toBlobInfo(attrs))
+	}
+
+	return blobs, nil
+}
+
+func (r defaultBlobRepository) Download(ctx context.Context, blobName string) (io.ReadCloser, error) {
+	reader, err := r.bucket().Object(blobName).NewReader(ctx)
+	if err != nil {
+		return nil, wrapError(blobName, err)
+	}
+
+	return reader, nil
+}
+
+func (r defaultBlobRepository) Delete(ctx context.Context, blobName string) error {
+	err := r.bucket().Object(blobName).Delete(ctx)
+	if err != nil && !errors.Is(err, cloudstorage.ErrObjectNotExist) {
+		return fmt.Errorf("failed to delete object %s from gcs: %w", blobName, err)
+	}
+
+	return nil
+}
+
+func (r defaultBlobRepository) Stat(ctx context.Context, blobName string) (storage.BlobInfo, error) {
+	attrs, err := r.bucket().Object(blobName).Attrs(ctx)
+	if err != nil {
+		return storage.BlobInfo{}, wrapError(blobName, err)
+	}
+
+	return toBlobInfo(attrs), nil
+}
+
+// wrapError maps the not found errors of GCS to storage.ErrBlobNotFound
+func wrapError(blobName string, err error) error {
+	if errors.Is(err, cloudstorage.ErrObjectNotExist) {
+		return fmt.Errorf("%w: %s", storage.ErrBlobNotFound, blobName)
+	}
+
+	return fmt.Errorf("failed to access object %s in gcs: %w", blobName, err)
+}
+
+func toBlobInfo(attrs *cloudstorage.ObjectAttrs) storage.BlobInfo {
+	var metadata map[string]string
+	if len(attrs.Metadata) > 0 {
+		metadata = make(map[string]string, len(attrs.Metadata))
+		for key, value := range attrs.Metadata {
+			metadata[key] = value
+		}
+	}
+
+	return storage.BlobInfo{
+		Name:         attrs.Name,
+		Size:         attrs.Size,
+		LastModified: attrs.Updated,
+		ContentType:  attrs.ContentType,
+		Metadata:     metadata,
+	}
+}
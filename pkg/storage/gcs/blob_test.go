@@ -0,0 +1,327 @@
+package gcs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	cloudstorage "cloud.google.com/go/storage"
+	"github.com/kumojin/repo-backup-cli/pkg/config"
+	"github.com/kumojin/repo-backup-cli/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+)
+
+const testBucket = "backups"
+
+type fakeObject struct {
+	Name        string            `json:"name"`
+	Bucket      string            `json:"bucket"`
+	Size        string            `json:"size"`
+	ContentType string            `json:"contentType"`
+	Updated     string            `json:"updated"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	data        []byte
+}
+
+// fakeGCS is an in-memory stand-in for the subset of the GCS JSON and XML APIs used by the blob repository
+type fakeGCS struct {
+	mu      sync.Mutex
+	objects map[string]*fakeObject
+	// uploads holds the resumable uploads in progress, by upload ID
+	uploads map[string]*fakeObject
+	// chunks counts the chunks received by the resumable uploads
+	chunks int
+}
+
+func (f *fakeGCS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := r.URL.EscapedPath()
+	switch {
+	case r.Method == http.MethodPost && strings.HasPrefix(path, "/upload/storage/v1/b/"+testBucket+"/o"):
+		f.startUpload(w, r)
+	case strings.HasPrefix(path, "/upload/resumable/"):
+		f.uploadChunk(w, r, strings.TrimPrefix(path, "/upload/resumable/"))
+	case r.Method == http.MethodGet && path == "/storage/v1/b/"+testBucket+"/o":
+		f.list(w, r.URL.Query().Get("prefix"))
+	case strings.HasPrefix(path, "/storage/v1/b/"+testBucket+"/o/"):
+		name, _ := url.PathUnescape(strings.TrimPrefix(path, "/storage/v1/b/"+testBucket+"/o/"))
+		object, ok := f.objects[name]
+		switch {
+		case !ok:
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": map[string]any{"code": 404, "message": "No such object"}})
+		case r.Method == http.MethodDelete:
+			delete(f.objects, name)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			writeJSON(w, http.StatusOK, object)
+		}
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/"+testBucket+"/"):
+		// Downloads go through the XML API
+		name, _ := url.PathUnescape(strings.TrimPrefix(path, "/"+testBucket+"/"))
+		object, ok := f.objects[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", object.ContentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(object.data)))
+		_, _ = w.Write(object.data)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+// startUpload creates the object at once for the multipart uploads and returns the session URL of the resumable ones
+func (f *fakeGCS) startUpload(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Query().Get("uploadType") {
+	case "resumable":
+		object := &fakeObject{}
+		_ = json.NewDecoder(r.Body).Decode(object)
+		uploadID := strconv.Itoa(len(f.uploads) + 1)
+		f.uploads[uploadID] = object
+		w.Header().Set("Location", "http://"+r.Host+"/upload/resumable/"+uploadID)
+		w.WriteHeader(http.StatusOK)
+	case "multipart":
+		_, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		reader := multipart.NewReader(r.Body, params["boundary"])
+
+		object := &fakeObject{}
+		part, _ := reader.NextPart()
+		_ = json.NewDecoder(part).Decode(object)
+		part, _ = reader.NextPart()
+		object.data, _ = io.ReadAll(part)
+
+		writeJSON(w, http.StatusOK, f.create(object))
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func (f *fakeGCS) uploadChunk(w http.ResponseWriter, r *http.Request, uploadID string) {
+	upload, ok := f.uploads[uploadID]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	data, _ := io.ReadAll(r.Body)
+	upload.data = append(upload.data, data...)
+	f.chunks++
+
+	// The total size is only known with the last chunk, e.g. "bytes 0-262143/*" then "bytes 262144-300000/300001"
+	if strings.HasSuffix(r.Header.Get("Content-Range"), "/*") {
+		w.Header().Set("Range", fmt.Sprintf("bytes=0-%d", len(upload.data)-1))
+		// Like GCS, the client asks for a 200 to keep its HTTP library from following the 308
+		if r.Header.Get("X-GUploader-No-308") == "yes" {
+			w.Header().Set("X-HTTP-Status-Code-Override", "308")
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusPermanentRedirect)
+		return
+	}
+
+	delete(f.uploads, uploadID)
+	writeJSON(w, http.StatusOK, f.create(upload))
+}
+
+func (f *fakeGCS) create(object *fakeObject) *fakeObject {
+	object.Bucket = testBucket
+	object.Size = strconv.Itoa(len(object.data))
+	object.Updated = time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC).Format(time.RFC3339)
+	f.objects[object.Name] = object
+
+	return object
+}
+
+func (f *fakeGCS) list(w http.ResponseWriter, prefix string) {
+	var names []string
+	for name := range f.objects {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	items := make([]*fakeObject, 0, len(names))
+	for _, name := range names {
+		items = append(items, f.objects[name])
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"kind": "storage#objects", "items": items})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func newTestBlobRepository(t *testing.T) (defaultBlobRepository, *fakeGCS) {
+	t.Helper()
+
+	fake := &fakeGCS{objects: map[string]*fakeObject{}, uploads: map[string]*fakeObject{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client, err := cloudstorage.NewClient(context.Background(),
+		option.WithEndpoint(server.URL+"/storage/v1/"),
+		option.WithoutAuthentication(),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	cfg := &config.Config{GCSStorageConfig: config.GCSStorageConfig{BucketName: testBucket}}
+
+	return NewBlobRepository(cfg, client).(defaultBlobRepository), fake
+}
+
+func TestBlobRepository_UploadDownloadStat(t *testing.T) {
+	// Given
+	repository, fake := newTestBlobRepository(t)
+	ctx := context.Background()
+
+	// When
+	location, err := repository.Upload(ctx, "2024-05-06-kumojin-migration.tar.gz", strings.NewReader("archive"))
+	require.NoError(t, err)
+	fake.objects["2024-05-06-kumojin-migration.tar.gz"].Metadata = map[string]string{"organization": "kumojin"}
+
+	reader, err := repository.Download(ctx, "2024-05-06-kumojin-migration.tar.gz")
+	require.NoError(t, err)
+	content, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+
+	info, err := repository.Stat(ctx, "2024-05-06-kumojin-migration.tar.gz")
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, "gs://backups/2024-05-06-kumojin-migration.tar.gz", location)
+	assert.Equal(t, "archive", string(content))
+	assert.Equal(t, storage.BlobInfo{
+		Name:         "2024-05-06-kumojin-migration.tar.gz",
+		Size:         7,
+		LastModified: time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC),
+		ContentType:  "application/octet-stream",
+		Metadata:     map[string]string{"organization": "kumojin"},
+	}, info)
+}
+
+func TestBlobRepository_ResumableUpload(t *testing.T) {
+	// Given
+	repository, fake := newTestBlobRepository(t)
+	repository.chunkSize = 256 * 1024
+	archive := strings.Repeat("a", 600*1024)
+
+	// When
+	_, err := repository.Upload(context.Background(), "2024-05-06/kumojin/repo1.tar.gz", strings.NewReader(archive))
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, 3, fake.chunks)
+	require.Contains(t, fake.objects, "2024-05-06/kumojin/repo1.tar.gz")
+	assert.Equal(t, archive, string(fake.objects["2024-05-06/kumojin/repo1.tar.gz"].data))
+	assert.Equal(t, "application/octet-stream", fake.objects["2024-05-06/kumojin/repo1.tar.gz"].ContentType)
+}
+
+// failingReader fails after returning its content, like a download dropping half way
+type failingReader struct {
+	content io.Reader
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	n, err := r.content.Read(p)
+	if errors.Is(err, io.EOF) {
+		return n, errors.New("connection reset")
+	}
+	return n, err
+}
+
+func TestBlobRepository_FailedUploadCreatesNoObject(t *testing.T) {
+	// Given
+	repository, fake := newTestBlobRepository(t)
+	repository.chunkSize = 256 * 1024
+
+	// When
+	_, err := repository.Upload(context.Background(), "backup.tar.gz", &failingReader{content: strings.NewReader(strings.Repeat("a", 300*1024))})
+
+	// Then
+	assert.ErrorContains(t, err, "connection reset")
+	assert.Empty(t, fake.objects)
+}
+
+func TestBlobRepository_List(t *testing.T) {
+	// Given
+	repository, _ := newTestBlobRepository(t)
+	ctx := context.Background()
+
+	for _, name := range []string{"2024-05-07-kumojin-migration.tar.gz", "2024-05-06-kumojin-migration.tar.gz", "2024-05-06-other-migration.tar.gz", "other.tar.gz"} {
+		_, err := repository.Upload(ctx, name, strings.NewReader(name))
+		require.NoError(t, err)
+	}
+
+	// When
+	blobs, err := repository.List(ctx, "2024-05-0")
+
+	// Then
+	require.NoError(t, err)
+	var names []string
+	for _, blob := range blobs {
+		names = append(names, blob.Name)
+		assert.Equal(t, int64(len(blob.Name)), blob.Size)
+	}
+	assert.Equal(t, []string{
+		"2024-05-06-kumojin-migration.tar.gz",
+		"2024-05-06-other-migration.tar.gz",
+		"2024-05-07-kumojin-migration.tar.gz",
+	}, names)
+}
+
+func TestBlobRepository_NotFound(t *testing.T) {
+	// Given
+	repository, _ := newTestBlobRepository(t)
+	ctx := context.Background()
+
+	// When
+	_, downloadErr := repository.Download(ctx, "missing.tar.gz")
+	_, statErr := repository.Stat(ctx, "missing.tar.gz")
+	deleteErr := repository.Delete(ctx, "missing.tar.gz")
+
+	// Then
+	assert.ErrorIs(t, downloadErr, storage.ErrBlobNotFound)
+	assert.ErrorIs(t, statErr, storage.ErrBlobNotFound)
+	assert.NoError(t, deleteErr)
+}
+
+func TestBlobRepository_Delete(t *testing.T) {
+	// Given
+	repository, fake := newTestBlobRepository(t)
+	ctx := context.Background()
+
+	_, err := repository.Upload(ctx, "2024-05-06/kumojin/repo1.tar.gz", strings.NewReader("archive"))
+	require.NoError(t, err)
+
+	// When
+	err = repository.Delete(ctx, "2024-05-06/kumojin/repo1.tar.gz")
+
+	// Then
+	assert.NoError(t, err)
+	assert.Empty(t, fake.objects)
+}
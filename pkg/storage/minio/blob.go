@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/kumojin/repo-backup-cli/pkg/config"
 	"github.com/kumojin/repo-backup-cli/pkg/storage"
 	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
 )
 
 type defaultBlobRepository struct {
@@ -20,12 +22,119 @@ func NewBlobRepository(cfg *config.Config, client *minio.Client) storage.BlobRep
 }
 
 func (r defaultBlobRepository) Upload(ctx context.Context, blobName string, in io.Reader) (string, error) {
-	info, err := r.client.PutObject(ctx, r.cfg.ObjectStorageConfig.BucketName, blobName, in, -1, minio.PutObjectOptions{
-		ContentType: "application/octet-stream",
-	})
+	options, err := putObjectOptions(r.cfg.ObjectStorageConfig, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	info, err := r.client.PutObject(ctx, r.cfg.ObjectStorageConfig.BucketName, blobName, in, -1, options)
 	if err != nil {
 		return "", fmt.Errorf("failed to upload object to object storage: %w", err)
 	}
 
 	return info.Location, nil
 }
+
+// putObjectOptions applies the encryption, the storage class and the object lock retention of the configuration,
+// the retention starts at now
+func putObjectOptions(objectConfig config.ObjectStorageConfig, now time.Time) (minio.PutObjectOptions, error) {
+	options := minio.PutObjectOptions{
+		ContentType:  "application/octet-stream",
+		StorageClass: objectConfig.StorageClass,
+	}
+
+	switch objectConfig.ServerSideEncryption {
+	case config.ObjectStorageSSES3:
+		options.ServerSideEncryption = encrypt.NewSSE()
+	case config.ObjectStorageSSEKMS:
+		sse, err := encrypt.NewSSEKMS(objectConfig.SSEKMSKeyID, nil)
+		if err != nil {
+			return minio.PutObjectOptions{}, fmt.Errorf("failed to configure server-side encryption: %w", err)
+		}
+		options.ServerSideEncryption = sse
+	}
+
+	if objectConfig.ObjectLockMode != "" {
+		options.Mode = minio.RetentionMode(objectConfig.ObjectLockMode)
+		options.RetainUntilDate = now.AddDate(0, 0, objectConfig.ObjectLockRetentionDays).UTC()
+	}
+
+	return options, nil
+}
+
+func (r defaultBlobRepository) List(ctx context.Context, prefix string) ([]storage.BlobInfo, error) {
+	var blobs []storage.BlobInfo
+	for object := range r.client.ListObjects(ctx, r.cfg.ObjectStorageConfig.BucketName, minio.ListObjectsOptions{
+		Prefix:       prefix,
+		Recursive:    true,
+		WithMetadata: true,
+	}) {
+		if object.Err != nil {
+			return nil, fmt.Errorf("failed to list objects from object storage: %w", object.Err)
+		}
+
+		blobs = append(blobs, toBlobInfo(object))
+	}
+
+	return blobs, nil
+}
+
+func (r defaultBlobRepository) Download(ctx context.Context, blobName string) (io.ReadCloser, error) {
+	object, err := r.client.GetObject(ctx, r.cfg.ObjectStorageConfig.BucketName, blobName, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, wrapError(blobName, err)
+	}
+
+	// GetObject is lazy, the object is only requested on first access
+	if _, err := object.Stat(); err != nil {
+		_ = object.Close()
+		return nil, wrapError(blobName, err)
+	}
+
+	return object, nil
+}
+
+func (r defaultBlobRepository) Delete(ctx context.Context, blobName string) error {
+	err := r.client.RemoveObject(ctx, r.cfg.ObjectStorageConfig.BucketName, blobName, minio.RemoveObjectOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to delete object %s from object storage: %w", blobName, err)
+	}
+
+	return nil
+}
+
+func (r defaultBlobRepository) Stat(ctx context.Context, blobName string) (storage.BlobInfo, error) {
+	object, err := r.client.StatObject(ctx, r.cfg.ObjectStorageConfig.BucketName, blobName, minio.StatObjectOptions{})
+	if err != nil {
+		return storage.BlobInfo{}, wrapError(blobName, err)
+	}
+
+	return toBlobInfo(object), nil
+}
+
+// wrapError maps the not found errors of object storage to storage.ErrBlobNotFound
+func wrapError(blobName string, err error) error {
+	if minio.ToErrorResponse(err).Code == minio.NoSuchKey {
+		return fmt.Errorf("%w: %s", storage.ErrBlobNotFound, blobName)
+	}
+
+	return fmt.Errorf("failed to access object %s in object storage: %w", blobName, err)
+}
+
+func toBlobInfo(object minio.ObjectInfo) storage.BlobInfo {
+	var metadata map[string]string
+	if len(object.UserMetadata) > 0 {
+		metadata = make(map[string]string, len(object.UserMetadata))
+		for key, value := range object.UserMetadata {
+			metadata[key] = value
+		}
+	}
+
+	return storage.BlobInfo{
+		Name:         object.Key,
+		Size:         object.Size,
+		LastModified: object.LastModified,
+		ContentType:  object.ContentType,
+		Metadata:     metadata,
+	}
+}
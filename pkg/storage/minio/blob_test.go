@@ -0,0 +1,362 @@
+package minio
+
+import (
+	"bufio"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kumojin/repo-backup-cli/pkg/config"
+	"github.com/kumojin/repo-backup-cli/pkg/storage"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testBucket = "backups"
+
+type fakeObject struct {
+	data         []byte
+	contentType  string
+	metadata     map[string]string
+	lastModified time.Time
+	// header is the header of the request creating the object
+	header http.Header
+}
+
+// fakeS3 is an in-memory stand-in for the subset of the S3 API used by the blob repository
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]fakeObject
+	// uploads holds the multipart uploads in progress, by upload ID
+	uploads map[string]*fakeObject
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/"+testBucket), "/")
+
+	switch {
+	case key == "" && r.URL.Query().Has("location"):
+		writeXML(w, http.StatusOK, struct {
+			XMLName xml.Name `xml:"LocationConstraint"`
+		}{})
+	case key == "" && r.Method == http.MethodGet:
+		f.list(w, r.URL.Query().Get("prefix"))
+	case r.Method == http.MethodPost && r.URL.Query().Has("uploads"):
+		uploadID := strconv.Itoa(len(f.uploads) + 1)
+		f.uploads[uploadID] = newFakeObject(r.Header, nil)
+		writeXML(w, http.StatusOK, struct {
+			XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+			Bucket   string
+			Key      string
+			UploadId string
+		}{Bucket: testBucket, Key: key, UploadId: uploadID})
+	case r.Method == http.MethodPut && r.URL.Query().Has("uploadId"):
+		data := readBody(r)
+		upload := f.uploads[r.URL.Query().Get("uploadId")]
+		upload.data = append(upload.data, data...)
+		w.Header().Set("ETag", `"etag"`)
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodPost && r.URL.Query().Has("uploadId"):
+		f.objects[key] = *f.uploads[r.URL.Query().Get("uploadId")]
+		writeXML(w, http.StatusOK, struct {
+			XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+			Bucket  string
+			Key     string
+			ETag    string
+		}{Bucket: testBucket, Key: key, ETag: `"etag"`})
+	case r.Method == http.MethodPut:
+		data := readBody(r)
+		f.objects[key] = *newFakeObject(r.Header, data)
+		w.Header().Set("ETag", `"etag"`)
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodHead || r.Method == http.MethodGet:
+		object, ok := f.objects[key]
+		if !ok {
+			writeXML(w, http.StatusNotFound, minio.ErrorResponse{Code: minio.NoSuchKey, Message: "The specified key does not exist.", Key: key})
+			return
+		}
+
+		w.Header().Set("Content-Type", object.contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(object.data)))
+		w.Header().Set("Last-Modified", object.lastModified.Format(http.TimeFormat))
+		w.Header().Set("ETag", `"etag"`)
+		for name, value := range object.metadata {
+			w.Header().Set("X-Amz-Meta-"+name, value)
+		}
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(object.data)
+		}
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+// readBody returns the payload of an upload, decoding the aws-chunked encoding used to sign streams over plain HTTP
+func readBody(r *http.Request) []byte {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		data, _ := io.ReadAll(r.Body)
+		return data
+	}
+
+	var data []byte
+	reader := bufio.NewReader(r.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return data
+		}
+
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil || size == 0 {
+			return data
+		}
+
+		chunk := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, chunk); err != nil {
+			return data
+		}
+		data = append(data, chunk[:size]...)
+	}
+}
+
+func newFakeObject(header http.Header, data []byte) *fakeObject {
+	metadata := map[string]string{}
+	for name, values := range header {
+		if strings.HasPrefix(name, "X-Amz-Meta-") {
+			metadata[strings.TrimPrefix(name, "X-Amz-Meta-")] = values[0]
+		}
+	}
+
+	return &fakeObject{
+		data:         data,
+		contentType:  header.Get("Content-Type"),
+		metadata:     metadata,
+		lastModified: time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC),
+		header:       header.Clone(),
+	}
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, prefix string) {
+	type content struct {
+		Key          string
+		LastModified string
+		Size         int
+		ETag         string
+	}
+
+	var keys []string
+	for key := range f.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var contents []content
+	for _, key := range keys {
+		contents = append(contents, content{
+			Key:          key,
+			LastModified: f.objects[key].lastModified.Format(time.RFC3339),
+			Size:         len(f.objects[key].data),
+			ETag:         `"etag"`,
+		})
+	}
+
+	writeXML(w, http.StatusOK, struct {
+		XMLName     xml.Name `xml:"ListBucketResult"`
+		Name        string
+		Prefix      string
+		KeyCount    int
+		IsTruncated bool
+		Contents    []content
+	}{Name: testBucket, Prefix: prefix, KeyCount: len(contents), Contents: contents})
+}
+
+func writeXML(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_ = xml.NewEncoder(w).Encode(body)
+}
+
+func newTestBlobRepository(t *testing.T) (storage.BlobRepository, *fakeS3) {
+	t.Helper()
+
+	return newTestBlobRepositoryWithConfig(t, config.ObjectStorageConfig{})
+}
+
+func newTestBlobRepositoryWithConfig(t *testing.T, objectConfig config.ObjectStorageConfig) (storage.BlobRepository, *fakeS3) {
+	t.Helper()
+
+	fake := &fakeS3{objects: map[string]fakeObject{}, uploads: map[string]*fakeObject{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client, err := minio.New(strings.TrimPrefix(server.URL, "http://"), &minio.Options{
+		Creds:  credentials.NewStaticV4("access", "secret", ""),
+		Secure: false,
+	})
+	require.NoError(t, err)
+
+	objectConfig.BucketName = testBucket
+	cfg := &config.Config{ObjectStorageConfig: objectConfig}
+
+	return NewBlobRepository(cfg, client), fake
+}
+
+func TestBlobRepository_UploadDownloadStat(t *testing.T) {
+	// Given
+	repository, fake := newTestBlobRepository(t)
+	ctx := context.Background()
+
+	// When
+	_, err := repository.Upload(ctx, "2024-05-06-kumojin-migration.tar.gz", strings.NewReader("archive"))
+	require.NoError(t, err)
+	fake.objects["2024-05-06-kumojin-migration.tar.gz"].metadata["Organization"] = "kumojin"
+
+	reader, err := repository.Download(ctx, "2024-05-06-kumojin-migration.tar.gz")
+	require.NoError(t, err)
+	content, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+
+	info, err := repository.Stat(ctx, "2024-05-06-kumojin-migration.tar.gz")
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, "archive", string(content))
+	assert.Equal(t, storage.BlobInfo{
+		Name:         "2024-05-06-kumojin-migration.tar.gz",
+		Size:         7,
+		LastModified: time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC),
+		ContentType:  "application/octet-stream",
+		Metadata:     map[string]string{"Organization": "kumojin"},
+	}, info)
+}
+
+func TestBlobRepository_List(t *testing.T) {
+	// Given
+	repository, _ := newTestBlobRepository(t)
+	ctx := context.Background()
+
+	for _, name := range []string{"2024-05-07-kumojin-migration.tar.gz", "2024-05-06-kumojin-migration.tar.gz", "2024-05-06-other-migration.tar.gz"} {
+		_, err := repository.Upload(ctx, name, strings.NewReader(name))
+		require.NoError(t, err)
+	}
+
+	// When
+	blobs, err := repository.List(ctx, "2024-05-0")
+
+	// Then
+	require.NoError(t, err)
+	var names []string
+	for _, blob := range blobs {
+		names = append(names, blob.Name)
+		assert.Equal(t, int64(len(blob.Name)), blob.Size)
+	}
+	assert.Equal(t, []string{
+		"2024-05-06-kumojin-migration.tar.gz",
+		"2024-05-06-other-migration.tar.gz",
+		"2024-05-07-kumojin-migration.tar.gz",
+	}, names)
+}
+
+func TestBlobRepository_NotFound(t *testing.T) {
+	// Given
+	repository, _ := newTestBlobRepository(t)
+	ctx := context.Background()
+
+	// When
+	_, downloadErr := repository.Download(ctx, "missing.tar.gz")
+	_, statErr := repository.Stat(ctx, "missing.tar.gz")
+	deleteErr := repository.Delete(ctx, "missing.tar.gz")
+
+	// Then
+	assert.ErrorIs(t, downloadErr, storage.ErrBlobNotFound)
+	assert.ErrorIs(t, statErr, storage.ErrBlobNotFound)
+	assert.NoError(t, deleteErr)
+}
+
+func TestBlobRepository_Delete(t *testing.T) {
+	// Given
+	repository, fake := newTestBlobRepository(t)
+	ctx := context.Background()
+
+	_, err := repository.Upload(ctx, "backup.tar.gz", strings.NewReader("archive"))
+	require.NoError(t, err)
+
+	// When
+	err = repository.Delete(ctx, "backup.tar.gz")
+
+	// Then
+	assert.NoError(t, err)
+	assert.Empty(t, fake.objects)
+}
+
+func TestBlobRepository_UploadOptions(t *testing.T) {
+	// Given
+	repository, fake := newTestBlobRepositoryWithConfig(t, config.ObjectStorageConfig{
+		ServerSideEncryption:    config.ObjectStorageSSEKMS,
+		SSEKMSKeyID:             "arn:aws:kms:ca-central-1:123456789012:key/backups",
+		StorageClass:            "GLACIER_IR",
+		ObjectLockMode:          config.ObjectLockCompliance,
+		ObjectLockRetentionDays: 30,
+	})
+	before := time.Now().AddDate(0, 0, 30).Truncate(time.Second)
+
+	// When
+	_, err := repository.Upload(context.Background(), "backup.tar.gz", strings.NewReader("archive"))
+
+	// Then
+	require.NoError(t, err)
+	header := fake.objects["backup.tar.gz"].header
+	assert.Equal(t, "aws:kms", header.Get("X-Amz-Server-Side-Encryption"))
+	assert.Equal(t, "arn:aws:kms:ca-central-1:123456789012:key/backups", header.Get("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id"))
+	assert.Equal(t, "GLACIER_IR", header.Get("X-Amz-Storage-Class"))
+	assert.Equal(t, "COMPLIANCE", header.Get("X-Amz-Object-Lock-Mode"))
+
+	retainUntil, err := time.Parse(time.RFC3339, header.Get("X-Amz-Object-Lock-Retain-Until-Date"))
+	require.NoError(t, err)
+	assert.WithinRange(t, retainUntil, before, time.Now().AddDate(0, 0, 30))
+}
+
+func TestBlobRepository_UploadWithoutOptions(t *testing.T) {
+	// Given
+	repository, fake := newTestBlobRepository(t)
+
+	// When
+	_, err := repository.Upload(context.Background(), "backup.tar.gz", strings.NewReader("archive"))
+
+	// Then
+	require.NoError(t, err)
+	header := fake.objects["backup.tar.gz"].header
+	assert.Empty(t, header.Get("X-Amz-Server-Side-Encryption"))
+	assert.Empty(t, header.Get("X-Amz-Storage-Class"))
+	assert.Empty(t, header.Get("X-Amz-Object-Lock-Mode"))
+}
+
+func TestPutObjectOptions_SSES3(t *testing.T) {
+	options, err := putObjectOptions(config.ObjectStorageConfig{ServerSideEncryption: config.ObjectStorageSSES3}, time.Now())
+
+	require.NoError(t, err)
+	require.NotNil(t, options.ServerSideEncryption)
+	assert.Equal(t, encrypt.S3, options.ServerSideEncryption.Type())
+}
@@ -0,0 +1,30 @@
+package minio
+
+import (
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// NewCredentials returns the first credentials found among the static keys, the AWS and MinIO environment
+// variables, the AWS credentials file and the IAM role of the EC2 instance, the ECS task or the EKS pod
+// (IRSA and pod identity). The temporary credentials of the IAM role are refreshed before they expire.
+func NewCredentials(accessKey, secretKey string) *credentials.Credentials {
+	var providers []credentials.Provider
+	if accessKey != "" && secretKey != "" {
+		providers = append(providers, &credentials.Static{
+			Value: credentials.Value{
+				AccessKeyID:     accessKey,
+				SecretAccessKey: secretKey,
+				SignerType:      credentials.SignatureV4,
+			},
+		})
+	}
+
+	providers = append(providers,
+		&credentials.EnvAWS{},
+		&credentials.EnvMinio{},
+		&credentials.FileAWSCredentials{},
+		&credentials.IAM{},
+	)
+
+	return credentials.NewChainCredentials(providers)
+}
@@ -0,0 +1,110 @@
+package minio
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clearCredentialsEnv hides the credentials of the machine running the tests from the chain
+func clearCredentialsEnv(t *testing.T) {
+	t.Helper()
+
+	for _, name := range []string{
+		"AWS_ACCESS_KEY_ID", "AWS_ACCESS_KEY", "AWS_SECRET_ACCESS_KEY", "AWS_SECRET_KEY", "AWS_SESSION_TOKEN",
+		"MINIO_ROOT_USER", "MINIO_ROOT_PASSWORD", "MINIO_ACCESS_KEY", "MINIO_SECRET_KEY",
+		"AWS_WEB_IDENTITY_TOKEN_FILE", "AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", "AWS_CONTAINER_CREDENTIALS_FULL_URI",
+		"AWS_CONTAINER_AUTHORIZATION_TOKEN", "AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE",
+	} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+}
+
+// containerCredentialsServer stands in for the credentials endpoint of an ECS task, each request issues new
+// credentials valid for lifetime
+type containerCredentialsServer struct {
+	mu       sync.Mutex
+	issued   int
+	lifetime time.Duration
+}
+
+func (s *containerCredentialsServer) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.issued++
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"AccessKeyId":     "role-" + strconv.Itoa(s.issued),
+		"SecretAccessKey": "secret",
+		"Token":           "session",
+		"Expiration":      time.Now().Add(s.lifetime).UTC().Format(time.RFC3339),
+	})
+}
+
+func TestNewCredentials_StaticKeysFirst(t *testing.T) {
+	// Given
+	clearCredentialsEnv(t)
+	t.Setenv("AWS_ACCESS_KEY_ID", "env")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "env-secret")
+
+	// When
+	value, err := NewCredentials("access", "secret").Get()
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, "access", value.AccessKeyID)
+	assert.Equal(t, "secret", value.SecretAccessKey)
+}
+
+func TestNewCredentials_Environment(t *testing.T) {
+	// Given
+	clearCredentialsEnv(t)
+	t.Setenv("AWS_ACCESS_KEY_ID", "env")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "env-secret")
+	t.Setenv("AWS_SESSION_TOKEN", "env-session")
+
+	// When
+	value, err := NewCredentials("", "").Get()
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, "env", value.AccessKeyID)
+	assert.Equal(t, "env-session", value.SessionToken)
+}
+
+func TestNewCredentials_IAMRoleRefreshed(t *testing.T) {
+	// Given
+	clearCredentialsEnv(t)
+	credentialsServer := &containerCredentialsServer{lifetime: -time.Minute}
+	server := httptest.NewServer(credentialsServer)
+	t.Cleanup(server.Close)
+	t.Setenv("AWS_CONTAINER_CREDENTIALS_FULL_URI", server.URL)
+
+	creds := NewCredentials("", "")
+
+	// When
+	expired, expiredErr := creds.Get()
+	credentialsServer.mu.Lock()
+	credentialsServer.lifetime = time.Hour
+	credentialsServer.mu.Unlock()
+	refreshed, refreshedErr := creds.Get()
+	cached, cachedErr := creds.Get()
+
+	// Then
+	require.NoError(t, expiredErr)
+	require.NoError(t, refreshedErr)
+	require.NoError(t, cachedErr)
+	assert.Equal(t, "role-1", expired.AccessKeyID)
+	assert.Equal(t, "session", expired.SessionToken)
+	assert.Equal(t, "role-2", refreshed.AccessKeyID, "expired credentials are refreshed")
+	assert.Equal(t, "role-2", cached.AccessKeyID, "valid credentials are reused")
+}
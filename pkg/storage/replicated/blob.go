@@ -0,0 +1,340 @@
+package replicated
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kumojin/repo-backup-cli/pkg/logging"
+	"github.com/kumojin/repo-backup-cli/pkg/storage"
+)
+
+// Policy is the number of destinations which must succeed for an operation to succeed
+type Policy string
+
+const (
+	// PolicyAll requires every destination to succeed
+	PolicyAll Policy = "all"
+	// PolicyQuorum requires a majority of the destinations to succeed
+	PolicyQuorum Policy = "quorum"
+
+	// chunkSize is the size of the chunks written to every destination at the same time
+	chunkSize = 1024 * 1024
+)
+
+// required returns the number of destinations which must succeed among total
+func (p Policy) required(total int) int {
+	if p == PolicyQuorum {
+		return total/2 + 1
+	}
+
+	return total
+}
+
+// Destination is a storage backend the blobs are replicated to
+type Destination struct {
+	Name       string
+	Repository storage.BlobRepository
+}
+
+// result is the outcome of an operation on a destination
+type result struct {
+	destination string
+	location    string
+	err         error
+}
+
+type defaultBlobRepository struct {
+	destinations []Destination
+	policy       Policy
+}
+
+// NewBlobRepository replicates the blobs to every destination, the blobs are read from the destination holding the
+// newest copy
+func NewBlobRepository(destinations []Destination, policy Policy) storage.BlobRepository {
+	return defaultBlobRepository{destinations: destinations, policy: policy}
+}
+
+// Upload streams the blob to every destination at the same time, it returns the locations of the destinations
+// which succeeded. The failures tolerated by the policy are logged.
+func (r defaultBlobRepository) Upload(ctx context.Context, blobName string, in io.Reader) (string, error) {
+	results := make([]result, len(r.destinations))
+	writers := make([]*io.PipeWriter, len(r.destinations))
+
+	var wg sync.WaitGroup
+	for i, destination := range r.destinations {
+		reader, writer := io.Pipe()
+		writers[i] = writer
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			location, err := destination.Repository.Upload(ctx, blobName, reader)
+			// Unblocks the writes to a destination which stopped reading
+			_ = reader.CloseWithError(fmt.Errorf("upload to %s ended", destination.Name))
+			results[i] = result{destination: destination.Name, location: location, err: err}
+		}()
+	}
+
+	readErr := tee(in, writers)
+	for _, writer := range writers {
+		// A nil error closes the pipe with io.EOF
+		_ = writer.CloseWithError(readErr)
+	}
+	wg.Wait()
+
+	if readErr != nil {
+		return "", fmt.Errorf("failed to read blob %s: %w", blobName, readErr)
+	}
+
+	if err := r.check(ctx, "upload", blobName, results); err != nil {
+		return "", err
+	}
+
+	var locations []string
+	for _, result := range results {
+		if result.err == nil {
+			locations = append(locations, result.location)
+		}
+	}
+
+	return strings.Join(locations, ", "), nil
+}
+
+// tee copies in to every writer, a writer failing is dropped without stopping the others. It returns early once
+// every writer failed.
+func tee(in io.Reader, writers []*io.PipeWriter) error {
+	active := make([]bool, len(writers))
+	for i := range active {
+		active[i] = true
+	}
+
+	buffer := make([]byte, chunkSize)
+	for {
+		n, readErr := readChunk(in, buffer)
+		if n > 0 {
+			var wg sync.WaitGroup
+			for i, writer := range writers {
+				if !active[i] {
+					continue
+				}
+
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if _, err := writer.Write(buffer[:n]); err != nil {
+						active[i] = false
+					}
+				}()
+			}
+			wg.Wait()
+		}
+
+		if errors.Is(readErr, io.EOF) {
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+
+		anyActive := false
+		for _, isActive := range active {
+			anyActive = anyActive || isActive
+		}
+		if !anyActive {
+			return nil
+		}
+	}
+}
+
+// readChunk fills buffer from in, it returns io.EOF once in ended. Unlike io.ReadFull, a short chunk is only the end
+// of the blob when in returned io.EOF: an io.ErrUnexpectedEOF of in, such as a truncated body, is an error.
+func readChunk(in io.Reader, buffer []byte) (int, error) {
+	n := 0
+	for n < len(buffer) {
+		read, err := in.Read(buffer[n:])
+		n += read
+		if err != nil {
+			return n, err
+		}
+	}
+
+	return n, nil
+}
+
+// check logs the failures of the destinations and fails when fewer destinations than required by the policy
+// succeeded
+func (r defaultBlobRepository) check(ctx context.Context, operation, blobName string, results []result) error {
+	succeeded := 0
+	var errs []error
+	for _, result := range results {
+		if result.err == nil {
+			succeeded++
+			continue
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", result.destination, result.err))
+	}
+
+	if succeeded < r.policy.required(len(results)) {
+		return fmt.Errorf("failed to %s blob %s on %d of %d destinations: %w", operation, blobName, len(errs), len(results), errors.Join(errs...))
+	}
+
+	if len(errs) > 0 {
+		logger := logging.NewLogger(ctx)
+		for _, result := range results {
+			if result.err != nil {
+				logger.Warn("could not replicate blob, the replication policy is still met",
+					slog.String("operation", operation),
+					slog.String("blobName", blobName),
+					slog.String("destination", result.destination),
+					slog.Any("error", result.err),
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+// List merges the blobs of the destinations, so that a blob missing from one of them is still listed
+func (r defaultBlobRepository) List(ctx context.Context, prefix string) ([]storage.BlobInfo, error) {
+	lists := make([][]storage.BlobInfo, len(r.destinations))
+	results := r.each(func(i int, destination Destination) error {
+		blobs, err := destination.Repository.List(ctx, prefix)
+		lists[i] = blobs
+		return err
+	})
+
+	if err := r.check(ctx, "list", prefix, results); err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	var blobs []storage.BlobInfo
+	for _, list := range lists {
+		for _, blob := range list {
+			if !seen[blob.Name] {
+				seen[blob.Name] = true
+				blobs = append(blobs, blob)
+			}
+		}
+	}
+
+	sort.Slice(blobs, func(i, j int) bool {
+		return blobs[i].Name < blobs[j].Name
+	})
+
+	return blobs, nil
+}
+
+// Download reads the newest copy of the blob, from the next destination holding it when the download fails
+func (r defaultBlobRepository) Download(ctx context.Context, blobName string) (io.ReadCloser, error) {
+	copies, err := r.copies(ctx, blobName)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for _, candidate := range copies {
+		reader, err := candidate.destination.Repository.Download(ctx, blobName)
+		if err == nil {
+			return reader, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", candidate.destination.Name, err))
+	}
+
+	return nil, notFoundOrJoin(blobName, errs)
+}
+
+// Delete removes the blob from every destination
+func (r defaultBlobRepository) Delete(ctx context.Context, blobName string) error {
+	results := r.each(func(_ int, destination Destination) error {
+		return destination.Repository.Delete(ctx, blobName)
+	})
+
+	return r.check(ctx, "delete", blobName, results)
+}
+
+// Stat returns the metadata of the newest copy of the blob
+func (r defaultBlobRepository) Stat(ctx context.Context, blobName string) (storage.BlobInfo, error) {
+	copies, err := r.copies(ctx, blobName)
+	if err != nil {
+		return storage.BlobInfo{}, err
+	}
+
+	return copies[0].info, nil
+}
+
+// blobCopy is the copy of a blob held by a destination
+type blobCopy struct {
+	destination Destination
+	info        storage.BlobInfo
+}
+
+// copies returns the copies of the blob from the newest to the oldest, in the order of the destinations when they
+// were modified at the same time. A destination missing a write tolerated by the policy keeps a stale copy of the
+// blobs rewritten by every run, such as the catalog or the migration state, so it is only read when the newer copies
+// cannot be.
+func (r defaultBlobRepository) copies(ctx context.Context, blobName string) ([]blobCopy, error) {
+	infos := make([]storage.BlobInfo, len(r.destinations))
+	results := r.each(func(i int, destination Destination) error {
+		info, err := destination.Repository.Stat(ctx, blobName)
+		infos[i] = info
+		return err
+	})
+
+	var copies []blobCopy
+	var errs []error
+	for i, result := range results {
+		if result.err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", result.destination, result.err))
+			continue
+		}
+		copies = append(copies, blobCopy{destination: r.destinations[i], info: infos[i]})
+	}
+
+	if len(copies) == 0 {
+		return nil, notFoundOrJoin(blobName, errs)
+	}
+
+	sort.SliceStable(copies, func(i, j int) bool {
+		return copies[i].info.LastModified.After(copies[j].info.LastModified)
+	})
+
+	return copies, nil
+}
+
+// each runs operation on every destination at the same time
+func (r defaultBlobRepository) each(operation func(i int, destination Destination) error) []result {
+	results := make([]result, len(r.destinations))
+
+	var wg sync.WaitGroup
+	for i, destination := range r.destinations {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = result{destination: destination.Name, err: operation(i, destination)}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// notFoundOrJoin returns storage.ErrBlobNotFound when no destination holds the blob, otherwise the errors of the
+// destinations
+func notFoundOrJoin(blobName string, errs []error) error {
+	for _, err := range errs {
+		if !errors.Is(err, storage.ErrBlobNotFound) {
+			return fmt.Errorf("failed to access blob %s: %w", blobName, errors.Join(errs...))
+		}
+	}
+
+	return fmt.Errorf("%w: %s", storage.ErrBlobNotFound, blobName)
+}
@@ -0,0 +1,375 @@
+package replicated
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/kumojin/repo-backup-cli/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRepository keeps the blobs in memory, its upload can fail before reading, half way or after reading the blob
+type fakeRepository struct {
+	name  string
+	mu    sync.Mutex
+	blobs map[string][]byte
+	// modified are the last modification times of the blobs
+	modified map[string]time.Time
+	// failUploadAfter fails the uploads once that many bytes were read, -1 never fails
+	failUploadAfter int
+	// err fails every other operation
+	err error
+}
+
+func newFakeRepository(name string) *fakeRepository {
+	return &fakeRepository{name: name, blobs: map[string][]byte{}, modified: map[string]time.Time{}, failUploadAfter: -1}
+}
+
+func (f *fakeRepository) Upload(_ context.Context, blobName string, in io.Reader) (string, error) {
+	if f.failUploadAfter >= 0 {
+		_, _ = io.CopyN(io.Discard, in, int64(f.failUploadAfter))
+		return "", fmt.Errorf("%s is unavailable", f.name)
+	}
+
+	content, err := io.ReadAll(in)
+	if err != nil {
+		return "", err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.blobs[blobName] = content
+	f.modified[blobName] = time.Now()
+
+	return f.name + "://" + blobName, nil
+}
+
+func (f *fakeRepository) List(_ context.Context, prefix string) ([]storage.BlobInfo, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var blobs []storage.BlobInfo
+	for name, content := range f.blobs {
+		if strings.HasPrefix(name, prefix) {
+			blobs = append(blobs, storage.BlobInfo{Name: name, Size: int64(len(content))})
+		}
+	}
+	sort.Slice(blobs, func(i, j int) bool { return blobs[i].Name < blobs[j].Name })
+
+	return blobs, nil
+}
+
+func (f *fakeRepository) Download(_ context.Context, blobName string) (io.ReadCloser, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	content, ok := f.blobs[blobName]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", storage.ErrBlobNotFound, blobName)
+	}
+
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+func (f *fakeRepository) Delete(_ context.Context, blobName string) error {
+	if f.err != nil {
+		return f.err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.blobs, blobName)
+
+	return nil
+}
+
+func (f *fakeRepository) Stat(_ context.Context, blobName string) (storage.BlobInfo, error) {
+	if f.err != nil {
+		return storage.BlobInfo{}, f.err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	content, ok := f.blobs[blobName]
+	if !ok {
+		return storage.BlobInfo{}, fmt.Errorf("%w: %s", storage.ErrBlobNotFound, blobName)
+	}
+
+	return storage.BlobInfo{Name: blobName, Size: int64(len(content)), LastModified: f.modified[blobName]}, nil
+}
+
+func newTestBlobRepository(policy Policy, repositories ...*fakeRepository) storage.BlobRepository {
+	destinations := make([]Destination, len(repositories))
+	for i, repository := range repositories {
+		destinations[i] = Destination{Name: repository.name, Repository: repository}
+	}
+
+	return NewBlobRepository(destinations, policy)
+}
+
+// archive is larger than a chunk, so that the destinations failing half way fail between two chunks
+var archive = strings.Repeat("archive ", chunkSize/4)
+
+func TestBlobRepository_UploadToEveryDestination(t *testing.T) {
+	// Given
+	azure, object := newFakeRepository("azure"), newFakeRepository("object")
+	repository := newTestBlobRepository(PolicyAll, azure, object)
+
+	// When
+	location, err := repository.Upload(context.Background(), "backup.tar.gz", strings.NewReader(archive))
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, "azure://backup.tar.gz, object://backup.tar.gz", location)
+	assert.Equal(t, archive, string(azure.blobs["backup.tar.gz"]))
+	assert.Equal(t, archive, string(object.blobs["backup.tar.gz"]))
+}
+
+func TestBlobRepository_UploadPolicies(t *testing.T) {
+	tests := []struct {
+		name             string
+		policy           Policy
+		failUploadsAfter []int
+		expectedLocation string
+		expectedErr      []string
+	}{
+		{
+			name:             "all fails when one destination fails before reading",
+			policy:           PolicyAll,
+			failUploadsAfter: []int{-1, 0, -1},
+			expectedErr:      []string{"failed to upload blob backup.tar.gz on 1 of 3 destinations", "object: object is unavailable"},
+		},
+		{
+			name:             "all fails when one destination fails half way",
+			policy:           PolicyAll,
+			failUploadsAfter: []int{-1, -1, chunkSize + 1},
+			expectedErr:      []string{"on 1 of 3 destinations", "gcs: gcs is unavailable"},
+		},
+		{
+			name:             "quorum succeeds with a majority",
+			policy:           PolicyQuorum,
+			failUploadsAfter: []int{-1, chunkSize + 1, -1},
+			expectedLocation: "azure://backup.tar.gz, gcs://backup.tar.gz",
+		},
+		{
+			name:             "quorum succeeds when the first destination fails",
+			policy:           PolicyQuorum,
+			failUploadsAfter: []int{0, -1, -1},
+			expectedLocation: "object://backup.tar.gz, gcs://backup.tar.gz",
+		},
+		{
+			name:             "quorum fails without a majority",
+			policy:           PolicyQuorum,
+			failUploadsAfter: []int{-1, 0, chunkSize + 1},
+			expectedErr:      []string{"on 2 of 3 destinations", "object: object is unavailable", "gcs: gcs is unavailable"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			repositories := []*fakeRepository{newFakeRepository("azure"), newFakeRepository("object"), newFakeRepository("gcs")}
+			for i, failAfter := range tt.failUploadsAfter {
+				repositories[i].failUploadAfter = failAfter
+			}
+			repository := newTestBlobRepository(tt.policy, repositories...)
+
+			// When
+			location, err := repository.Upload(context.Background(), "backup.tar.gz", strings.NewReader(archive))
+
+			// Then
+			if len(tt.expectedErr) > 0 {
+				for _, expectedErr := range tt.expectedErr {
+					assert.ErrorContains(t, err, expectedErr)
+				}
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedLocation, location)
+			for _, fake := range repositories {
+				if fake.failUploadAfter < 0 {
+					assert.Equal(t, archive, string(fake.blobs["backup.tar.gz"]), fake.name)
+				}
+			}
+		})
+	}
+}
+
+// failingReader fails after returning its content, like a download dropping half way
+type failingReader struct {
+	content io.Reader
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	n, err := r.content.Read(p)
+	if errors.Is(err, io.EOF) {
+		return n, errors.New("connection reset")
+	}
+	return n, err
+}
+
+func TestBlobRepository_UploadFailingSource(t *testing.T) {
+	// Given
+	azure, object := newFakeRepository("azure"), newFakeRepository("object")
+	repository := newTestBlobRepository(PolicyQuorum, azure, object)
+
+	// When
+	_, err := repository.Upload(context.Background(), "backup.tar.gz", &failingReader{content: strings.NewReader(archive)})
+
+	// Then
+	assert.ErrorContains(t, err, "connection reset")
+	assert.Empty(t, azure.blobs, "the destinations see the error instead of the end of the blob")
+	assert.Empty(t, object.blobs)
+}
+
+func TestBlobRepository_UploadTruncatedSource(t *testing.T) {
+	// Given
+	azure, object := newFakeRepository("azure"), newFakeRepository("object")
+	truncated := io.MultiReader(strings.NewReader(archive[:chunkSize+10]), iotest.ErrReader(io.ErrUnexpectedEOF))
+
+	// When
+	_, allErr := newTestBlobRepository(PolicyAll, azure, object).Upload(context.Background(), "backup.tar.gz", truncated)
+	_, quorumErr := newTestBlobRepository(PolicyQuorum, azure, object).
+		Upload(context.Background(), "backup.tar.gz", iotest.ErrReader(io.ErrUnexpectedEOF))
+
+	// Then
+	assert.ErrorIs(t, allErr, io.ErrUnexpectedEOF)
+	assert.ErrorIs(t, quorumErr, io.ErrUnexpectedEOF)
+	assert.Empty(t, azure.blobs, "a truncated blob is not the end of the blob")
+	assert.Empty(t, object.blobs)
+}
+
+func TestBlobRepository_UploadEveryDestinationFailing(t *testing.T) {
+	// Given
+	azure, object := newFakeRepository("azure"), newFakeRepository("object")
+	azure.failUploadAfter = 0
+	object.failUploadAfter = 10
+	repository := newTestBlobRepository(PolicyQuorum, azure, object)
+
+	// When
+	_, err := repository.Upload(context.Background(), "backup.tar.gz", strings.NewReader(archive))
+
+	// Then
+	assert.ErrorContains(t, err, "on 2 of 2 destinations")
+}
+
+func TestBlobRepository_DownloadFallsBack(t *testing.T) {
+	// Given
+	azure, object := newFakeRepository("azure"), newFakeRepository("object")
+	azure.err = errors.New("azure is unavailable")
+	object.blobs["backup.tar.gz"] = []byte("archive")
+	repository := newTestBlobRepository(PolicyAll, azure, object)
+
+	// When
+	reader, err := repository.Download(context.Background(), "backup.tar.gz")
+	require.NoError(t, err)
+	content, readErr := io.ReadAll(reader)
+	info, statErr := repository.Stat(context.Background(), "backup.tar.gz")
+	_, notFoundErr := newTestBlobRepository(PolicyAll, newFakeRepository("azure"), newFakeRepository("object")).
+		Download(context.Background(), "backup.tar.gz")
+
+	// Then
+	require.NoError(t, readErr)
+	assert.Equal(t, "archive", string(content))
+	require.NoError(t, statErr)
+	assert.Equal(t, int64(7), info.Size)
+	assert.ErrorIs(t, notFoundErr, storage.ErrBlobNotFound)
+}
+
+func TestBlobRepository_ReadsNewestCopy(t *testing.T) {
+	// Given
+	azure, object, gcs := newFakeRepository("azure"), newFakeRepository("object"), newFakeRepository("gcs")
+	now := time.Date(2024, 5, 7, 2, 0, 0, 0, time.UTC)
+	// azure missed the last rewrite of the catalog, which the quorum tolerated
+	azure.blobs["catalog.jsonl"] = []byte("stale")
+	azure.modified["catalog.jsonl"] = now.Add(-24 * time.Hour)
+	object.blobs["catalog.jsonl"] = []byte("newest")
+	object.modified["catalog.jsonl"] = now
+	gcs.err = errors.New("gcs is unavailable")
+	repository := newTestBlobRepository(PolicyQuorum, azure, object, gcs)
+
+	// When
+	reader, err := repository.Download(context.Background(), "catalog.jsonl")
+	require.NoError(t, err)
+	content, readErr := io.ReadAll(reader)
+	info, statErr := repository.Stat(context.Background(), "catalog.jsonl")
+
+	// Then
+	require.NoError(t, readErr)
+	assert.Equal(t, "newest", string(content))
+	require.NoError(t, statErr)
+	assert.Equal(t, now, info.LastModified)
+}
+
+func TestBlobRepository_ListMergesDestinations(t *testing.T) {
+	// Given
+	azure, object, gcs := newFakeRepository("azure"), newFakeRepository("object"), newFakeRepository("gcs")
+	azure.blobs["2024-05-06-kumojin-migration.tar.gz"] = []byte("archive")
+	object.blobs["2024-05-06-kumojin-migration.tar.gz"] = []byte("archive")
+	object.blobs["2024-05-07-kumojin-migration.tar.gz"] = []byte("archive")
+	gcs.err = errors.New("gcs is unavailable")
+
+	// When
+	blobs, quorumErr := newTestBlobRepository(PolicyQuorum, azure, object, gcs).List(context.Background(), "2024-05")
+	_, allErr := newTestBlobRepository(PolicyAll, azure, object, gcs).List(context.Background(), "2024-05")
+
+	// Then
+	require.NoError(t, quorumErr)
+	require.Len(t, blobs, 2)
+	assert.Equal(t, "2024-05-06-kumojin-migration.tar.gz", blobs[0].Name)
+	assert.Equal(t, "2024-05-07-kumojin-migration.tar.gz", blobs[1].Name)
+	assert.ErrorContains(t, allErr, "gcs: gcs is unavailable")
+}
+
+func TestBlobRepository_DeleteFromEveryDestination(t *testing.T) {
+	// Given
+	azure, object := newFakeRepository("azure"), newFakeRepository("object")
+	azure.blobs["backup.tar.gz"] = []byte("archive")
+	object.blobs["backup.tar.gz"] = []byte("archive")
+	repository := newTestBlobRepository(PolicyAll, azure, object)
+
+	// When
+	err := repository.Delete(context.Background(), "backup.tar.gz")
+
+	// Then
+	require.NoError(t, err)
+	assert.Empty(t, azure.blobs)
+	assert.Empty(t, object.blobs)
+}
+
+func TestBlobRepository_DeleteQuorumToleratesFirstDestinationFailing(t *testing.T) {
+	// Given
+	azure, object, gcs := newFakeRepository("azure"), newFakeRepository("object"), newFakeRepository("gcs")
+	azure.err = errors.New("azure is unavailable")
+	object.blobs["catalog.jsonl"] = []byte("catalog")
+	gcs.blobs["catalog.jsonl"] = []byte("catalog")
+	repository := newTestBlobRepository(PolicyQuorum, azure, object, gcs)
+
+	// When
+	err := repository.Delete(context.Background(), "catalog.jsonl")
+
+	// Then
+	require.NoError(t, err)
+	assert.Empty(t, object.blobs)
+	assert.Empty(t, gcs.blobs)
+}
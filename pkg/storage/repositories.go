@@ -2,9 +2,30 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"io"
+	"time"
 )
 
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobInfo is the metadata of a stored blob
+type BlobInfo struct {
+	Name         string
+	Size         int64
+	LastModified time.Time
+	ContentType  string
+	Metadata     map[string]string
+}
+
 type BlobRepository interface {
 	Upload(ctx context.Context, blobName string, in io.Reader) (string, error)
+	// List returns the blobs whose name starts with prefix, sorted by name
+	List(ctx context.Context, prefix string) ([]BlobInfo, error)
+	// Download returns the content of the blob, which must be closed by the caller, or ErrBlobNotFound
+	Download(ctx context.Context, blobName string) (io.ReadCloser, error)
+	// Delete removes the blob, deleting a blob that does not exist is not an error
+	Delete(ctx context.Context, blobName string) error
+	// Stat returns the metadata of the blob or ErrBlobNotFound
+	Stat(ctx context.Context, blobName string) (BlobInfo, error)
 }
@@ -0,0 +1,301 @@
+package sftp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kumojin/repo-backup-cli/pkg/storage"
+)
+
+const contentType = "application/octet-stream"
+
+type defaultBlobRepository struct {
+	server Server
+	root   string
+}
+
+// NewBlobRepository stores the blobs as files under root on the SFTP server, the slashes of the blob names are
+// directories
+func NewBlobRepository(server Server, root string) storage.BlobRepository {
+	return defaultBlobRepository{server: server, root: path.Clean(root)}
+}
+
+// path returns the remote path of the blob, blob names leaving the root are rejected
+func (r defaultBlobRepository) path(blobName string) (string, error) {
+	if !filepath.IsLocal(blobName) {
+		return "", fmt.Errorf("invalid blob name: %s", blobName)
+	}
+
+	return path.Join(r.root, blobName), nil
+}
+
+// url returns the sftp:// URL of the remote path, paths relative to the home directory of the user start with /~/
+func (r defaultBlobRepository) url(remotePath string) string {
+	if !path.IsAbs(remotePath) {
+		remotePath = "/~/" + remotePath
+	}
+
+	location := url.URL{
+		Scheme: "sftp",
+		User:   url.User(r.server.User),
+		Host:   r.server.address(),
+		Path:   remotePath,
+	}
+
+	return location.String()
+}
+
+// Upload writes the blob to a temporary file renamed once complete, so that an interrupted upload never leaves a
+// partial blob behind
+func (r defaultBlobRepository) Upload(ctx context.Context, blobName string, in io.Reader) (string, error) {
+	remotePath, err := r.path(blobName)
+	if err != nil {
+		return "", err
+	}
+
+	conn, err := r.server.connect(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = conn.Close() }()
+
+	if err := conn.MkdirAll(path.Dir(remotePath)); err != nil {
+		return "", fmt.Errorf("failed to create directory of blob %s: %w", blobName, err)
+	}
+
+	tempPath, err := tempPath(remotePath)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if ctx.Err() != nil {
+			r.removeTempFile(context.WithoutCancel(ctx), tempPath)
+			return
+		}
+		_ = conn.Remove(tempPath)
+	}()
+
+	if err := writeFile(conn, tempPath, in); err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		return "", fmt.Errorf("failed to write blob %s: %w", blobName, err)
+	}
+
+	if err := rename(conn, tempPath, remotePath); err != nil {
+		return "", fmt.Errorf("failed to write blob %s: %w", blobName, err)
+	}
+
+	return r.url(remotePath), nil
+}
+
+// removeTempFile removes the temporary file of an upload whose connection was closed when its context was done
+func (r defaultBlobRepository) removeTempFile(ctx context.Context, tempPath string) {
+	conn, err := r.server.connect(ctx)
+	if err != nil {
+		return
+	}
+	defer func() { _ = conn.Close() }()
+
+	_ = conn.Remove(tempPath)
+}
+
+// tempPath returns a hidden name next to the blob, the renaming stays on the same filesystem
+func tempPath(remotePath string) (string, error) {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to generate temporary name: %w", err)
+	}
+
+	return path.Join(path.Dir(remotePath), "."+path.Base(remotePath)+"."+hex.EncodeToString(suffix)+".tmp"), nil
+}
+
+func writeFile(conn *connection, remotePath string, in io.Reader) error {
+	file, err := conn.OpenFile(remotePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.ReadFrom(in); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// rename replaces the blob atomically with the posix-rename extension of OpenSSH, servers without it can only
+// rename to a free name
+func rename(conn *connection, oldPath, newPath string) error {
+	if _, ok := conn.HasExtension("posix-rename@openssh.com"); ok {
+		return conn.PosixRename(oldPath, newPath)
+	}
+
+	if err := conn.Remove(newPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return conn.Rename(oldPath, newPath)
+}
+
+func (r defaultBlobRepository) List(ctx context.Context, prefix string) ([]storage.BlobInfo, error) {
+	conn, err := r.server.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	var blobs []storage.BlobInfo
+
+	walker := conn.Walk(r.root)
+	for walker.Step() {
+		if err := walker.Err(); err != nil {
+			// The root is only created by the first upload
+			if walker.Path() == r.root && errors.Is(err, fs.ErrNotExist) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to list blobs: %w", err)
+		}
+
+		info := walker.Stat()
+		// Hidden directories never hold blobs
+		if info.IsDir() && walker.Path() != r.root && strings.HasPrefix(info.Name(), ".") {
+			walker.SkipDir()
+			continue
+		}
+
+		if info.IsDir() || isTempFile(info.Name()) {
+			continue
+		}
+
+		blobName := strings.TrimPrefix(strings.TrimPrefix(walker.Path(), r.root), "/")
+		if !strings.HasPrefix(blobName, prefix) {
+			continue
+		}
+
+		blobs = append(blobs, toBlobInfo(blobName, info))
+	}
+
+	sort.Slice(blobs, func(i, j int) bool {
+		return blobs[i].Name < blobs[j].Name
+	})
+
+	return blobs, nil
+}
+
+// remoteFile closes the connection along with the file
+type remoteFile struct {
+	io.ReadCloser
+	conn *connection
+}
+
+func (f remoteFile) Close() error {
+	err := f.ReadCloser.Close()
+	if connErr := f.conn.Close(); err == nil {
+		err = connErr
+	}
+
+	return err
+}
+
+func (r defaultBlobRepository) Download(ctx context.Context, blobName string) (io.ReadCloser, error) {
+	remotePath, err := r.path(blobName)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := r.server.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := conn.Open(remotePath)
+	if err != nil {
+		_ = conn.Close()
+		return nil, wrapError(blobName, err)
+	}
+
+	return remoteFile{ReadCloser: file, conn: conn}, nil
+}
+
+// Delete removes the blob and the directories it leaves empty
+func (r defaultBlobRepository) Delete(ctx context.Context, blobName string) error {
+	remotePath, err := r.path(blobName)
+	if err != nil {
+		return err
+	}
+
+	conn, err := r.server.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	if err := conn.Remove(remotePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete blob %s: %w", blobName, err)
+	}
+
+	for dir := path.Dir(remotePath); dir != r.root; dir = path.Dir(dir) {
+		if conn.RemoveDirectory(dir) != nil {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (r defaultBlobRepository) Stat(ctx context.Context, blobName string) (storage.BlobInfo, error) {
+	remotePath, err := r.path(blobName)
+	if err != nil {
+		return storage.BlobInfo{}, err
+	}
+
+	conn, err := r.server.connect(ctx)
+	if err != nil {
+		return storage.BlobInfo{}, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	info, err := conn.Stat(remotePath)
+	if err != nil {
+		return storage.BlobInfo{}, wrapError(blobName, err)
+	}
+	if info.IsDir() {
+		return storage.BlobInfo{}, fmt.Errorf("%w: %s", storage.ErrBlobNotFound, blobName)
+	}
+
+	return toBlobInfo(blobName, info), nil
+}
+
+func isTempFile(name string) bool {
+	return strings.HasPrefix(name, ".") && strings.HasSuffix(name, ".tmp")
+}
+
+// wrapError maps the missing files to storage.ErrBlobNotFound
+func wrapError(blobName string, err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %s", storage.ErrBlobNotFound, blobName)
+	}
+
+	return fmt.Errorf("failed to access blob %s: %w", blobName, err)
+}
+
+func toBlobInfo(blobName string, info fs.FileInfo) storage.BlobInfo {
+	return storage.BlobInfo{
+		Name:         blobName,
+		Size:         info.Size(),
+		LastModified: info.ModTime(),
+		ContentType:  contentType,
+	}
+}
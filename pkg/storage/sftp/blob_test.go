@@ -0,0 +1,378 @@
+package sftp
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/kumojin/repo-backup-cli/pkg/storage"
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	testUser     = "backup"
+	testPassword = "s3cret"
+)
+
+// testServer is an in-process SSH server serving the local filesystem over SFTP, it accepts testPassword and the
+// client key
+type testServer struct {
+	host      string
+	port      int
+	hostKey   ssh.Signer
+	clientKey []byte
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	_, hostPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	hostKey, err := ssh.NewSignerFromKey(hostPrivateKey)
+	require.NoError(t, err)
+
+	clientPublicKey, clientPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	authorizedKey, err := ssh.NewPublicKey(clientPublicKey)
+	require.NoError(t, err)
+	clientKeyBlock, err := ssh.MarshalPrivateKey(clientPrivateKey, "")
+	require.NoError(t, err)
+
+	serverConfig := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() == testUser && string(password) == testPassword {
+				return nil, nil
+			}
+			return nil, errors.New("invalid password")
+		},
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if conn.User() == testUser && bytes.Equal(key.Marshal(), authorizedKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, errors.New("unknown key")
+		},
+	}
+	serverConfig.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSFTP(conn, serverConfig)
+		}
+	}()
+
+	address := listener.Addr().(*net.TCPAddr)
+	return &testServer{
+		host:      address.IP.String(),
+		port:      address.Port,
+		hostKey:   hostKey,
+		clientKey: pem.EncodeToMemory(clientKeyBlock),
+	}
+}
+
+func serveSFTP(conn net.Conn, serverConfig *ssh.ServerConfig) {
+	defer func() { _ = conn.Close() }()
+
+	_, channels, requests, err := ssh.NewServerConn(conn, serverConfig)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			for request := range channelRequests {
+				_ = request.Reply(request.Type == "subsystem" && string(request.Payload[4:]) == "sftp", nil)
+			}
+		}()
+
+		go func() {
+			defer func() { _ = channel.Close() }()
+
+			server, err := sftp.NewServer(channel)
+			if err != nil {
+				return
+			}
+			_ = server.Serve()
+		}()
+	}
+}
+
+// knownHostsFile writes a known_hosts file trusting hostKey for the server
+func (s *testServer) knownHostsFile(t *testing.T, hostKey ssh.PublicKey) string {
+	t.Helper()
+
+	address := knownhosts.Normalize(net.JoinHostPort(s.host, strconv.Itoa(s.port)))
+	file := filepath.Join(t.TempDir(), "known_hosts")
+	require.NoError(t, os.WriteFile(file, []byte(knownhosts.Line([]string{address}, hostKey)+"\n"), 0o600))
+
+	return file
+}
+
+func (s *testServer) server(t *testing.T) Server {
+	t.Helper()
+
+	return Server{
+		Host:           s.host,
+		Port:           s.port,
+		User:           testUser,
+		PrivateKey:     s.clientKey,
+		KnownHostsFile: s.knownHostsFile(t, s.hostKey.PublicKey()),
+	}
+}
+
+func readBlob(t *testing.T, repository storage.BlobRepository, blobName string) string {
+	t.Helper()
+
+	reader, err := repository.Download(context.Background(), blobName)
+	require.NoError(t, err)
+	defer func() { _ = reader.Close() }()
+
+	content, err := io.ReadAll(reader)
+	require.NoError(t, err)
+
+	return string(content)
+}
+
+func TestBlobRepository_UploadAndDownload(t *testing.T) {
+	// Given
+	testServer := newTestServer(t)
+	root := t.TempDir()
+	repository := NewBlobRepository(testServer.server(t), root)
+
+	// When
+	location, err := repository.Upload(context.Background(), "2025-07-23/kumojin/repo1.tar.gz", strings.NewReader("archive"))
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, "sftp://backup@"+net.JoinHostPort(testServer.host, strconv.Itoa(testServer.port))+root+"/2025-07-23/kumojin/repo1.tar.gz", location)
+	assert.Equal(t, "archive", readBlob(t, repository, "2025-07-23/kumojin/repo1.tar.gz"))
+
+	content, err := os.ReadFile(filepath.Join(root, "2025-07-23", "kumojin", "repo1.tar.gz"))
+	require.NoError(t, err)
+	assert.Equal(t, "archive", string(content))
+}
+
+func TestBlobRepository_PasswordAuthentication(t *testing.T) {
+	// Given
+	testServer := newTestServer(t)
+	server := testServer.server(t)
+	server.PrivateKey = nil
+	server.Password = testPassword
+	repository := NewBlobRepository(server, t.TempDir())
+
+	// When
+	_, err := repository.Upload(context.Background(), "archive.tar.gz", strings.NewReader("archive"))
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, "archive", readBlob(t, repository, "archive.tar.gz"))
+}
+
+func TestBlobRepository_RejectsUnknownHostKey(t *testing.T) {
+	// Given
+	testServer := newTestServer(t)
+	otherPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	otherHostKey, err := ssh.NewPublicKey(otherPublicKey)
+	require.NoError(t, err)
+
+	server := testServer.server(t)
+	server.KnownHostsFile = testServer.knownHostsFile(t, otherHostKey)
+	repository := NewBlobRepository(server, t.TempDir())
+
+	// When
+	_, err = repository.Upload(context.Background(), "archive.tar.gz", strings.NewReader("archive"))
+
+	// Then
+	var keyErr *knownhosts.KeyError
+	assert.ErrorAs(t, err, &keyErr)
+}
+
+func TestBlobRepository_UploadReplacesBlob(t *testing.T) {
+	// Given
+	testServer := newTestServer(t)
+	repository := NewBlobRepository(testServer.server(t), t.TempDir())
+	_, err := repository.Upload(context.Background(), "archive.tar.gz", strings.NewReader("first"))
+	require.NoError(t, err)
+
+	// When
+	_, err = repository.Upload(context.Background(), "archive.tar.gz", strings.NewReader("second"))
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, "second", readBlob(t, repository, "archive.tar.gz"))
+}
+
+// failingReader fails after returning its content, like a download dropping half way
+type failingReader struct {
+	content io.Reader
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	n, err := r.content.Read(p)
+	if errors.Is(err, io.EOF) {
+		return n, errors.New("connection reset")
+	}
+	return n, err
+}
+
+func TestBlobRepository_FailedUploadLeavesNoPartialBlob(t *testing.T) {
+	// Given
+	testServer := newTestServer(t)
+	root := t.TempDir()
+	repository := NewBlobRepository(testServer.server(t), root)
+	_, err := repository.Upload(context.Background(), "archive.tar.gz", strings.NewReader("previous"))
+	require.NoError(t, err)
+
+	// When
+	_, err = repository.Upload(context.Background(), "archive.tar.gz", &failingReader{content: strings.NewReader("partial")})
+
+	// Then
+	assert.ErrorContains(t, err, "connection reset")
+	assert.Equal(t, "previous", readBlob(t, repository, "archive.tar.gz"))
+
+	entries, err := os.ReadDir(root)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "the temporary file is removed")
+}
+
+// endlessReader is a source which never ends, it cancels the upload once it has read after bytes
+type endlessReader struct {
+	read   int
+	after  int
+	cancel context.CancelFunc
+}
+
+func (r *endlessReader) Read(p []byte) (int, error) {
+	r.read += len(p)
+	if r.read >= r.after {
+		r.cancel()
+	}
+
+	return len(p), nil
+}
+
+func TestBlobRepository_CancelledUploadLeavesNoPartialBlob(t *testing.T) {
+	// Given
+	testServer := newTestServer(t)
+	root := t.TempDir()
+	repository := NewBlobRepository(testServer.server(t), root)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// When
+	_, err := repository.Upload(ctx, "archive.tar.gz", &endlessReader{after: 1 << 20, cancel: cancel})
+
+	// Then
+	assert.ErrorIs(t, err, context.Canceled)
+
+	entries, err := os.ReadDir(root)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "the temporary file is removed")
+}
+
+func TestBlobRepository_List(t *testing.T) {
+	// Given
+	testServer := newTestServer(t)
+	root := t.TempDir()
+	repository := NewBlobRepository(testServer.server(t), root)
+	for _, blobName := range []string{
+		"2025-07-23-kumojin-migration.tar.gz",
+		"2025-07-22-kumojin-migration.tar.gz",
+		"2025-07-23/kumojin/repo1.tar.gz",
+		"other.tar.gz",
+	} {
+		_, err := repository.Upload(context.Background(), blobName, strings.NewReader(blobName))
+		require.NoError(t, err)
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".upload.tmp"), []byte("partial"), 0o644))
+
+	// When
+	blobs, err := repository.List(context.Background(), "2025-07-2")
+	missingBlobs, missingErr := NewBlobRepository(testServer.server(t), filepath.Join(root, "missing")).List(context.Background(), "")
+
+	// Then
+	require.NoError(t, err)
+	names := make([]string, len(blobs))
+	for i, blob := range blobs {
+		names[i] = blob.Name
+	}
+	assert.Equal(t, []string{
+		"2025-07-22-kumojin-migration.tar.gz",
+		"2025-07-23-kumojin-migration.tar.gz",
+		"2025-07-23/kumojin/repo1.tar.gz",
+	}, names)
+	assert.Equal(t, int64(len("2025-07-22-kumojin-migration.tar.gz")), blobs[0].Size)
+	assert.Equal(t, "application/octet-stream", blobs[0].ContentType)
+
+	assert.NoError(t, missingErr)
+	assert.Empty(t, missingBlobs)
+}
+
+func TestBlobRepository_StatAndDelete(t *testing.T) {
+	// Given
+	testServer := newTestServer(t)
+	root := t.TempDir()
+	repository := NewBlobRepository(testServer.server(t), root)
+	_, err := repository.Upload(context.Background(), "2025-07-23/kumojin/repo1.tar.gz", strings.NewReader("archive"))
+	require.NoError(t, err)
+
+	// When
+	info, statErr := repository.Stat(context.Background(), "2025-07-23/kumojin/repo1.tar.gz")
+	_, dirErr := repository.Stat(context.Background(), "2025-07-23")
+	deleteErr := repository.Delete(context.Background(), "2025-07-23/kumojin/repo1.tar.gz")
+	missingErr := repository.Delete(context.Background(), "2025-07-23/kumojin/repo1.tar.gz")
+	_, downloadErr := repository.Download(context.Background(), "2025-07-23/kumojin/repo1.tar.gz")
+
+	// Then
+	require.NoError(t, statErr)
+	assert.Equal(t, int64(7), info.Size)
+	assert.ErrorIs(t, dirErr, storage.ErrBlobNotFound)
+	assert.NoError(t, deleteErr)
+	assert.NoError(t, missingErr)
+	assert.ErrorIs(t, downloadErr, storage.ErrBlobNotFound)
+
+	entries, err := os.ReadDir(root)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "the empty directories are removed")
+}
+
+func TestBlobRepository_RejectsNamesOutsideRoot(t *testing.T) {
+	repository := NewBlobRepository(Server{}, "/backups")
+
+	_, err := repository.Upload(context.Background(), "../escape.tar.gz", strings.NewReader("archive"))
+
+	assert.EqualError(t, err, "invalid blob name: ../escape.tar.gz")
+}
@@ -0,0 +1,130 @@
+package sftp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const dialTimeout = 30 * time.Second
+
+// Server is the SFTP server holding the backups
+type Server struct {
+	Host string
+	Port int
+	User string
+	// Password and PrivateKey authenticate the user, the key is offered first when both are set
+	Password             string
+	PrivateKey           []byte
+	PrivateKeyPassphrase string
+	// KnownHostsFile verifies the host key of the server, unknown or changed keys are rejected
+	KnownHostsFile string
+}
+
+func (s Server) address() string {
+	return net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+}
+
+func (s Server) clientConfig() (*ssh.ClientConfig, error) {
+	hostKeyCallback, err := knownhosts.New(s.KnownHostsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known hosts: %w", err)
+	}
+
+	var auth []ssh.AuthMethod
+	if len(s.PrivateKey) > 0 {
+		signer, err := s.signer()
+		if err != nil {
+			return nil, err
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if s.Password != "" {
+		auth = append(auth, ssh.Password(s.Password))
+	}
+	if len(auth) == 0 {
+		return nil, errors.New("no sftp password or private key is set")
+	}
+
+	return &ssh.ClientConfig{
+		User:            s.User,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         dialTimeout,
+	}, nil
+}
+
+func (s Server) signer() (ssh.Signer, error) {
+	var signer ssh.Signer
+	var err error
+	if s.PrivateKeyPassphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(s.PrivateKey, []byte(s.PrivateKeyPassphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey(s.PrivateKey)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse sftp private key: %w", err)
+	}
+
+	return signer, nil
+}
+
+// connection is an SFTP session over its own SSH connection
+type connection struct {
+	*sftp.Client
+	ssh *ssh.Client
+	// stop stops closing the connection once the context of the operation is done
+	stop func() bool
+}
+
+// connect opens a new connection, each operation uses its own so that a backup waiting hours for a migration does
+// not depend on an idle connection kept open by the server. The connection is closed once the context is done,
+// which aborts the operation in progress.
+func (s Server) connect(ctx context.Context) (*connection, error) {
+	clientConfig, err := s.clientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := net.Dialer{Timeout: dialTimeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", s.address())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to sftp server %s: %w", s.address(), err)
+	}
+	stop := context.AfterFunc(ctx, func() { _ = netConn.Close() })
+
+	sshConn, channels, requests, err := ssh.NewClientConn(netConn, s.address(), clientConfig)
+	if err != nil {
+		stop()
+		_ = netConn.Close()
+		return nil, fmt.Errorf("failed to connect to sftp server %s: %w", s.address(), err)
+	}
+	sshClient := ssh.NewClient(sshConn, channels, requests)
+
+	sftpClient, err := sftp.NewClient(sshClient, sftp.UseConcurrentWrites(true))
+	if err != nil {
+		stop()
+		_ = sshClient.Close()
+		return nil, fmt.Errorf("failed to start sftp session on %s: %w", s.address(), err)
+	}
+
+	return &connection{Client: sftpClient, ssh: sshClient, stop: stop}, nil
+}
+
+func (c *connection) Close() error {
+	c.stop()
+
+	err := c.Client.Close()
+	if sshErr := c.ssh.Close(); err == nil {
+		err = sshErr
+	}
+
+	return err
+}
@@ -38,6 +38,265 @@ func (_m *MockBlobRepository) EXPECT() *MockBlobRepository_Expecter {
 	return &MockBlobRepository_Expecter{mock: &_m.Mock}
 }
 
+// Delete provides a mock function for the type MockBlobRepository
+func (_mock *MockBlobRepository) Delete(ctx context.Context, blobName string) error {
+	ret := _mock.Called(ctx, blobName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, blobName)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockBlobRepository_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockBlobRepository_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - blobName string
+func (_e *MockBlobRepository_Expecter) Delete(ctx interface{}, blobName interface{}) *MockBlobRepository_Delete_Call {
+	return &MockBlobRepository_Delete_Call{Call: _e.mock.On("Delete", ctx, blobName)}
+}
+
+func (_c *MockBlobRepository_Delete_Call) Run(run func(ctx context.Context, blobName string)) *MockBlobRepository_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockBlobRepository_Delete_Call) Return(err error) *MockBlobRepository_Delete_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockBlobRepository_Delete_Call) RunAndReturn(run func(ctx context.Context, blobName string) error) *MockBlobRepository_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Download provides a mock function for the type MockBlobRepository
+func (_mock *MockBlobRepository) Download(ctx context.Context, blobName string) (io.ReadCloser, error) {
+	ret := _mock.Called(ctx, blobName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Download")
+	}
+
+	var r0 io.ReadCloser
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (io.ReadCloser, error)); ok {
+		return returnFunc(ctx, blobName)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) io.ReadCloser); ok {
+		r0 = returnFunc(ctx, blobName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(io.ReadCloser)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, blobName)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockBlobRepository_Download_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Download'
+type MockBlobRepository_Download_Call struct {
+	*mock.Call
+}
+
+// Download is a helper method to define mock.On call
+//   - ctx context.Context
+//   - blobName string
+func (_e *MockBlobRepository_Expecter) Download(ctx interface{}, blobName interface{}) *MockBlobRepository_Download_Call {
+	return &MockBlobRepository_Download_Call{Call: _e.mock.On("Download", ctx, blobName)}
+}
+
+func (_c *MockBlobRepository_Download_Call) Run(run func(ctx context.Context, blobName string)) *MockBlobRepository_Download_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockBlobRepository_Download_Call) Return(readCloser io.ReadCloser, err error) *MockBlobRepository_Download_Call {
+	_c.Call.Return(readCloser, err)
+	return _c
+}
+
+func (_c *MockBlobRepository_Download_Call) RunAndReturn(run func(ctx context.Context, blobName string) (io.ReadCloser, error)) *MockBlobRepository_Download_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function for the type MockBlobRepository
+func (_mock *MockBlobRepository) List(ctx context.Context, prefix string) ([]BlobInfo, error) {
+	ret := _mock.Called(ctx, prefix)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []BlobInfo
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) ([]BlobInfo, error)); ok {
+		return returnFunc(ctx, prefix)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) []BlobInfo); ok {
+		r0 = returnFunc(ctx, prefix)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]BlobInfo)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, prefix)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockBlobRepository_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type MockBlobRepository_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx context.Context
+//   - prefix string
+func (_e *MockBlobRepository_Expecter) List(ctx interface{}, prefix interface{}) *MockBlobRepository_List_Call {
+	return &MockBlobRepository_List_Call{Call: _e.mock.On("List", ctx, prefix)}
+}
+
+func (_c *MockBlobRepository_List_Call) Run(run func(ctx context.Context, prefix string)) *MockBlobRepository_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockBlobRepository_List_Call) Return(blobInfos []BlobInfo, err error) *MockBlobRepository_List_Call {
+	_c.Call.Return(blobInfos, err)
+	return _c
+}
+
+func (_c *MockBlobRepository_List_Call) RunAndReturn(run func(ctx context.Context, prefix string) ([]BlobInfo, error)) *MockBlobRepository_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Stat provides a mock function for the type MockBlobRepository
+func (_mock *MockBlobRepository) Stat(ctx context.Context, blobName string) (BlobInfo, error) {
+	ret := _mock.Called(ctx, blobName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Stat")
+	}
+
+	var r0 BlobInfo
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (BlobInfo, error)); ok {
+		return returnFunc(ctx, blobName)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) BlobInfo); ok {
+		r0 = returnFunc(ctx, blobName)
+	} else {
+		r0 = ret.Get(0).(BlobInfo)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, blobName)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockBlobRepository_Stat_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Stat'
+type MockBlobRepository_Stat_Call struct {
+	*mock.Call
+}
+
+// Stat is a helper method to define mock.On call
+//   - ctx context.Context
+//   - blobName string
+func (_e *MockBlobRepository_Expecter) Stat(ctx interface{}, blobName interface{}) *MockBlobRepository_Stat_Call {
+	return &MockBlobRepository_Stat_Call{Call: _e.mock.On("Stat", ctx, blobName)}
+}
+
+func (_c *MockBlobRepository_Stat_Call) Run(run func(ctx context.Context, blobName string)) *MockBlobRepository_Stat_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockBlobRepository_Stat_Call) Return(blobInfo BlobInfo, err error) *MockBlobRepository_Stat_Call {
+	_c.Call.Return(blobInfo, err)
+	return _c
+}
+
+func (_c *MockBlobRepository_Stat_Call) RunAndReturn(run func(ctx context.Context, blobName string) (BlobInfo, error)) *MockBlobRepository_Stat_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Upload provides a mock function for the type MockBlobRepository
 func (_mock *MockBlobRepository) Upload(ctx context.Context, blobName string, in io.Reader) (string, error) {
 	ret := _mock.Called(ctx, blobName, in)
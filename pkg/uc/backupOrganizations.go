@@ -0,0 +1,92 @@
+package uc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kumojin/repo-backup-cli/pkg/logging"
+	"golang.org/x/sync/errgroup"
+)
+
+var ErrOrganizationBackupFailed = errors.New("organization backup failed")
+
+// DefaultConcurrency is the default number of organizations backed up at the same time
+const DefaultConcurrency = 3
+
+// OrganizationBackupFunc backs up one organization and returns the location of its backup
+type OrganizationBackupFunc func(ctx context.Context, organization string) (string, error)
+
+// OrganizationBackupResult is the outcome of the backup of one organization
+type OrganizationBackupResult struct {
+	Organization string
+	Location     string
+	Duration     time.Duration
+	Err          error
+}
+
+type BackupOrganizationsUseCase interface {
+	// Do backs up every organization, a failed backup does not stop the others. The results are in the order of the
+	// organizations and ErrOrganizationBackupFailed is returned when any backup failed.
+	Do(ctx context.Context, organizations []string, backup OrganizationBackupFunc) ([]OrganizationBackupResult, error)
+}
+
+type backupOrganizationsUseCase struct {
+	concurrency int
+}
+
+// NewBackupOrganizationsUseCase backs up at most concurrency organizations at the same time
+func NewBackupOrganizationsUseCase(concurrency int) BackupOrganizationsUseCase {
+	if concurrency < 1 {
+		concurrency = DefaultConcurrency
+	}
+
+	return &backupOrganizationsUseCase{
+		concurrency: concurrency,
+	}
+}
+
+func (uc *backupOrganizationsUseCase) Do(ctx context.Context, organizations []string, backup OrganizationBackupFunc) ([]OrganizationBackupResult, error) {
+	logger := logging.NewLogger(ctx)
+	results := make([]OrganizationBackupResult, len(organizations))
+
+	// Not bound to a context, the failure of an organization must not cancel the others
+	var group errgroup.Group
+	group.SetLimit(uc.concurrency)
+
+	for i, organization := range organizations {
+		group.Go(func() error {
+			start := getCurrentTime()
+			location, err := backup(ctx, organization)
+
+			results[i] = OrganizationBackupResult{
+				Organization: organization,
+				Location:     location,
+				Duration:     getCurrentTime().Sub(start),
+				Err:          err,
+			}
+
+			if err != nil {
+				logger.Error("could not backup organization", slog.String("organization", organization), slog.Any("error", err))
+			}
+			return nil
+		})
+	}
+
+	_ = group.Wait()
+
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return results, fmt.Errorf("%w: %d of %d organizations", ErrOrganizationBackupFailed, failed, len(organizations))
+	}
+
+	return results, nil
+}
@@ -0,0 +1,80 @@
+package uc
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupOrganizationsUseCase_Success(t *testing.T) {
+	// Given
+	useCase := NewBackupOrganizationsUseCase(2)
+	backup := func(_ context.Context, organization string) (string, error) {
+		return "https://storage/" + organization, nil
+	}
+
+	// When
+	results, err := useCase.Do(context.Background(), []string{"kumojin", "other", "third"}, backup)
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	for i, organization := range []string{"kumojin", "other", "third"} {
+		assert.Equal(t, organization, results[i].Organization)
+		assert.Equal(t, "https://storage/"+organization, results[i].Location)
+		assert.NoError(t, results[i].Err)
+	}
+}
+
+func TestBackupOrganizationsUseCase_FailureDoesNotStopOthers(t *testing.T) {
+	// Given
+	useCase := NewBackupOrganizationsUseCase(1)
+	expectedError := errors.New("migration failed")
+	backup := func(ctx context.Context, organization string) (string, error) {
+		if organization == "kumojin" {
+			return "", expectedError
+		}
+		return "https://storage/" + organization, ctx.Err()
+	}
+
+	// When
+	results, err := useCase.Do(context.Background(), []string{"kumojin", "other"}, backup)
+
+	// Then
+	assert.ErrorIs(t, err, ErrOrganizationBackupFailed)
+	assert.EqualError(t, err, "organization backup failed: 1 of 2 organizations")
+	assert.ErrorIs(t, results[0].Err, expectedError)
+	assert.NoError(t, results[1].Err)
+	assert.Equal(t, "https://storage/other", results[1].Location)
+}
+
+func TestBackupOrganizationsUseCase_ConcurrencyLimit(t *testing.T) {
+	// Given
+	useCase := NewBackupOrganizationsUseCase(2)
+	var running, maxRunning atomic.Int32
+	backup := func(context.Context, string) (string, error) {
+		current := running.Add(1)
+		defer running.Add(-1)
+
+		for {
+			previous := maxRunning.Load()
+			if current <= previous || maxRunning.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return "", nil
+	}
+
+	// When
+	_, err := useCase.Do(context.Background(), []string{"a", "b", "c", "d", "e"}, backup)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), maxRunning.Load())
+}
@@ -0,0 +1,112 @@
+package uc
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	gh "github.com/google/go-github/v90/github"
+)
+
+// archiveExtension is the extension of the archives produced by a backup
+const archiveExtension = ".tar.gz"
+
+// BatchOptions controls how the repositories of an organization are split into several migrations.
+// A zero value disables the corresponding limit.
+type BatchOptions struct {
+	// MaxRepositories is the maximum number of repositories in a single migration
+	MaxRepositories int
+	// MaxSize is the maximum total size of the repositories in a single migration, in bytes
+	MaxSize uint64
+}
+
+func (o BatchOptions) IsEnabled() bool {
+	return o.MaxRepositories > 0 || o.MaxSize > 0
+}
+
+// BackupArchive describes one archive produced by a backup
+type BackupArchive struct {
+	Batch        int      `json:"batch"`
+	BatchCount   int      `json:"batchCount"`
+	MigrationID  int64    `json:"migrationId,omitempty"`
+	Repositories []string `json:"repositories"`
+	Location     string   `json:"location"`
+}
+
+// FileName returns the file name of the archive for the given base name,
+// suffixed with the batch number when the backup is split in several archives
+func (a BackupArchive) FileName(baseName string) string {
+	if a.BatchCount <= 1 {
+		return baseName + archiveExtension
+	}
+
+	return fmt.Sprintf("%s-batch-%d-of-%d%s", baseName, a.Batch, a.BatchCount, archiveExtension)
+}
+
+// BatchManifest ties together the archives of a backup split in several batches
+type BatchManifest struct {
+	Organization string          `json:"organization"`
+	CreatedAt    time.Time       `json:"createdAt"`
+	Archives     []BackupArchive `json:"archives"`
+	// Type is BackupTypeFull or BackupTypeIncremental, Base names the full backup an incremental backup depends on
+	Type string `json:"type,omitempty"`
+	Base string `json:"base,omitempty"`
+}
+
+// batchManifestFileName returns the file name of the batch manifest for the given base name
+func batchManifestFileName(baseName string) string {
+	return baseName + "-batches.json"
+}
+
+// marshalBatchManifest returns the JSON batch manifest of the archives of an organization
+func marshalBatchManifest(organization string, archives []BackupArchive, plan backupPlan) ([]byte, error) {
+	manifest := BatchManifest{
+		Organization: organization,
+		CreatedAt:    getCurrentTime().UTC(),
+		Archives:     archives,
+		Type:         plan.Type,
+		Base:         plan.Base,
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal batch manifest: %w", err)
+	}
+
+	return data, nil
+}
+
+// splitIntoBatches splits the repositories in batches respecting the options. Repositories are kept in order
+// and a repository larger than the size budget gets a batch of its own.
+func splitIntoBatches(repos []gh.Repository, options BatchOptions) [][]gh.Repository {
+	if len(repos) == 0 {
+		return nil
+	}
+
+	if !options.IsEnabled() {
+		return [][]gh.Repository{repos}
+	}
+
+	var batches [][]gh.Repository
+	var current []gh.Repository
+	var currentSize uint64
+
+	for _, repo := range repos {
+		// Repository sizes are reported in kilobytes by the GitHub API
+		size := uint64(repo.GetSize()) * 1024
+
+		exceedsCount := options.MaxRepositories > 0 && len(current) >= options.MaxRepositories
+		exceedsSize := options.MaxSize > 0 && currentSize+size > options.MaxSize
+
+		if len(current) > 0 && (exceedsCount || exceedsSize) {
+			batches = append(batches, current)
+			current = nil
+			currentSize = 0
+		}
+
+		current = append(current, repo)
+		currentSize += size
+	}
+
+	return append(batches, current)
+}
@@ -0,0 +1,76 @@
+package uc
+
+import (
+	"testing"
+
+	gh "github.com/google/go-github/v90/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitIntoBatches(t *testing.T) {
+	repo := func(name string, sizeKB int) gh.Repository {
+		return gh.Repository{Name: gh.Ptr(name), Size: gh.Ptr(sizeKB)}
+	}
+	names := func(batches [][]gh.Repository) [][]string {
+		var result [][]string
+		for _, batch := range batches {
+			var batchNames []string
+			for _, r := range batch {
+				batchNames = append(batchNames, r.GetName())
+			}
+			result = append(result, batchNames)
+		}
+		return result
+	}
+
+	repos := []gh.Repository{repo("a", 100), repo("b", 300), repo("c", 50), repo("d", 2000), repo("e", 10)}
+
+	tests := []struct {
+		name     string
+		repos    []gh.Repository
+		options  BatchOptions
+		expected [][]string
+	}{
+		{
+			name:     "no repositories",
+			repos:    nil,
+			options:  BatchOptions{MaxRepositories: 2},
+			expected: nil,
+		},
+		{
+			name:     "batching disabled",
+			repos:    repos,
+			options:  BatchOptions{},
+			expected: [][]string{{"a", "b", "c", "d", "e"}},
+		},
+		{
+			name:     "by repository count",
+			repos:    repos,
+			options:  BatchOptions{MaxRepositories: 2},
+			expected: [][]string{{"a", "b"}, {"c", "d"}, {"e"}},
+		},
+		{
+			name:     "by size",
+			repos:    repos,
+			options:  BatchOptions{MaxSize: 400 * 1024},
+			expected: [][]string{{"a", "b"}, {"c"}, {"d"}, {"e"}},
+		},
+		{
+			name:     "by count and size",
+			repos:    repos,
+			options:  BatchOptions{MaxRepositories: 3, MaxSize: 500 * 1024},
+			expected: [][]string{{"a", "b", "c"}, {"d"}, {"e"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, names(splitIntoBatches(tt.repos, tt.options)))
+		})
+	}
+}
+
+func TestBackupArchive_FileName(t *testing.T) {
+	assert.Equal(t, "2025-07-23-kumojin-migration.tar.gz", BackupArchive{Batch: 1, BatchCount: 1}.FileName("2025-07-23-kumojin-migration"))
+	assert.Equal(t, "2025-07-23-kumojin-migration-batch-2-of-3.tar.gz", BackupArchive{Batch: 2, BatchCount: 3}.FileName("2025-07-23-kumojin-migration"))
+}
@@ -0,0 +1,190 @@
+package uc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kumojin/repo-backup-cli/internal/version"
+	"github.com/kumojin/repo-backup-cli/pkg/storage"
+)
+
+// CatalogFileName is the blob holding the catalog, one JSON entry per line
+const CatalogFileName = "catalog.jsonl"
+
+var ErrCatalogEntryNotFound = errors.New("catalog entry not found")
+
+// CatalogEntry records an archive saved by a backup, its ID is the name of the archive
+type CatalogEntry struct {
+	ID           string    `json:"id"`
+	Organization string    `json:"organization"`
+	CreatedAt    time.Time `json:"createdAt"`
+	MigrationID  int64     `json:"migrationId,omitempty"`
+	Repositories []string  `json:"repositories"`
+	// SHA256 and Size describe the archive as stored, after encryption
+	SHA256      string `json:"sha256"`
+	Size        int64  `json:"size"`
+	Location    string `json:"location"`
+	ToolVersion string `json:"toolVersion,omitempty"`
+	// Backup is the name of the backup the archive belongs to, such as 2025-07-22-kumojin-migration
+	Backup string `json:"backup,omitempty"`
+	// StartedAt is when the backup started, the next incremental backup saves the repositories changed since
+	StartedAt time.Time `json:"startedAt,omitzero"`
+	// Type is BackupTypeFull or BackupTypeIncremental, entries without a type are full backups
+	Type string `json:"type,omitempty"`
+	// Base is the name of the full backup an incremental backup depends on
+	Base string `json:"base,omitempty"`
+}
+
+// backupName returns the name of the backup of the entry, the ID for the entries recorded without one
+func (e CatalogEntry) backupName() string {
+	if e.Backup == "" {
+		return e.ID
+	}
+	return e.Backup
+}
+
+// CatalogStore keeps the entries of the archives saved by the backups
+type CatalogStore interface {
+	// List returns the entries, oldest first
+	List(ctx context.Context) ([]CatalogEntry, error)
+	// Get returns the entry with the ID or ErrCatalogEntryNotFound
+	Get(ctx context.Context, id string) (CatalogEntry, error)
+	// Add appends the entries, an entry replaces the entry with the same ID
+	Add(ctx context.Context, entries []CatalogEntry) error
+	// Delete removes the entries with the IDs, deleting an entry that does not exist is not an error
+	Delete(ctx context.Context, ids []string) error
+}
+
+type blobCatalogStore struct {
+	blobRepository storage.BlobRepository
+	// mu serializes the updates of the organizations backed up at the same time, the catalog is rewritten by each
+	mu sync.Mutex
+}
+
+// NewBlobCatalogStore stores the catalog in the catalog.jsonl blob
+func NewBlobCatalogStore(blobRepository storage.BlobRepository) CatalogStore {
+	return &blobCatalogStore{
+		blobRepository: blobRepository,
+	}
+}
+
+func (s *blobCatalogStore) List(ctx context.Context) ([]CatalogEntry, error) {
+	reader, err := s.blobRepository.Download(ctx, CatalogFileName)
+	if errors.Is(err, storage.ErrBlobNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to download catalog: %w", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	var entries []CatalogEntry
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var entry CatalogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse catalog line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to download catalog: %w", err)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+
+	return entries, nil
+}
+
+func (s *blobCatalogStore) Get(ctx context.Context, id string) (CatalogEntry, error) {
+	entries, err := s.List(ctx)
+	if err != nil {
+		return CatalogEntry{}, err
+	}
+
+	for _, entry := range entries {
+		if entry.ID == id {
+			return entry, nil
+		}
+	}
+
+	return CatalogEntry{}, fmt.Errorf("%w: %s", ErrCatalogEntryNotFound, id)
+}
+
+func (s *blobCatalogStore) Add(ctx context.Context, entries []CatalogEntry) error {
+	return s.update(ctx, func(existing []CatalogEntry) []CatalogEntry {
+		existing = slices.DeleteFunc(existing, func(entry CatalogEntry) bool {
+			return slices.ContainsFunc(entries, func(added CatalogEntry) bool { return added.ID == entry.ID })
+		})
+
+		return append(existing, entries...)
+	})
+}
+
+func (s *blobCatalogStore) Delete(ctx context.Context, ids []string) error {
+	return s.update(ctx, func(existing []CatalogEntry) []CatalogEntry {
+		return slices.DeleteFunc(existing, func(entry CatalogEntry) bool {
+			return slices.Contains(ids, entry.ID)
+		})
+	})
+}
+
+// update rewrites the catalog with the entries returned by change
+func (s *blobCatalogStore) update(ctx context.Context, change func(entries []CatalogEntry) []CatalogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	var content bytes.Buffer
+	encoder := json.NewEncoder(&content)
+	for _, entry := range change(entries) {
+		if err := encoder.Encode(entry); err != nil {
+			return fmt.Errorf("failed to marshal catalog entry: %w", err)
+		}
+	}
+
+	if _, err := s.blobRepository.Upload(ctx, CatalogFileName, &content); err != nil {
+		return fmt.Errorf("failed to upload catalog: %w", err)
+	}
+
+	return nil
+}
+
+// newCatalogEntry returns the entry of an archive of the backup whose content was read through checksum
+func newCatalogEntry(organization string, archiveName string, archive BackupArchive, checksum *checksumReader, location string, backup backupRun) CatalogEntry {
+	return CatalogEntry{
+		ID:           archiveName,
+		Organization: organization,
+		CreatedAt:    getCurrentTime().UTC(),
+		MigrationID:  archive.MigrationID,
+		Repositories: archive.Repositories,
+		SHA256:       checksum.SHA256(),
+		Size:         checksum.size,
+		Location:     location,
+		ToolVersion:  version.Tag,
+		Backup:       backup.Name,
+		StartedAt:    backup.StartedAt,
+		Type:         backup.Plan.Type,
+		Base:         backup.Plan.Base,
+	}
+}
@@ -0,0 +1,96 @@
+package uc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kumojin/repo-backup-cli/pkg/storage/filesystem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlobCatalogStore_AddListGetDelete(t *testing.T) {
+	// Given
+	root := t.TempDir()
+	store := NewBlobCatalogStore(filesystem.NewBlobRepository(root))
+	first := CatalogEntry{
+		ID:           "2025-07-22-kumojin-migration.tar.gz",
+		Organization: "kumojin",
+		CreatedAt:    time.Date(2025, 7, 22, 0, 0, 0, 0, time.UTC),
+		Repositories: []string{"repo1"},
+	}
+	second := CatalogEntry{
+		ID:           "2025-07-23-kumojin-migration.tar.gz",
+		Organization: "kumojin",
+		CreatedAt:    time.Date(2025, 7, 23, 0, 0, 0, 0, time.UTC),
+		Repositories: []string{"repo1", "repo2"},
+	}
+	replaced := second
+	replaced.Size = 42
+
+	// When
+	empty, emptyErr := store.List(context.Background())
+	require.NoError(t, store.Add(context.Background(), []CatalogEntry{second}))
+	require.NoError(t, store.Add(context.Background(), []CatalogEntry{first}))
+	require.NoError(t, store.Add(context.Background(), []CatalogEntry{replaced}))
+	entries, listErr := store.List(context.Background())
+	entry, getErr := store.Get(context.Background(), replaced.ID)
+	deleteErr := store.Delete(context.Background(), []string{first.ID, "2025-07-21-kumojin-migration.tar.gz"})
+	_, missingErr := store.Get(context.Background(), first.ID)
+
+	// Then
+	require.NoError(t, emptyErr)
+	assert.Empty(t, empty)
+
+	require.NoError(t, listErr)
+	assert.Equal(t, []CatalogEntry{first, replaced}, entries, "the entries are sorted oldest first")
+
+	require.NoError(t, getErr)
+	assert.Equal(t, replaced, entry)
+
+	require.NoError(t, deleteErr)
+	assert.ErrorIs(t, missingErr, ErrCatalogEntryNotFound)
+
+	content, err := os.ReadFile(filepath.Join(root, CatalogFileName))
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":"2025-07-23-kumojin-migration.tar.gz","organization":"kumojin","createdAt":"2025-07-23T00:00:00Z","repositories":["repo1","repo2"],"sha256":"","size":42,"location":""}`+"\n", string(content))
+}
+
+func TestBlobCatalogStore_ConcurrentAdd(t *testing.T) {
+	// Given
+	store := NewBlobCatalogStore(filesystem.NewBlobRepository(t.TempDir()))
+
+	// When
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, store.Add(context.Background(), []CatalogEntry{{ID: fmt.Sprintf("org%d", i), Organization: fmt.Sprintf("org%d", i)}}))
+		}()
+	}
+	wg.Wait()
+
+	// Then
+	entries, err := store.List(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, entries, 10, "no update is lost")
+}
+
+func TestBlobCatalogStore_InvalidLine(t *testing.T) {
+	// Given
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, CatalogFileName), []byte("{\"id\":\"a\"}\n\nnot json\n"), 0o644))
+	store := NewBlobCatalogStore(filesystem.NewBlobRepository(root))
+
+	// When
+	_, err := store.List(context.Background())
+
+	// Then
+	assert.ErrorContains(t, err, "failed to parse catalog line 3")
+}
@@ -9,38 +9,61 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/kumojin/repo-backup-cli/pkg/download"
 	"github.com/kumojin/repo-backup-cli/pkg/github"
 	"github.com/kumojin/repo-backup-cli/pkg/logging"
+	"golang.org/x/sync/errgroup"
 )
 
-var ErrMigrationFailed = errors.New("migration failed")
+var (
+	ErrMigrationFailed       = errors.New("migration failed")
+	ErrNoRepositories        = errors.New("no repositories to backup")
+	ErrNoChangedRepositories = errors.New("no repositories changed since the full backup")
+)
 
 const defaultPollingInterval = 5 * time.Second
 
-type SaveBackupFunc func(reader io.Reader) (string, error)
+type SaveBackupFunc func(archive BackupArchive, reader io.Reader) (string, error)
 
 type CreateBackupUseCase interface {
-	Do(ctx context.Context, organization string, saveBackupFunc SaveBackupFunc) (string, error)
+	// Do backs up the repositories of the organization changed since changedSince, a zero changedSince backs up
+	// every repository
+	Do(ctx context.Context, organization string, contents github.MigrationContents, changedSince time.Time, saveBackupFunc SaveBackupFunc) ([]BackupArchive, error)
 	WithPollingInterval(interval time.Duration) CreateBackupUseCase
+	WithBatchOptions(options BatchOptions) CreateBackupUseCase
+	// WithDownloader replaces the downloader of the migration archives
+	WithDownloader(downloader download.Downloader) CreateBackupUseCase
+	// WithMigrationState saves the started migrations in store, when resume is set the migrations of an
+	// interrupted backup are polled again instead of starting new ones
+	WithMigrationState(store MigrationStateStore, resume bool) CreateBackupUseCase
+	// WithRecorder records the unchanged repositories, the states of the migrations and the duration of the phases
+	WithRecorder(recorder RunRecorder) CreateBackupUseCase
 }
 
 type createBackupUseCase struct {
 	githubClient                     github.Client
-	listPrivateReposUseCase          ListPrivateReposUseCase
+	listRepositoriesUseCase          ListRepositoriesUseCase
 	getOrganizationArchiveUrlUseCase GetOrganizationArchiveUrlUseCase
 	pollingInterval                  time.Duration
+	batchOptions                     BatchOptions
+	downloader                       download.Downloader
+	stateStore                       MigrationStateStore
+	resume                           bool
+	recorder                         RunRecorder
 }
 
 func NewCreateBackupUseCase(
 	client github.Client,
-	listPrivateRepoUseCase ListPrivateReposUseCase,
+	listRepositoriesUseCase ListRepositoriesUseCase,
 	getOrganizationArchiveUrlUseCase GetOrganizationArchiveUrlUseCase,
 ) CreateBackupUseCase {
 	return &createBackupUseCase{
 		githubClient:                     client,
-		listPrivateReposUseCase:          listPrivateRepoUseCase,
+		listRepositoriesUseCase:          listRepositoriesUseCase,
 		getOrganizationArchiveUrlUseCase: getOrganizationArchiveUrlUseCase,
 		pollingInterval:                  defaultPollingInterval,
+		downloader:                       download.NewDownloader(http.DefaultClient, download.Options{}),
+		recorder:                         noopRunRecorder{},
 	}
 }
 
@@ -49,39 +72,158 @@ func (uc *createBackupUseCase) WithPollingInterval(interval time.Duration) Creat
 	return uc
 }
 
-func (uc *createBackupUseCase) Do(ctx context.Context, organization string, saveBackupFunc SaveBackupFunc) (string, error) {
-	repos, err := uc.listPrivateReposUseCase.Do(ctx, organization)
+// WithBatchOptions splits the repositories into several migrations according to the options
+func (uc *createBackupUseCase) WithBatchOptions(options BatchOptions) CreateBackupUseCase {
+	uc.batchOptions = options
+	return uc
+}
+
+func (uc *createBackupUseCase) WithDownloader(downloader download.Downloader) CreateBackupUseCase {
+	uc.downloader = downloader
+	return uc
+}
+
+func (uc *createBackupUseCase) WithMigrationState(store MigrationStateStore, resume bool) CreateBackupUseCase {
+	uc.stateStore = store
+	uc.resume = resume
+	return uc
+}
+
+func (uc *createBackupUseCase) WithRecorder(recorder RunRecorder) CreateBackupUseCase {
+	uc.recorder = recorder
+	return uc
+}
+
+func (uc *createBackupUseCase) Do(ctx context.Context, organization string, contents github.MigrationContents, changedSince time.Time, saveBackupFunc SaveBackupFunc) ([]BackupArchive, error) {
+	repos, err := uc.listRepositoriesUseCase.Do(ctx, organization)
 	if err != nil {
-		return "", fmt.Errorf("failed to list private repositories: %w", err)
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
 	}
 
-	repoNames := make([]string, len(repos))
-	for i, repo := range repos {
-		repoNames[i] = *repo.Name
+	recordUnchangedRepositories(uc.recorder, organization, repos, changedSince)
+	repos, err = changedRepositories(repos, changedSince)
+	if err != nil {
+		return nil, err
 	}
 
-	migration, err := uc.githubClient.StartMigration(ctx, organization, repoNames)
-	if err != nil {
-		return "", fmt.Errorf("failed to start migration: %w", err)
+	batches := splitIntoBatches(repos, uc.batchOptions)
+	if len(batches) == 0 {
+		return nil, ErrNoRepositories
 	}
 
+	resumer := uc.newMigrationResumer(ctx, organization, contents)
+	state := MigrationState{
+		Organization: organization,
+		Contents:     contents,
+		StartedAt:    getCurrentTime().UTC(),
+	}
+
+	archives := make([]BackupArchive, len(batches))
+	for i, batch := range batches {
+		repoNames := make([]string, len(batch))
+		for j, repo := range batch {
+			repoNames[j] = *repo.Name
+		}
+
+		migrationID, resumed := resumer.find(ctx, repoNames)
+		if !resumed {
+			migration, err := uc.githubClient.StartMigration(ctx, organization, repoNames, contents)
+			if err != nil {
+				uc.recorder.RecordPhase(organization, PhaseMigrate, 0, err)
+				return nil, fmt.Errorf("failed to start migration: %w", err)
+			}
+			migrationID = migration.GetID()
+		}
+
+		archives[i] = BackupArchive{
+			Batch:        i + 1,
+			BatchCount:   len(batches),
+			MigrationID:  migrationID,
+			Repositories: repoNames,
+		}
+		if !resumed {
+			uc.recorder.RecordMigrationState(organization, archives[i], "started")
+		}
+
+		state.Migrations = append(state.Migrations, StartedMigration{ID: migrationID, Repositories: repoNames})
+		if resumer.resumedFromState {
+			state.StartedAt = resumer.state.StartedAt
+		}
+		if err := uc.saveMigrationState(ctx, state); err != nil {
+			return nil, err
+		}
+	}
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	for i := range archives {
+		group.Go(func() error {
+			location, err := uc.saveMigrationArchive(groupCtx, organization, contents, archives[i], saveBackupFunc)
+			if err != nil {
+				return err
+			}
+
+			archives[i].Location = location
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	if uc.stateStore != nil {
+		if err := uc.stateStore.Delete(ctx, organization); err != nil {
+			return nil, err
+		}
+	}
+
+	return archives, nil
+}
+
+func (uc *createBackupUseCase) saveMigrationState(ctx context.Context, state MigrationState) error {
+	if uc.stateStore == nil {
+		return nil
+	}
+
+	return uc.stateStore.Save(ctx, state)
+}
+
+// saveMigrationArchive waits for the migration of the archive to be exported and saves it
+func (uc *createBackupUseCase) saveMigrationArchive(
+	ctx context.Context,
+	organization string,
+	contents github.MigrationContents,
+	archive BackupArchive,
+	saveBackupFunc SaveBackupFunc,
+) (string, error) {
 	ticker := time.NewTicker(uc.pollingInterval)
 	defer ticker.Stop()
 
 	logger := logging.NewLogger(ctx).With(
 		slog.String("organization", organization),
-		slog.Int64("migrationID", migration.GetID()),
+		slog.Int64("migrationID", archive.MigrationID),
+		slog.Int("batch", archive.Batch),
+		slog.Int("batchCount", archive.BatchCount),
+		slog.Any("migrationContents", contents),
 	)
 
+	migrateStart := getCurrentTime()
+	recordPhase := func(phase string, start time.Time, err error) {
+		uc.recorder.RecordPhase(organization, phase, getCurrentTime().Sub(start), err)
+	}
+
 	for {
 		select {
 		case <-ticker.C:
-			migration, err = uc.githubClient.GetMigrationStatus(ctx, organization, migration.GetID())
+			migration, err := uc.githubClient.GetMigrationStatus(ctx, organization, archive.MigrationID)
 			if err != nil {
+				recordPhase(PhaseMigrate, migrateStart, err)
 				return "", fmt.Errorf("failed to get migration status: %w", err)
 			}
+			uc.recorder.RecordMigrationState(organization, archive, migration.GetState())
 
 			if migration.GetState() == "failed" {
+				recordPhase(PhaseMigrate, migrateStart, ErrMigrationFailed)
 				return "", ErrMigrationFailed
 			}
 
@@ -89,25 +231,67 @@ func (uc *createBackupUseCase) Do(ctx context.Context, organization string, save
 				logger.Info("migration in progress, waiting for completion")
 				break
 			}
+			recordPhase(PhaseMigrate, migrateStart, nil)
 
-			url, err := uc.getOrganizationArchiveUrlUseCase.Do(ctx, organization, migration.GetID())
+			downloadStart := getCurrentTime()
+			url, err := uc.getOrganizationArchiveUrlUseCase.Do(ctx, organization, archive.MigrationID)
 			if err != nil {
+				recordPhase(PhaseDownload, downloadStart, err)
 				return "", fmt.Errorf("failed to get migration archive URL: %w", err)
 			}
 
-			resp, err := http.Get(url)
+			// The archive URL is signed for a short time, a new one is requested when it expires while resuming
+			getURL := func(ctx context.Context) (string, error) {
+				if url == "" {
+					return uc.getOrganizationArchiveUrlUseCase.Do(ctx, organization, archive.MigrationID)
+				}
+
+				archiveURL := url
+				url = ""
+				return archiveURL, nil
+			}
+
+			reader, err := uc.downloader.Open(ctx, getURL)
 			if err != nil {
+				recordPhase(PhaseDownload, downloadStart, err)
+				var statusErr *download.StatusError
+				if errors.As(err, &statusErr) {
+					return "", fmt.Errorf("failed to download archive, got status: %s", statusErr.Status)
+				}
 				return "", fmt.Errorf("failed to download archive: %w", err)
 			}
-			defer func() { _ = resp.Body.Close() }()
+			defer func() { _ = reader.Close() }()
 
-			if resp.StatusCode != http.StatusOK {
-				return "", fmt.Errorf("failed to download archive, got status: %s", resp.Status)
+			// A failed download is recorded before its error reaches the upload reading the archive
+			downloadFailed := false
+			location, err := saveBackupFunc(archive, &errorRecordingReader{reader: reader, record: func(err error) {
+				downloadFailed = true
+				recordPhase(PhaseDownload, downloadStart, err)
+			}})
+			if !downloadFailed {
+				recordPhase(PhaseDownload, downloadStart, nil)
 			}
 
-			return saveBackupFunc(resp.Body)
+			return location, err
 		case <-ctx.Done():
 			return "", ctx.Err()
 		}
 	}
 }
+
+// errorRecordingReader calls record with the first error of the reader other than io.EOF
+type errorRecordingReader struct {
+	reader   io.Reader
+	record   func(err error)
+	recorded bool
+}
+
+func (r *errorRecordingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) && !r.recorded {
+		r.recorded = true
+		r.record(err)
+	}
+
+	return n, err
+}
@@ -6,12 +6,17 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	gh "github.com/google/go-github/v90/github"
+	"github.com/kumojin/repo-backup-cli/pkg/download"
 	"github.com/kumojin/repo-backup-cli/pkg/github"
+	"github.com/kumojin/repo-backup-cli/pkg/storage/filesystem"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockSaveBackupFunc is a mock implementation of SaveBackupFunc
@@ -19,7 +24,7 @@ type MockSaveBackupFunc struct {
 	mock.Mock
 }
 
-func (m *MockSaveBackupFunc) Do(reader io.Reader) (string, error) {
+func (m *MockSaveBackupFunc) Do(_ BackupArchive, reader io.Reader) (string, error) {
 	// Read the content to pass it to the mock for assertions
 	content, err := io.ReadAll(reader)
 	if err != nil {
@@ -32,26 +37,26 @@ func (m *MockSaveBackupFunc) Do(reader io.Reader) (string, error) {
 // createBackupTestMocks contains all the mocks used in tests
 type createBackupTestMocks struct {
 	githubClient              *github.MockClient
-	listPrivateRepos          *MockListPrivateReposUseCase
+	listRepositories          *MockListRepositoriesUseCase
 	getOrganizationArchiveUrl *MockGetOrganizationArchiveUrlUseCase
-	saveBackupFunc            func(reader io.Reader) (string, error)
+	saveBackupFunc            SaveBackupFunc
 	saveBackupMock            *MockSaveBackupFunc
 }
 
 // newCreateBackupTestMocks creates and returns all the mocks needed for testing
 func newCreateBackupTestMocks(t *testing.T) *createBackupTestMocks {
 	mockGithubClient := github.NewMockClient(t)
-	mockListPrivateRepos := NewMockListPrivateReposUseCase(t)
+	mockListRepositories := NewMockListRepositoriesUseCase(t)
 	mockGetArchiveUrl := NewMockGetOrganizationArchiveUrlUseCase(t)
 
 	mockSaveBackup := new(MockSaveBackupFunc)
-	saveBackupFunc := func(reader io.Reader) (string, error) {
-		return mockSaveBackup.Do(reader)
+	saveBackupFunc := func(archive BackupArchive, reader io.Reader) (string, error) {
+		return mockSaveBackup.Do(archive, reader)
 	}
 
 	return &createBackupTestMocks{
 		githubClient:              mockGithubClient,
-		listPrivateRepos:          mockListPrivateRepos,
+		listRepositories:          mockListRepositories,
 		getOrganizationArchiveUrl: mockGetArchiveUrl,
 		saveBackupFunc:            saveBackupFunc,
 		saveBackupMock:            mockSaveBackup,
@@ -60,14 +65,19 @@ func newCreateBackupTestMocks(t *testing.T) *createBackupTestMocks {
 
 // createUseCase creates a new use case instance with the provided mocks
 func (m *createBackupTestMocks) createUseCase() CreateBackupUseCase {
-	return NewCreateBackupUseCase(m.githubClient, m.listPrivateRepos, m.getOrganizationArchiveUrl).
-		WithPollingInterval(1) // Use 1ns for faster tests
+	return NewCreateBackupUseCase(m.githubClient, m.listRepositories, m.getOrganizationArchiveUrl).
+		WithPollingInterval(1). // Use 1ns for faster tests
+		WithDownloader(download.NewDownloader(http.DefaultClient, download.Options{
+			MaxRetries:     2,
+			InitialBackoff: time.Millisecond,
+		}))
 }
 
 func TestCreateBackupUseCase_Success(t *testing.T) {
 	// Given
 	mocks := newCreateBackupTestMocks(t)
 	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
 	repos := []gh.Repository{
 		{Name: gh.Ptr("repo1")},
 		{Name: gh.Ptr("repo2")},
@@ -86,10 +96,10 @@ func TestCreateBackupUseCase_Success(t *testing.T) {
 	}))
 	defer server.Close()
 
-	mocks.listPrivateRepos.EXPECT().Do(mock.Anything, organization).Return(repos, nil)
+	mocks.listRepositories.EXPECT().Do(mock.Anything, organization).Return(repos, nil)
 
 	mocks.githubClient.EXPECT().
-		StartMigration(mock.Anything, organization, repoNames).
+		StartMigration(mock.Anything, organization, repoNames, contents).
 		Return(migration, nil)
 
 	mocks.githubClient.EXPECT().
@@ -103,11 +113,13 @@ func TestCreateBackupUseCase_Success(t *testing.T) {
 	useCase := mocks.createUseCase()
 
 	// When
-	result, err := useCase.Do(context.Background(), organization, mocks.saveBackupFunc)
+	result, err := useCase.Do(context.Background(), organization, contents, time.Time{}, mocks.saveBackupFunc)
 
 	// Then
 	assert.NoError(t, err)
-	assert.Equal(t, savePath, result)
+	assert.Equal(t, []BackupArchive{
+		{Batch: 1, BatchCount: 1, MigrationID: 12345, Repositories: repoNames, Location: savePath},
+	}, result)
 	mocks.saveBackupMock.AssertExpectations(t)
 }
 
@@ -116,6 +128,7 @@ func TestCreateBackupUseCase_SuccessOnSecondCall(t *testing.T) {
 	mocks := newCreateBackupTestMocks(t)
 
 	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
 	repos := []gh.Repository{
 		{Name: gh.Ptr("repo1")},
 		{Name: gh.Ptr("repo2")},
@@ -138,10 +151,10 @@ func TestCreateBackupUseCase_SuccessOnSecondCall(t *testing.T) {
 	}))
 	defer server.Close()
 
-	mocks.listPrivateRepos.EXPECT().Do(mock.Anything, organization).Return(repos, nil)
+	mocks.listRepositories.EXPECT().Do(mock.Anything, organization).Return(repos, nil)
 
 	mocks.githubClient.EXPECT().
-		StartMigration(mock.Anything, organization, repoNames).
+		StartMigration(mock.Anything, organization, repoNames, contents).
 		Return(pendingMigration, nil)
 
 	mocks.githubClient.EXPECT().
@@ -161,11 +174,13 @@ func TestCreateBackupUseCase_SuccessOnSecondCall(t *testing.T) {
 	useCase := mocks.createUseCase()
 
 	// When
-	result, err := useCase.Do(context.Background(), organization, mocks.saveBackupFunc)
+	result, err := useCase.Do(context.Background(), organization, contents, time.Time{}, mocks.saveBackupFunc)
 
 	// Then
 	assert.NoError(t, err)
-	assert.Equal(t, savePath, result)
+	assert.Equal(t, []BackupArchive{
+		{Batch: 1, BatchCount: 1, MigrationID: 12345, Repositories: repoNames, Location: savePath},
+	}, result)
 	mocks.saveBackupMock.AssertExpectations(t)
 }
 
@@ -174,14 +189,15 @@ func TestCreateBackupUseCase_ListRepositoriesError(t *testing.T) {
 	mocks := newCreateBackupTestMocks(t)
 
 	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
 	expectedError := errors.New("failed to list repositories")
 
-	mocks.listPrivateRepos.EXPECT().Do(mock.Anything, organization).Return([]gh.Repository{}, expectedError)
+	mocks.listRepositories.EXPECT().Do(mock.Anything, organization).Return([]gh.Repository{}, expectedError)
 
 	useCase := mocks.createUseCase()
 
 	// When
-	result, err := useCase.Do(context.Background(), organization, mocks.saveBackupFunc)
+	result, err := useCase.Do(context.Background(), organization, contents, time.Time{}, mocks.saveBackupFunc)
 
 	// Then
 	assert.ErrorIs(t, err, expectedError)
@@ -193,6 +209,7 @@ func TestCreateBackupUseCase_StartMigrationError(t *testing.T) {
 	mocks := newCreateBackupTestMocks(t)
 
 	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
 	repos := []gh.Repository{
 		{Name: gh.Ptr("repo1")},
 		{Name: gh.Ptr("repo2")},
@@ -200,16 +217,16 @@ func TestCreateBackupUseCase_StartMigrationError(t *testing.T) {
 	repoNames := []string{"repo1", "repo2"}
 	expectedError := errors.New("failed to start migration")
 
-	mocks.listPrivateRepos.EXPECT().Do(mock.Anything, organization).Return(repos, nil)
+	mocks.listRepositories.EXPECT().Do(mock.Anything, organization).Return(repos, nil)
 
 	mocks.githubClient.EXPECT().
-		StartMigration(mock.Anything, organization, repoNames).
+		StartMigration(mock.Anything, organization, repoNames, contents).
 		Return(nil, expectedError)
 
 	useCase := mocks.createUseCase()
 
 	// When
-	result, err := useCase.Do(context.Background(), organization, mocks.saveBackupFunc)
+	result, err := useCase.Do(context.Background(), organization, contents, time.Time{}, mocks.saveBackupFunc)
 
 	// Then
 	assert.ErrorIs(t, err, expectedError)
@@ -221,6 +238,7 @@ func TestCreateBackupUseCase_FailedMigration(t *testing.T) {
 	mocks := newCreateBackupTestMocks(t)
 
 	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
 	repos := []gh.Repository{
 		{Name: gh.Ptr("repo1")},
 		{Name: gh.Ptr("repo2")},
@@ -235,10 +253,10 @@ func TestCreateBackupUseCase_FailedMigration(t *testing.T) {
 		State: gh.Ptr("failed"),
 	}
 
-	mocks.listPrivateRepos.EXPECT().Do(mock.Anything, organization).Return(repos, nil)
+	mocks.listRepositories.EXPECT().Do(mock.Anything, organization).Return(repos, nil)
 
 	mocks.githubClient.EXPECT().
-		StartMigration(mock.Anything, organization, repoNames).
+		StartMigration(mock.Anything, organization, repoNames, contents).
 		Return(migration, nil)
 
 	mocks.githubClient.EXPECT().
@@ -248,7 +266,7 @@ func TestCreateBackupUseCase_FailedMigration(t *testing.T) {
 	useCase := mocks.createUseCase()
 
 	// When
-	result, err := useCase.Do(context.Background(), organization, mocks.saveBackupFunc)
+	result, err := useCase.Do(context.Background(), organization, contents, time.Time{}, mocks.saveBackupFunc)
 
 	// Then
 	assert.ErrorIs(t, err, ErrMigrationFailed)
@@ -260,6 +278,7 @@ func TestCreateBackupUseCase_GetMigrationStatusError(t *testing.T) {
 	mocks := newCreateBackupTestMocks(t)
 
 	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
 	repos := []gh.Repository{
 		{Name: gh.Ptr("repo1")},
 		{Name: gh.Ptr("repo2")},
@@ -271,10 +290,10 @@ func TestCreateBackupUseCase_GetMigrationStatusError(t *testing.T) {
 	}
 	expectedError := errors.New("failed to get migration status")
 
-	mocks.listPrivateRepos.EXPECT().Do(mock.Anything, organization).Return(repos, nil)
+	mocks.listRepositories.EXPECT().Do(mock.Anything, organization).Return(repos, nil)
 
 	mocks.githubClient.EXPECT().
-		StartMigration(mock.Anything, organization, repoNames).
+		StartMigration(mock.Anything, organization, repoNames, contents).
 		Return(migration, nil)
 
 	mocks.githubClient.EXPECT().
@@ -284,7 +303,7 @@ func TestCreateBackupUseCase_GetMigrationStatusError(t *testing.T) {
 	useCase := mocks.createUseCase()
 
 	// When
-	result, err := useCase.Do(context.Background(), organization, mocks.saveBackupFunc)
+	result, err := useCase.Do(context.Background(), organization, contents, time.Time{}, mocks.saveBackupFunc)
 
 	// Then
 	assert.ErrorIs(t, err, expectedError)
@@ -296,6 +315,7 @@ func TestCreateBackupUseCase_GetArchiveURLError(t *testing.T) {
 	mocks := newCreateBackupTestMocks(t)
 
 	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
 	repos := []gh.Repository{
 		{Name: gh.Ptr("repo1")},
 		{Name: gh.Ptr("repo2")},
@@ -307,10 +327,10 @@ func TestCreateBackupUseCase_GetArchiveURLError(t *testing.T) {
 	}
 	expectedError := errors.New("failed to get archive URL")
 
-	mocks.listPrivateRepos.EXPECT().Do(mock.Anything, organization).Return(repos, nil)
+	mocks.listRepositories.EXPECT().Do(mock.Anything, organization).Return(repos, nil)
 
 	mocks.githubClient.EXPECT().
-		StartMigration(mock.Anything, organization, repoNames).
+		StartMigration(mock.Anything, organization, repoNames, contents).
 		Return(migration, nil)
 
 	mocks.githubClient.EXPECT().
@@ -322,7 +342,7 @@ func TestCreateBackupUseCase_GetArchiveURLError(t *testing.T) {
 	useCase := mocks.createUseCase()
 
 	// When
-	result, err := useCase.Do(context.Background(), organization, mocks.saveBackupFunc)
+	result, err := useCase.Do(context.Background(), organization, contents, time.Time{}, mocks.saveBackupFunc)
 
 	// Then
 	assert.Error(t, err)
@@ -336,6 +356,7 @@ func TestCreateBackupUseCase_SaveBackupError(t *testing.T) {
 
 	// Setup test data
 	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
 	repos := []gh.Repository{
 		{Name: gh.Ptr("repo1")},
 		{Name: gh.Ptr("repo2")},
@@ -354,10 +375,10 @@ func TestCreateBackupUseCase_SaveBackupError(t *testing.T) {
 	}))
 	defer server.Close()
 
-	mocks.listPrivateRepos.EXPECT().Do(mock.Anything, organization).Return(repos, nil)
+	mocks.listRepositories.EXPECT().Do(mock.Anything, organization).Return(repos, nil)
 
 	mocks.githubClient.EXPECT().
-		StartMigration(mock.Anything, organization, repoNames).
+		StartMigration(mock.Anything, organization, repoNames, contents).
 		Return(migration, nil)
 
 	mocks.githubClient.EXPECT().
@@ -371,7 +392,7 @@ func TestCreateBackupUseCase_SaveBackupError(t *testing.T) {
 	useCase := mocks.createUseCase()
 
 	// When
-	result, err := useCase.Do(context.Background(), organization, mocks.saveBackupFunc)
+	result, err := useCase.Do(context.Background(), organization, contents, time.Time{}, mocks.saveBackupFunc)
 
 	// Then
 	assert.Error(t, err)
@@ -384,6 +405,7 @@ func TestCreateBackupUseCase_HTTPStatusNotOK(t *testing.T) {
 	mocks := newCreateBackupTestMocks(t)
 
 	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
 	repos := []gh.Repository{
 		{Name: gh.Ptr("repo1")},
 		{Name: gh.Ptr("repo2")},
@@ -400,10 +422,10 @@ func TestCreateBackupUseCase_HTTPStatusNotOK(t *testing.T) {
 	}))
 	defer server.Close()
 
-	mocks.listPrivateRepos.EXPECT().Do(mock.Anything, organization).Return(repos, nil)
+	mocks.listRepositories.EXPECT().Do(mock.Anything, organization).Return(repos, nil)
 
 	mocks.githubClient.EXPECT().
-		StartMigration(mock.Anything, organization, repoNames).
+		StartMigration(mock.Anything, organization, repoNames, contents).
 		Return(migration, nil)
 
 	mocks.githubClient.EXPECT().
@@ -415,7 +437,7 @@ func TestCreateBackupUseCase_HTTPStatusNotOK(t *testing.T) {
 	useCase := mocks.createUseCase()
 
 	// When
-	result, err := useCase.Do(context.Background(), organization, mocks.saveBackupFunc)
+	result, err := useCase.Do(context.Background(), organization, contents, time.Time{}, mocks.saveBackupFunc)
 
 	// Then
 	assert.Error(t, err)
@@ -428,6 +450,7 @@ func TestCreateBackupUseCase_HTTPGetError(t *testing.T) {
 	mocks := newCreateBackupTestMocks(t)
 
 	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
 	repos := []gh.Repository{
 		{Name: gh.Ptr("repo1")},
 		{Name: gh.Ptr("repo2")},
@@ -440,10 +463,10 @@ func TestCreateBackupUseCase_HTTPGetError(t *testing.T) {
 	// Use an invalid URL scheme to force an HTTP error
 	invalidURL := "invalid://not-a-valid-url"
 
-	mocks.listPrivateRepos.EXPECT().Do(mock.Anything, organization).Return(repos, nil)
+	mocks.listRepositories.EXPECT().Do(mock.Anything, organization).Return(repos, nil)
 
 	mocks.githubClient.EXPECT().
-		StartMigration(mock.Anything, organization, repoNames).
+		StartMigration(mock.Anything, organization, repoNames, contents).
 		Return(migration, nil)
 
 	mocks.githubClient.EXPECT().
@@ -455,7 +478,7 @@ func TestCreateBackupUseCase_HTTPGetError(t *testing.T) {
 	useCase := mocks.createUseCase()
 
 	// When
-	result, err := useCase.Do(context.Background(), organization, mocks.saveBackupFunc)
+	result, err := useCase.Do(context.Background(), organization, contents, time.Time{}, mocks.saveBackupFunc)
 
 	// Then
 	assert.Error(t, err)
@@ -463,11 +486,78 @@ func TestCreateBackupUseCase_HTTPGetError(t *testing.T) {
 	assert.Empty(t, result)
 }
 
+func TestCreateBackupUseCase_ResumesDroppedDownloadWithNewURL(t *testing.T) {
+	// Given
+	mocks := newCreateBackupTestMocks(t)
+
+	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
+	repos := []gh.Repository{
+		{Name: gh.Ptr("repo1")},
+	}
+	migration := &gh.Migration{
+		ID:    gh.Ptr(int64(12345)),
+		State: gh.Ptr("exported"),
+	}
+	archiveContent := "mock archive content"
+	savePath := "/tmp/backup.zip"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/expired" && r.Header.Get("Range") != "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		if r.URL.Path == "/expired" {
+			// Drop the connection in the middle of the archive
+			w.Header().Set("Content-Length", "20")
+			_, _ = w.Write([]byte(archiveContent[:10]))
+			controller := http.NewResponseController(w)
+			_ = controller.Flush()
+			conn, _, err := controller.Hijack()
+			if err == nil {
+				_ = conn.Close()
+			}
+			return
+		}
+
+		assert.Equal(t, "bytes=10-", r.Header.Get("Range"))
+		http.ServeContent(w, r, "archive.tar.gz", time.Time{}, strings.NewReader(archiveContent))
+	}))
+	defer server.Close()
+
+	mocks.listRepositories.EXPECT().Do(mock.Anything, organization).Return(repos, nil)
+
+	mocks.githubClient.EXPECT().
+		StartMigration(mock.Anything, organization, []string{"repo1"}, contents).
+		Return(migration, nil)
+
+	mocks.githubClient.EXPECT().
+		GetMigrationStatus(mock.Anything, organization, int64(12345)).
+		Return(migration, nil)
+
+	mocks.getOrganizationArchiveUrl.EXPECT().Do(mock.Anything, organization, int64(12345)).Return(server.URL+"/expired", nil).Once()
+	mocks.getOrganizationArchiveUrl.EXPECT().Do(mock.Anything, organization, int64(12345)).Return(server.URL+"/refreshed", nil).Once()
+
+	mocks.saveBackupMock.On("Do", archiveContent).Return(savePath, nil)
+
+	useCase := mocks.createUseCase()
+
+	// When
+	result, err := useCase.Do(context.Background(), organization, contents, time.Time{}, mocks.saveBackupFunc)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	mocks.saveBackupMock.AssertExpectations(t)
+}
+
 func TestCreateBackupUseCase_ContextCancellation(t *testing.T) {
 	// Given
 	mocks := newCreateBackupTestMocks(t)
 
 	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
 	repos := []gh.Repository{
 		{Name: gh.Ptr("repo1")},
 		{Name: gh.Ptr("repo2")},
@@ -480,10 +570,10 @@ func TestCreateBackupUseCase_ContextCancellation(t *testing.T) {
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	mocks.listPrivateRepos.EXPECT().Do(mock.Anything, organization).Return(repos, nil)
+	mocks.listRepositories.EXPECT().Do(mock.Anything, organization).Return(repos, nil)
 
 	mocks.githubClient.EXPECT().
-		StartMigration(mock.Anything, organization, repoNames).
+		StartMigration(mock.Anything, organization, repoNames, contents).
 		Return(migration, nil)
 
 	mocks.githubClient.EXPECT().
@@ -496,9 +586,337 @@ func TestCreateBackupUseCase_ContextCancellation(t *testing.T) {
 	cancel()
 
 	// When
-	result, err := useCase.Do(ctx, organization, mocks.saveBackupFunc)
+	result, err := useCase.Do(ctx, organization, contents, time.Time{}, mocks.saveBackupFunc)
 
 	// Then
 	assert.ErrorIs(t, err, context.Canceled)
 	assert.Empty(t, result)
 }
+
+func TestCreateBackupUseCase_PassesMigrationContents(t *testing.T) {
+	// Given
+	mocks := newCreateBackupTestMocks(t)
+
+	organization := "kumojin"
+	contents := github.MigrationContents{
+		GitData:     true,
+		Attachments: true,
+		Releases:    true,
+	}
+	repos := []gh.Repository{
+		{Name: gh.Ptr("repo1")},
+	}
+	expectedError := errors.New("failed to start migration")
+
+	mocks.listRepositories.EXPECT().Do(mock.Anything, organization).Return(repos, nil)
+
+	mocks.githubClient.EXPECT().
+		StartMigration(mock.Anything, organization, []string{"repo1"}, contents).
+		Return(nil, expectedError)
+
+	useCase := mocks.createUseCase()
+
+	// When
+	result, err := useCase.Do(context.Background(), organization, contents, time.Time{}, mocks.saveBackupFunc)
+
+	// Then
+	assert.ErrorIs(t, err, expectedError)
+	assert.Empty(t, result)
+}
+
+func TestCreateBackupUseCase_Batches(t *testing.T) {
+	// Given
+	mocks := newCreateBackupTestMocks(t)
+
+	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
+	repos := []gh.Repository{
+		{Name: gh.Ptr("repo1")},
+		{Name: gh.Ptr("repo2")},
+		{Name: gh.Ptr("repo3")},
+	}
+	firstMigration := &gh.Migration{
+		ID:    gh.Ptr(int64(1)),
+		State: gh.Ptr("exported"),
+	}
+	secondMigration := &gh.Migration{
+		ID:    gh.Ptr(int64(2)),
+		State: gh.Ptr("exported"),
+	}
+	archiveContent := "mock archive content"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(archiveContent))
+	}))
+	defer server.Close()
+
+	mocks.listRepositories.EXPECT().Do(mock.Anything, organization).Return(repos, nil)
+
+	mocks.githubClient.EXPECT().
+		StartMigration(mock.Anything, organization, []string{"repo1", "repo2"}, contents).
+		Return(firstMigration, nil)
+	mocks.githubClient.EXPECT().
+		StartMigration(mock.Anything, organization, []string{"repo3"}, contents).
+		Return(secondMigration, nil)
+
+	mocks.githubClient.EXPECT().
+		GetMigrationStatus(mock.Anything, organization, int64(1)).
+		Return(firstMigration, nil)
+	mocks.githubClient.EXPECT().
+		GetMigrationStatus(mock.Anything, organization, int64(2)).
+		Return(secondMigration, nil)
+
+	mocks.getOrganizationArchiveUrl.EXPECT().Do(mock.Anything, organization, int64(1)).Return(server.URL, nil)
+	mocks.getOrganizationArchiveUrl.EXPECT().Do(mock.Anything, organization, int64(2)).Return(server.URL, nil)
+
+	mocks.saveBackupMock.On("Do", archiveContent).Return("/tmp/backup.tar.gz", nil).Twice()
+
+	useCase := mocks.createUseCase().WithBatchOptions(BatchOptions{MaxRepositories: 2})
+
+	// When
+	result, err := useCase.Do(context.Background(), organization, contents, time.Time{}, mocks.saveBackupFunc)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []BackupArchive{
+		{Batch: 1, BatchCount: 2, MigrationID: 1, Repositories: []string{"repo1", "repo2"}, Location: "/tmp/backup.tar.gz"},
+		{Batch: 2, BatchCount: 2, MigrationID: 2, Repositories: []string{"repo3"}, Location: "/tmp/backup.tar.gz"},
+	}, result)
+	mocks.saveBackupMock.AssertExpectations(t)
+}
+
+func TestCreateBackupUseCase_NoRepositories(t *testing.T) {
+	// Given
+	mocks := newCreateBackupTestMocks(t)
+
+	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
+
+	mocks.listRepositories.EXPECT().Do(mock.Anything, organization).Return([]gh.Repository{}, nil)
+
+	useCase := mocks.createUseCase()
+
+	// When
+	result, err := useCase.Do(context.Background(), organization, contents, time.Time{}, mocks.saveBackupFunc)
+
+	// Then
+	assert.ErrorIs(t, err, ErrNoRepositories)
+	assert.Empty(t, result)
+}
+
+// newArchiveServer returns a server answering every request with the archive content
+func newArchiveServer(t *testing.T, archiveContent string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(archiveContent))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestCreateBackupUseCase_SavesAndDeletesMigrationState(t *testing.T) {
+	// Given
+	mocks := newCreateBackupTestMocks(t)
+	store := NewBlobMigrationStateStore(filesystem.NewBlobRepository(t.TempDir()))
+
+	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
+	repos := []gh.Repository{{Name: gh.Ptr("repo1")}}
+	migration := &gh.Migration{
+		ID:    gh.Ptr(int64(12345)),
+		State: gh.Ptr("exported"),
+	}
+	server := newArchiveServer(t, "mock archive content")
+
+	mocks.listRepositories.EXPECT().Do(mock.Anything, organization).Return(repos, nil)
+	mocks.githubClient.EXPECT().StartMigration(mock.Anything, organization, []string{"repo1"}, contents).Return(migration, nil)
+	mocks.githubClient.EXPECT().GetMigrationStatus(mock.Anything, organization, int64(12345)).Return(migration, nil)
+	mocks.getOrganizationArchiveUrl.EXPECT().Do(mock.Anything, organization, int64(12345)).Return(server.URL, nil)
+
+	var stateWhileSaving MigrationState
+	saveBackupFunc := func(BackupArchive, io.Reader) (string, error) {
+		var err error
+		stateWhileSaving, err = store.Load(context.Background(), organization)
+		return "/tmp/backup.tar.gz", err
+	}
+
+	useCase := mocks.createUseCase().WithMigrationState(store, false)
+
+	// When
+	_, err := useCase.Do(context.Background(), organization, contents, time.Time{}, saveBackupFunc)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, organization, stateWhileSaving.Organization)
+	assert.Equal(t, contents, stateWhileSaving.Contents)
+	assert.Equal(t, []StartedMigration{{ID: 12345, Repositories: []string{"repo1"}}}, stateWhileSaving.Migrations)
+
+	_, err = store.Load(context.Background(), organization)
+	assert.ErrorIs(t, err, ErrMigrationStateNotFound)
+}
+
+func TestCreateBackupUseCase_ResumesMigrationFromState(t *testing.T) {
+	// Given
+	now := time.Date(2025, 7, 23, 9, 0, 0, 0, time.UTC)
+	useFakeClock(t, now)
+	mocks := newCreateBackupTestMocks(t)
+	store := NewBlobMigrationStateStore(filesystem.NewBlobRepository(t.TempDir()))
+
+	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
+	repos := []gh.Repository{{Name: gh.Ptr("repo1")}, {Name: gh.Ptr("repo2")}}
+	migration := &gh.Migration{
+		ID:    gh.Ptr(int64(12345)),
+		State: gh.Ptr("exported"),
+	}
+	server := newArchiveServer(t, "mock archive content")
+
+	require.NoError(t, store.Save(context.Background(), MigrationState{
+		Organization: organization,
+		Contents:     contents,
+		StartedAt:    now.Add(-time.Hour),
+		Migrations:   []StartedMigration{{ID: 12345, Repositories: []string{"repo2", "repo1"}}},
+	}))
+
+	mocks.listRepositories.EXPECT().Do(mock.Anything, organization).Return(repos, nil)
+	mocks.githubClient.EXPECT().GetMigrationStatus(mock.Anything, organization, int64(12345)).Return(migration, nil)
+	mocks.getOrganizationArchiveUrl.EXPECT().Do(mock.Anything, organization, int64(12345)).Return(server.URL, nil)
+	mocks.saveBackupMock.On("Do", "mock archive content").Return("/tmp/backup.tar.gz", nil)
+
+	useCase := mocks.createUseCase().WithMigrationState(store, true)
+
+	// When
+	result, err := useCase.Do(context.Background(), organization, contents, time.Time{}, mocks.saveBackupFunc)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []BackupArchive{
+		{Batch: 1, BatchCount: 1, MigrationID: 12345, Repositories: []string{"repo1", "repo2"}, Location: "/tmp/backup.tar.gz"},
+	}, result)
+	mocks.githubClient.AssertNotCalled(t, "StartMigration", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateBackupUseCase_StartsNewMigrationAfterCompletedBackup(t *testing.T) {
+	// Given
+	now := time.Date(2025, 7, 23, 9, 0, 0, 0, time.UTC)
+	useFakeClock(t, now)
+	mocks := newCreateBackupTestMocks(t)
+	store := NewBlobMigrationStateStore(filesystem.NewBlobRepository(t.TempDir()))
+
+	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
+	repos := []gh.Repository{{Name: gh.Ptr("repo1")}}
+	completedMigration := &gh.Migration{
+		ID:    gh.Ptr(int64(1)),
+		State: gh.Ptr("exported"),
+	}
+	migration := &gh.Migration{
+		ID:    gh.Ptr(int64(2)),
+		State: gh.Ptr("exported"),
+	}
+	server := newArchiveServer(t, "mock archive content")
+
+	mocks.listRepositories.EXPECT().Do(mock.Anything, organization).Return(repos, nil)
+	mocks.githubClient.EXPECT().StartMigration(mock.Anything, organization, []string{"repo1"}, contents).Return(completedMigration, nil).Once()
+	mocks.githubClient.EXPECT().StartMigration(mock.Anything, organization, []string{"repo1"}, contents).Return(migration, nil).Once()
+	mocks.githubClient.EXPECT().GetMigrationStatus(mock.Anything, organization, int64(1)).Return(completedMigration, nil)
+	mocks.githubClient.EXPECT().GetMigrationStatus(mock.Anything, organization, int64(2)).Return(migration, nil)
+	mocks.getOrganizationArchiveUrl.EXPECT().Do(mock.Anything, organization, mock.AnythingOfType("int64")).Return(server.URL, nil)
+	mocks.saveBackupMock.On("Do", "mock archive content").Return("/tmp/backup.tar.gz", nil)
+
+	useCase := mocks.createUseCase().WithMigrationState(store, true)
+
+	_, err := useCase.Do(context.Background(), organization, contents, time.Time{}, mocks.saveBackupFunc)
+	require.NoError(t, err)
+
+	// When
+	result, err := useCase.Do(context.Background(), organization, contents, time.Time{}, mocks.saveBackupFunc)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), result[0].MigrationID, "the migration of the completed backup is not saved again")
+}
+
+func TestCreateBackupUseCase_StartsNewMigrationWhenSavedOneFailed(t *testing.T) {
+	// Given
+	now := time.Date(2025, 7, 23, 9, 0, 0, 0, time.UTC)
+	useFakeClock(t, now)
+	mocks := newCreateBackupTestMocks(t)
+	store := NewBlobMigrationStateStore(filesystem.NewBlobRepository(t.TempDir()))
+
+	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
+	repos := []gh.Repository{{Name: gh.Ptr("repo1")}}
+	failedMigration := &gh.Migration{
+		ID:    gh.Ptr(int64(1)),
+		State: gh.Ptr("failed"),
+	}
+	migration := &gh.Migration{
+		ID:    gh.Ptr(int64(2)),
+		State: gh.Ptr("exported"),
+	}
+	server := newArchiveServer(t, "mock archive content")
+
+	require.NoError(t, store.Save(context.Background(), MigrationState{
+		Organization: organization,
+		Contents:     contents,
+		StartedAt:    now.Add(-time.Hour),
+		Migrations:   []StartedMigration{{ID: 1, Repositories: []string{"repo1"}}},
+	}))
+
+	mocks.listRepositories.EXPECT().Do(mock.Anything, organization).Return(repos, nil)
+	mocks.githubClient.EXPECT().GetMigrationStatus(mock.Anything, organization, int64(1)).Return(failedMigration, nil)
+	mocks.githubClient.EXPECT().StartMigration(mock.Anything, organization, []string{"repo1"}, contents).Return(migration, nil)
+	mocks.githubClient.EXPECT().GetMigrationStatus(mock.Anything, organization, int64(2)).Return(migration, nil)
+	mocks.getOrganizationArchiveUrl.EXPECT().Do(mock.Anything, organization, int64(2)).Return(server.URL, nil)
+	mocks.saveBackupMock.On("Do", "mock archive content").Return("/tmp/backup.tar.gz", nil)
+
+	useCase := mocks.createUseCase().WithMigrationState(store, true)
+
+	// When
+	result, err := useCase.Do(context.Background(), organization, contents, time.Time{}, mocks.saveBackupFunc)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), result[0].MigrationID)
+}
+
+func TestCreateBackupUseCase_IgnoresSavedStateWithoutResume(t *testing.T) {
+	// Given
+	now := time.Date(2025, 7, 23, 9, 0, 0, 0, time.UTC)
+	useFakeClock(t, now)
+	mocks := newCreateBackupTestMocks(t)
+	store := NewBlobMigrationStateStore(filesystem.NewBlobRepository(t.TempDir()))
+
+	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
+	repos := []gh.Repository{{Name: gh.Ptr("repo1")}}
+	migration := &gh.Migration{
+		ID:    gh.Ptr(int64(2)),
+		State: gh.Ptr("exported"),
+	}
+	server := newArchiveServer(t, "mock archive content")
+
+	require.NoError(t, store.Save(context.Background(), MigrationState{
+		Organization: organization,
+		Contents:     contents,
+		StartedAt:    now.Add(-time.Hour),
+		Migrations:   []StartedMigration{{ID: 1, Repositories: []string{"repo1"}}},
+	}))
+
+	mocks.listRepositories.EXPECT().Do(mock.Anything, organization).Return(repos, nil)
+	mocks.githubClient.EXPECT().StartMigration(mock.Anything, organization, []string{"repo1"}, contents).Return(migration, nil)
+	mocks.githubClient.EXPECT().GetMigrationStatus(mock.Anything, organization, int64(2)).Return(migration, nil)
+	mocks.getOrganizationArchiveUrl.EXPECT().Do(mock.Anything, organization, int64(2)).Return(server.URL, nil)
+	mocks.saveBackupMock.On("Do", "mock archive content").Return("/tmp/backup.tar.gz", nil)
+
+	useCase := mocks.createUseCase().WithMigrationState(store, false)
+
+	// When
+	result, err := useCase.Do(context.Background(), organization, contents, time.Time{}, mocks.saveBackupFunc)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), result[0].MigrationID)
+}
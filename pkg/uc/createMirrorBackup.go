@@ -0,0 +1,177 @@
+package uc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	gh "github.com/google/go-github/v90/github"
+	"github.com/kumojin/repo-backup-cli/pkg/archive"
+	"github.com/kumojin/repo-backup-cli/pkg/download"
+	"github.com/kumojin/repo-backup-cli/pkg/git"
+	"github.com/kumojin/repo-backup-cli/pkg/github"
+	"github.com/kumojin/repo-backup-cli/pkg/logging"
+)
+
+type createMirrorBackupUseCase struct {
+	gitClient               git.Client
+	listRepositoriesUseCase ListRepositoriesUseCase
+	batchOptions            BatchOptions
+	recorder                RunRecorder
+}
+
+// NewCreateMirrorBackupUseCase creates a backup use case cloning the repositories with git instead of
+// relying on the GitHub migrations API. Each archive holds a bundle per repository under
+// repositories/<organization>/<repository>.bundle.
+func NewCreateMirrorBackupUseCase(
+	gitClient git.Client,
+	listRepositoriesUseCase ListRepositoriesUseCase,
+) CreateBackupUseCase {
+	return &createMirrorBackupUseCase{
+		gitClient:               gitClient,
+		listRepositoriesUseCase: listRepositoriesUseCase,
+		recorder:                noopRunRecorder{},
+	}
+}
+
+// WithPollingInterval is a no-op, mirror backups do not wait on GitHub
+func (uc *createMirrorBackupUseCase) WithPollingInterval(_ time.Duration) CreateBackupUseCase {
+	return uc
+}
+
+// WithDownloader is a no-op, mirror backups clone the repositories instead of downloading archives
+func (uc *createMirrorBackupUseCase) WithDownloader(_ download.Downloader) CreateBackupUseCase {
+	return uc
+}
+
+// WithMigrationState is a no-op, mirror backups do not start migrations
+func (uc *createMirrorBackupUseCase) WithMigrationState(_ MigrationStateStore, _ bool) CreateBackupUseCase {
+	return uc
+}
+
+// WithRecorder records the unchanged and the empty repositories and the duration of the clones as the download phase,
+// mirror backups do not start migrations
+func (uc *createMirrorBackupUseCase) WithRecorder(recorder RunRecorder) CreateBackupUseCase {
+	uc.recorder = recorder
+	return uc
+}
+
+func (uc *createMirrorBackupUseCase) WithBatchOptions(options BatchOptions) CreateBackupUseCase {
+	uc.batchOptions = options
+	return uc
+}
+
+// Do clones every repository of the organization, only git data is backed up so the migration contents are ignored
+func (uc *createMirrorBackupUseCase) Do(ctx context.Context, organization string, _ github.MigrationContents, changedSince time.Time, saveBackupFunc SaveBackupFunc) ([]BackupArchive, error) {
+	repos, err := uc.listRepositoriesUseCase.Do(ctx, organization)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+
+	recordUnchangedRepositories(uc.recorder, organization, repos, changedSince)
+	repos, err = changedRepositories(repos, changedSince)
+	if err != nil {
+		return nil, err
+	}
+
+	batches := splitIntoBatches(repos, uc.batchOptions)
+	if len(batches) == 0 {
+		return nil, ErrNoRepositories
+	}
+
+	archives := make([]BackupArchive, len(batches))
+	for i, batch := range batches {
+		archives[i] = BackupArchive{
+			Batch:      i + 1,
+			BatchCount: len(batches),
+		}
+
+		archives[i], err = uc.saveMirrorArchive(ctx, organization, archives[i], batch, saveBackupFunc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return archives, nil
+}
+
+func (uc *createMirrorBackupUseCase) saveMirrorArchive(
+	ctx context.Context,
+	organization string,
+	backupArchive BackupArchive,
+	repos []gh.Repository,
+	saveBackupFunc SaveBackupFunc,
+) (BackupArchive, error) {
+	logger := logging.NewLogger(ctx).With(
+		slog.String("organization", organization),
+		slog.Int("batch", backupArchive.Batch),
+		slog.Int("batchCount", backupArchive.BatchCount),
+	)
+
+	workDir, err := os.MkdirTemp("", "rbk-mirror-*")
+	if err != nil {
+		return backupArchive, fmt.Errorf("failed to create working directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(workDir) }()
+
+	archiveDir := filepath.Join(workDir, "archive")
+	bundleDir := filepath.Join(archiveDir, "repositories", organization)
+	if err := os.MkdirAll(bundleDir, 0o755); err != nil {
+		return backupArchive, fmt.Errorf("failed to create working directory: %w", err)
+	}
+
+	for _, repo := range repos {
+		mirrorPath := filepath.Join(workDir, "mirrors", repo.GetName()+".git")
+
+		logger.Info("cloning repository", slog.String("repository", repo.GetName()))
+
+		start := getCurrentTime()
+		if err := uc.gitClient.MirrorClone(ctx, repo.GetCloneURL(), mirrorPath); err != nil {
+			uc.recorder.RecordPhase(organization, PhaseDownload, getCurrentTime().Sub(start), err)
+			return backupArchive, fmt.Errorf("failed to clone repository %s: %w", repo.GetName(), err)
+		}
+
+		err := uc.gitClient.CreateBundle(ctx, mirrorPath, filepath.Join(bundleDir, repo.GetName()+".bundle"))
+		if !errors.Is(err, git.ErrEmptyRepository) {
+			uc.recorder.RecordPhase(organization, PhaseDownload, getCurrentTime().Sub(start), err)
+		}
+		if errors.Is(err, git.ErrEmptyRepository) {
+			logger.Warn("repository is empty, skipping it", slog.String("repository", repo.GetName()))
+			uc.recorder.RecordExcludedRepository(organization, ExcludedRepository{Name: repo.GetName(), Reason: "empty repository"})
+			continue
+		}
+		if err != nil {
+			return backupArchive, fmt.Errorf("failed to bundle repository %s: %w", repo.GetName(), err)
+		}
+
+		// Bundles hold all the data, the mirror is only kept until the bundle is written to limit disk usage
+		if err := os.RemoveAll(mirrorPath); err != nil {
+			return backupArchive, fmt.Errorf("failed to clean up repository %s: %w", repo.GetName(), err)
+		}
+
+		backupArchive.Repositories = append(backupArchive.Repositories, repo.GetName())
+	}
+
+	reader, writer := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = writer.CloseWithError(archive.WriteTarGz(writer, archiveDir))
+	}()
+
+	location, err := saveBackupFunc(backupArchive, reader)
+	_ = reader.Close()
+	<-done
+
+	if err != nil {
+		return backupArchive, err
+	}
+
+	backupArchive.Location = location
+	return backupArchive, nil
+}
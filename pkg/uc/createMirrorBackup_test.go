@@ -0,0 +1,186 @@
+package uc
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gh "github.com/google/go-github/v90/github"
+	"github.com/kumojin/repo-backup-cli/pkg/git"
+	"github.com/kumojin/repo-backup-cli/pkg/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newLocalGitRepository creates a git repository in a temporary directory and returns its file:// URL
+func newLocalGitRepository(t *testing.T, name string, withCommit bool) string {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	path := filepath.Join(t.TempDir(), name)
+	runGit(t, "", "init", "--quiet", path)
+
+	if withCommit {
+		runGit(t, path, "-c", "user.name=rbk", "-c", "user.email=rbk@example.com", "commit", "--quiet", "--allow-empty", "-m", "initial commit")
+	}
+
+	return "file://" + path
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+}
+
+// listTarGzEntries returns the names of the regular files in a gzipped tarball
+func listTarGzEntries(t *testing.T, reader io.Reader) []string {
+	t.Helper()
+
+	gzipReader, err := gzip.NewReader(reader)
+	require.NoError(t, err)
+
+	var names []string
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+
+		if header.Typeflag == tar.TypeReg {
+			names = append(names, header.Name)
+		}
+	}
+
+	return names
+}
+
+func TestCreateMirrorBackupUseCase_Success(t *testing.T) {
+	// Given
+	organization := "kumojin"
+	listRepositories := NewMockListRepositoriesUseCase(t)
+	repos := []gh.Repository{
+		{Name: gh.Ptr("repo1"), CloneURL: gh.Ptr(newLocalGitRepository(t, "repo1", true))},
+		{Name: gh.Ptr("empty"), CloneURL: gh.Ptr(newLocalGitRepository(t, "empty", false))},
+		{Name: gh.Ptr("repo2"), CloneURL: gh.Ptr(newLocalGitRepository(t, "repo2", true))},
+	}
+
+	listRepositories.EXPECT().Do(mock.Anything, organization).Return(repos, nil)
+
+	var entries []string
+	saveBackupFunc := func(archive BackupArchive, reader io.Reader) (string, error) {
+		entries = listTarGzEntries(t, reader)
+		return "/tmp/backup.tar.gz", nil
+	}
+
+	useCase := NewCreateMirrorBackupUseCase(git.NewClient(""), listRepositories)
+
+	// When
+	result, err := useCase.Do(context.Background(), organization, github.DefaultMigrationContents(), time.Time{}, saveBackupFunc)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []BackupArchive{
+		{Batch: 1, BatchCount: 1, Repositories: []string{"repo1", "repo2"}, Location: "/tmp/backup.tar.gz"},
+	}, result)
+	assert.ElementsMatch(t, []string{
+		"repositories/kumojin/repo1.bundle",
+		"repositories/kumojin/repo2.bundle",
+	}, entries)
+}
+
+func TestCreateMirrorBackupUseCase_Batches(t *testing.T) {
+	// Given
+	organization := "kumojin"
+	listRepositories := NewMockListRepositoriesUseCase(t)
+	repos := []gh.Repository{
+		{Name: gh.Ptr("repo1"), CloneURL: gh.Ptr(newLocalGitRepository(t, "repo1", true))},
+		{Name: gh.Ptr("repo2"), CloneURL: gh.Ptr(newLocalGitRepository(t, "repo2", true))},
+	}
+
+	listRepositories.EXPECT().Do(mock.Anything, organization).Return(repos, nil)
+
+	var entries [][]string
+	saveBackupFunc := func(archive BackupArchive, reader io.Reader) (string, error) {
+		entries = append(entries, listTarGzEntries(t, reader))
+		return archive.FileName("backup"), nil
+	}
+
+	useCase := NewCreateMirrorBackupUseCase(git.NewClient(""), listRepositories).
+		WithBatchOptions(BatchOptions{MaxRepositories: 1})
+
+	// When
+	result, err := useCase.Do(context.Background(), organization, github.DefaultMigrationContents(), time.Time{}, saveBackupFunc)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []BackupArchive{
+		{Batch: 1, BatchCount: 2, Repositories: []string{"repo1"}, Location: "backup-batch-1-of-2.tar.gz"},
+		{Batch: 2, BatchCount: 2, Repositories: []string{"repo2"}, Location: "backup-batch-2-of-2.tar.gz"},
+	}, result)
+	assert.Equal(t, [][]string{
+		{"repositories/kumojin/repo1.bundle"},
+		{"repositories/kumojin/repo2.bundle"},
+	}, entries)
+}
+
+func TestCreateMirrorBackupUseCase_CloneError(t *testing.T) {
+	// Given
+	organization := "kumojin"
+	listRepositories := NewMockListRepositoriesUseCase(t)
+	gitClient := git.NewMockClient(t)
+	expectedError := errors.New("authentication failed")
+
+	listRepositories.EXPECT().Do(mock.Anything, organization).Return([]gh.Repository{
+		{Name: gh.Ptr("repo1"), CloneURL: gh.Ptr("https://github.com/kumojin/repo1.git")},
+	}, nil)
+
+	gitClient.EXPECT().
+		MirrorClone(mock.Anything, "https://github.com/kumojin/repo1.git", mock.AnythingOfType("string")).
+		Return(expectedError)
+
+	useCase := NewCreateMirrorBackupUseCase(gitClient, listRepositories)
+
+	// When
+	result, err := useCase.Do(context.Background(), organization, github.DefaultMigrationContents(), time.Time{}, func(BackupArchive, io.Reader) (string, error) {
+		t.Fatal("no archive should be saved")
+		return "", nil
+	})
+
+	// Then
+	assert.ErrorIs(t, err, expectedError)
+	assert.Empty(t, result)
+}
+
+func TestCreateMirrorBackupUseCase_ListRepositoriesError(t *testing.T) {
+	// Given
+	organization := "kumojin"
+	listRepositories := NewMockListRepositoriesUseCase(t)
+	expectedError := errors.New("failed to list repositories")
+
+	listRepositories.EXPECT().Do(mock.Anything, organization).Return(nil, expectedError)
+
+	useCase := NewCreateMirrorBackupUseCase(git.NewMockClient(t), listRepositories)
+
+	// When
+	result, err := useCase.Do(context.Background(), organization, github.DefaultMigrationContents(), time.Time{}, nil)
+
+	// Then
+	assert.ErrorIs(t, err, expectedError)
+	assert.Empty(t, result)
+}
@@ -1,23 +1,48 @@
 package uc
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"sort"
+	"sync"
 	"time"
 
+	"github.com/kumojin/repo-backup-cli/pkg/encryption"
+	"github.com/kumojin/repo-backup-cli/pkg/github"
+	"github.com/kumojin/repo-backup-cli/pkg/logging"
+	"github.com/kumojin/repo-backup-cli/pkg/migration"
 	"github.com/kumojin/repo-backup-cli/pkg/storage"
 )
 
 var getCurrentTime = time.Now
 
 type CreateRemoteBackupUseCase interface {
-	Do(ctx context.Context, organization string) (string, error)
+	// WithEncryptor encrypts the archives for the recipients of encryptor before saving them
+	WithEncryptor(encryptor encryption.Encryptor) CreateRemoteBackupUseCase
+	// WithCatalog adds the saved archives to the catalog once the backup succeeded
+	WithCatalog(catalog CatalogStore) CreateRemoteBackupUseCase
+	// WithSplitRepositories also saves an archive per repository, see splitSaveBackupFunc
+	WithSplitRepositories(splitRepositories bool) CreateRemoteBackupUseCase
+	// WithIncremental makes a full backup every fullIntervalDays and incremental backups of the repositories changed
+	// since the full backup in between, it needs the catalog. Every backup is full when fullIntervalDays is 0.
+	WithIncremental(fullIntervalDays int) CreateRemoteBackupUseCase
+	// WithRecorder records the plan of the backups, the saved archives and the duration of the uploads
+	WithRecorder(recorder RunRecorder) CreateRemoteBackupUseCase
+	Do(ctx context.Context, organization string, contents github.MigrationContents) (string, error)
 }
 
 type createRemoteBackupUseCase struct {
 	blobRepository      storage.BlobRepository
 	createBackupUseCase CreateBackupUseCase
+	encryptor           encryption.Encryptor
+	catalog             CatalogStore
+	splitRepositories   bool
+	fullIntervalDays    int
+	recorder            RunRecorder
 }
 
 func NewCreateRemoteBackupUseCase(
@@ -27,14 +52,172 @@ func NewCreateRemoteBackupUseCase(
 	return &createRemoteBackupUseCase{
 		blobRepository:      blobRepository,
 		createBackupUseCase: createBackupUseCase,
+		recorder:            noopRunRecorder{},
 	}
 }
 
-func (uc *createRemoteBackupUseCase) Do(ctx context.Context, organization string) (string, error) {
-	saveMigrationArchive := func(reader io.Reader) (string, error) {
-		blobName := fmt.Sprintf("%s-%s-migration.tar.gz", getCurrentTime().Format(time.DateOnly), organization)
-		return uc.blobRepository.Upload(ctx, blobName, reader)
+func (uc *createRemoteBackupUseCase) WithEncryptor(encryptor encryption.Encryptor) CreateRemoteBackupUseCase {
+	uc.encryptor = encryptor
+	return uc
+}
+
+func (uc *createRemoteBackupUseCase) WithCatalog(catalog CatalogStore) CreateRemoteBackupUseCase {
+	uc.catalog = catalog
+	return uc
+}
+
+func (uc *createRemoteBackupUseCase) WithSplitRepositories(splitRepositories bool) CreateRemoteBackupUseCase {
+	uc.splitRepositories = splitRepositories
+	return uc
+}
+
+func (uc *createRemoteBackupUseCase) WithIncremental(fullIntervalDays int) CreateRemoteBackupUseCase {
+	uc.fullIntervalDays = fullIntervalDays
+	return uc
+}
+
+func (uc *createRemoteBackupUseCase) WithRecorder(recorder RunRecorder) CreateRemoteBackupUseCase {
+	uc.recorder = recorder
+	return uc
+}
+
+func (uc *createRemoteBackupUseCase) Do(ctx context.Context, organization string, contents github.MigrationContents) (string, error) {
+	logger := logging.NewLogger(ctx).With(slog.String("organization", organization))
+
+	baseName := fmt.Sprintf("%s-%s-migration", getCurrentTime().Format(time.DateOnly), organization)
+
+	backup, err := uc.planBackup(ctx, organization, baseName)
+	if err != nil {
+		return "", err
+	}
+	uc.recorder.RecordPlan(organization, backup.Plan.Type, backup.Plan.Base)
+	if backup.Plan.Type == BackupTypeIncremental {
+		logger.Info("making an incremental backup",
+			slog.String("base", backup.Plan.Base),
+			slog.Time("changedSince", backup.Plan.ChangedSince),
+		)
+	}
+
+	// The archives of the batches are saved at the same time
+	var entriesMu sync.Mutex
+	var entries []CatalogEntry
+
+	saveMigrationArchive := func(archive BackupArchive, reader io.Reader) (string, error) {
+		blobName := encryptedFileName(archive, baseName, uc.encryptor)
+		start := getCurrentTime()
+
+		location, checksum, err := uc.upload(ctx, organization, blobName, archive, reader, backup.Plan)
+		if err != nil {
+			return "", err
+		}
+		uc.recorder.RecordArchive(organization, newArchiveReport(blobName, archive, checksum, location, getCurrentTime().Sub(start)))
+
+		entriesMu.Lock()
+		entries = append(entries, newCatalogEntry(organization, blobName, archive, checksum, location, backup))
+		entriesMu.Unlock()
+
+		return location, nil
+	}
+
+	saveBackupFunc := encryptSaveBackupFunc(uc.encryptor, saveMigrationArchive)
+	if uc.splitRepositories {
+		saveBackupFunc = splitSaveBackupFunc(saveBackupFunc, func(archive BackupArchive, part migration.Part) error {
+			entry, err := uc.saveRepositoryArchive(ctx, organization, archive, part, backup)
+			if err != nil {
+				return err
+			}
+
+			entriesMu.Lock()
+			entries = append(entries, entry)
+			entriesMu.Unlock()
+
+			return nil
+		})
+	}
+
+	archives, err := uc.createBackupUseCase.Do(ctx, organization, contents, backup.Plan.ChangedSince, saveBackupFunc)
+	if errors.Is(err, ErrNoChangedRepositories) {
+		logger.Info("no repository changed since the full backup, nothing to save")
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	location := archives[0].Location
+	if len(archives) > 1 {
+		manifest, err := marshalBatchManifest(organization, archives, backup.Plan)
+		if err != nil {
+			return "", err
+		}
+
+		location, err = uc.blobRepository.Upload(ctx, batchManifestFileName(baseName), bytes.NewReader(manifest))
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if uc.catalog != nil {
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].ID < entries[j].ID
+		})
+
+		if err := uc.catalog.Add(ctx, entries); err != nil {
+			return "", fmt.Errorf("failed to update backup catalog: %w", err)
+		}
+	}
+
+	return location, nil
+}
+
+// planBackup plans the backup named baseName from the catalog, every backup is full when incremental backups
+// are disabled
+func (uc *createRemoteBackupUseCase) planBackup(ctx context.Context, organization string, baseName string) (backupRun, error) {
+	backup := backupRun{
+		Name:      baseName,
+		StartedAt: getCurrentTime().UTC(),
+		Plan:      backupPlan{Type: BackupTypeFull},
+	}
+
+	if uc.fullIntervalDays <= 0 || uc.catalog == nil {
+		return backup, nil
+	}
+
+	entries, err := uc.catalog.List(ctx)
+	if err != nil {
+		return backupRun{}, fmt.Errorf("failed to plan incremental backup: %w", err)
+	}
+	backup.Plan = planBackup(entries, organization, baseName, uc.fullIntervalDays, backup.StartedAt)
+
+	return backup, nil
+}
+
+// upload saves the content of reader in the blob along with its integrity manifest and records the upload phase
+func (uc *createRemoteBackupUseCase) upload(ctx context.Context, organization string, blobName string, archive BackupArchive, reader io.Reader, plan backupPlan) (string, *checksumReader, error) {
+	start := getCurrentTime()
+	location, checksum, err := uc.uploadWithManifest(ctx, organization, blobName, archive, reader, plan)
+	uc.recorder.RecordPhase(organization, PhaseUpload, getCurrentTime().Sub(start), err)
+
+	return location, checksum, err
+}
+
+// uploadWithManifest saves the content of reader in the blob, then its integrity manifest
+func (uc *createRemoteBackupUseCase) uploadWithManifest(ctx context.Context, organization string, blobName string, archive BackupArchive, reader io.Reader, plan backupPlan) (string, *checksumReader, error) {
+	checksum := newChecksumReader(reader)
+
+	location, err := uc.blobRepository.Upload(ctx, blobName, checksum)
+	if err != nil {
+		return "", nil, err
+	}
+
+	manifest, err := marshalIntegrityManifest(organization, blobName, archive, checksum, plan)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if _, err := uc.blobRepository.Upload(ctx, integrityManifestFileName(blobName), bytes.NewReader(manifest)); err != nil {
+		return "", nil, fmt.Errorf("failed to upload integrity manifest: %w", err)
 	}
 
-	return uc.createBackupUseCase.Do(ctx, organization, saveMigrationArchive)
+	return location, checksum, nil
 }
@@ -1,16 +1,25 @@
 package uc
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"filippo.io/age"
+	"github.com/kumojin/repo-backup-cli/pkg/encryption"
+	"github.com/kumojin/repo-backup-cli/pkg/github"
 	"github.com/kumojin/repo-backup-cli/pkg/storage"
+	"github.com/kumojin/repo-backup-cli/pkg/storage/filesystem"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // createRemoteBackupTestMocks contains all the mocks used in tests
@@ -32,6 +41,13 @@ func newCreateRemoteBackupTestMocks(t *testing.T) *createRemoteBackupTestMocks {
 	}
 }
 
+// expectIntegrityManifestUpload expects the upload of the integrity manifest of the blob
+func (m *createRemoteBackupTestMocks) expectIntegrityManifestUpload(blobName string) {
+	m.blobRepository.EXPECT().
+		Upload(mock.Anything, blobName+".manifest.json", mock.AnythingOfType("*bytes.Reader")).
+		Return("https://storage.azure.com/blob/"+blobName+".manifest.json", nil)
+}
+
 // createUseCase creates a CreateRemoteBackupUseCase with the mocks
 func (m *createRemoteBackupTestMocks) createUseCase() CreateRemoteBackupUseCase {
 	return NewCreateRemoteBackupUseCase(
@@ -44,32 +60,34 @@ func TestCreateRemoteBackupUseCase_Success(t *testing.T) {
 	// Given
 	mocks := newCreateRemoteBackupTestMocks(t)
 	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
 	archiveContent := "mock archive content"
 	expectedBlobURL := "https://storage.azure.com/blob/2025-07-23-org-migration.tar.gz"
 
 	mocks.createBackupUseCase.EXPECT().
-		Do(mock.Anything, organization, mock.AnythingOfType("uc.SaveBackupFunc")).
-		Run(func(ctx context.Context, org string, saveFunc SaveBackupFunc) {
+		Do(mock.Anything, organization, contents, time.Time{}, mock.AnythingOfType("uc.SaveBackupFunc")).
+		Run(func(ctx context.Context, org string, _ github.MigrationContents, _ time.Time, saveFunc SaveBackupFunc) {
 			reader := strings.NewReader(archiveContent)
-			_, _ = saveFunc(reader)
+			_, _ = saveFunc(BackupArchive{Batch: 1, BatchCount: 1}, reader)
 		}).
-		Return(expectedBlobURL, nil)
+		Return([]BackupArchive{{Batch: 1, BatchCount: 1, Location: expectedBlobURL}}, nil)
 
 	mocks.blobRepository.EXPECT().
 		Upload(mock.Anything, mock.MatchedBy(func(blobName string) bool {
 			return strings.Contains(blobName, "kumojin-migration.tar.gz")
-		}), mock.AnythingOfType("*strings.Reader")).
+		}), mock.AnythingOfType("*uc.checksumReader")).
 		Run(func(ctx context.Context, blobName string, reader io.Reader) {
 			content, err := io.ReadAll(reader)
 			assert.NoError(t, err)
 			assert.Equal(t, archiveContent, string(content))
 		}).
 		Return(expectedBlobURL, nil)
+	mocks.expectIntegrityManifestUpload("2025-07-23-kumojin-migration.tar.gz")
 
 	useCase := mocks.createUseCase()
 
 	// When
-	result, err := useCase.Do(context.Background(), organization)
+	result, err := useCase.Do(context.Background(), organization, contents)
 
 	// Then
 	assert.NoError(t, err)
@@ -80,16 +98,17 @@ func TestCreateRemoteBackupUseCase_CreateBackupError(t *testing.T) {
 	// Given
 	mocks := newCreateRemoteBackupTestMocks(t)
 	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
 	expectedError := errors.New("failed to create backup")
 
 	mocks.createBackupUseCase.EXPECT().
-		Do(mock.Anything, organization, mock.AnythingOfType("uc.SaveBackupFunc")).
-		Return("", expectedError)
+		Do(mock.Anything, organization, contents, time.Time{}, mock.AnythingOfType("uc.SaveBackupFunc")).
+		Return(nil, expectedError)
 
 	useCase := mocks.createUseCase()
 
 	// When
-	result, err := useCase.Do(context.Background(), organization)
+	result, err := useCase.Do(context.Background(), organization, contents)
 
 	// Then
 	assert.ErrorIs(t, err, expectedError)
@@ -100,28 +119,29 @@ func TestCreateRemoteBackupUseCase_BlobUploadError(t *testing.T) {
 	// Given
 	mocks := newCreateRemoteBackupTestMocks(t)
 	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
 	archiveContent := "mock archive content"
 	uploadError := errors.New("failed to upload blob")
 
 	mocks.createBackupUseCase.EXPECT().
-		Do(mock.Anything, organization, mock.AnythingOfType("uc.SaveBackupFunc")).
-		Run(func(ctx context.Context, org string, saveFunc SaveBackupFunc) {
+		Do(mock.Anything, organization, contents, time.Time{}, mock.AnythingOfType("uc.SaveBackupFunc")).
+		Run(func(ctx context.Context, org string, _ github.MigrationContents, _ time.Time, saveFunc SaveBackupFunc) {
 			reader := strings.NewReader(archiveContent)
-			_, err := saveFunc(reader)
+			_, err := saveFunc(BackupArchive{Batch: 1, BatchCount: 1}, reader)
 			assert.ErrorIs(t, err, uploadError)
 		}).
-		Return("", uploadError)
+		Return(nil, uploadError)
 
 	mocks.blobRepository.EXPECT().
 		Upload(mock.Anything, mock.MatchedBy(func(blobName string) bool {
 			return strings.Contains(blobName, "kumojin-migration.tar.gz")
-		}), mock.AnythingOfType("*strings.Reader")).
+		}), mock.AnythingOfType("*uc.checksumReader")).
 		Return("", uploadError)
 
 	useCase := mocks.createUseCase()
 
 	// When
-	result, err := useCase.Do(context.Background(), organization)
+	result, err := useCase.Do(context.Background(), organization, contents)
 
 	// Then
 	assert.ErrorIs(t, err, uploadError)
@@ -132,33 +152,418 @@ func TestCreateRemoteBackupUseCase_BlobNameFormat(t *testing.T) {
 	// Given
 	mocks := newCreateRemoteBackupTestMocks(t)
 	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
 	archiveContent := "mock archive content"
 	expectedBlobURL := "https://storage.azure.com/blob/test-blob.tar.gz"
 
 	var capturedBlobName string
 
 	mocks.createBackupUseCase.EXPECT().
-		Do(mock.Anything, organization, mock.AnythingOfType("uc.SaveBackupFunc")).
-		Run(func(ctx context.Context, org string, saveFunc SaveBackupFunc) {
+		Do(mock.Anything, organization, contents, time.Time{}, mock.AnythingOfType("uc.SaveBackupFunc")).
+		Run(func(ctx context.Context, org string, _ github.MigrationContents, _ time.Time, saveFunc SaveBackupFunc) {
 			reader := strings.NewReader(archiveContent)
-			_, _ = saveFunc(reader)
+			_, _ = saveFunc(BackupArchive{Batch: 1, BatchCount: 1}, reader)
 		}).
-		Return(expectedBlobURL, nil)
+		Return([]BackupArchive{{Batch: 1, BatchCount: 1, Location: expectedBlobURL}}, nil)
 
 	mocks.blobRepository.EXPECT().
-		Upload(mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("*strings.Reader")).
+		Upload(mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("*uc.checksumReader")).
 		Run(func(ctx context.Context, blobName string, reader io.Reader) {
 			capturedBlobName = blobName
 		}).
-		Return(expectedBlobURL, nil)
+		Return(expectedBlobURL, nil).
+		Once()
+	mocks.expectIntegrityManifestUpload("2025-07-23-kumojin-migration.tar.gz")
 
 	useCase := mocks.createUseCase()
 
 	// When
-	result, err := useCase.Do(context.Background(), organization)
+	result, err := useCase.Do(context.Background(), organization, contents)
 
 	// Then
 	assert.NoError(t, err)
 	assert.Equal(t, expectedBlobURL, result)
 	assert.Equal(t, capturedBlobName, "2025-07-23-kumojin-migration.tar.gz")
 }
+
+func TestCreateRemoteBackupUseCase_BatchesUploadManifest(t *testing.T) {
+	// Given
+	mocks := newCreateRemoteBackupTestMocks(t)
+	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
+	archives := []BackupArchive{
+		{Batch: 1, BatchCount: 2, MigrationID: 1, Repositories: []string{"repo1"}},
+		{Batch: 2, BatchCount: 2, MigrationID: 2, Repositories: []string{"repo2"}},
+	}
+	expectedManifestURL := "https://storage.azure.com/blob/2025-07-23-kumojin-migration-batches.json"
+
+	var uploadedNames []string
+	var manifest BatchManifest
+
+	mocks.createBackupUseCase.EXPECT().
+		Do(mock.Anything, organization, contents, time.Time{}, mock.AnythingOfType("uc.SaveBackupFunc")).
+		RunAndReturn(func(ctx context.Context, org string, _ github.MigrationContents, _ time.Time, saveFunc SaveBackupFunc) ([]BackupArchive, error) {
+			for i := range archives {
+				location, err := saveFunc(archives[i], strings.NewReader("mock archive content"))
+				assert.NoError(t, err)
+				archives[i].Location = location
+			}
+			return archives, nil
+		})
+
+	mocks.blobRepository.EXPECT().
+		Upload(mock.Anything, mock.MatchedBy(func(blobName string) bool {
+			return strings.HasSuffix(blobName, ".tar.gz")
+		}), mock.Anything).
+		RunAndReturn(func(ctx context.Context, blobName string, reader io.Reader) (string, error) {
+			uploadedNames = append(uploadedNames, blobName)
+			return "https://storage.azure.com/blob/" + blobName, nil
+		}).
+		Twice()
+	mocks.expectIntegrityManifestUpload("2025-07-23-kumojin-migration-batch-1-of-2.tar.gz")
+	mocks.expectIntegrityManifestUpload("2025-07-23-kumojin-migration-batch-2-of-2.tar.gz")
+
+	mocks.blobRepository.EXPECT().
+		Upload(mock.Anything, "2025-07-23-kumojin-migration-batches.json", mock.Anything).
+		Run(func(ctx context.Context, blobName string, reader io.Reader) {
+			assert.NoError(t, json.NewDecoder(reader).Decode(&manifest))
+		}).
+		Return(expectedManifestURL, nil)
+
+	useCase := mocks.createUseCase()
+
+	// When
+	result, err := useCase.Do(context.Background(), organization, contents)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, expectedManifestURL, result)
+	assert.Equal(t, []string{
+		"2025-07-23-kumojin-migration-batch-1-of-2.tar.gz",
+		"2025-07-23-kumojin-migration-batch-2-of-2.tar.gz",
+	}, uploadedNames)
+	assert.Equal(t, organization, manifest.Organization)
+	assert.Len(t, manifest.Archives, 2)
+	assert.Equal(t, "https://storage.azure.com/blob/2025-07-23-kumojin-migration-batch-2-of-2.tar.gz", manifest.Archives[1].Location)
+}
+
+func TestCreateRemoteBackupUseCase_Encrypted(t *testing.T) {
+	// Given
+	mocks := newCreateRemoteBackupTestMocks(t)
+	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
+	archiveContent := "mock archive content"
+	expectedBlobURL := "https://storage.azure.com/blob/2025-07-23-kumojin-migration.tar.gz.age"
+
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	encryptor, err := encryption.NewAgeEncryptor([]string{identity.Recipient().String()})
+	require.NoError(t, err)
+
+	var uploaded []byte
+
+	mocks.createBackupUseCase.EXPECT().
+		Do(mock.Anything, organization, contents, time.Time{}, mock.AnythingOfType("uc.SaveBackupFunc")).
+		RunAndReturn(func(ctx context.Context, org string, _ github.MigrationContents, _ time.Time, saveFunc SaveBackupFunc) ([]BackupArchive, error) {
+			location, err := saveFunc(BackupArchive{Batch: 1, BatchCount: 1}, strings.NewReader(archiveContent))
+			return []BackupArchive{{Batch: 1, BatchCount: 1, Location: location}}, err
+		})
+
+	mocks.blobRepository.EXPECT().
+		Upload(mock.Anything, "2025-07-23-kumojin-migration.tar.gz.age", mock.Anything).
+		RunAndReturn(func(ctx context.Context, blobName string, reader io.Reader) (string, error) {
+			uploaded, err = io.ReadAll(reader)
+			return expectedBlobURL, err
+		})
+	mocks.expectIntegrityManifestUpload("2025-07-23-kumojin-migration.tar.gz.age")
+
+	useCase := mocks.createUseCase().WithEncryptor(encryptor)
+
+	// When
+	result, err := useCase.Do(context.Background(), organization, contents)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, expectedBlobURL, result)
+	assert.NotContains(t, string(uploaded), archiveContent)
+
+	decrypted, err := age.Decrypt(bytes.NewReader(uploaded), identity)
+	require.NoError(t, err)
+	plaintext, err := io.ReadAll(decrypted)
+	require.NoError(t, err)
+	assert.Equal(t, archiveContent, string(plaintext))
+}
+
+func TestCreateRemoteBackupUseCase_IntegrityManifest(t *testing.T) {
+	// Given
+	mocks := newCreateRemoteBackupTestMocks(t)
+	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
+	archiveContent := "mock archive content"
+
+	var manifest IntegrityManifest
+
+	mocks.createBackupUseCase.EXPECT().
+		Do(mock.Anything, organization, contents, time.Time{}, mock.AnythingOfType("uc.SaveBackupFunc")).
+		RunAndReturn(func(ctx context.Context, org string, _ github.MigrationContents, _ time.Time, saveFunc SaveBackupFunc) ([]BackupArchive, error) {
+			archive := BackupArchive{Batch: 1, BatchCount: 1, Repositories: []string{"repo1", "repo2"}}
+			location, err := saveFunc(archive, strings.NewReader(archiveContent))
+			archive.Location = location
+			return []BackupArchive{archive}, err
+		})
+
+	mocks.blobRepository.EXPECT().
+		Upload(mock.Anything, "2025-07-23-kumojin-migration.tar.gz", mock.Anything).
+		RunAndReturn(func(ctx context.Context, blobName string, reader io.Reader) (string, error) {
+			_, err := io.Copy(io.Discard, reader)
+			return "https://storage.azure.com/blob/" + blobName, err
+		})
+
+	mocks.blobRepository.EXPECT().
+		Upload(mock.Anything, "2025-07-23-kumojin-migration.tar.gz.manifest.json", mock.Anything).
+		RunAndReturn(func(ctx context.Context, blobName string, reader io.Reader) (string, error) {
+			return "https://storage.azure.com/blob/" + blobName, json.NewDecoder(reader).Decode(&manifest)
+		})
+
+	useCase := mocks.createUseCase()
+
+	// When
+	_, err := useCase.Do(context.Background(), organization, contents)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, IntegrityManifest{
+		Archive:      "2025-07-23-kumojin-migration.tar.gz",
+		Organization: organization,
+		CreatedAt:    time.Date(2025, 7, 23, 0, 0, 0, 0, time.UTC),
+		SHA256:       "b10c4854966ae4b7549a4f1bf964eb09d76b2a9510d543acb81d50c9bbb6e88d",
+		Size:         int64(len(archiveContent)),
+		Repositories: []string{"repo1", "repo2"},
+		Type:         BackupTypeFull,
+	}, manifest)
+}
+
+func TestCreateRemoteBackupUseCase_FilesystemRepository(t *testing.T) {
+	// Given
+	mocks := newCreateRemoteBackupTestMocks(t)
+	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
+	archiveContent := "mock archive content"
+
+	root := t.TempDir()
+	archivePath := filepath.Join(root, "2025-07-23-kumojin-migration.tar.gz")
+
+	mocks.createBackupUseCase.EXPECT().
+		Do(mock.Anything, organization, contents, time.Time{}, mock.AnythingOfType("uc.SaveBackupFunc")).
+		RunAndReturn(func(_ context.Context, _ string, _ github.MigrationContents, _ time.Time, saveFunc SaveBackupFunc) ([]BackupArchive, error) {
+			location, err := saveFunc(BackupArchive{Batch: 1, BatchCount: 1}, strings.NewReader(archiveContent))
+			return []BackupArchive{{Batch: 1, BatchCount: 1, Location: location}}, err
+		})
+
+	useCase := NewCreateRemoteBackupUseCase(filesystem.NewBlobRepository(root), mocks.createBackupUseCase)
+
+	// When
+	result, err := useCase.Do(context.Background(), organization, contents)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, archivePath, result)
+
+	content, err := os.ReadFile(archivePath)
+	require.NoError(t, err)
+	assert.Equal(t, archiveContent, string(content))
+
+	manifestContent, err := os.ReadFile(archivePath + ".manifest.json")
+	require.NoError(t, err)
+	var manifest IntegrityManifest
+	require.NoError(t, json.Unmarshal(manifestContent, &manifest))
+	assert.Equal(t, "2025-07-23-kumojin-migration.tar.gz", manifest.Archive)
+	assert.Equal(t, "b10c4854966ae4b7549a4f1bf964eb09d76b2a9510d543acb81d50c9bbb6e88d", manifest.SHA256)
+	assert.Equal(t, int64(len(archiveContent)), manifest.Size)
+}
+
+func TestCreateRemoteBackupUseCase_Catalog(t *testing.T) {
+	// Given
+	mocks := newCreateRemoteBackupTestMocks(t)
+	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
+	archiveContent := "mock archive content"
+
+	root := t.TempDir()
+	blobRepository := filesystem.NewBlobRepository(root)
+
+	mocks.createBackupUseCase.EXPECT().
+		Do(mock.Anything, organization, contents, time.Time{}, mock.AnythingOfType("uc.SaveBackupFunc")).
+		RunAndReturn(func(_ context.Context, _ string, _ github.MigrationContents, _ time.Time, saveFunc SaveBackupFunc) ([]BackupArchive, error) {
+			archives := []BackupArchive{
+				{Batch: 1, BatchCount: 2, MigrationID: 1, Repositories: []string{"repo1"}},
+				{Batch: 2, BatchCount: 2, MigrationID: 2, Repositories: []string{"repo2"}},
+			}
+			// The batches are saved in any order
+			for _, i := range []int{1, 0} {
+				location, err := saveFunc(archives[i], strings.NewReader(archiveContent))
+				if err != nil {
+					return nil, err
+				}
+				archives[i].Location = location
+			}
+			return archives, nil
+		})
+
+	catalog := NewBlobCatalogStore(blobRepository)
+	useCase := NewCreateRemoteBackupUseCase(blobRepository, mocks.createBackupUseCase).WithCatalog(catalog)
+
+	// When
+	_, err := useCase.Do(context.Background(), organization, contents)
+
+	// Then
+	require.NoError(t, err)
+
+	entries, err := catalog.List(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []CatalogEntry{
+		{
+			ID:           "2025-07-23-kumojin-migration-batch-1-of-2.tar.gz",
+			Organization: organization,
+			CreatedAt:    time.Date(2025, 7, 23, 0, 0, 0, 0, time.UTC),
+			MigrationID:  1,
+			Repositories: []string{"repo1"},
+			SHA256:       "b10c4854966ae4b7549a4f1bf964eb09d76b2a9510d543acb81d50c9bbb6e88d",
+			Size:         int64(len(archiveContent)),
+			Location:     filepath.Join(root, "2025-07-23-kumojin-migration-batch-1-of-2.tar.gz"),
+			Backup:       "2025-07-23-kumojin-migration",
+			StartedAt:    time.Date(2025, 7, 23, 0, 0, 0, 0, time.UTC),
+			Type:         BackupTypeFull,
+		},
+		{
+			ID:           "2025-07-23-kumojin-migration-batch-2-of-2.tar.gz",
+			Organization: organization,
+			CreatedAt:    time.Date(2025, 7, 23, 0, 0, 0, 0, time.UTC),
+			MigrationID:  2,
+			Repositories: []string{"repo2"},
+			SHA256:       "b10c4854966ae4b7549a4f1bf964eb09d76b2a9510d543acb81d50c9bbb6e88d",
+			Size:         int64(len(archiveContent)),
+			Location:     filepath.Join(root, "2025-07-23-kumojin-migration-batch-2-of-2.tar.gz"),
+			Backup:       "2025-07-23-kumojin-migration",
+			StartedAt:    time.Date(2025, 7, 23, 0, 0, 0, 0, time.UTC),
+			Type:         BackupTypeFull,
+		},
+	}, entries)
+}
+
+func TestCreateRemoteBackupUseCase_CatalogNotUpdatedOnFailure(t *testing.T) {
+	// Given
+	mocks := newCreateRemoteBackupTestMocks(t)
+	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
+	expectedError := errors.New("migration failed")
+
+	blobRepository := filesystem.NewBlobRepository(t.TempDir())
+
+	mocks.createBackupUseCase.EXPECT().
+		Do(mock.Anything, organization, contents, time.Time{}, mock.AnythingOfType("uc.SaveBackupFunc")).
+		RunAndReturn(func(_ context.Context, _ string, _ github.MigrationContents, _ time.Time, saveFunc SaveBackupFunc) ([]BackupArchive, error) {
+			_, err := saveFunc(BackupArchive{Batch: 1, BatchCount: 2}, strings.NewReader("mock archive content"))
+			require.NoError(t, err)
+			return nil, expectedError
+		})
+
+	catalog := NewBlobCatalogStore(blobRepository)
+	useCase := NewCreateRemoteBackupUseCase(blobRepository, mocks.createBackupUseCase).WithCatalog(catalog)
+
+	// When
+	_, err := useCase.Do(context.Background(), organization, contents)
+
+	// Then
+	assert.ErrorIs(t, err, expectedError)
+
+	entries, err := catalog.List(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestCreateRemoteBackupUseCase_Incremental(t *testing.T) {
+	// Given
+	mocks := newCreateRemoteBackupTestMocks(t)
+	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
+	fullStartedAt := time.Date(2025, 7, 21, 2, 0, 0, 0, time.UTC)
+
+	root := t.TempDir()
+	blobRepository := filesystem.NewBlobRepository(root)
+	catalog := NewBlobCatalogStore(blobRepository)
+	require.NoError(t, catalog.Add(context.Background(), []CatalogEntry{{
+		ID:           "2025-07-21-kumojin-migration.tar.gz",
+		Organization: organization,
+		CreatedAt:    fullStartedAt.Add(time.Hour),
+		Backup:       "2025-07-21-kumojin-migration",
+		StartedAt:    fullStartedAt,
+		Type:         BackupTypeFull,
+	}}))
+
+	mocks.createBackupUseCase.EXPECT().
+		Do(mock.Anything, organization, contents, fullStartedAt, mock.AnythingOfType("uc.SaveBackupFunc")).
+		RunAndReturn(func(_ context.Context, _ string, _ github.MigrationContents, _ time.Time, saveFunc SaveBackupFunc) ([]BackupArchive, error) {
+			archive := BackupArchive{Batch: 1, BatchCount: 1, Repositories: []string{"repo1"}}
+			location, err := saveFunc(archive, strings.NewReader("mock archive content"))
+			archive.Location = location
+			return []BackupArchive{archive}, err
+		})
+
+	useCase := NewCreateRemoteBackupUseCase(blobRepository, mocks.createBackupUseCase).
+		WithCatalog(catalog).
+		WithIncremental(7)
+
+	// When
+	_, err := useCase.Do(context.Background(), organization, contents)
+
+	// Then
+	require.NoError(t, err)
+
+	entry, err := catalog.Get(context.Background(), "2025-07-23-kumojin-migration.tar.gz")
+	require.NoError(t, err)
+	assert.Equal(t, BackupTypeIncremental, entry.Type)
+	assert.Equal(t, "2025-07-21-kumojin-migration", entry.Base)
+
+	content, err := os.ReadFile(filepath.Join(root, "2025-07-23-kumojin-migration.tar.gz.manifest.json"))
+	require.NoError(t, err)
+	var manifest IntegrityManifest
+	require.NoError(t, json.Unmarshal(content, &manifest))
+	assert.Equal(t, BackupTypeIncremental, manifest.Type)
+	assert.Equal(t, "2025-07-21-kumojin-migration", manifest.Base, "the manifest names the base full backup")
+}
+
+func TestCreateRemoteBackupUseCase_IncrementalWithoutChanges(t *testing.T) {
+	// Given
+	mocks := newCreateRemoteBackupTestMocks(t)
+	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
+
+	blobRepository := filesystem.NewBlobRepository(t.TempDir())
+	catalog := NewBlobCatalogStore(blobRepository)
+	require.NoError(t, catalog.Add(context.Background(), []CatalogEntry{{
+		ID:           "2025-07-21-kumojin-migration.tar.gz",
+		Organization: organization,
+		CreatedAt:    time.Date(2025, 7, 21, 0, 0, 0, 0, time.UTC),
+		Type:         BackupTypeFull,
+	}}))
+
+	mocks.createBackupUseCase.EXPECT().
+		Do(mock.Anything, organization, contents, mock.AnythingOfType("time.Time"), mock.AnythingOfType("uc.SaveBackupFunc")).
+		Return(nil, ErrNoChangedRepositories)
+
+	useCase := NewCreateRemoteBackupUseCase(blobRepository, mocks.createBackupUseCase).
+		WithCatalog(catalog).
+		WithIncremental(7)
+
+	// When
+	location, err := useCase.Do(context.Background(), organization, contents)
+
+	// Then
+	require.NoError(t, err)
+	assert.Empty(t, location)
+
+	entries, err := catalog.List(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "nothing is recorded")
+}
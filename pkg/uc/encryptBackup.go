@@ -0,0 +1,31 @@
+package uc
+
+import (
+	"io"
+
+	"github.com/kumojin/repo-backup-cli/pkg/encryption"
+)
+
+// encryptSaveBackupFunc returns a SaveBackupFunc streaming the archives through encryptor before saving them with
+// saveBackupFunc, archives are saved as is when encryptor is nil
+func encryptSaveBackupFunc(encryptor encryption.Encryptor, saveBackupFunc SaveBackupFunc) SaveBackupFunc {
+	if encryptor == nil {
+		return saveBackupFunc
+	}
+
+	return func(archive BackupArchive, reader io.Reader) (string, error) {
+		encrypted := encryption.EncryptReader(encryptor, reader)
+		defer func() { _ = encrypted.Close() }()
+
+		return saveBackupFunc(archive, encrypted)
+	}
+}
+
+// encryptedFileName returns the name of the archive once encrypted with encryptor, which may be nil
+func encryptedFileName(archive BackupArchive, baseName string, encryptor encryption.Encryptor) string {
+	if encryptor == nil {
+		return archive.FileName(baseName)
+	}
+
+	return archive.FileName(baseName) + encryptor.Extension()
+}
@@ -0,0 +1,125 @@
+package uc
+
+import (
+	"fmt"
+	"time"
+
+	gh "github.com/google/go-github/v90/github"
+)
+
+const (
+	// BackupTypeFull is a backup of every repository
+	BackupTypeFull = "full"
+	// BackupTypeIncremental is a backup of the repositories changed since its base full backup, restoring it only
+	// needs the base for the other repositories
+	BackupTypeIncremental = "incremental"
+)
+
+// backupPlan tells how the next backup of an organization is made
+type backupPlan struct {
+	Type string
+	// Base is the name of the full backup an incremental backup depends on, such as 2025-07-20-kumojin-migration
+	Base string
+	// ChangedSince is when the base full backup started, an incremental backup saves every repository changed
+	// since, so that it does not depend on the incremental backups made before it. It is zero for a full backup.
+	ChangedSince time.Time
+}
+
+// backupRun is the backup of an organization being made
+type backupRun struct {
+	// Name is the name of the backup, such as 2025-07-22-kumojin-migration
+	Name      string
+	StartedAt time.Time
+	Plan      backupPlan
+}
+
+// planBackup plans the backup named backupName of the organization from the catalog entries, oldest first. A full
+// backup is made when there is none of the organization in the catalog or when the last one is at least
+// fullIntervalDays old, an incremental backup is made otherwise. Running a backup again the same day makes the
+// same kind of backup, since it replaces the archives saved earlier that day.
+func planBackup(entries []CatalogEntry, organization string, backupName string, fullIntervalDays int, now time.Time) backupPlan {
+	full := backupPlan{Type: BackupTypeFull}
+	if fullIntervalDays <= 0 {
+		return full
+	}
+
+	var lastFull *CatalogEntry
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Organization == organization && entry.Type != BackupTypeIncremental {
+			lastFull = entry
+		}
+	}
+
+	if lastFull == nil || lastFull.backupName() == backupName {
+		return full
+	}
+	if daysBetween(lastFull.CreatedAt, now) >= fullIntervalDays {
+		return full
+	}
+
+	changedSince := lastFull.StartedAt
+	if changedSince.IsZero() {
+		changedSince = lastFull.CreatedAt
+	}
+
+	return backupPlan{
+		Type:         BackupTypeIncremental,
+		Base:         lastFull.backupName(),
+		ChangedSince: changedSince,
+	}
+}
+
+// daysBetween returns the number of calendar days from one time to the other in UTC, a backup running a bit earlier
+// than the previous one still counts a whole day
+func daysBetween(from time.Time, to time.Time) int {
+	fromDate, _ := time.Parse(time.DateOnly, from.UTC().Format(time.DateOnly))
+	toDate, _ := time.Parse(time.DateOnly, to.UTC().Format(time.DateOnly))
+
+	return int(toDate.Sub(fromDate).Hours() / 24)
+}
+
+// changedRepositories returns the repositories pushed to or updated after changedSince, every repository when it
+// is zero. A repository without a push nor an update time is considered changed.
+func changedRepositories(repos []gh.Repository, changedSince time.Time) ([]gh.Repository, error) {
+	if changedSince.IsZero() || len(repos) == 0 {
+		return repos, nil
+	}
+
+	var changed []gh.Repository
+	for _, repo := range repos {
+		if isChangedSince(repo, changedSince) {
+			changed = append(changed, repo)
+		}
+	}
+
+	if len(changed) == 0 {
+		return nil, ErrNoChangedRepositories
+	}
+
+	return changed, nil
+}
+
+// recordUnchangedRepositories records the repositories which are not backed up since they did not change
+func recordUnchangedRepositories(recorder RunRecorder, organization string, repos []gh.Repository, changedSince time.Time) {
+	if changedSince.IsZero() {
+		return
+	}
+
+	for _, repo := range repos {
+		if !isChangedSince(repo, changedSince) {
+			recorder.RecordExcludedRepository(organization, ExcludedRepository{
+				Name:   repo.GetName(),
+				Reason: fmt.Sprintf("unchanged since %s", changedSince.Format(time.RFC3339)),
+			})
+		}
+	}
+}
+
+func isChangedSince(repo gh.Repository, changedSince time.Time) bool {
+	if repo.PushedAt == nil && repo.UpdatedAt == nil {
+		return true
+	}
+
+	return repo.GetPushedAt().After(changedSince) || repo.GetUpdatedAt().After(changedSince)
+}
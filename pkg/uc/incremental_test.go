@@ -0,0 +1,145 @@
+package uc
+
+import (
+	"testing"
+	"time"
+
+	gh "github.com/google/go-github/v90/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanBackup(t *testing.T) {
+	now := time.Date(2025, 7, 23, 2, 0, 0, 0, time.UTC)
+	full := CatalogEntry{
+		ID:           "2025-07-20-kumojin-migration.tar.gz",
+		Organization: "kumojin",
+		CreatedAt:    time.Date(2025, 7, 20, 2, 30, 0, 0, time.UTC),
+		Backup:       "2025-07-20-kumojin-migration",
+		StartedAt:    time.Date(2025, 7, 20, 2, 0, 0, 0, time.UTC),
+		Type:         BackupTypeFull,
+	}
+	incremental := CatalogEntry{
+		ID:           "2025-07-22-kumojin-migration.tar.gz",
+		Organization: "kumojin",
+		CreatedAt:    time.Date(2025, 7, 22, 2, 10, 0, 0, time.UTC),
+		Backup:       "2025-07-22-kumojin-migration",
+		StartedAt:    time.Date(2025, 7, 22, 2, 0, 0, 0, time.UTC),
+		Type:         BackupTypeIncremental,
+		Base:         "2025-07-20-kumojin-migration",
+	}
+	other := CatalogEntry{
+		ID:           "2025-07-22-other-migration.tar.gz",
+		Organization: "other",
+		CreatedAt:    time.Date(2025, 7, 22, 3, 0, 0, 0, time.UTC),
+		Type:         BackupTypeFull,
+	}
+	legacy := CatalogEntry{
+		ID:           "2025-07-21-kumojin-migration.tar.gz",
+		Organization: "kumojin",
+		CreatedAt:    time.Date(2025, 7, 21, 2, 30, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name             string
+		entries          []CatalogEntry
+		backupName       string
+		fullIntervalDays int
+		expected         backupPlan
+	}{
+		{
+			name:             "disabled",
+			entries:          []CatalogEntry{full},
+			backupName:       "2025-07-23-kumojin-migration",
+			fullIntervalDays: 0,
+			expected:         backupPlan{Type: BackupTypeFull},
+		},
+		{
+			name:             "first backup",
+			entries:          []CatalogEntry{other},
+			backupName:       "2025-07-23-kumojin-migration",
+			fullIntervalDays: 7,
+			expected:         backupPlan{Type: BackupTypeFull},
+		},
+		{
+			name:             "incremental after the full backup",
+			entries:          []CatalogEntry{full, other},
+			backupName:       "2025-07-23-kumojin-migration",
+			fullIntervalDays: 7,
+			expected:         backupPlan{Type: BackupTypeIncremental, Base: full.Backup, ChangedSince: full.StartedAt},
+		},
+		{
+			name:             "incremental after an incremental backup saves the changes since the full backup",
+			entries:          []CatalogEntry{full, incremental},
+			backupName:       "2025-07-23-kumojin-migration",
+			fullIntervalDays: 7,
+			expected:         backupPlan{Type: BackupTypeIncremental, Base: full.Backup, ChangedSince: full.StartedAt},
+		},
+		{
+			name:             "full backup when the interval elapsed, even a bit earlier in the day",
+			entries:          []CatalogEntry{full, incremental},
+			backupName:       "2025-07-23-kumojin-migration",
+			fullIntervalDays: 3,
+			expected:         backupPlan{Type: BackupTypeFull},
+		},
+		{
+			name:             "same day as the full backup",
+			entries:          []CatalogEntry{full},
+			backupName:       full.Backup,
+			fullIntervalDays: 7,
+			expected:         backupPlan{Type: BackupTypeFull},
+		},
+		{
+			name:             "same day as an incremental backup",
+			entries:          []CatalogEntry{full, incremental},
+			backupName:       incremental.Backup,
+			fullIntervalDays: 7,
+			expected:         backupPlan{Type: BackupTypeIncremental, Base: full.Backup, ChangedSince: full.StartedAt},
+		},
+		{
+			name:             "entry without a type nor start time",
+			entries:          []CatalogEntry{legacy},
+			backupName:       "2025-07-23-kumojin-migration",
+			fullIntervalDays: 7,
+			expected:         backupPlan{Type: BackupTypeIncremental, Base: legacy.ID, ChangedSince: legacy.CreatedAt},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, planBackup(tt.entries, "kumojin", tt.backupName, tt.fullIntervalDays, now))
+		})
+	}
+}
+
+func TestChangedRepositories(t *testing.T) {
+	// Given
+	since := time.Date(2025, 7, 22, 2, 0, 0, 0, time.UTC)
+	before := &gh.Timestamp{Time: since.Add(-time.Hour)}
+	after := &gh.Timestamp{Time: since.Add(time.Hour)}
+	repos := []gh.Repository{
+		{Name: gh.Ptr("unchanged"), PushedAt: before, UpdatedAt: before},
+		{Name: gh.Ptr("pushed"), PushedAt: after, UpdatedAt: before},
+		{Name: gh.Ptr("updated"), PushedAt: before, UpdatedAt: after},
+		{Name: gh.Ptr("never pushed"), UpdatedAt: before},
+		{Name: gh.Ptr("unknown")},
+	}
+
+	// When
+	all, allErr := changedRepositories(repos, time.Time{})
+	changed, changedErr := changedRepositories(repos, since)
+	_, noneErr := changedRepositories(repos[:1], since)
+
+	// Then
+	require.NoError(t, allErr)
+	assert.Equal(t, repos, all)
+
+	require.NoError(t, changedErr)
+	var names []string
+	for _, repo := range changed {
+		names = append(names, repo.GetName())
+	}
+	assert.Equal(t, []string{"pushed", "updated", "unknown"}, names)
+
+	assert.ErrorIs(t, noneErr, ErrNoChangedRepositories)
+}
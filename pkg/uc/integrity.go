@@ -0,0 +1,74 @@
+package uc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+)
+
+// IntegrityManifestExtension is appended to the name of an archive to name its integrity manifest
+const IntegrityManifestExtension = ".manifest.json"
+
+// IntegrityManifest records what was saved for an archive, it is stored next to the archive as <archive>.manifest.json
+type IntegrityManifest struct {
+	Archive      string    `json:"archive"`
+	Organization string    `json:"organization"`
+	CreatedAt    time.Time `json:"createdAt"`
+	// SHA256 is the hex encoded checksum of the archive as stored, after encryption
+	SHA256       string   `json:"sha256"`
+	Size         int64    `json:"size"`
+	Repositories []string `json:"repositories"`
+	// Type is BackupTypeFull or BackupTypeIncremental, Base names the full backup an incremental backup depends on
+	Type string `json:"type,omitempty"`
+	Base string `json:"base,omitempty"`
+}
+
+func integrityManifestFileName(archiveName string) string {
+	return archiveName + IntegrityManifestExtension
+}
+
+// checksumReader computes the SHA-256 checksum and the size of what is read through it
+type checksumReader struct {
+	reader io.Reader
+	hash   hash.Hash
+	size   int64
+}
+
+func newChecksumReader(reader io.Reader) *checksumReader {
+	return &checksumReader{reader: reader, hash: sha256.New()}
+}
+
+func (r *checksumReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.hash.Write(p[:n])
+	r.size += int64(n)
+
+	return n, err
+}
+
+func (r *checksumReader) SHA256() string {
+	return hex.EncodeToString(r.hash.Sum(nil))
+}
+
+// marshalIntegrityManifest returns the manifest of an archive whose content was read through checksum
+func marshalIntegrityManifest(organization string, archiveName string, archive BackupArchive, checksum *checksumReader, plan backupPlan) ([]byte, error) {
+	manifest, err := json.MarshalIndent(IntegrityManifest{
+		Archive:      archiveName,
+		Organization: organization,
+		CreatedAt:    getCurrentTime().UTC(),
+		SHA256:       checksum.SHA256(),
+		Size:         checksum.size,
+		Repositories: archive.Repositories,
+		Type:         plan.Type,
+		Base:         plan.Base,
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal integrity manifest: %w", err)
+	}
+
+	return manifest, nil
+}
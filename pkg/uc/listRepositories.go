@@ -0,0 +1,63 @@
+package uc
+
+import (
+	"context"
+
+	gh "github.com/google/go-github/v90/github"
+	"github.com/kumojin/repo-backup-cli/pkg/github"
+)
+
+// ListRepositoriesUseCase lists the repositories of an organization selected by its filter, which defaults to the
+// private repositories that are not archived
+type ListRepositoriesUseCase interface {
+	Do(ctx context.Context, organization string) ([]gh.Repository, error)
+	WithFilter(filter RepositoryFilter) ListRepositoriesUseCase
+	// WithRecorder records the duration of the listing and the repositories which are not selected by the filter,
+	// with the reason
+	WithRecorder(recorder RunRecorder) ListRepositoriesUseCase
+}
+
+type listRepositoriesUseCase struct {
+	githubClient github.Client
+	filter       RepositoryFilter
+	recorder     RunRecorder
+}
+
+func NewListRepositoriesUseCase(client github.Client) ListRepositoriesUseCase {
+	return &listRepositoriesUseCase{
+		githubClient: client,
+		recorder:     noopRunRecorder{},
+	}
+}
+
+func (uc *listRepositoriesUseCase) WithFilter(filter RepositoryFilter) ListRepositoriesUseCase {
+	uc.filter = filter
+	return uc
+}
+
+func (uc *listRepositoriesUseCase) WithRecorder(recorder RunRecorder) ListRepositoriesUseCase {
+	uc.recorder = recorder
+	return uc
+}
+
+func (uc *listRepositoriesUseCase) Do(ctx context.Context, organization string) ([]gh.Repository, error) {
+	start := getCurrentTime()
+	repos, err := uc.githubClient.ListOrgRepos(ctx, organization, uc.filter.listType())
+	uc.recorder.RecordPhase(organization, PhaseList, getCurrentTime().Sub(start), err)
+	if err != nil {
+		return nil, err
+	}
+
+	var filteredRepos []gh.Repository
+	for _, repo := range repos {
+		reason := uc.filter.ExclusionReason(repo)
+		if reason != "" {
+			uc.recorder.RecordExcludedRepository(organization, ExcludedRepository{Name: repo.GetName(), Reason: reason})
+			continue
+		}
+
+		filteredRepos = append(filteredRepos, *repo)
+	}
+
+	return filteredRepos, nil
+}
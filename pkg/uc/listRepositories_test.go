@@ -0,0 +1,154 @@
+package uc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	gh "github.com/google/go-github/v90/github"
+	"github.com/kumojin/repo-backup-cli/pkg/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestListRepositoriesUseCase_SuccessfullyListNonArchivedRepos(t *testing.T) {
+	// Given
+	mockClient := github.NewMockClient(t)
+
+	mockClient.EXPECT().
+		ListOrgRepos(mock.Anything, "kumojin", "private").
+		Return(
+			[]*gh.Repository{
+				{
+					Name:     gh.Ptr("repo1"),
+					Archived: gh.Ptr(false),
+					Private:  gh.Ptr(true),
+				},
+				{
+					Name:     gh.Ptr("repo2"),
+					Archived: gh.Ptr(true), // Archived repo, should be filtered out
+					Private:  gh.Ptr(true),
+				},
+				{
+					Name:     gh.Ptr("repo3"),
+					Archived: gh.Ptr(false),
+					Private:  gh.Ptr(true),
+				},
+			},
+			nil,
+		)
+
+	useCase := NewListRepositoriesUseCase(mockClient)
+
+	// When
+	repos, err := useCase.Do(context.Background(), "kumojin")
+
+	// Then
+	assert.NoError(t, err)
+
+	expectedRepos := []gh.Repository{
+		{
+			Name:     gh.Ptr("repo1"),
+			Archived: gh.Ptr(false),
+			Private:  gh.Ptr(true),
+		},
+		{
+			Name:     gh.Ptr("repo3"),
+			Archived: gh.Ptr(false),
+			Private:  gh.Ptr(true),
+		},
+	}
+	assert.Equal(t, expectedRepos, repos)
+}
+
+func TestListRepositoriesUseCase_ErrorFromGitHubClient(t *testing.T) {
+	// Given
+	mockClient := github.NewMockClient(t)
+
+	githubApiError := errors.New("github API error")
+	mockClient.EXPECT().
+		ListOrgRepos(mock.Anything, "kumojin", "private").
+		Return(nil, githubApiError)
+
+	useCase := NewListRepositoriesUseCase(mockClient)
+
+	// When
+	repos, err := useCase.Do(context.Background(), "kumojin")
+
+	// Then
+	assert.ErrorIs(t, err, githubApiError)
+	assert.Nil(t, repos)
+}
+
+func TestListRepositoriesUseCase_NoRepositoriesFound(t *testing.T) {
+	// Given
+	mockClient := github.NewMockClient(t)
+
+	mockClient.EXPECT().
+		ListOrgRepos(mock.Anything, "kumojin", "private").
+		Return([]*gh.Repository{}, nil)
+
+	useCase := NewListRepositoriesUseCase(mockClient)
+
+	// When
+	repos, err := useCase.Do(context.Background(), "kumojin")
+
+	// Then
+	assert.NoError(t, err)
+	assert.Empty(t, repos)
+}
+
+func TestListRepositoriesUseCase_WithFilter(t *testing.T) {
+	// Given
+	mockClient := github.NewMockClient(t)
+
+	mockClient.EXPECT().
+		ListOrgRepos(mock.Anything, "kumojin", "all").
+		Return(
+			[]*gh.Repository{
+				{Name: gh.Ptr("api"), Visibility: gh.Ptr("internal"), Archived: gh.Ptr(true)},
+				{Name: gh.Ptr("web"), Visibility: gh.Ptr("private")},
+				{Name: gh.Ptr("generated-docs"), Visibility: gh.Ptr("public")},
+			},
+			nil,
+		)
+
+	useCase := NewListRepositoriesUseCase(mockClient).WithFilter(RepositoryFilter{
+		Exclude:         []string{"generated-*"},
+		Visibility:      github.VisibilityAll,
+		IncludeArchived: true,
+	})
+
+	// When
+	repos, err := useCase.Do(context.Background(), "kumojin")
+
+	// Then
+	assert.NoError(t, err)
+	assert.Len(t, repos, 2)
+	assert.Equal(t, "api", repos[0].GetName())
+	assert.Equal(t, "web", repos[1].GetName())
+}
+
+func TestListRepositoriesUseCase_RecordsExcludedRepos(t *testing.T) {
+	// Given
+	mockClient := github.NewMockClient(t)
+	recorder := NewMockRunRecorder(t)
+
+	mockClient.EXPECT().
+		ListOrgRepos(mock.Anything, "kumojin", "private").
+		Return([]*gh.Repository{
+			{Name: gh.Ptr("repo1"), Private: gh.Ptr(true)},
+			{Name: gh.Ptr("repo2"), Private: gh.Ptr(true), Archived: gh.Ptr(true)},
+		}, nil)
+	recorder.EXPECT().RecordPhase("kumojin", PhaseList, mock.AnythingOfType("time.Duration"), nil).Return()
+	recorder.EXPECT().RecordExcludedRepository("kumojin", ExcludedRepository{Name: "repo2", Reason: "archived"}).Return()
+
+	useCase := NewListRepositoriesUseCase(mockClient).WithRecorder(recorder)
+
+	// When
+	repos, err := useCase.Do(context.Background(), "kumojin")
+
+	// Then
+	assert.NoError(t, err)
+	assert.Len(t, repos, 1)
+}
@@ -0,0 +1,126 @@
+package uc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/kumojin/repo-backup-cli/pkg/github"
+	"github.com/kumojin/repo-backup-cli/pkg/storage"
+)
+
+var ErrMigrationStateNotFound = errors.New("migration state not found")
+
+// maxResumableMigrationAge is the age after which a migration is not resumed, GitHub deletes the archives after 7 days
+const maxResumableMigrationAge = 24 * time.Hour
+
+// MigrationState records the migrations started by a backup, so that a backup which was interrupted can resume them
+type MigrationState struct {
+	Organization string                   `json:"organization"`
+	Contents     github.MigrationContents `json:"contents"`
+	StartedAt    time.Time                `json:"startedAt"`
+	Migrations   []StartedMigration       `json:"migrations"`
+}
+
+// StartedMigration is a migration started for a batch of repositories
+type StartedMigration struct {
+	ID           int64    `json:"id"`
+	Repositories []string `json:"repositories"`
+}
+
+// MigrationStateStore saves the migration state of an organization between runs
+type MigrationStateStore interface {
+	// Load returns the state saved for the organization or ErrMigrationStateNotFound
+	Load(ctx context.Context, organization string) (MigrationState, error)
+	Save(ctx context.Context, state MigrationState) error
+	// Delete removes the state of the organization, deleting a state that does not exist is not an error
+	Delete(ctx context.Context, organization string) error
+}
+
+func migrationStateFileName(organization string) string {
+	return organization + "-migration-state.json"
+}
+
+type blobMigrationStateStore struct {
+	blobRepository storage.BlobRepository
+}
+
+// NewBlobMigrationStateStore stores the state of each organization in the <organization>-migration-state.json blob
+func NewBlobMigrationStateStore(blobRepository storage.BlobRepository) MigrationStateStore {
+	return &blobMigrationStateStore{
+		blobRepository: blobRepository,
+	}
+}
+
+func (s *blobMigrationStateStore) Load(ctx context.Context, organization string) (MigrationState, error) {
+	reader, err := s.blobRepository.Download(ctx, migrationStateFileName(organization))
+	if errors.Is(err, storage.ErrBlobNotFound) {
+		return MigrationState{}, ErrMigrationStateNotFound
+	}
+	if err != nil {
+		return MigrationState{}, fmt.Errorf("failed to download migration state: %w", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return MigrationState{}, fmt.Errorf("failed to download migration state: %w", err)
+	}
+
+	return unmarshalMigrationState(content)
+}
+
+func (s *blobMigrationStateStore) Save(ctx context.Context, state MigrationState) error {
+	content, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal migration state: %w", err)
+	}
+
+	if _, err := s.blobRepository.Upload(ctx, migrationStateFileName(state.Organization), bytes.NewReader(content)); err != nil {
+		return fmt.Errorf("failed to upload migration state: %w", err)
+	}
+
+	return nil
+}
+
+func (s *blobMigrationStateStore) Delete(ctx context.Context, organization string) error {
+	if err := s.blobRepository.Delete(ctx, migrationStateFileName(organization)); err != nil {
+		return fmt.Errorf("failed to delete migration state: %w", err)
+	}
+
+	return nil
+}
+
+func unmarshalMigrationState(content []byte) (MigrationState, error) {
+	var state MigrationState
+	if err := json.Unmarshal(content, &state); err != nil {
+		return MigrationState{}, fmt.Errorf("failed to parse migration state: %w", err)
+	}
+
+	return state, nil
+}
+
+// sameRepositories tells whether both lists hold the same repositories, in any order
+func sameRepositories(a []string, b []string) bool {
+	return slices.Equal(slices.Sorted(slices.Values(a)), slices.Sorted(slices.Values(b)))
+}
+
+// findStartedMigration returns the migration of the state started for the repositories
+func (s MigrationState) findStartedMigration(organization string, contents github.MigrationContents, repoNames []string, now time.Time) (int64, bool) {
+	if s.Organization != organization || s.Contents != contents || now.Sub(s.StartedAt) > maxResumableMigrationAge {
+		return 0, false
+	}
+
+	for _, migration := range s.Migrations {
+		if sameRepositories(migration.Repositories, repoNames) {
+			return migration.ID, true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,115 @@
+package uc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/kumojin/repo-backup-cli/pkg/github"
+	"github.com/kumojin/repo-backup-cli/pkg/storage"
+	"github.com/kumojin/repo-backup-cli/pkg/storage/filesystem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMigrationState() MigrationState {
+	return MigrationState{
+		Organization: "kumojin",
+		Contents:     github.DefaultMigrationContents(),
+		StartedAt:    time.Date(2025, 7, 23, 9, 0, 0, 0, time.UTC),
+		Migrations: []StartedMigration{
+			{ID: 1, Repositories: []string{"repo1", "repo2"}},
+			{ID: 2, Repositories: []string{"repo3"}},
+		},
+	}
+}
+
+func TestBlobMigrationStateStore_Filesystem(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	store := NewBlobMigrationStateStore(filesystem.NewBlobRepository(t.TempDir()))
+	state := newTestMigrationState()
+
+	// When
+	_, notFoundErr := store.Load(ctx, "kumojin")
+	saveErr := store.Save(ctx, state)
+	loaded, loadErr := store.Load(ctx, "kumojin")
+	deleteErr := store.Delete(ctx, "kumojin")
+	_, deletedErr := store.Load(ctx, "kumojin")
+
+	// Then
+	assert.ErrorIs(t, notFoundErr, ErrMigrationStateNotFound)
+	require.NoError(t, saveErr)
+	require.NoError(t, loadErr)
+	assert.Equal(t, state, loaded)
+	assert.NoError(t, deleteErr)
+	assert.ErrorIs(t, deletedErr, ErrMigrationStateNotFound)
+	assert.NoError(t, store.Delete(ctx, "kumojin"))
+}
+
+func TestBlobMigrationStateStore(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	blobRepository := storage.NewMockBlobRepository(t)
+	store := NewBlobMigrationStateStore(blobRepository)
+	state := newTestMigrationState()
+
+	var saved []byte
+	blobRepository.EXPECT().Upload(mock.Anything, "kumojin-migration-state.json", mock.Anything).
+		RunAndReturn(func(_ context.Context, _ string, in io.Reader) (string, error) {
+			content, err := io.ReadAll(in)
+			saved = content
+			return "https://storage/kumojin-migration-state.json", err
+		})
+	blobRepository.EXPECT().Download(mock.Anything, "kumojin-migration-state.json").
+		RunAndReturn(func(context.Context, string) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(saved)), nil
+		}).Once()
+	blobRepository.EXPECT().Download(mock.Anything, "other-migration-state.json").Return(nil, storage.ErrBlobNotFound)
+
+	// When
+	saveErr := store.Save(ctx, state)
+	loaded, loadErr := store.Load(ctx, "kumojin")
+	_, notFoundErr := store.Load(ctx, "other")
+
+	// Then
+	require.NoError(t, saveErr)
+	require.NoError(t, loadErr)
+	assert.Equal(t, state, loaded)
+	assert.ErrorIs(t, notFoundErr, ErrMigrationStateNotFound)
+}
+
+func TestMigrationState_FindStartedMigration(t *testing.T) {
+	state := newTestMigrationState()
+	contents := github.DefaultMigrationContents()
+	now := state.StartedAt.Add(time.Hour)
+
+	tests := []struct {
+		name         string
+		organization string
+		contents     github.MigrationContents
+		repoNames    []string
+		now          time.Time
+		expectedID   int64
+		expectedOk   bool
+	}{
+		{name: "same repositories", organization: "kumojin", contents: contents, repoNames: []string{"repo3"}, now: now, expectedID: 2, expectedOk: true},
+		{name: "repositories in another order", organization: "kumojin", contents: contents, repoNames: []string{"repo2", "repo1"}, now: now, expectedID: 1, expectedOk: true},
+		{name: "other repositories", organization: "kumojin", contents: contents, repoNames: []string{"repo1"}, now: now},
+		{name: "other organization", organization: "other", contents: contents, repoNames: []string{"repo3"}, now: now},
+		{name: "other contents", organization: "kumojin", contents: github.MigrationContents{GitData: true}, repoNames: []string{"repo3"}, now: now},
+		{name: "too old", organization: "kumojin", contents: contents, repoNames: []string{"repo3"}, now: state.StartedAt.Add(25 * time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, ok := state.findStartedMigration(tt.organization, tt.contents, tt.repoNames, tt.now)
+
+			assert.Equal(t, tt.expectedOk, ok)
+			assert.Equal(t, tt.expectedID, id)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package uc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/kumojin/repo-backup-cli/pkg/logging"
+	"github.com/kumojin/repo-backup-cli/pkg/storage"
+)
+
+var ErrRetentionPolicyDisabled = errors.New("retention policy keeps no backup, set at least one of the daily, weekly, monthly or yearly counts")
+
+// PruneReport lists the blobs kept and deleted by a prune, deleted blobs are only planned during a dry run
+type PruneReport struct {
+	Kept    []string
+	Deleted []string
+}
+
+type PruneBackupsUseCase interface {
+	Do(ctx context.Context, organization string, policy RetentionPolicy, dryRun bool) (PruneReport, error)
+	// WithCatalog removes the deleted archives from the catalog and keeps the full backups the kept incremental
+	// backups depend on
+	WithCatalog(catalog CatalogStore) PruneBackupsUseCase
+}
+
+type pruneBackupsUseCase struct {
+	blobRepository storage.BlobRepository
+	catalog        CatalogStore
+}
+
+func NewPruneBackupsUseCase(blobRepository storage.BlobRepository) PruneBackupsUseCase {
+	return &pruneBackupsUseCase{
+		blobRepository: blobRepository,
+	}
+}
+
+func (uc *pruneBackupsUseCase) WithCatalog(catalog CatalogStore) PruneBackupsUseCase {
+	uc.catalog = catalog
+	return uc
+}
+
+// Do deletes the remote backups of the organization which are not kept by the policy
+func (uc *pruneBackupsUseCase) Do(ctx context.Context, organization string, policy RetentionPolicy, dryRun bool) (PruneReport, error) {
+	var report PruneReport
+
+	if !policy.IsEnabled() {
+		return report, ErrRetentionPolicyDisabled
+	}
+
+	blobs, err := uc.blobRepository.List(ctx, "")
+	if err != nil {
+		return report, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	blobNames := make([]string, 0, len(blobs))
+	for _, blob := range blobs {
+		blobNames = append(blobNames, blob.Name)
+	}
+
+	keep, remove := policy.apply(groupRemoteBackups(blobNames, organization), getCurrentTime())
+
+	if uc.catalog != nil {
+		entries, err := uc.catalog.List(ctx)
+		if err != nil {
+			return report, fmt.Errorf("failed to read backup catalog: %w", err)
+		}
+		keep, remove = keepBaseBackups(entries, keep, remove)
+	}
+
+	for _, backup := range keep {
+		report.Kept = append(report.Kept, backup.Blobs...)
+	}
+
+	logger := logging.NewLogger(ctx).With(
+		slog.String("organization", organization),
+		slog.Bool("dryRun", dryRun),
+	)
+
+	for _, backup := range remove {
+		for _, blobName := range backup.Blobs {
+			if !dryRun {
+				logger.Info("deleting backup", slog.String("blob", blobName))
+
+				if err := uc.blobRepository.Delete(ctx, blobName); err != nil {
+					return report, fmt.Errorf("failed to delete backup %s: %w", blobName, err)
+				}
+			}
+
+			report.Deleted = append(report.Deleted, blobName)
+		}
+	}
+
+	if uc.catalog != nil && !dryRun && len(report.Deleted) > 0 {
+		if err := uc.catalog.Delete(ctx, report.Deleted); err != nil {
+			return report, fmt.Errorf("failed to update backup catalog: %w", err)
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,197 @@
+package uc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kumojin/repo-backup-cli/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// useFakeClock sets the time returned by getCurrentTime for the duration of the test
+func useFakeClock(t *testing.T, now time.Time) {
+	t.Helper()
+
+	getCurrentTime = func() time.Time { return now }
+	t.Cleanup(func() { getCurrentTime = time.Now })
+}
+
+// remoteBackupBlobs returns the blobs of a backup of the organization made every day of the last days, ending today
+func remoteBackupBlobs(organization string, days int) []storage.BlobInfo {
+	var blobs []storage.BlobInfo
+	for i := 0; i < days; i++ {
+		date := getCurrentTime().AddDate(0, 0, -i).Format(time.DateOnly)
+		blobs = append(blobs, storage.BlobInfo{Name: fmt.Sprintf("%s-%s-migration.tar.gz", date, organization)})
+	}
+
+	return blobs
+}
+
+func TestPruneBackupsUseCase_Success(t *testing.T) {
+	// Given
+	useFakeClock(t, time.Date(2025, 7, 23, 9, 0, 0, 0, time.UTC))
+	blobRepository := storage.NewMockBlobRepository(t)
+
+	blobs := remoteBackupBlobs("kumojin", 10)
+	blobs = append(blobs, remoteBackupBlobs("other", 10)...)
+	blobs = append(blobs,
+		storage.BlobInfo{Name: "2025-07-01-kumojin-migration-batch-1-of-2.tar.gz"},
+		storage.BlobInfo{Name: "2025-07-01-kumojin-migration-batch-2-of-2.tar.gz"},
+		storage.BlobInfo{Name: "2025-07-01-kumojin-migration-batches.json"},
+		storage.BlobInfo{Name: "notes.txt"},
+	)
+
+	blobRepository.EXPECT().List(mock.Anything, "").Return(blobs, nil)
+
+	var deleted []string
+	blobRepository.EXPECT().Delete(mock.Anything, mock.AnythingOfType("string")).
+		RunAndReturn(func(_ context.Context, blobName string) error {
+			deleted = append(deleted, blobName)
+			return nil
+		})
+
+	useCase := NewPruneBackupsUseCase(blobRepository)
+
+	// When
+	report, err := useCase.Do(context.Background(), "kumojin", RetentionPolicy{Daily: 3, Weekly: 2}, false)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"2025-07-23-kumojin-migration.tar.gz",
+		"2025-07-22-kumojin-migration.tar.gz",
+		"2025-07-21-kumojin-migration.tar.gz",
+		"2025-07-20-kumojin-migration.tar.gz",
+	}, report.Kept)
+	assert.Equal(t, []string{
+		"2025-07-19-kumojin-migration.tar.gz",
+		"2025-07-18-kumojin-migration.tar.gz",
+		"2025-07-17-kumojin-migration.tar.gz",
+		"2025-07-16-kumojin-migration.tar.gz",
+		"2025-07-15-kumojin-migration.tar.gz",
+		"2025-07-14-kumojin-migration.tar.gz",
+		"2025-07-01-kumojin-migration-batch-1-of-2.tar.gz",
+		"2025-07-01-kumojin-migration-batch-2-of-2.tar.gz",
+		"2025-07-01-kumojin-migration-batches.json",
+	}, report.Deleted)
+	assert.Equal(t, report.Deleted, deleted)
+}
+
+func TestPruneBackupsUseCase_DryRun(t *testing.T) {
+	// Given
+	useFakeClock(t, time.Date(2025, 7, 23, 9, 0, 0, 0, time.UTC))
+	blobRepository := storage.NewMockBlobRepository(t)
+
+	blobRepository.EXPECT().List(mock.Anything, "").Return(remoteBackupBlobs("kumojin", 5), nil)
+
+	useCase := NewPruneBackupsUseCase(blobRepository)
+
+	// When
+	report, err := useCase.Do(context.Background(), "kumojin", RetentionPolicy{Daily: 2}, true)
+
+	// Then
+	require.NoError(t, err)
+	assert.Len(t, report.Kept, 2)
+	assert.Equal(t, []string{
+		"2025-07-21-kumojin-migration.tar.gz",
+		"2025-07-20-kumojin-migration.tar.gz",
+		"2025-07-19-kumojin-migration.tar.gz",
+	}, report.Deleted)
+	blobRepository.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestPruneBackupsUseCase_DeleteError(t *testing.T) {
+	// Given
+	useFakeClock(t, time.Date(2025, 7, 23, 9, 0, 0, 0, time.UTC))
+	blobRepository := storage.NewMockBlobRepository(t)
+	expectedError := errors.New("access denied")
+
+	blobRepository.EXPECT().List(mock.Anything, "").Return(remoteBackupBlobs("kumojin", 3), nil)
+	blobRepository.EXPECT().Delete(mock.Anything, "2025-07-22-kumojin-migration.tar.gz").Return(expectedError)
+
+	useCase := NewPruneBackupsUseCase(blobRepository)
+
+	// When
+	report, err := useCase.Do(context.Background(), "kumojin", RetentionPolicy{Daily: 1}, false)
+
+	// Then
+	assert.ErrorIs(t, err, expectedError)
+	assert.Empty(t, report.Deleted)
+}
+
+func TestPruneBackupsUseCase_PolicyDisabled(t *testing.T) {
+	// Given
+	useCase := NewPruneBackupsUseCase(storage.NewMockBlobRepository(t))
+
+	// When
+	_, err := useCase.Do(context.Background(), "kumojin", RetentionPolicy{}, false)
+
+	// Then
+	assert.ErrorIs(t, err, ErrRetentionPolicyDisabled)
+}
+
+func TestPruneBackupsUseCase_UpdatesCatalog(t *testing.T) {
+	// Given
+	useFakeClock(t, time.Date(2025, 7, 23, 9, 0, 0, 0, time.UTC))
+	blobRepository := storage.NewMockBlobRepository(t)
+	catalog := NewMockCatalogStore(t)
+
+	blobRepository.EXPECT().List(mock.Anything, "").Return(remoteBackupBlobs("kumojin", 3), nil)
+	blobRepository.EXPECT().Delete(mock.Anything, mock.AnythingOfType("string")).Return(nil)
+	catalog.EXPECT().List(mock.Anything).Return(nil, nil)
+	catalog.EXPECT().Delete(mock.Anything, []string{"2025-07-22-kumojin-migration.tar.gz", "2025-07-21-kumojin-migration.tar.gz"}).Return(nil)
+
+	useCase := NewPruneBackupsUseCase(blobRepository).WithCatalog(catalog)
+
+	// When
+	_, err := useCase.Do(context.Background(), "kumojin", RetentionPolicy{Daily: 1}, false)
+	_, dryRunErr := useCase.Do(context.Background(), "kumojin", RetentionPolicy{Daily: 1}, true)
+
+	// Then
+	require.NoError(t, err)
+	require.NoError(t, dryRunErr)
+}
+
+func TestPruneBackupsUseCase_KeepsBaseOfIncrementalBackups(t *testing.T) {
+	// Given
+	useFakeClock(t, time.Date(2025, 7, 23, 9, 0, 0, 0, time.UTC))
+	blobRepository := storage.NewMockBlobRepository(t)
+	catalog := NewMockCatalogStore(t)
+
+	blobRepository.EXPECT().List(mock.Anything, "").Return(remoteBackupBlobs("kumojin", 5), nil)
+	blobRepository.EXPECT().Delete(mock.Anything, mock.AnythingOfType("string")).Return(nil)
+	catalog.EXPECT().List(mock.Anything).Return([]CatalogEntry{
+		{ID: "2025-07-19-kumojin-migration.tar.gz", Organization: "kumojin", Type: BackupTypeFull},
+		{ID: "2025-07-20-kumojin-migration.tar.gz", Organization: "kumojin", Type: BackupTypeIncremental, Base: "2025-07-19-kumojin-migration"},
+		{ID: "2025-07-21-kumojin-migration.tar.gz", Organization: "kumojin", Type: BackupTypeFull},
+		{ID: "2025-07-22-kumojin-migration.tar.gz", Organization: "kumojin", Type: BackupTypeIncremental, Base: "2025-07-21-kumojin-migration"},
+		{ID: "2025-07-23-kumojin-migration.tar.gz", Organization: "kumojin", Type: BackupTypeIncremental, Base: "2025-07-21-kumojin-migration"},
+	}, nil)
+	catalog.EXPECT().Delete(mock.Anything, []string{
+		"2025-07-22-kumojin-migration.tar.gz",
+		"2025-07-20-kumojin-migration.tar.gz",
+		"2025-07-19-kumojin-migration.tar.gz",
+	}).Return(nil)
+
+	useCase := NewPruneBackupsUseCase(blobRepository).WithCatalog(catalog)
+
+	// When
+	report, err := useCase.Do(context.Background(), "kumojin", RetentionPolicy{Daily: 1}, false)
+
+	// Then
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"2025-07-23-kumojin-migration.tar.gz",
+		"2025-07-21-kumojin-migration.tar.gz",
+	}, report.Kept, "the base full backup is kept, not the incremental backups in between")
+	assert.Equal(t, []string{
+		"2025-07-22-kumojin-migration.tar.gz",
+		"2025-07-20-kumojin-migration.tar.gz",
+		"2025-07-19-kumojin-migration.tar.gz",
+	}, report.Deleted)
+}
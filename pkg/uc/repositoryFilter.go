@@ -0,0 +1,140 @@
+package uc
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	gh "github.com/google/go-github/v90/github"
+	"github.com/kumojin/repo-backup-cli/pkg/github"
+)
+
+// RepositoryFilter selects the repositories of an organization, the zero value selects the private repositories
+// which are not archived. Globs are matched without case, regular expressions are matched against the exact name.
+type RepositoryFilter struct {
+	// Include are globs of the names to select, every repository is selected when neither Include nor IncludeRegex is set
+	Include      []string
+	IncludeRegex *regexp.Regexp
+	Exclude      []string
+	ExcludeRegex *regexp.Regexp
+	// IncludeTopics selects the repositories having at least one of the topics
+	IncludeTopics []string
+	ExcludeTopics []string
+	// Visibility is one of the github.Visibility values, private when empty
+	Visibility      string
+	IncludeArchived bool
+	ExcludeForks    bool
+	// MaxSize skips the repositories larger than this size in bytes, as reported by GitHub, zero disables the limit
+	MaxSize uint64
+	// PushedSince skips the repositories which were not pushed to since this time, zero disables the check
+	PushedSince time.Time
+}
+
+func (f RepositoryFilter) Validate() error {
+	for _, pattern := range slices.Concat(f.Include, f.Exclude) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid repository name pattern %q: %w", pattern, err)
+		}
+	}
+
+	return github.ValidateVisibility(f.visibility())
+}
+
+func (f RepositoryFilter) visibility() string {
+	if f.Visibility == "" {
+		return github.VisibilityPrivate
+	}
+	return f.Visibility
+}
+
+// listType returns the type of repositories to request from GitHub, which does not have one for internal repositories
+func (f RepositoryFilter) listType() string {
+	switch f.visibility() {
+	case github.VisibilityPrivate, github.VisibilityPublic:
+		return f.visibility()
+	default:
+		return github.VisibilityAll
+	}
+}
+
+// Match tells whether the repository is selected by the filter
+func (f RepositoryFilter) Match(repo *gh.Repository) bool {
+	return f.ExclusionReason(repo) == ""
+}
+
+// ExclusionReason tells why the repository is not selected by the filter, it is empty when the repository is selected
+func (f RepositoryFilter) ExclusionReason(repo *gh.Repository) string {
+	name := repo.GetName()
+
+	if len(f.Include) > 0 || f.IncludeRegex != nil {
+		if !matchesAnyGlob(f.Include, name) && !matchesRegex(f.IncludeRegex, name) {
+			return "not included by the name filter"
+		}
+	}
+	if matchesAnyGlob(f.Exclude, name) || matchesRegex(f.ExcludeRegex, name) {
+		return "excluded by the name filter"
+	}
+
+	if len(f.IncludeTopics) > 0 && !hasAnyTopic(repo, f.IncludeTopics) {
+		return "has none of the included topics"
+	}
+	if hasAnyTopic(repo, f.ExcludeTopics) {
+		return "has an excluded topic"
+	}
+
+	if f.visibility() != github.VisibilityAll && repositoryVisibility(repo) != f.visibility() {
+		return fmt.Sprintf("visibility is %s", repositoryVisibility(repo))
+	}
+	if repo.GetArchived() && !f.IncludeArchived {
+		return "archived"
+	}
+	if repo.GetFork() && f.ExcludeForks {
+		return "fork"
+	}
+
+	// GitHub reports the size in kilobytes
+	if f.MaxSize > 0 && uint64(repo.GetSize())*1024 > f.MaxSize {
+		return "larger than the maximum size"
+	}
+	if !f.PushedSince.IsZero() && repo.GetPushedAt().Before(f.PushedSince) {
+		return fmt.Sprintf("not pushed since %s", f.PushedSince.Format(time.RFC3339))
+	}
+
+	return ""
+}
+
+func matchesAnyGlob(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(strings.ToLower(pattern), strings.ToLower(name)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesRegex(pattern *regexp.Regexp, name string) bool {
+	return pattern != nil && pattern.MatchString(name)
+}
+
+func hasAnyTopic(repo *gh.Repository, topics []string) bool {
+	for _, topic := range repo.GetTopics() {
+		if slices.ContainsFunc(topics, func(wanted string) bool { return strings.EqualFold(wanted, topic) }) {
+			return true
+		}
+	}
+	return false
+}
+
+// repositoryVisibility returns the visibility of the repository, older GitHub Enterprise Server versions only report whether it is private
+func repositoryVisibility(repo *gh.Repository) string {
+	if visibility := repo.GetVisibility(); visibility != "" {
+		return visibility
+	}
+	if repo.GetPrivate() {
+		return github.VisibilityPrivate
+	}
+	return github.VisibilityPublic
+}
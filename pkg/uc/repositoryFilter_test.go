@@ -0,0 +1,132 @@
+package uc
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	gh "github.com/google/go-github/v90/github"
+	"github.com/kumojin/repo-backup-cli/pkg/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepositoryFilter_Match(t *testing.T) {
+	pushedAt := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	repo := func(update func(repo *gh.Repository)) *gh.Repository {
+		repo := &gh.Repository{
+			Name:     gh.Ptr("Api-Server"),
+			Private:  gh.Ptr(true),
+			Archived: gh.Ptr(false),
+			Fork:     gh.Ptr(false),
+			Topics:   []string{"backend", "go"},
+			Size:     gh.Ptr(2048),
+			PushedAt: &gh.Timestamp{Time: pushedAt},
+		}
+		if update != nil {
+			update(repo)
+		}
+		return repo
+	}
+
+	tests := []struct {
+		name     string
+		filter   RepositoryFilter
+		repo     *gh.Repository
+		expected bool
+	}{
+		{name: "zero value selects private repositories", filter: RepositoryFilter{}, repo: repo(nil), expected: true},
+		{name: "zero value skips archived repositories", filter: RepositoryFilter{}, repo: repo(func(r *gh.Repository) { r.Archived = gh.Ptr(true) }), expected: false},
+		{name: "zero value skips public repositories", filter: RepositoryFilter{}, repo: repo(func(r *gh.Repository) { r.Private = gh.Ptr(false) }), expected: false},
+		{name: "archived repositories included", filter: RepositoryFilter{IncludeArchived: true}, repo: repo(func(r *gh.Repository) { r.Archived = gh.Ptr(true) }), expected: true},
+
+		{name: "include glob without case", filter: RepositoryFilter{Include: []string{"api-*"}}, repo: repo(nil), expected: true},
+		{name: "include glob not matching", filter: RepositoryFilter{Include: []string{"web-*"}}, repo: repo(nil), expected: false},
+		{name: "include regex", filter: RepositoryFilter{Include: []string{"web-*"}, IncludeRegex: regexp.MustCompile(`^Api-`)}, repo: repo(nil), expected: true},
+		{name: "exclude glob", filter: RepositoryFilter{Exclude: []string{"*-server"}}, repo: repo(nil), expected: false},
+		{name: "exclude wins over include", filter: RepositoryFilter{Include: []string{"api-*"}, Exclude: []string{"*-server"}}, repo: repo(nil), expected: false},
+		{name: "exclude regex", filter: RepositoryFilter{ExcludeRegex: regexp.MustCompile(`(?i)server$`)}, repo: repo(nil), expected: false},
+
+		{name: "include topic", filter: RepositoryFilter{IncludeTopics: []string{"frontend", "Go"}}, repo: repo(nil), expected: true},
+		{name: "include topic missing", filter: RepositoryFilter{IncludeTopics: []string{"frontend"}}, repo: repo(nil), expected: false},
+		{name: "exclude topic", filter: RepositoryFilter{ExcludeTopics: []string{"backend"}}, repo: repo(nil), expected: false},
+
+		{name: "internal visibility", filter: RepositoryFilter{Visibility: github.VisibilityInternal}, repo: repo(func(r *gh.Repository) { r.Visibility = gh.Ptr("internal") }), expected: true},
+		{name: "internal visibility skips private", filter: RepositoryFilter{Visibility: github.VisibilityInternal}, repo: repo(nil), expected: false},
+		{name: "public visibility", filter: RepositoryFilter{Visibility: github.VisibilityPublic}, repo: repo(func(r *gh.Repository) { r.Private = gh.Ptr(false) }), expected: true},
+		{name: "all visibilities", filter: RepositoryFilter{Visibility: github.VisibilityAll}, repo: repo(func(r *gh.Repository) { r.Visibility = gh.Ptr("public") }), expected: true},
+
+		{name: "forks included by default", filter: RepositoryFilter{}, repo: repo(func(r *gh.Repository) { r.Fork = gh.Ptr(true) }), expected: true},
+		{name: "forks excluded", filter: RepositoryFilter{ExcludeForks: true}, repo: repo(func(r *gh.Repository) { r.Fork = gh.Ptr(true) }), expected: false},
+
+		{name: "size below ceiling", filter: RepositoryFilter{MaxSize: 2048 * 1024}, repo: repo(nil), expected: true},
+		{name: "size above ceiling", filter: RepositoryFilter{MaxSize: 1024 * 1024}, repo: repo(nil), expected: false},
+
+		{name: "pushed since", filter: RepositoryFilter{PushedSince: pushedAt.Add(-time.Hour)}, repo: repo(nil), expected: true},
+		{name: "not pushed since", filter: RepositoryFilter{PushedSince: pushedAt.Add(time.Hour)}, repo: repo(nil), expected: false},
+		{name: "never pushed", filter: RepositoryFilter{PushedSince: pushedAt}, repo: repo(func(r *gh.Repository) { r.PushedAt = nil }), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.filter.Match(tt.repo))
+		})
+	}
+}
+
+func TestRepositoryFilter_ExclusionReason(t *testing.T) {
+	repo := &gh.Repository{
+		Name:     gh.Ptr("api-server"),
+		Private:  gh.Ptr(true),
+		Archived: gh.Ptr(true),
+		PushedAt: &gh.Timestamp{Time: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	tests := []struct {
+		name     string
+		filter   RepositoryFilter
+		expected string
+	}{
+		{name: "selected", filter: RepositoryFilter{IncludeArchived: true}, expected: ""},
+		{name: "archived", filter: RepositoryFilter{}, expected: "archived"},
+		{name: "name", filter: RepositoryFilter{Exclude: []string{"api-*"}}, expected: "excluded by the name filter"},
+		{name: "visibility", filter: RepositoryFilter{Visibility: github.VisibilityPublic}, expected: "visibility is private"},
+		{
+			name:     "pushed since",
+			filter:   RepositoryFilter{IncludeArchived: true, PushedSince: time.Date(2025, 7, 2, 0, 0, 0, 0, time.UTC)},
+			expected: "not pushed since 2025-07-02T00:00:00Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.filter.ExclusionReason(repo))
+			assert.Equal(t, tt.expected == "", tt.filter.Match(repo))
+		})
+	}
+}
+
+func TestRepositoryFilter_ListType(t *testing.T) {
+	tests := []struct {
+		visibility string
+		expected   string
+	}{
+		{visibility: "", expected: "private"},
+		{visibility: github.VisibilityPrivate, expected: "private"},
+		{visibility: github.VisibilityPublic, expected: "public"},
+		{visibility: github.VisibilityInternal, expected: "all"},
+		{visibility: github.VisibilityAll, expected: "all"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected+"/"+tt.visibility, func(t *testing.T) {
+			assert.Equal(t, tt.expected, RepositoryFilter{Visibility: tt.visibility}.listType())
+		})
+	}
+}
+
+func TestRepositoryFilter_Validate(t *testing.T) {
+	assert.NoError(t, RepositoryFilter{}.Validate())
+	assert.NoError(t, RepositoryFilter{Include: []string{"api-*"}, Visibility: github.VisibilityAll}.Validate())
+	assert.Error(t, RepositoryFilter{Include: []string{"api-["}}.Validate())
+	assert.Error(t, RepositoryFilter{Visibility: "secret"}.Validate())
+}
@@ -0,0 +1,223 @@
+package uc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	gh "github.com/google/go-github/v90/github"
+	"github.com/kumojin/repo-backup-cli/pkg/archive"
+	"github.com/kumojin/repo-backup-cli/pkg/git"
+	"github.com/kumojin/repo-backup-cli/pkg/github"
+	"github.com/kumojin/repo-backup-cli/pkg/logging"
+	"github.com/kumojin/repo-backup-cli/pkg/migration"
+)
+
+type RestoreBackupUseCase interface {
+	// Do restores the repositories of the archive in the organization and returns the actions performed.
+	// When dryRun is set, the actions are only planned and nothing is created.
+	Do(ctx context.Context, reader io.Reader, organization string, dryRun bool) ([]string, error)
+}
+
+type restoreBackupUseCase struct {
+	githubClient github.Client
+	gitClient    git.Client
+}
+
+// restoreAction is a single step of a restore
+type restoreAction struct {
+	description string
+	run         func(ctx context.Context) error
+}
+
+// restoredRepository holds what is learned about a repository while it is being restored
+type restoredRepository struct {
+	cloneURL         string
+	milestoneNumbers map[string]int
+}
+
+func NewRestoreBackupUseCase(githubClient github.Client, gitClient git.Client) RestoreBackupUseCase {
+	return &restoreBackupUseCase{
+		githubClient: githubClient,
+		gitClient:    gitClient,
+	}
+}
+
+func (uc *restoreBackupUseCase) Do(ctx context.Context, reader io.Reader, organization string, dryRun bool) ([]string, error) {
+	workDir, err := os.MkdirTemp("", "rbk-restore-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create working directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(workDir) }()
+
+	if err := archive.ExtractTarGz(reader, workDir); err != nil {
+		return nil, fmt.Errorf("failed to extract archive: %w", err)
+	}
+
+	backup, err := migration.Open(workDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	if len(backup.Repositories) == 0 {
+		return nil, ErrNoRepositories
+	}
+
+	var actions []restoreAction
+	for _, repository := range backup.Repositories {
+		actions = append(actions, uc.planRepository(organization, repository)...)
+	}
+
+	logger := logging.NewLogger(ctx).With(
+		slog.String("organization", organization),
+		slog.Bool("dryRun", dryRun),
+	)
+
+	var descriptions []string
+	for _, action := range actions {
+		if !dryRun {
+			logger.Info("restoring", slog.String("action", action.description))
+
+			if err := action.run(ctx); err != nil {
+				return descriptions, fmt.Errorf("failed to %s: %w", action.description, err)
+			}
+		}
+
+		descriptions = append(descriptions, action.description)
+	}
+
+	return descriptions, nil
+}
+
+// planRepository returns the actions restoring a repository, its git data, labels, milestones and issues
+func (uc *restoreBackupUseCase) planRepository(organization string, repository migration.Repository) []restoreAction {
+	name := repository.Name
+	fullName := organization + "/" + name
+	restored := &restoredRepository{milestoneNumbers: make(map[string]int)}
+
+	actions := []restoreAction{{
+		description: fmt.Sprintf("create repository %s", fullName),
+		run: func(ctx context.Context) error {
+			created, err := uc.githubClient.CreateRepository(ctx, organization, &gh.Repository{
+				Name:        gh.Ptr(name),
+				Description: gh.Ptr(repository.Description),
+				Homepage:    repository.Website,
+				Private:     gh.Ptr(repository.Private),
+				HasIssues:   gh.Ptr(repository.HasIssues),
+				HasWiki:     gh.Ptr(repository.HasWiki),
+			})
+			if err != nil {
+				return err
+			}
+
+			restored.cloneURL = created.GetCloneURL()
+			return nil
+		},
+	}}
+
+	if repository.GitPath != "" {
+		actions = append(actions, restoreAction{
+			description: fmt.Sprintf("push git data of %s from %s", fullName, filepath.Base(repository.GitPath)),
+			run: func(ctx context.Context) error {
+				return uc.pushGitData(ctx, repository, restored.cloneURL)
+			},
+		})
+	}
+
+	for _, label := range repository.Labels {
+		actions = append(actions, restoreAction{
+			description: fmt.Sprintf("create label %q in %s", label.Name, fullName),
+			run: func(ctx context.Context) error {
+				err := uc.githubClient.CreateLabel(ctx, organization, name, gh.CreateIssueLabelRequest{
+					Name:        label.Name,
+					Color:       gh.Ptr(label.Color),
+					Description: gh.Ptr(label.Description),
+				})
+				// New repositories come with default labels which may clash with the restored ones
+				if github.IsAlreadyExists(err) {
+					return nil
+				}
+
+				return err
+			},
+		})
+	}
+
+	for _, milestone := range repository.Milestones {
+		actions = append(actions, restoreAction{
+			description: fmt.Sprintf("create milestone %q in %s", milestone.Title, fullName),
+			run: func(ctx context.Context) error {
+				request := &gh.Milestone{
+					Title:       gh.Ptr(milestone.Title),
+					Description: gh.Ptr(milestone.Description),
+					State:       gh.Ptr(milestone.State),
+				}
+				if milestone.DueOn != nil {
+					request.DueOn = &gh.Timestamp{Time: *milestone.DueOn}
+				}
+
+				created, err := uc.githubClient.CreateMilestone(ctx, organization, name, request)
+				if err != nil {
+					return err
+				}
+
+				restored.milestoneNumbers[milestone.URL] = created.GetNumber()
+				return nil
+			},
+		})
+	}
+
+	for _, issue := range repository.Issues {
+		actions = append(actions, restoreAction{
+			description: fmt.Sprintf("create issue %q in %s", issue.Title, fullName),
+			run: func(ctx context.Context) error {
+				return uc.createIssue(ctx, organization, name, issue, restored)
+			},
+		})
+	}
+
+	return actions
+}
+
+func (uc *restoreBackupUseCase) pushGitData(ctx context.Context, repository migration.Repository, cloneURL string) error {
+	repositoryPath := repository.GitPath
+
+	// Bundles cannot be pushed from directly, they are cloned to a bare repository first
+	if repository.HasGitBundle() {
+		repositoryPath = strings.TrimSuffix(repository.GitPath, filepath.Ext(repository.GitPath)) + ".git"
+		if err := uc.gitClient.MirrorClone(ctx, repository.GitPath, repositoryPath); err != nil {
+			return err
+		}
+	}
+
+	return uc.gitClient.Push(ctx, repositoryPath, cloneURL)
+}
+
+func (uc *restoreBackupUseCase) createIssue(ctx context.Context, organization string, repo string, issue migration.Issue, restored *restoredRepository) error {
+	body := fmt.Sprintf("%s\n\n---\n_Originally opened by %s on %s_", issue.Body, issue.Author(), issue.CreatedAt.Format(time.DateOnly))
+
+	request := gh.CreateIssueRequest{
+		Title:  issue.Title,
+		Body:   gh.Ptr(body),
+		Labels: issue.LabelNames(),
+	}
+	if number, ok := restored.milestoneNumbers[issue.Milestone]; ok {
+		request.Milestone = gh.Ptr(number)
+	}
+
+	created, err := uc.githubClient.CreateIssue(ctx, organization, repo, request)
+	if err != nil {
+		return err
+	}
+
+	if !issue.IsClosed() {
+		return nil
+	}
+
+	return uc.githubClient.CloseIssue(ctx, organization, repo, created.GetNumber())
+}
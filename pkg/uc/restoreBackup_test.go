@@ -0,0 +1,265 @@
+package uc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	gh "github.com/google/go-github/v90/github"
+	"github.com/kumojin/repo-backup-cli/pkg/archive"
+	"github.com/kumojin/repo-backup-cli/pkg/git"
+	"github.com/kumojin/repo-backup-cli/pkg/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGitHubAPI records the restore requests and answers them like the GitHub API would
+type fakeGitHubAPI struct {
+	mu       sync.Mutex
+	cloneURL string
+	requests []string
+	issues   []gh.CreateIssueRequest
+}
+
+func (f *fakeGitHubAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+
+	var body map[string]any
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/orgs/restored/repos":
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		writeJSON(w, http.StatusCreated, map[string]any{"name": body["name"], "clone_url": f.cloneURL})
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/labels"):
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["name"] == "bug" {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]any{
+				"message": "Validation Failed",
+				"errors":  []map[string]string{{"resource": "Label", "code": "already_exists", "field": "name"}},
+			})
+			return
+		}
+		writeJSON(w, http.StatusCreated, body)
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/milestones"):
+		writeJSON(w, http.StatusCreated, map[string]any{"number": 7})
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/issues"):
+		var issue gh.CreateIssueRequest
+		_ = json.NewDecoder(r.Body).Decode(&issue)
+		f.issues = append(f.issues, issue)
+		writeJSON(w, http.StatusCreated, map[string]any{"number": len(f.issues)})
+	case r.Method == http.MethodPatch && strings.Contains(r.URL.Path, "/issues/"):
+		writeJSON(w, http.StatusOK, map[string]any{"state": "closed"})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func newRestoreTestClient(t *testing.T, handler http.Handler) github.Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	baseURL := server.URL + "/"
+	ghClient, err := gh.NewClient(gh.WithURLs(&baseURL, &baseURL))
+	require.NoError(t, err)
+
+	return github.NewClient(ghClient)
+}
+
+// newMigrationArchive builds a tar.gz archive laid out like a GitHub migration archive
+func newMigrationArchive(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	source := strings.TrimPrefix(newLocalGitRepository(t, "repo1", true), "file://")
+	dir := t.TempDir()
+	runGit(t, "", "clone", "--quiet", "--bare", source, filepath.Join(dir, "repositories", "kumojin", "repo1.git"))
+
+	files := map[string]string{
+		"repositories_000001.json": `[{
+			"url": "https://github.com/kumojin/repo1",
+			"name": "repo1",
+			"description": "First repository",
+			"private": true,
+			"has_issues": true,
+			"labels": [
+				{"url": "https://github.com/kumojin/repo1/labels/bug", "name": "bug", "color": "d73a4a"},
+				{"url": "https://github.com/kumojin/repo1/labels/needs%20review", "name": "needs review", "color": "0075ca"}
+			]
+		}]`,
+		"milestones_000001.json": `[{
+			"url": "https://github.com/kumojin/repo1/milestones/1",
+			"repository": "https://github.com/kumojin/repo1",
+			"title": "v1",
+			"state": "open"
+		}]`,
+		"issues_000001.json": `[{
+			"url": "https://github.com/kumojin/repo1/issues/1",
+			"repository": "https://github.com/kumojin/repo1",
+			"user": "https://github.com/octocat",
+			"title": "Open issue",
+			"body": "Something is broken",
+			"milestone": "https://github.com/kumojin/repo1/milestones/1",
+			"labels": ["https://github.com/kumojin/repo1/labels/needs%20review"],
+			"created_at": "2024-01-02T03:04:05Z"
+		}, {
+			"url": "https://github.com/kumojin/repo1/issues/2",
+			"repository": "https://github.com/kumojin/repo1",
+			"user": "https://github.com/octocat",
+			"title": "Closed issue",
+			"body": "Fixed already",
+			"labels": [],
+			"created_at": "2024-01-03T03:04:05Z",
+			"closed_at": "2024-01-04T03:04:05Z"
+		}]`,
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+
+	buffer := &bytes.Buffer{}
+	require.NoError(t, archive.WriteTarGz(buffer, dir))
+
+	return buffer
+}
+
+// newBareGitRepository creates an empty bare repository standing for the restored repository
+func newBareGitRepository(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "restored.git")
+	runGit(t, "", "init", "--quiet", "--bare", path)
+
+	return path
+}
+
+func listRefs(t *testing.T, repositoryPath string) string {
+	t.Helper()
+
+	output, err := exec.Command("git", "--git-dir", repositoryPath, "for-each-ref", "--format=%(refname)").CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	return strings.TrimSpace(string(output))
+}
+
+func TestRestoreBackupUseCase_Success(t *testing.T) {
+	// Given
+	reader := newMigrationArchive(t)
+	target := newBareGitRepository(t)
+	api := &fakeGitHubAPI{cloneURL: "file://" + target}
+
+	useCase := NewRestoreBackupUseCase(newRestoreTestClient(t, api), git.NewClient(""))
+
+	// When
+	actions, err := useCase.Do(context.Background(), reader, "restored", false)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"create repository restored/repo1",
+		"push git data of restored/repo1 from repo1.git",
+		`create label "bug" in restored/repo1`,
+		`create label "needs review" in restored/repo1`,
+		`create milestone "v1" in restored/repo1`,
+		`create issue "Open issue" in restored/repo1`,
+		`create issue "Closed issue" in restored/repo1`,
+	}, actions)
+
+	assert.Equal(t, []string{
+		"POST /orgs/restored/repos",
+		"POST /repos/restored/repo1/labels",
+		"POST /repos/restored/repo1/labels",
+		"POST /repos/restored/repo1/milestones",
+		"POST /repos/restored/repo1/issues",
+		"POST /repos/restored/repo1/issues",
+		"PATCH /repos/restored/repo1/issues/2",
+	}, api.requests)
+
+	require.Len(t, api.issues, 2)
+	assert.Equal(t, "Open issue", api.issues[0].Title)
+	assert.Equal(t, []string{"needs review"}, api.issues[0].GetLabels())
+	assert.Equal(t, 7, api.issues[0].GetMilestone())
+	assert.Contains(t, api.issues[0].GetBody(), "Something is broken")
+	assert.Contains(t, api.issues[0].GetBody(), "Originally opened by octocat on 2024-01-02")
+	assert.Nil(t, api.issues[1].Milestone)
+
+	assert.NotEmpty(t, listRefs(t, target))
+}
+
+func TestRestoreBackupUseCase_MirrorBundle(t *testing.T) {
+	// Given
+	source := strings.TrimPrefix(newLocalGitRepository(t, "repo2", true), "file://")
+	dir := t.TempDir()
+	bundleDir := filepath.Join(dir, "repositories", "kumojin")
+	require.NoError(t, os.MkdirAll(bundleDir, 0o755))
+	runGit(t, source, "bundle", "create", "--quiet", filepath.Join(bundleDir, "repo2.bundle"), "--all")
+
+	reader := &bytes.Buffer{}
+	require.NoError(t, archive.WriteTarGz(reader, dir))
+
+	target := newBareGitRepository(t)
+	api := &fakeGitHubAPI{cloneURL: "file://" + target}
+
+	useCase := NewRestoreBackupUseCase(newRestoreTestClient(t, api), git.NewClient(""))
+
+	// When
+	actions, err := useCase.Do(context.Background(), reader, "restored", false)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"create repository restored/repo2",
+		"push git data of restored/repo2 from repo2.bundle",
+	}, actions)
+	assert.Equal(t, []string{"POST /orgs/restored/repos"}, api.requests)
+	assert.Equal(t, listRefs(t, filepath.Join(source, ".git")), listRefs(t, target))
+}
+
+func TestRestoreBackupUseCase_DryRun(t *testing.T) {
+	// Given
+	reader := newMigrationArchive(t)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s during a dry run", r.Method, r.URL.Path)
+	})
+
+	useCase := NewRestoreBackupUseCase(newRestoreTestClient(t, handler), git.NewMockClient(t))
+
+	// When
+	actions, err := useCase.Do(context.Background(), reader, "restored", true)
+
+	// Then
+	require.NoError(t, err)
+	assert.Len(t, actions, 7)
+	assert.Equal(t, "create repository restored/repo1", actions[0])
+}
+
+func TestRestoreBackupUseCase_EmptyArchive(t *testing.T) {
+	// Given
+	reader := &bytes.Buffer{}
+	require.NoError(t, archive.WriteTarGz(reader, t.TempDir()))
+
+	useCase := NewRestoreBackupUseCase(github.NewMockClient(t), git.NewMockClient(t))
+
+	// When
+	actions, err := useCase.Do(context.Background(), reader, "restored", false)
+
+	// Then
+	assert.ErrorIs(t, err, ErrNoRepositories)
+	assert.Empty(t, actions)
+}
@@ -0,0 +1,81 @@
+package uc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/kumojin/repo-backup-cli/pkg/github"
+	"github.com/kumojin/repo-backup-cli/pkg/logging"
+)
+
+// migrationResumer finds the migrations of an interrupted backup in the saved state, so that their export is not
+// thrown away. Only the recorded migrations are resumed: the state is deleted once a backup completes, so a migration
+// listed by GitHub without a state may have been saved already.
+type migrationResumer struct {
+	githubClient github.Client
+	organization string
+	contents     github.MigrationContents
+	enabled      bool
+	logger       *slog.Logger
+
+	state            MigrationState
+	hasState         bool
+	resumedFromState bool
+}
+
+func (uc *createBackupUseCase) newMigrationResumer(ctx context.Context, organization string, contents github.MigrationContents) *migrationResumer {
+	resumer := &migrationResumer{
+		githubClient: uc.githubClient,
+		organization: organization,
+		contents:     contents,
+		enabled:      uc.resume,
+		logger:       logging.NewLogger(ctx).With(slog.String("organization", organization)),
+	}
+
+	if uc.stateStore == nil {
+		return resumer
+	}
+
+	state, err := uc.stateStore.Load(ctx, organization)
+	switch {
+	case errors.Is(err, ErrMigrationStateNotFound):
+	case err != nil:
+		resumer.logger.Warn("could not load migration state", slog.Any("error", err))
+	case !uc.resume:
+		resumer.logger.Warn("found the migrations of an interrupted backup, resume is disabled so new migrations are started")
+	default:
+		resumer.state = state
+		resumer.hasState = true
+	}
+
+	return resumer
+}
+
+// find returns a migration which can be polled again for the repositories
+func (r *migrationResumer) find(ctx context.Context, repoNames []string) (int64, bool) {
+	if !r.enabled || !r.hasState {
+		return 0, false
+	}
+
+	migrationID, ok := r.state.findStartedMigration(r.organization, r.contents, repoNames, getCurrentTime())
+	if !ok || !r.isResumable(ctx, migrationID) {
+		return 0, false
+	}
+
+	r.resumedFromState = true
+	r.logger.Info("resuming migration from saved state", slog.Int64("migrationID", migrationID))
+
+	return migrationID, true
+}
+
+// isResumable tells whether the saved migration still exists and did not fail
+func (r *migrationResumer) isResumable(ctx context.Context, migrationID int64) bool {
+	migration, err := r.githubClient.GetMigrationStatus(ctx, r.organization, migrationID)
+	if err != nil {
+		r.logger.Warn("could not get status of saved migration", slog.Int64("migrationID", migrationID), slog.Any("error", err))
+		return false
+	}
+
+	return migration.GetState() != "failed"
+}
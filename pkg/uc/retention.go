@@ -0,0 +1,205 @@
+package uc
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"time"
+)
+
+// remoteBackupNamePattern matches the blobs written by createRemoteBackupUseCase: the archive, the archives of
+// the batches and their manifest, the archives split by repository, and the run report. Anything may follow, such as the extension
+// of an encrypted archive.
+var remoteBackupNamePattern = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})(?:-(.+)-migration(?:-batch-\d+-of-\d+\.tar\.gz|-batches\.json|-report\.json|\.tar\.gz)|/([^/.]+)(?:/[^/]+\.tar\.gz|\.metadata(?:-batch-\d+-of-\d+)?\.tar\.gz))`)
+
+// RetentionPolicy is a grandfather-father-son policy: the newest backup of each of the last Daily days, Weekly
+// weeks, Monthly months and Yearly years is kept, counting the current period
+type RetentionPolicy struct {
+	Daily   int
+	Weekly  int
+	Monthly int
+	Yearly  int
+}
+
+// IsEnabled tells whether the policy keeps any backup, pruning with a policy keeping nothing is refused
+func (p RetentionPolicy) IsEnabled() bool {
+	return p.Daily > 0 || p.Weekly > 0 || p.Monthly > 0 || p.Yearly > 0
+}
+
+// remoteBackup groups the blobs of a single backup run of an organization
+type remoteBackup struct {
+	Date         time.Time
+	Organization string
+	Blobs        []string
+}
+
+// parseRemoteBackupName returns the date and organization of a blob written by createRemoteBackupUseCase
+func parseRemoteBackupName(blobName string) (time.Time, string, bool) {
+	matches := remoteBackupNamePattern.FindStringSubmatch(blobName)
+	if matches == nil {
+		return time.Time{}, "", false
+	}
+
+	date, err := time.Parse(time.DateOnly, matches[1])
+	if err != nil {
+		return time.Time{}, "", false
+	}
+
+	organization := matches[2]
+	if organization == "" {
+		organization = matches[3]
+	}
+
+	return date, organization, true
+}
+
+// groupRemoteBackups returns the backups of the organization found in blobNames, newest first
+func groupRemoteBackups(blobNames []string, organization string) []remoteBackup {
+	byDate := make(map[time.Time]*remoteBackup)
+	for _, blobName := range blobNames {
+		date, blobOrganization, ok := parseRemoteBackupName(blobName)
+		if !ok || blobOrganization != organization {
+			continue
+		}
+
+		backup, ok := byDate[date]
+		if !ok {
+			backup = &remoteBackup{Date: date, Organization: organization}
+			byDate[date] = backup
+		}
+		backup.Blobs = append(backup.Blobs, blobName)
+	}
+
+	backups := make([]remoteBackup, 0, len(byDate))
+	for _, backup := range byDate {
+		sort.Strings(backup.Blobs)
+		backups = append(backups, *backup)
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Date.After(backups[j].Date)
+	})
+
+	return backups
+}
+
+// retentionRule keeps the newest backup of each of the last count periods
+type retentionRule struct {
+	count int
+	// start returns the start of the period containing the date
+	start func(date time.Time) time.Time
+	// previous returns the start of the period n periods before the one starting at date
+	previous func(date time.Time, n int) time.Time
+}
+
+func (p RetentionPolicy) rules() []retentionRule {
+	return []retentionRule{
+		{
+			count:    p.Daily,
+			start:    func(date time.Time) time.Time { return date },
+			previous: func(date time.Time, n int) time.Time { return date.AddDate(0, 0, -n) },
+		},
+		{
+			count: p.Weekly,
+			start: func(date time.Time) time.Time {
+				// Weeks start on monday
+				return date.AddDate(0, 0, -(int(date.Weekday())+6)%7)
+			},
+			previous: func(date time.Time, n int) time.Time { return date.AddDate(0, 0, -7*n) },
+		},
+		{
+			count: p.Monthly,
+			start: func(date time.Time) time.Time {
+				return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+			},
+			previous: func(date time.Time, n int) time.Time { return date.AddDate(0, -n, 0) },
+		},
+		{
+			count: p.Yearly,
+			start: func(date time.Time) time.Time {
+				return time.Date(date.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+			},
+			previous: func(date time.Time, n int) time.Time { return date.AddDate(-n, 0, 0) },
+		},
+	}
+}
+
+// apply splits the backups, sorted newest first, between the ones to keep and the ones to delete.
+// The newest backup and backups dated after now are always kept.
+func (p RetentionPolicy) apply(backups []remoteBackup, now time.Time) (keep []remoteBackup, remove []remoteBackup) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	kept := make([]bool, len(backups))
+	if len(backups) > 0 {
+		kept[0] = true
+	}
+
+	for _, rule := range p.rules() {
+		if rule.count <= 0 {
+			continue
+		}
+
+		current := rule.start(today)
+		oldest := rule.previous(current, rule.count-1)
+		seen := make(map[time.Time]bool)
+
+		for i, backup := range backups {
+			if backup.Date.After(today) {
+				kept[i] = true
+				continue
+			}
+
+			period := rule.start(backup.Date)
+			if period.Before(oldest) || seen[period] {
+				continue
+			}
+
+			seen[period] = true
+			kept[i] = true
+		}
+	}
+
+	for i, backup := range backups {
+		if kept[i] {
+			keep = append(keep, backup)
+		} else {
+			remove = append(remove, backup)
+		}
+	}
+
+	return keep, remove
+}
+
+// keepBaseBackups moves the backups that the kept incremental backups depend on, according to the catalog entries,
+// from the backups to delete to the backups to keep. An incremental backup holds every repository changed since its
+// base full backup, restoring it only needs the base.
+func keepBaseBackups(entries []CatalogEntry, keep []remoteBackup, remove []remoteBackup) ([]remoteBackup, []remoteBackup) {
+	kept := make(map[string]bool)
+	for _, backup := range keep {
+		for _, blobName := range backup.Blobs {
+			kept[blobName] = true
+		}
+	}
+
+	var baseDates []time.Time
+	for _, entry := range entries {
+		if entry.Type != BackupTypeIncremental || entry.Base == "" || !kept[entry.ID] {
+			continue
+		}
+
+		if baseDate, _, ok := parseRemoteBackupName(entry.Base + archiveExtension); ok {
+			baseDates = append(baseDates, baseDate)
+		}
+	}
+
+	var stillRemoved []remoteBackup
+	for _, backup := range remove {
+		if slices.ContainsFunc(baseDates, backup.Date.Equal) {
+			keep = append(keep, backup)
+		} else {
+			stillRemoved = append(stillRemoved, backup)
+		}
+	}
+
+	return keep, stillRemoved
+}
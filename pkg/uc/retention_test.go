@@ -0,0 +1,211 @@
+package uc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRemoteBackupName(t *testing.T) {
+	tests := []struct {
+		name         string
+		blobName     string
+		expectedDate string
+		expectedOrg  string
+		expectedOk   bool
+	}{
+		{
+			name:         "archive",
+			blobName:     "2025-07-23-kumojin-migration.tar.gz",
+			expectedDate: "2025-07-23",
+			expectedOrg:  "kumojin",
+			expectedOk:   true,
+		},
+		{
+			name:         "batch archive",
+			blobName:     "2025-07-23-kumojin-migration-batch-2-of-3.tar.gz",
+			expectedDate: "2025-07-23",
+			expectedOrg:  "kumojin",
+			expectedOk:   true,
+		},
+		{
+			name:         "batch manifest",
+			blobName:     "2025-07-23-kumojin-migration-batches.json",
+			expectedDate: "2025-07-23",
+			expectedOrg:  "kumojin",
+			expectedOk:   true,
+		},
+		{
+			name:         "encrypted archive",
+			blobName:     "2025-07-23-kumojin-migration.tar.gz.age",
+			expectedDate: "2025-07-23",
+			expectedOrg:  "kumojin",
+			expectedOk:   true,
+		},
+		{
+			name:         "run report",
+			blobName:     "2025-07-23-kumojin-migration-report.json",
+			expectedDate: "2025-07-23",
+			expectedOrg:  "kumojin",
+			expectedOk:   true,
+		},
+		{
+			name:         "integrity manifest",
+			blobName:     "2025-07-23-kumojin-migration.tar.gz.manifest.json",
+			expectedDate: "2025-07-23",
+			expectedOrg:  "kumojin",
+			expectedOk:   true,
+		},
+		{
+			name:         "organization with dashes",
+			blobName:     "2025-07-23-my-migration-org-migration.tar.gz",
+			expectedDate: "2025-07-23",
+			expectedOrg:  "my-migration-org",
+			expectedOk:   true,
+		},
+		{
+			name:         "repository archive",
+			blobName:     "2025-07-23/kumojin/repo1.tar.gz.age",
+			expectedDate: "2025-07-23",
+			expectedOrg:  "kumojin",
+			expectedOk:   true,
+		},
+		{
+			name:         "shared archive of a batch",
+			blobName:     "2025-07-23/kumojin.metadata-batch-1-of-2.tar.gz.manifest.json",
+			expectedDate: "2025-07-23",
+			expectedOrg:  "kumojin",
+			expectedOk:   true,
+		},
+		{
+			name:       "unrelated directory",
+			blobName:   "2025-07-23/kumojin/notes.txt",
+			expectedOk: false,
+		},
+		{
+			name:       "unrelated blob",
+			blobName:   "notes.txt",
+			expectedOk: false,
+		},
+		{
+			name:       "invalid date",
+			blobName:   "2025-13-45-kumojin-migration.tar.gz",
+			expectedOk: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			date, organization, ok := parseRemoteBackupName(tt.blobName)
+
+			assert.Equal(t, tt.expectedOk, ok)
+			if tt.expectedOk {
+				assert.Equal(t, tt.expectedDate, date.Format(time.DateOnly))
+				assert.Equal(t, tt.expectedOrg, organization)
+			}
+		})
+	}
+}
+
+func TestRetentionPolicy_Apply(t *testing.T) {
+	// Wednesday
+	now := time.Date(2025, 7, 23, 14, 30, 0, 0, time.UTC)
+
+	dailyBackups := func(from string, to string) []remoteBackup {
+		start, _ := time.Parse(time.DateOnly, from)
+		end, _ := time.Parse(time.DateOnly, to)
+
+		var backups []remoteBackup
+		for date := end; !date.Before(start); date = date.AddDate(0, 0, -1) {
+			backups = append(backups, remoteBackup{Date: date})
+		}
+		return backups
+	}
+	backups := func(dates ...string) []remoteBackup {
+		var result []remoteBackup
+		for _, value := range dates {
+			date, _ := time.Parse(time.DateOnly, value)
+			result = append(result, remoteBackup{Date: date})
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		policy   RetentionPolicy
+		backups  []remoteBackup
+		expected []string
+	}{
+		{
+			name:     "no backups",
+			policy:   RetentionPolicy{Daily: 7},
+			backups:  nil,
+			expected: nil,
+		},
+		{
+			name:    "daily",
+			policy:  RetentionPolicy{Daily: 3},
+			backups: dailyBackups("2025-07-01", "2025-07-23"),
+			expected: []string{
+				"2025-07-23", "2025-07-22", "2025-07-21",
+			},
+		},
+		{
+			name:    "weekly starts on monday",
+			policy:  RetentionPolicy{Weekly: 3},
+			backups: dailyBackups("2025-07-01", "2025-07-23"),
+			expected: []string{
+				"2025-07-23", "2025-07-20", "2025-07-13",
+			},
+		},
+		{
+			name:    "grandfather father son",
+			policy:  RetentionPolicy{Daily: 7, Weekly: 4, Monthly: 6, Yearly: 2},
+			backups: dailyBackups("2023-06-01", "2025-07-23"),
+			expected: []string{
+				"2025-07-23", "2025-07-22", "2025-07-21", "2025-07-20", "2025-07-19", "2025-07-18", "2025-07-17",
+				"2025-07-13", "2025-07-06",
+				"2025-06-30", "2025-05-31", "2025-04-30", "2025-03-31", "2025-02-28",
+				"2024-12-31",
+			},
+		},
+		{
+			name:     "periods without backups are not made up",
+			policy:   RetentionPolicy{Daily: 7, Monthly: 2},
+			backups:  backups("2025-07-20", "2025-07-02", "2025-06-15", "2025-05-31", "2025-04-30"),
+			expected: []string{"2025-07-20", "2025-06-15"},
+		},
+		{
+			name:     "newest backup is always kept",
+			policy:   RetentionPolicy{Daily: 7},
+			backups:  backups("2025-03-01", "2025-02-01"),
+			expected: []string{"2025-03-01"},
+		},
+		{
+			name:     "backups after now are kept",
+			policy:   RetentionPolicy{Daily: 1},
+			backups:  backups("2025-07-25", "2025-07-24", "2025-07-23", "2025-07-22"),
+			expected: []string{"2025-07-25", "2025-07-24", "2025-07-23"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keep, remove := tt.policy.apply(tt.backups, now)
+
+			var kept []string
+			for _, backup := range keep {
+				kept = append(kept, backup.Date.Format(time.DateOnly))
+			}
+
+			assert.Equal(t, tt.expected, kept)
+			assert.Len(t, remove, len(tt.backups)-len(keep))
+		})
+	}
+}
+
+func TestRetentionPolicy_IsEnabled(t *testing.T) {
+	assert.False(t, RetentionPolicy{}.IsEnabled())
+	assert.True(t, RetentionPolicy{Yearly: 1}.IsEnabled())
+}
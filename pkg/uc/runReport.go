@@ -0,0 +1,322 @@
+package uc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kumojin/repo-backup-cli/internal/version"
+	"github.com/kumojin/repo-backup-cli/pkg/storage"
+)
+
+// The phases of a backup, downloads and uploads are streamed so their durations overlap
+const (
+	PhaseList     = "list"
+	PhaseMigrate  = "migrate"
+	PhaseDownload = "download"
+	PhaseUpload   = "upload"
+	// PhaseOther is the stage of the failures outside of the phases, such as updating the catalog
+	PhaseOther = "other"
+)
+
+const (
+	ReportStatusSucceeded = "succeeded"
+	ReportStatusFailed    = "failed"
+	// ReportStatusUnchanged is the status of an incremental backup which saved nothing, no repository changed
+	ReportStatusUnchanged = "unchanged"
+)
+
+// RunReport is the machine readable report of a backup run, written once every organization is backed up
+type RunReport struct {
+	StartedAt   time.Time `json:"startedAt"`
+	FinishedAt  time.Time `json:"finishedAt"`
+	ToolVersion string    `json:"toolVersion,omitempty"`
+	// Status is ReportStatusFailed when the backup of any organization failed, ReportStatusSucceeded otherwise
+	Status string `json:"status"`
+	// Destinations are the storage backends the archives are saved to
+	Destinations  []string             `json:"destinations"`
+	Organizations []OrganizationReport `json:"organizations"`
+}
+
+// OrganizationReport is the part of the run report about the backup of an organization
+type OrganizationReport struct {
+	Organization string `json:"organization"`
+	Status       string `json:"status"`
+	Error        string `json:"error,omitempty"`
+	// FailedStage is the phase which failed first, PhaseOther when the failure happened outside of the phases
+	FailedStage string    `json:"failedStage,omitempty"`
+	StartedAt   time.Time `json:"startedAt"`
+	FinishedAt  time.Time `json:"finishedAt"`
+	// PhaseDurations are the seconds spent in each phase, summed over the batches and repositories
+	PhaseDurations map[string]float64 `json:"phaseDurationsSeconds"`
+	// Type is BackupTypeFull or BackupTypeIncremental, Base names the full backup an incremental backup depends on
+	Type     string `json:"type,omitempty"`
+	Base     string `json:"base,omitempty"`
+	Location string `json:"location,omitempty"`
+	// Repositories are the repositories saved in the archives
+	Repositories         []string             `json:"repositories"`
+	ExcludedRepositories []ExcludedRepository `json:"excludedRepositories"`
+	Migrations           []MigrationReport    `json:"migrations,omitempty"`
+	Archives             []ArchiveReport      `json:"archives"`
+}
+
+// ExcludedRepository is a repository of the organization which was not backed up
+type ExcludedRepository struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// MigrationReport is a migration started or resumed by the backup, with the states reported by GitHub
+type MigrationReport struct {
+	ID           int64                  `json:"id"`
+	Batch        int                    `json:"batch"`
+	Repositories []string               `json:"repositories"`
+	States       []MigrationStateChange `json:"states"`
+}
+
+// MigrationStateChange is the first time a state of a migration was seen
+type MigrationStateChange struct {
+	State string    `json:"state"`
+	At    time.Time `json:"at"`
+}
+
+// ArchiveReport is an archive saved by the backup, the duration covers the whole transfer of the archive, from
+// its download or creation to its upload
+type ArchiveReport struct {
+	Name            string   `json:"name"`
+	Batch           int      `json:"batch"`
+	MigrationID     int64    `json:"migrationId,omitempty"`
+	Repositories    []string `json:"repositories"`
+	Location        string   `json:"location"`
+	SHA256          string   `json:"sha256"`
+	Size            int64    `json:"size"`
+	DurationSeconds float64  `json:"durationSeconds"`
+	BytesPerSecond  float64  `json:"bytesPerSecond"`
+}
+
+// RunRecorder records what the backups of a run do for the run report, it is called by the organizations backed up
+// at the same time
+type RunRecorder interface {
+	RecordExcludedRepository(organization string, repository ExcludedRepository)
+	RecordPlan(organization string, backupType string, base string)
+	// RecordMigrationState records the state of a migration when it differs from the last one recorded
+	RecordMigrationState(organization string, archive BackupArchive, state string)
+	RecordArchive(organization string, archive ArchiveReport)
+	// RecordPhase adds the duration to the phase, the first phase recorded with an error is the failed stage
+	RecordPhase(organization string, phase string, duration time.Duration, err error)
+}
+
+// noopRunRecorder is the recorder of the use cases when the run is not reported
+type noopRunRecorder struct{}
+
+func (noopRunRecorder) RecordExcludedRepository(string, ExcludedRepository) {}
+func (noopRunRecorder) RecordPlan(string, string, string)                   {}
+func (noopRunRecorder) RecordMigrationState(string, BackupArchive, string)  {}
+func (noopRunRecorder) RecordArchive(string, ArchiveReport)                 {}
+func (noopRunRecorder) RecordPhase(string, string, time.Duration, error)    {}
+
+// RunReportRecorder builds the RunReport of a run
+type RunReportRecorder struct {
+	mu            sync.Mutex
+	report        RunReport
+	organizations map[string]*OrganizationReport
+}
+
+// NewRunReportRecorder starts the report of a run saving the archives to the destinations
+func NewRunReportRecorder(destinations []string) *RunReportRecorder {
+	return &RunReportRecorder{
+		report: RunReport{
+			StartedAt:    getCurrentTime().UTC(),
+			ToolVersion:  version.Tag,
+			Destinations: destinations,
+		},
+		organizations: make(map[string]*OrganizationReport),
+	}
+}
+
+// organization returns the report of the organization, the caller holds the lock
+func (r *RunReportRecorder) organization(organization string) *OrganizationReport {
+	report, ok := r.organizations[organization]
+	if !ok {
+		report = &OrganizationReport{
+			Organization:         organization,
+			StartedAt:            getCurrentTime().UTC(),
+			PhaseDurations:       map[string]float64{},
+			Repositories:         []string{},
+			ExcludedRepositories: []ExcludedRepository{},
+			Archives:             []ArchiveReport{},
+		}
+		r.organizations[organization] = report
+	}
+
+	return report
+}
+
+// StartOrganization records the start of the backup of the organization
+func (r *RunReportRecorder) StartOrganization(organization string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.organization(organization).StartedAt = getCurrentTime().UTC()
+}
+
+// FinishOrganization records the outcome of the backup of the organization and returns its report
+func (r *RunReportRecorder) FinishOrganization(organization string, location string, err error) OrganizationReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := r.organization(organization)
+	report.FinishedAt = getCurrentTime().UTC()
+	report.Location = location
+
+	switch {
+	case err != nil:
+		report.Status = ReportStatusFailed
+		report.Error = err.Error()
+		if report.FailedStage == "" {
+			report.FailedStage = PhaseOther
+		}
+	case len(report.Archives) == 0:
+		report.Status = ReportStatusUnchanged
+	default:
+		report.Status = ReportStatusSucceeded
+	}
+
+	return *report
+}
+
+func (r *RunReportRecorder) RecordExcludedRepository(organization string, repository ExcludedRepository) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := r.organization(organization)
+	report.ExcludedRepositories = append(report.ExcludedRepositories, repository)
+}
+
+func (r *RunReportRecorder) RecordPlan(organization string, backupType string, base string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := r.organization(organization)
+	report.Type = backupType
+	report.Base = base
+}
+
+func (r *RunReportRecorder) RecordMigrationState(organization string, archive BackupArchive, state string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := r.organization(organization)
+
+	index := slices.IndexFunc(report.Migrations, func(migration MigrationReport) bool {
+		return migration.ID == archive.MigrationID
+	})
+	if index < 0 {
+		report.Migrations = append(report.Migrations, MigrationReport{
+			ID:           archive.MigrationID,
+			Batch:        archive.Batch,
+			Repositories: archive.Repositories,
+		})
+		index = len(report.Migrations) - 1
+	}
+
+	migration := &report.Migrations[index]
+	if len(migration.States) > 0 && migration.States[len(migration.States)-1].State == state {
+		return
+	}
+	migration.States = append(migration.States, MigrationStateChange{State: state, At: getCurrentTime().UTC()})
+}
+
+func (r *RunReportRecorder) RecordArchive(organization string, archive ArchiveReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := r.organization(organization)
+	report.Archives = append(report.Archives, archive)
+	sort.Slice(report.Archives, func(i, j int) bool {
+		return report.Archives[i].Name < report.Archives[j].Name
+	})
+
+	for _, repository := range archive.Repositories {
+		if !slices.Contains(report.Repositories, repository) {
+			report.Repositories = append(report.Repositories, repository)
+		}
+	}
+	sort.Strings(report.Repositories)
+}
+
+func (r *RunReportRecorder) RecordPhase(organization string, phase string, duration time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := r.organization(organization)
+	report.PhaseDurations[phase] += duration.Seconds()
+	if err != nil && report.FailedStage == "" {
+		report.FailedStage = phase
+	}
+}
+
+// Report returns the report of the run with the organizations in the given order
+func (r *RunReportRecorder) Report(organizations []string) RunReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := r.report
+	report.FinishedAt = getCurrentTime().UTC()
+	report.Status = ReportStatusSucceeded
+	report.Organizations = make([]OrganizationReport, 0, len(organizations))
+	for _, organization := range organizations {
+		organizationReport := *r.organization(organization)
+		if organizationReport.Status == ReportStatusFailed {
+			report.Status = ReportStatusFailed
+		}
+		report.Organizations = append(report.Organizations, organizationReport)
+	}
+
+	return report
+}
+
+// newArchiveReport returns the report of an archive whose content was read through checksum in duration
+func newArchiveReport(archiveName string, archive BackupArchive, checksum *checksumReader, location string, duration time.Duration) ArchiveReport {
+	report := ArchiveReport{
+		Name:            archiveName,
+		Batch:           archive.Batch,
+		MigrationID:     archive.MigrationID,
+		Repositories:    archive.Repositories,
+		Location:        location,
+		SHA256:          checksum.SHA256(),
+		Size:            checksum.size,
+		DurationSeconds: duration.Seconds(),
+	}
+	if duration > 0 {
+		report.BytesPerSecond = float64(checksum.size) / duration.Seconds()
+	}
+
+	return report
+}
+
+// runReportFileName returns <date>-<organization>-migration-report.json, next to the archives of the backup
+func runReportFileName(organization string, startedAt time.Time) string {
+	// Dated like the archives, in local time
+	return fmt.Sprintf("%s-%s-migration-report.json", startedAt.Local().Format(time.DateOnly), organization)
+}
+
+// SaveOrganizationReport uploads the report of the organization next to the archives of its backup
+func SaveOrganizationReport(ctx context.Context, blobRepository storage.BlobRepository, report OrganizationReport) (string, error) {
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal run report: %w", err)
+	}
+
+	location, err := blobRepository.Upload(ctx, runReportFileName(report.Organization, report.StartedAt), bytes.NewReader(content))
+	if err != nil {
+		return "", fmt.Errorf("failed to upload run report: %w", err)
+	}
+
+	return location, nil
+}
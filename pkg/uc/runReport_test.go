@@ -0,0 +1,151 @@
+package uc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kumojin/repo-backup-cli/pkg/github"
+	"github.com/kumojin/repo-backup-cli/pkg/storage/filesystem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunReportRecorder_Report(t *testing.T) {
+	// Given
+	useFakeClock(t, time.Date(2025, 7, 23, 2, 0, 0, 0, time.UTC))
+	recorder := NewRunReportRecorder([]string{"azure", "object"})
+	archive := BackupArchive{Batch: 1, BatchCount: 1, MigrationID: 42, Repositories: []string{"repo1"}}
+
+	// When
+	recorder.StartOrganization("kumojin")
+	recorder.RecordExcludedRepository("kumojin", ExcludedRepository{Name: "old", Reason: "archived"})
+	recorder.RecordMigrationState("kumojin", archive, "started")
+	recorder.RecordMigrationState("kumojin", archive, "exporting")
+	recorder.RecordMigrationState("kumojin", archive, "exporting")
+	recorder.RecordMigrationState("kumojin", archive, "exported")
+	recorder.RecordArchive("kumojin", ArchiveReport{Name: "2025-07-23-kumojin-migration.tar.gz", Repositories: []string{"repo1"}})
+	recorder.FinishOrganization("kumojin", "https://storage/2025-07-23-kumojin-migration.tar.gz", nil)
+
+	recorder.StartOrganization("other")
+	recorder.FinishOrganization("other", "", nil)
+
+	recorder.StartOrganization("broken")
+	recorder.FinishOrganization("broken", "", errors.New("migration failed"))
+
+	report := recorder.Report([]string{"kumojin", "other", "broken"})
+
+	// Then
+	assert.Equal(t, ReportStatusFailed, report.Status)
+	assert.Equal(t, []string{"azure", "object"}, report.Destinations)
+	require.Len(t, report.Organizations, 3)
+
+	kumojin := report.Organizations[0]
+	assert.Equal(t, ReportStatusSucceeded, kumojin.Status)
+	assert.Equal(t, []string{"repo1"}, kumojin.Repositories)
+	assert.Equal(t, []ExcludedRepository{{Name: "old", Reason: "archived"}}, kumojin.ExcludedRepositories)
+	require.Len(t, kumojin.Migrations, 1)
+	assert.Equal(t, int64(42), kumojin.Migrations[0].ID)
+
+	var states []string
+	for _, change := range kumojin.Migrations[0].States {
+		states = append(states, change.State)
+	}
+	assert.Equal(t, []string{"started", "exporting", "exported"}, states, "a state is recorded when it changes")
+
+	assert.Equal(t, ReportStatusUnchanged, report.Organizations[1].Status)
+	assert.Equal(t, ReportStatusFailed, report.Organizations[2].Status)
+	assert.Equal(t, "migration failed", report.Organizations[2].Error)
+}
+
+func TestCreateRemoteBackupUseCase_RecordsArchives(t *testing.T) {
+	// Given
+	mocks := newCreateRemoteBackupTestMocks(t)
+	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
+	archiveContent := "mock archive content"
+
+	root := t.TempDir()
+	recorder := NewRunReportRecorder([]string{"filesystem"})
+
+	mocks.createBackupUseCase.EXPECT().
+		Do(mock.Anything, organization, contents, time.Time{}, mock.AnythingOfType("uc.SaveBackupFunc")).
+		RunAndReturn(func(_ context.Context, _ string, _ github.MigrationContents, _ time.Time, saveFunc SaveBackupFunc) ([]BackupArchive, error) {
+			archive := BackupArchive{Batch: 1, BatchCount: 1, MigrationID: 42, Repositories: []string{"repo1", "repo2"}}
+			location, err := saveFunc(archive, strings.NewReader(archiveContent))
+			archive.Location = location
+			return []BackupArchive{archive}, err
+		})
+
+	useCase := NewCreateRemoteBackupUseCase(filesystem.NewBlobRepository(root), mocks.createBackupUseCase).
+		WithRecorder(recorder)
+
+	// When
+	recorder.StartOrganization(organization)
+	location, err := useCase.Do(context.Background(), organization, contents)
+	report := recorder.FinishOrganization(organization, location, err)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, ReportStatusSucceeded, report.Status)
+	assert.Equal(t, BackupTypeFull, report.Type)
+	assert.Equal(t, []string{"repo1", "repo2"}, report.Repositories)
+	assert.Equal(t, []ArchiveReport{{
+		Name:         "2025-07-23-kumojin-migration.tar.gz",
+		Batch:        1,
+		MigrationID:  42,
+		Repositories: []string{"repo1", "repo2"},
+		Location:     filepath.Join(root, "2025-07-23-kumojin-migration.tar.gz"),
+		SHA256:       "b10c4854966ae4b7549a4f1bf964eb09d76b2a9510d543acb81d50c9bbb6e88d",
+		Size:         int64(len(archiveContent)),
+	}}, report.Archives)
+}
+
+func TestSaveOrganizationReport(t *testing.T) {
+	// Given
+	root := t.TempDir()
+	report := OrganizationReport{
+		Organization: "kumojin",
+		Status:       ReportStatusSucceeded,
+		StartedAt:    time.Date(2025, 7, 23, 12, 0, 0, 0, time.Local),
+	}
+
+	// When
+	location, err := SaveOrganizationReport(context.Background(), filesystem.NewBlobRepository(root), report)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "2025-07-23-kumojin-migration-report.json"), location)
+
+	content, err := os.ReadFile(location)
+	require.NoError(t, err)
+	var saved OrganizationReport
+	require.NoError(t, json.Unmarshal(content, &saved))
+	assert.Equal(t, report.Status, saved.Status)
+}
+
+func TestRunReportRecorder_FailedStage(t *testing.T) {
+	// Given
+	recorder := NewRunReportRecorder(nil)
+	downloadErr := errors.New("connection reset")
+
+	// When
+	recorder.RecordPhase("kumojin", PhaseList, time.Second, nil)
+	recorder.RecordPhase("kumojin", PhaseMigrate, 2*time.Second, nil)
+	recorder.RecordPhase("kumojin", PhaseMigrate, 3*time.Second, nil)
+	recorder.RecordPhase("kumojin", PhaseDownload, time.Second, downloadErr)
+	recorder.RecordPhase("kumojin", PhaseUpload, time.Second, downloadErr)
+	failed := recorder.FinishOrganization("kumojin", "", downloadErr)
+	other := recorder.FinishOrganization("other", "", errors.New("failed to update backup catalog"))
+
+	// Then
+	assert.Equal(t, PhaseDownload, failed.FailedStage, "the first failed phase is the failed stage")
+	assert.Equal(t, map[string]float64{PhaseList: 1, PhaseMigrate: 5, PhaseDownload: 1, PhaseUpload: 1}, failed.PhaseDurations)
+	assert.Equal(t, PhaseOther, other.FailedStage)
+}
@@ -0,0 +1,117 @@
+package uc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"github.com/kumojin/repo-backup-cli/pkg/archive"
+	"github.com/kumojin/repo-backup-cli/pkg/migration"
+)
+
+// sharedArchiveSuffix is appended to the organization to name the archive of the files shared by its repositories
+const sharedArchiveSuffix = ".metadata"
+
+// SavePartFunc saves a part of an archive split by repository
+type SavePartFunc func(archive BackupArchive, part migration.Part) error
+
+// splitSaveBackupFunc returns a SaveBackupFunc extracting the archives while saveBackupFunc saves them, the parts
+// of the archive split by repository are then saved with savePart. The archives are only read once.
+func splitSaveBackupFunc(saveBackupFunc SaveBackupFunc, savePart SavePartFunc) SaveBackupFunc {
+	return func(backupArchive BackupArchive, reader io.Reader) (string, error) {
+		workDir, err := os.MkdirTemp("", "rbk-split-*")
+		if err != nil {
+			return "", fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer func() { _ = os.RemoveAll(workDir) }()
+
+		extractReader, extractWriter := io.Pipe()
+		extracted := make(chan error, 1)
+		go func() {
+			err := archive.ExtractTarGz(extractReader, workDir)
+			if err == nil {
+				// The extraction stops at the end of the tarball, before the end of the gzip stream
+				_, err = io.Copy(io.Discard, extractReader)
+			}
+			// A failed extraction fails the save reading through the pipe
+			_ = extractReader.CloseWithError(err)
+			extracted <- err
+		}()
+
+		location, err := saveBackupFunc(backupArchive, io.TeeReader(reader, extractWriter))
+		_ = extractWriter.CloseWithError(err)
+		extractErr := <-extracted
+		if err != nil {
+			return "", err
+		}
+		if extractErr != nil {
+			return "", fmt.Errorf("failed to extract archive: %w", extractErr)
+		}
+
+		parts, err := migration.Split(workDir)
+		if err != nil {
+			return "", fmt.Errorf("failed to split archive: %w", err)
+		}
+
+		for _, part := range parts {
+			if err := savePart(backupArchive, part); err != nil {
+				return "", err
+			}
+		}
+
+		return location, nil
+	}
+}
+
+// partFileName returns <date>/<organization>/<repository>.tar.gz for the part of a repository and
+// <date>/<organization>.metadata.tar.gz, suffixed with the batch, for the shared part. The date is the one of the
+// backup name, so that the parts are pruned along with the archive of a backup running past midnight.
+func partFileName(organization string, archive BackupArchive, part migration.Part, backup backupRun) string {
+	backupDate, _, _ := parseRemoteBackupName(backup.Name + archiveExtension)
+	date := backupDate.Format(time.DateOnly)
+	if part.IsShared() {
+		return archive.FileName(path.Join(date, organization+sharedArchiveSuffix))
+	}
+
+	return path.Join(date, organization, part.Repository+archiveExtension)
+}
+
+// saveRepositoryArchive encrypts and saves a part of an archive with its integrity manifest, it returns the catalog
+// entry of the part
+func (uc *createRemoteBackupUseCase) saveRepositoryArchive(ctx context.Context, organization string, backupArchive BackupArchive, part migration.Part, backup backupRun) (CatalogEntry, error) {
+	blobName := partFileName(organization, backupArchive, part, backup)
+	if uc.encryptor != nil {
+		blobName += uc.encryptor.Extension()
+	}
+
+	partArchive := backupArchive
+	if !part.IsShared() {
+		partArchive.Repositories = []string{part.Repository}
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		_ = writer.CloseWithError(part.WriteTarGz(writer))
+	}()
+
+	var entry CatalogEntry
+	_, err := encryptSaveBackupFunc(uc.encryptor, func(archive BackupArchive, reader io.Reader) (string, error) {
+		location, checksum, err := uc.upload(ctx, organization, blobName, archive, reader, backup.Plan)
+		if err != nil {
+			return "", err
+		}
+
+		entry = newCatalogEntry(organization, blobName, archive, checksum, location, backup)
+		return location, nil
+	})(partArchive, reader)
+	// Stops the writing of the part when the upload failed before reading it all
+	_ = reader.CloseWithError(err)
+	if err != nil {
+		return CatalogEntry{}, fmt.Errorf("failed to save archive %s: %w", blobName, err)
+	}
+
+	return entry, nil
+}
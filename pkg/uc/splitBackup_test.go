@@ -0,0 +1,192 @@
+package uc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/kumojin/repo-backup-cli/pkg/archive"
+	"github.com/kumojin/repo-backup-cli/pkg/encryption"
+	"github.com/kumojin/repo-backup-cli/pkg/github"
+	"github.com/kumojin/repo-backup-cli/pkg/migration"
+	"github.com/kumojin/repo-backup-cli/pkg/storage"
+	"github.com/kumojin/repo-backup-cli/pkg/storage/filesystem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newSyntheticMigrationArchive builds a migration archive of two repositories without any real git data
+func newSyntheticMigrationArchive(t *testing.T) []byte {
+	t.Helper()
+
+	files := map[string]string{
+		"schema.json": `{"version":"1.0.1"}`,
+		"repositories_000001.json": `[
+			{"url":"https://github.com/kumojin/repo1","name":"repo1"},
+			{"url":"https://github.com/kumojin/repo2","name":"repo2"}
+		]`,
+		"issues_000001.json":                  `[{"url":"https://github.com/kumojin/repo2/issues/1","repository":"https://github.com/kumojin/repo2","title":"Issue"}]`,
+		"repositories/kumojin/repo1.git/HEAD": "ref: refs/heads/main",
+		"repositories/kumojin/repo2.git/HEAD": "ref: refs/heads/main",
+	}
+
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	var buffer bytes.Buffer
+	require.NoError(t, archive.WriteTarGz(&buffer, dir))
+
+	return buffer.Bytes()
+}
+
+// openBlobArchive decrypts and extracts an archive saved in the blob repository
+func openBlobArchive(t *testing.T, blobRepository storage.BlobRepository, blobName string, identity age.Identity) *migration.Archive {
+	t.Helper()
+
+	reader, err := blobRepository.Download(context.Background(), blobName)
+	require.NoError(t, err)
+	defer func() { _ = reader.Close() }()
+
+	decrypted, err := age.Decrypt(reader, identity)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, archive.ExtractTarGz(decrypted, dir))
+
+	opened, err := migration.Open(dir)
+	require.NoError(t, err)
+
+	return opened
+}
+
+func TestCreateRemoteBackupUseCase_SplitRepositories(t *testing.T) {
+	// Given
+	mocks := newCreateRemoteBackupTestMocks(t)
+	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
+	migrationArchive := newSyntheticMigrationArchive(t)
+
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	encryptor, err := encryption.NewAgeEncryptor([]string{identity.Recipient().String()})
+	require.NoError(t, err)
+
+	root := t.TempDir()
+	blobRepository := filesystem.NewBlobRepository(root)
+	catalog := NewBlobCatalogStore(filesystem.NewBlobRepository(t.TempDir()))
+
+	mocks.createBackupUseCase.EXPECT().
+		Do(mock.Anything, organization, contents, time.Time{}, mock.AnythingOfType("uc.SaveBackupFunc")).
+		RunAndReturn(func(_ context.Context, _ string, _ github.MigrationContents, _ time.Time, saveFunc SaveBackupFunc) ([]BackupArchive, error) {
+			// The migration ends the next day, the parts are still named after the backup
+			useFakeClock(t, time.Date(2025, 7, 24, 1, 0, 0, 0, time.UTC))
+
+			archive := BackupArchive{Batch: 1, BatchCount: 1, MigrationID: 1, Repositories: []string{"repo1", "repo2"}}
+			location, err := saveFunc(archive, bytes.NewReader(migrationArchive))
+			archive.Location = location
+			return []BackupArchive{archive}, err
+		})
+
+	useCase := NewCreateRemoteBackupUseCase(blobRepository, mocks.createBackupUseCase).
+		WithEncryptor(encryptor).
+		WithCatalog(catalog).
+		WithSplitRepositories(true)
+
+	// When
+	location, err := useCase.Do(context.Background(), organization, contents)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "2025-07-23-kumojin-migration.tar.gz.age"), location)
+
+	blobs, err := blobRepository.List(context.Background(), "")
+	require.NoError(t, err)
+	names := make([]string, len(blobs))
+	for i, blob := range blobs {
+		names[i] = blob.Name
+	}
+	assert.Equal(t, []string{
+		"2025-07-23-kumojin-migration.tar.gz.age",
+		"2025-07-23-kumojin-migration.tar.gz.age.manifest.json",
+		"2025-07-23/kumojin.metadata.tar.gz.age",
+		"2025-07-23/kumojin.metadata.tar.gz.age.manifest.json",
+		"2025-07-23/kumojin/repo1.tar.gz.age",
+		"2025-07-23/kumojin/repo1.tar.gz.age.manifest.json",
+		"2025-07-23/kumojin/repo2.tar.gz.age",
+		"2025-07-23/kumojin/repo2.tar.gz.age.manifest.json",
+	}, names)
+
+	entries, err := catalog.List(context.Background())
+	require.NoError(t, err)
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID
+		assert.Equal(t, "2025-07-23-kumojin-migration", entry.Backup, entry.ID)
+	}
+	assert.Equal(t, []string{
+		"2025-07-23-kumojin-migration.tar.gz.age",
+		"2025-07-23/kumojin.metadata.tar.gz.age",
+		"2025-07-23/kumojin/repo1.tar.gz.age",
+		"2025-07-23/kumojin/repo2.tar.gz.age",
+	}, ids)
+	assert.Equal(t, []string{"repo2"}, entries[3].Repositories)
+
+	whole := openBlobArchive(t, blobRepository, "2025-07-23-kumojin-migration.tar.gz.age", identity)
+	assert.Len(t, whole.Repositories, 2, "the whole archive is still saved")
+
+	repo2 := openBlobArchive(t, blobRepository, "2025-07-23/kumojin/repo2.tar.gz.age", identity)
+	require.Len(t, repo2.Repositories, 1)
+	assert.Equal(t, "repo2", repo2.Repositories[0].Name)
+	assert.Len(t, repo2.Repositories[0].Issues, 1)
+
+	manifestReader, err := blobRepository.Download(context.Background(), "2025-07-23/kumojin/repo2.tar.gz.age.manifest.json")
+	require.NoError(t, err)
+	defer func() { _ = manifestReader.Close() }()
+	result, err := NewVerifyBackupUseCase().Do(context.Background(), manifestReader, mustOpen(t, filepath.Join(root, "2025-07-23", "kumojin", "repo2.tar.gz.age")))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"repo2"}, result.Repositories)
+}
+
+func mustOpen(t *testing.T, path string) io.Reader {
+	t.Helper()
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = file.Close() })
+
+	return file
+}
+
+func TestCreateRemoteBackupUseCase_SplitInvalidArchive(t *testing.T) {
+	// Given
+	mocks := newCreateRemoteBackupTestMocks(t)
+	organization := "kumojin"
+	contents := github.DefaultMigrationContents()
+
+	mocks.createBackupUseCase.EXPECT().
+		Do(mock.Anything, organization, contents, time.Time{}, mock.AnythingOfType("uc.SaveBackupFunc")).
+		RunAndReturn(func(_ context.Context, _ string, _ github.MigrationContents, _ time.Time, saveFunc SaveBackupFunc) ([]BackupArchive, error) {
+			_, err := saveFunc(BackupArchive{Batch: 1, BatchCount: 1}, strings.NewReader(strings.Repeat("not a tarball", 10000)))
+			return nil, err
+		})
+
+	useCase := NewCreateRemoteBackupUseCase(filesystem.NewBlobRepository(t.TempDir()), mocks.createBackupUseCase).
+		WithSplitRepositories(true)
+
+	// When
+	_, err := useCase.Do(context.Background(), organization, contents)
+
+	// Then
+	assert.ErrorContains(t, err, "failed to read archive")
+}
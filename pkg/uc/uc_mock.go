@@ -6,19 +6,1707 @@ package uc
 
 import (
 	"context"
+	"io"
 	"time"
 
-	"github.com/google/go-github/v90/github"
+	github0 "github.com/google/go-github/v90/github"
+	"github.com/kumojin/repo-backup-cli/pkg/download"
+	"github.com/kumojin/repo-backup-cli/pkg/encryption"
+	"github.com/kumojin/repo-backup-cli/pkg/github"
 	mock "github.com/stretchr/testify/mock"
 )
 
+// NewMockBackupOrganizationsUseCase creates a new instance of MockBackupOrganizationsUseCase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockBackupOrganizationsUseCase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockBackupOrganizationsUseCase {
+	mock := &MockBackupOrganizationsUseCase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockBackupOrganizationsUseCase is an autogenerated mock type for the BackupOrganizationsUseCase type
+type MockBackupOrganizationsUseCase struct {
+	mock.Mock
+}
+
+type MockBackupOrganizationsUseCase_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockBackupOrganizationsUseCase) EXPECT() *MockBackupOrganizationsUseCase_Expecter {
+	return &MockBackupOrganizationsUseCase_Expecter{mock: &_m.Mock}
+}
+
+// Do provides a mock function for the type MockBackupOrganizationsUseCase
+func (_mock *MockBackupOrganizationsUseCase) Do(ctx context.Context, organizations []string, backup OrganizationBackupFunc) ([]OrganizationBackupResult, error) {
+	ret := _mock.Called(ctx, organizations, backup)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Do")
+	}
+
+	var r0 []OrganizationBackupResult
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string, OrganizationBackupFunc) ([]OrganizationBackupResult, error)); ok {
+		return returnFunc(ctx, organizations, backup)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string, OrganizationBackupFunc) []OrganizationBackupResult); ok {
+		r0 = returnFunc(ctx, organizations, backup)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]OrganizationBackupResult)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []string, OrganizationBackupFunc) error); ok {
+		r1 = returnFunc(ctx, organizations, backup)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockBackupOrganizationsUseCase_Do_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Do'
+type MockBackupOrganizationsUseCase_Do_Call struct {
+	*mock.Call
+}
+
+// Do is a helper method to define mock.On call
+//   - ctx context.Context
+//   - organizations []string
+//   - backup OrganizationBackupFunc
+func (_e *MockBackupOrganizationsUseCase_Expecter) Do(ctx interface{}, organizations interface{}, backup interface{}) *MockBackupOrganizationsUseCase_Do_Call {
+	return &MockBackupOrganizationsUseCase_Do_Call{Call: _e.mock.On("Do", ctx, organizations, backup)}
+}
+
+func (_c *MockBackupOrganizationsUseCase_Do_Call) Run(run func(ctx context.Context, organizations []string, backup OrganizationBackupFunc)) *MockBackupOrganizationsUseCase_Do_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []string
+		if args[1] != nil {
+			arg1 = args[1].([]string)
+		}
+		var arg2 OrganizationBackupFunc
+		if args[2] != nil {
+			arg2 = args[2].(OrganizationBackupFunc)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockBackupOrganizationsUseCase_Do_Call) Return(organizationBackupResults []OrganizationBackupResult, err error) *MockBackupOrganizationsUseCase_Do_Call {
+	_c.Call.Return(organizationBackupResults, err)
+	return _c
+}
+
+func (_c *MockBackupOrganizationsUseCase_Do_Call) RunAndReturn(run func(ctx context.Context, organizations []string, backup OrganizationBackupFunc) ([]OrganizationBackupResult, error)) *MockBackupOrganizationsUseCase_Do_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockCatalogStore creates a new instance of MockCatalogStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockCatalogStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockCatalogStore {
+	mock := &MockCatalogStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockCatalogStore is an autogenerated mock type for the CatalogStore type
+type MockCatalogStore struct {
+	mock.Mock
+}
+
+type MockCatalogStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockCatalogStore) EXPECT() *MockCatalogStore_Expecter {
+	return &MockCatalogStore_Expecter{mock: &_m.Mock}
+}
+
+// Add provides a mock function for the type MockCatalogStore
+func (_mock *MockCatalogStore) Add(ctx context.Context, entries []CatalogEntry) error {
+	ret := _mock.Called(ctx, entries)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Add")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []CatalogEntry) error); ok {
+		r0 = returnFunc(ctx, entries)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockCatalogStore_Add_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Add'
+type MockCatalogStore_Add_Call struct {
+	*mock.Call
+}
+
+// Add is a helper method to define mock.On call
+//   - ctx context.Context
+//   - entries []CatalogEntry
+func (_e *MockCatalogStore_Expecter) Add(ctx interface{}, entries interface{}) *MockCatalogStore_Add_Call {
+	return &MockCatalogStore_Add_Call{Call: _e.mock.On("Add", ctx, entries)}
+}
+
+func (_c *MockCatalogStore_Add_Call) Run(run func(ctx context.Context, entries []CatalogEntry)) *MockCatalogStore_Add_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []CatalogEntry
+		if args[1] != nil {
+			arg1 = args[1].([]CatalogEntry)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCatalogStore_Add_Call) Return(err error) *MockCatalogStore_Add_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockCatalogStore_Add_Call) RunAndReturn(run func(ctx context.Context, entries []CatalogEntry) error) *MockCatalogStore_Add_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Delete provides a mock function for the type MockCatalogStore
+func (_mock *MockCatalogStore) Delete(ctx context.Context, ids []string) error {
+	ret := _mock.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) error); ok {
+		r0 = returnFunc(ctx, ids)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockCatalogStore_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockCatalogStore_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ids []string
+func (_e *MockCatalogStore_Expecter) Delete(ctx interface{}, ids interface{}) *MockCatalogStore_Delete_Call {
+	return &MockCatalogStore_Delete_Call{Call: _e.mock.On("Delete", ctx, ids)}
+}
+
+func (_c *MockCatalogStore_Delete_Call) Run(run func(ctx context.Context, ids []string)) *MockCatalogStore_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []string
+		if args[1] != nil {
+			arg1 = args[1].([]string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCatalogStore_Delete_Call) Return(err error) *MockCatalogStore_Delete_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockCatalogStore_Delete_Call) RunAndReturn(run func(ctx context.Context, ids []string) error) *MockCatalogStore_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Get provides a mock function for the type MockCatalogStore
+func (_mock *MockCatalogStore) Get(ctx context.Context, id string) (CatalogEntry, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 CatalogEntry
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (CatalogEntry, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) CatalogEntry); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Get(0).(CatalogEntry)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCatalogStore_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type MockCatalogStore_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *MockCatalogStore_Expecter) Get(ctx interface{}, id interface{}) *MockCatalogStore_Get_Call {
+	return &MockCatalogStore_Get_Call{Call: _e.mock.On("Get", ctx, id)}
+}
+
+func (_c *MockCatalogStore_Get_Call) Run(run func(ctx context.Context, id string)) *MockCatalogStore_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCatalogStore_Get_Call) Return(catalogEntry CatalogEntry, err error) *MockCatalogStore_Get_Call {
+	_c.Call.Return(catalogEntry, err)
+	return _c
+}
+
+func (_c *MockCatalogStore_Get_Call) RunAndReturn(run func(ctx context.Context, id string) (CatalogEntry, error)) *MockCatalogStore_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function for the type MockCatalogStore
+func (_mock *MockCatalogStore) List(ctx context.Context) ([]CatalogEntry, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []CatalogEntry
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]CatalogEntry, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []CatalogEntry); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]CatalogEntry)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCatalogStore_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type MockCatalogStore_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockCatalogStore_Expecter) List(ctx interface{}) *MockCatalogStore_List_Call {
+	return &MockCatalogStore_List_Call{Call: _e.mock.On("List", ctx)}
+}
+
+func (_c *MockCatalogStore_List_Call) Run(run func(ctx context.Context)) *MockCatalogStore_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCatalogStore_List_Call) Return(catalogEntrys []CatalogEntry, err error) *MockCatalogStore_List_Call {
+	_c.Call.Return(catalogEntrys, err)
+	return _c
+}
+
+func (_c *MockCatalogStore_List_Call) RunAndReturn(run func(ctx context.Context) ([]CatalogEntry, error)) *MockCatalogStore_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockCreateBackupUseCase creates a new instance of MockCreateBackupUseCase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
-func NewMockCreateBackupUseCase(t interface {
+func NewMockCreateBackupUseCase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockCreateBackupUseCase {
+	mock := &MockCreateBackupUseCase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockCreateBackupUseCase is an autogenerated mock type for the CreateBackupUseCase type
+type MockCreateBackupUseCase struct {
+	mock.Mock
+}
+
+type MockCreateBackupUseCase_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockCreateBackupUseCase) EXPECT() *MockCreateBackupUseCase_Expecter {
+	return &MockCreateBackupUseCase_Expecter{mock: &_m.Mock}
+}
+
+// Do provides a mock function for the type MockCreateBackupUseCase
+func (_mock *MockCreateBackupUseCase) Do(ctx context.Context, organization string, contents github.MigrationContents, changedSince time.Time, saveBackupFunc SaveBackupFunc) ([]BackupArchive, error) {
+	ret := _mock.Called(ctx, organization, contents, changedSince, saveBackupFunc)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Do")
+	}
+
+	var r0 []BackupArchive
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, github.MigrationContents, time.Time, SaveBackupFunc) ([]BackupArchive, error)); ok {
+		return returnFunc(ctx, organization, contents, changedSince, saveBackupFunc)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, github.MigrationContents, time.Time, SaveBackupFunc) []BackupArchive); ok {
+		r0 = returnFunc(ctx, organization, contents, changedSince, saveBackupFunc)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]BackupArchive)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, github.MigrationContents, time.Time, SaveBackupFunc) error); ok {
+		r1 = returnFunc(ctx, organization, contents, changedSince, saveBackupFunc)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCreateBackupUseCase_Do_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Do'
+type MockCreateBackupUseCase_Do_Call struct {
+	*mock.Call
+}
+
+// Do is a helper method to define mock.On call
+//   - ctx context.Context
+//   - organization string
+//   - contents github.MigrationContents
+//   - changedSince time.Time
+//   - saveBackupFunc SaveBackupFunc
+func (_e *MockCreateBackupUseCase_Expecter) Do(ctx interface{}, organization interface{}, contents interface{}, changedSince interface{}, saveBackupFunc interface{}) *MockCreateBackupUseCase_Do_Call {
+	return &MockCreateBackupUseCase_Do_Call{Call: _e.mock.On("Do", ctx, organization, contents, changedSince, saveBackupFunc)}
+}
+
+func (_c *MockCreateBackupUseCase_Do_Call) Run(run func(ctx context.Context, organization string, contents github.MigrationContents, changedSince time.Time, saveBackupFunc SaveBackupFunc)) *MockCreateBackupUseCase_Do_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 github.MigrationContents
+		if args[2] != nil {
+			arg2 = args[2].(github.MigrationContents)
+		}
+		var arg3 time.Time
+		if args[3] != nil {
+			arg3 = args[3].(time.Time)
+		}
+		var arg4 SaveBackupFunc
+		if args[4] != nil {
+			arg4 = args[4].(SaveBackupFunc)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+			arg4,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCreateBackupUseCase_Do_Call) Return(backupArchives []BackupArchive, err error) *MockCreateBackupUseCase_Do_Call {
+	_c.Call.Return(backupArchives, err)
+	return _c
+}
+
+func (_c *MockCreateBackupUseCase_Do_Call) RunAndReturn(run func(ctx context.Context, organization string, contents github.MigrationContents, changedSince time.Time, saveBackupFunc SaveBackupFunc) ([]BackupArchive, error)) *MockCreateBackupUseCase_Do_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// WithBatchOptions provides a mock function for the type MockCreateBackupUseCase
+func (_mock *MockCreateBackupUseCase) WithBatchOptions(options BatchOptions) CreateBackupUseCase {
+	ret := _mock.Called(options)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WithBatchOptions")
+	}
+
+	var r0 CreateBackupUseCase
+	if returnFunc, ok := ret.Get(0).(func(BatchOptions) CreateBackupUseCase); ok {
+		r0 = returnFunc(options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(CreateBackupUseCase)
+		}
+	}
+	return r0
+}
+
+// MockCreateBackupUseCase_WithBatchOptions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WithBatchOptions'
+type MockCreateBackupUseCase_WithBatchOptions_Call struct {
+	*mock.Call
+}
+
+// WithBatchOptions is a helper method to define mock.On call
+//   - options BatchOptions
+func (_e *MockCreateBackupUseCase_Expecter) WithBatchOptions(options interface{}) *MockCreateBackupUseCase_WithBatchOptions_Call {
+	return &MockCreateBackupUseCase_WithBatchOptions_Call{Call: _e.mock.On("WithBatchOptions", options)}
+}
+
+func (_c *MockCreateBackupUseCase_WithBatchOptions_Call) Run(run func(options BatchOptions)) *MockCreateBackupUseCase_WithBatchOptions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 BatchOptions
+		if args[0] != nil {
+			arg0 = args[0].(BatchOptions)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCreateBackupUseCase_WithBatchOptions_Call) Return(createBackupUseCase CreateBackupUseCase) *MockCreateBackupUseCase_WithBatchOptions_Call {
+	_c.Call.Return(createBackupUseCase)
+	return _c
+}
+
+func (_c *MockCreateBackupUseCase_WithBatchOptions_Call) RunAndReturn(run func(options BatchOptions) CreateBackupUseCase) *MockCreateBackupUseCase_WithBatchOptions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// WithDownloader provides a mock function for the type MockCreateBackupUseCase
+func (_mock *MockCreateBackupUseCase) WithDownloader(downloader download.Downloader) CreateBackupUseCase {
+	ret := _mock.Called(downloader)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WithDownloader")
+	}
+
+	var r0 CreateBackupUseCase
+	if returnFunc, ok := ret.Get(0).(func(download.Downloader) CreateBackupUseCase); ok {
+		r0 = returnFunc(downloader)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(CreateBackupUseCase)
+		}
+	}
+	return r0
+}
+
+// MockCreateBackupUseCase_WithDownloader_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WithDownloader'
+type MockCreateBackupUseCase_WithDownloader_Call struct {
+	*mock.Call
+}
+
+// WithDownloader is a helper method to define mock.On call
+//   - downloader download.Downloader
+func (_e *MockCreateBackupUseCase_Expecter) WithDownloader(downloader interface{}) *MockCreateBackupUseCase_WithDownloader_Call {
+	return &MockCreateBackupUseCase_WithDownloader_Call{Call: _e.mock.On("WithDownloader", downloader)}
+}
+
+func (_c *MockCreateBackupUseCase_WithDownloader_Call) Run(run func(downloader download.Downloader)) *MockCreateBackupUseCase_WithDownloader_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 download.Downloader
+		if args[0] != nil {
+			arg0 = args[0].(download.Downloader)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCreateBackupUseCase_WithDownloader_Call) Return(createBackupUseCase CreateBackupUseCase) *MockCreateBackupUseCase_WithDownloader_Call {
+	_c.Call.Return(createBackupUseCase)
+	return _c
+}
+
+func (_c *MockCreateBackupUseCase_WithDownloader_Call) RunAndReturn(run func(downloader download.Downloader) CreateBackupUseCase) *MockCreateBackupUseCase_WithDownloader_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// WithMigrationState provides a mock function for the type MockCreateBackupUseCase
+func (_mock *MockCreateBackupUseCase) WithMigrationState(store MigrationStateStore, resume bool) CreateBackupUseCase {
+	ret := _mock.Called(store, resume)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WithMigrationState")
+	}
+
+	var r0 CreateBackupUseCase
+	if returnFunc, ok := ret.Get(0).(func(MigrationStateStore, bool) CreateBackupUseCase); ok {
+		r0 = returnFunc(store, resume)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(CreateBackupUseCase)
+		}
+	}
+	return r0
+}
+
+// MockCreateBackupUseCase_WithMigrationState_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WithMigrationState'
+type MockCreateBackupUseCase_WithMigrationState_Call struct {
+	*mock.Call
+}
+
+// WithMigrationState is a helper method to define mock.On call
+//   - store MigrationStateStore
+//   - resume bool
+func (_e *MockCreateBackupUseCase_Expecter) WithMigrationState(store interface{}, resume interface{}) *MockCreateBackupUseCase_WithMigrationState_Call {
+	return &MockCreateBackupUseCase_WithMigrationState_Call{Call: _e.mock.On("WithMigrationState", store, resume)}
+}
+
+func (_c *MockCreateBackupUseCase_WithMigrationState_Call) Run(run func(store MigrationStateStore, resume bool)) *MockCreateBackupUseCase_WithMigrationState_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 MigrationStateStore
+		if args[0] != nil {
+			arg0 = args[0].(MigrationStateStore)
+		}
+		var arg1 bool
+		if args[1] != nil {
+			arg1 = args[1].(bool)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCreateBackupUseCase_WithMigrationState_Call) Return(createBackupUseCase CreateBackupUseCase) *MockCreateBackupUseCase_WithMigrationState_Call {
+	_c.Call.Return(createBackupUseCase)
+	return _c
+}
+
+func (_c *MockCreateBackupUseCase_WithMigrationState_Call) RunAndReturn(run func(store MigrationStateStore, resume bool) CreateBackupUseCase) *MockCreateBackupUseCase_WithMigrationState_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// WithPollingInterval provides a mock function for the type MockCreateBackupUseCase
+func (_mock *MockCreateBackupUseCase) WithPollingInterval(interval time.Duration) CreateBackupUseCase {
+	ret := _mock.Called(interval)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WithPollingInterval")
+	}
+
+	var r0 CreateBackupUseCase
+	if returnFunc, ok := ret.Get(0).(func(time.Duration) CreateBackupUseCase); ok {
+		r0 = returnFunc(interval)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(CreateBackupUseCase)
+		}
+	}
+	return r0
+}
+
+// MockCreateBackupUseCase_WithPollingInterval_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WithPollingInterval'
+type MockCreateBackupUseCase_WithPollingInterval_Call struct {
+	*mock.Call
+}
+
+// WithPollingInterval is a helper method to define mock.On call
+//   - interval time.Duration
+func (_e *MockCreateBackupUseCase_Expecter) WithPollingInterval(interval interface{}) *MockCreateBackupUseCase_WithPollingInterval_Call {
+	return &MockCreateBackupUseCase_WithPollingInterval_Call{Call: _e.mock.On("WithPollingInterval", interval)}
+}
+
+func (_c *MockCreateBackupUseCase_WithPollingInterval_Call) Run(run func(interval time.Duration)) *MockCreateBackupUseCase_WithPollingInterval_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 time.Duration
+		if args[0] != nil {
+			arg0 = args[0].(time.Duration)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCreateBackupUseCase_WithPollingInterval_Call) Return(createBackupUseCase CreateBackupUseCase) *MockCreateBackupUseCase_WithPollingInterval_Call {
+	_c.Call.Return(createBackupUseCase)
+	return _c
+}
+
+func (_c *MockCreateBackupUseCase_WithPollingInterval_Call) RunAndReturn(run func(interval time.Duration) CreateBackupUseCase) *MockCreateBackupUseCase_WithPollingInterval_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// WithRecorder provides a mock function for the type MockCreateBackupUseCase
+func (_mock *MockCreateBackupUseCase) WithRecorder(recorder RunRecorder) CreateBackupUseCase {
+	ret := _mock.Called(recorder)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WithRecorder")
+	}
+
+	var r0 CreateBackupUseCase
+	if returnFunc, ok := ret.Get(0).(func(RunRecorder) CreateBackupUseCase); ok {
+		r0 = returnFunc(recorder)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(CreateBackupUseCase)
+		}
+	}
+	return r0
+}
+
+// MockCreateBackupUseCase_WithRecorder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WithRecorder'
+type MockCreateBackupUseCase_WithRecorder_Call struct {
+	*mock.Call
+}
+
+// WithRecorder is a helper method to define mock.On call
+//   - recorder RunRecorder
+func (_e *MockCreateBackupUseCase_Expecter) WithRecorder(recorder interface{}) *MockCreateBackupUseCase_WithRecorder_Call {
+	return &MockCreateBackupUseCase_WithRecorder_Call{Call: _e.mock.On("WithRecorder", recorder)}
+}
+
+func (_c *MockCreateBackupUseCase_WithRecorder_Call) Run(run func(recorder RunRecorder)) *MockCreateBackupUseCase_WithRecorder_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 RunRecorder
+		if args[0] != nil {
+			arg0 = args[0].(RunRecorder)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCreateBackupUseCase_WithRecorder_Call) Return(createBackupUseCase CreateBackupUseCase) *MockCreateBackupUseCase_WithRecorder_Call {
+	_c.Call.Return(createBackupUseCase)
+	return _c
+}
+
+func (_c *MockCreateBackupUseCase_WithRecorder_Call) RunAndReturn(run func(recorder RunRecorder) CreateBackupUseCase) *MockCreateBackupUseCase_WithRecorder_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockCreateRemoteBackupUseCase creates a new instance of MockCreateRemoteBackupUseCase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockCreateRemoteBackupUseCase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockCreateRemoteBackupUseCase {
+	mock := &MockCreateRemoteBackupUseCase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockCreateRemoteBackupUseCase is an autogenerated mock type for the CreateRemoteBackupUseCase type
+type MockCreateRemoteBackupUseCase struct {
+	mock.Mock
+}
+
+type MockCreateRemoteBackupUseCase_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockCreateRemoteBackupUseCase) EXPECT() *MockCreateRemoteBackupUseCase_Expecter {
+	return &MockCreateRemoteBackupUseCase_Expecter{mock: &_m.Mock}
+}
+
+// Do provides a mock function for the type MockCreateRemoteBackupUseCase
+func (_mock *MockCreateRemoteBackupUseCase) Do(ctx context.Context, organization string, contents github.MigrationContents) (string, error) {
+	ret := _mock.Called(ctx, organization, contents)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Do")
+	}
+
+	var r0 string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, github.MigrationContents) (string, error)); ok {
+		return returnFunc(ctx, organization, contents)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, github.MigrationContents) string); ok {
+		r0 = returnFunc(ctx, organization, contents)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, github.MigrationContents) error); ok {
+		r1 = returnFunc(ctx, organization, contents)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCreateRemoteBackupUseCase_Do_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Do'
+type MockCreateRemoteBackupUseCase_Do_Call struct {
+	*mock.Call
+}
+
+// Do is a helper method to define mock.On call
+//   - ctx context.Context
+//   - organization string
+//   - contents github.MigrationContents
+func (_e *MockCreateRemoteBackupUseCase_Expecter) Do(ctx interface{}, organization interface{}, contents interface{}) *MockCreateRemoteBackupUseCase_Do_Call {
+	return &MockCreateRemoteBackupUseCase_Do_Call{Call: _e.mock.On("Do", ctx, organization, contents)}
+}
+
+func (_c *MockCreateRemoteBackupUseCase_Do_Call) Run(run func(ctx context.Context, organization string, contents github.MigrationContents)) *MockCreateRemoteBackupUseCase_Do_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 github.MigrationContents
+		if args[2] != nil {
+			arg2 = args[2].(github.MigrationContents)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCreateRemoteBackupUseCase_Do_Call) Return(s string, err error) *MockCreateRemoteBackupUseCase_Do_Call {
+	_c.Call.Return(s, err)
+	return _c
+}
+
+func (_c *MockCreateRemoteBackupUseCase_Do_Call) RunAndReturn(run func(ctx context.Context, organization string, contents github.MigrationContents) (string, error)) *MockCreateRemoteBackupUseCase_Do_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// WithCatalog provides a mock function for the type MockCreateRemoteBackupUseCase
+func (_mock *MockCreateRemoteBackupUseCase) WithCatalog(catalog CatalogStore) CreateRemoteBackupUseCase {
+	ret := _mock.Called(catalog)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WithCatalog")
+	}
+
+	var r0 CreateRemoteBackupUseCase
+	if returnFunc, ok := ret.Get(0).(func(CatalogStore) CreateRemoteBackupUseCase); ok {
+		r0 = returnFunc(catalog)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(CreateRemoteBackupUseCase)
+		}
+	}
+	return r0
+}
+
+// MockCreateRemoteBackupUseCase_WithCatalog_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WithCatalog'
+type MockCreateRemoteBackupUseCase_WithCatalog_Call struct {
+	*mock.Call
+}
+
+// WithCatalog is a helper method to define mock.On call
+//   - catalog CatalogStore
+func (_e *MockCreateRemoteBackupUseCase_Expecter) WithCatalog(catalog interface{}) *MockCreateRemoteBackupUseCase_WithCatalog_Call {
+	return &MockCreateRemoteBackupUseCase_WithCatalog_Call{Call: _e.mock.On("WithCatalog", catalog)}
+}
+
+func (_c *MockCreateRemoteBackupUseCase_WithCatalog_Call) Run(run func(catalog CatalogStore)) *MockCreateRemoteBackupUseCase_WithCatalog_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 CatalogStore
+		if args[0] != nil {
+			arg0 = args[0].(CatalogStore)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCreateRemoteBackupUseCase_WithCatalog_Call) Return(createRemoteBackupUseCase CreateRemoteBackupUseCase) *MockCreateRemoteBackupUseCase_WithCatalog_Call {
+	_c.Call.Return(createRemoteBackupUseCase)
+	return _c
+}
+
+func (_c *MockCreateRemoteBackupUseCase_WithCatalog_Call) RunAndReturn(run func(catalog CatalogStore) CreateRemoteBackupUseCase) *MockCreateRemoteBackupUseCase_WithCatalog_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// WithEncryptor provides a mock function for the type MockCreateRemoteBackupUseCase
+func (_mock *MockCreateRemoteBackupUseCase) WithEncryptor(encryptor encryption.Encryptor) CreateRemoteBackupUseCase {
+	ret := _mock.Called(encryptor)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WithEncryptor")
+	}
+
+	var r0 CreateRemoteBackupUseCase
+	if returnFunc, ok := ret.Get(0).(func(encryption.Encryptor) CreateRemoteBackupUseCase); ok {
+		r0 = returnFunc(encryptor)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(CreateRemoteBackupUseCase)
+		}
+	}
+	return r0
+}
+
+// MockCreateRemoteBackupUseCase_WithEncryptor_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WithEncryptor'
+type MockCreateRemoteBackupUseCase_WithEncryptor_Call struct {
+	*mock.Call
+}
+
+// WithEncryptor is a helper method to define mock.On call
+//   - encryptor encryption.Encryptor
+func (_e *MockCreateRemoteBackupUseCase_Expecter) WithEncryptor(encryptor interface{}) *MockCreateRemoteBackupUseCase_WithEncryptor_Call {
+	return &MockCreateRemoteBackupUseCase_WithEncryptor_Call{Call: _e.mock.On("WithEncryptor", encryptor)}
+}
+
+func (_c *MockCreateRemoteBackupUseCase_WithEncryptor_Call) Run(run func(encryptor encryption.Encryptor)) *MockCreateRemoteBackupUseCase_WithEncryptor_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 encryption.Encryptor
+		if args[0] != nil {
+			arg0 = args[0].(encryption.Encryptor)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCreateRemoteBackupUseCase_WithEncryptor_Call) Return(createRemoteBackupUseCase CreateRemoteBackupUseCase) *MockCreateRemoteBackupUseCase_WithEncryptor_Call {
+	_c.Call.Return(createRemoteBackupUseCase)
+	return _c
+}
+
+func (_c *MockCreateRemoteBackupUseCase_WithEncryptor_Call) RunAndReturn(run func(encryptor encryption.Encryptor) CreateRemoteBackupUseCase) *MockCreateRemoteBackupUseCase_WithEncryptor_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// WithIncremental provides a mock function for the type MockCreateRemoteBackupUseCase
+func (_mock *MockCreateRemoteBackupUseCase) WithIncremental(fullIntervalDays int) CreateRemoteBackupUseCase {
+	ret := _mock.Called(fullIntervalDays)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WithIncremental")
+	}
+
+	var r0 CreateRemoteBackupUseCase
+	if returnFunc, ok := ret.Get(0).(func(int) CreateRemoteBackupUseCase); ok {
+		r0 = returnFunc(fullIntervalDays)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(CreateRemoteBackupUseCase)
+		}
+	}
+	return r0
+}
+
+// MockCreateRemoteBackupUseCase_WithIncremental_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WithIncremental'
+type MockCreateRemoteBackupUseCase_WithIncremental_Call struct {
+	*mock.Call
+}
+
+// WithIncremental is a helper method to define mock.On call
+//   - fullIntervalDays int
+func (_e *MockCreateRemoteBackupUseCase_Expecter) WithIncremental(fullIntervalDays interface{}) *MockCreateRemoteBackupUseCase_WithIncremental_Call {
+	return &MockCreateRemoteBackupUseCase_WithIncremental_Call{Call: _e.mock.On("WithIncremental", fullIntervalDays)}
+}
+
+func (_c *MockCreateRemoteBackupUseCase_WithIncremental_Call) Run(run func(fullIntervalDays int)) *MockCreateRemoteBackupUseCase_WithIncremental_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 int
+		if args[0] != nil {
+			arg0 = args[0].(int)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCreateRemoteBackupUseCase_WithIncremental_Call) Return(createRemoteBackupUseCase CreateRemoteBackupUseCase) *MockCreateRemoteBackupUseCase_WithIncremental_Call {
+	_c.Call.Return(createRemoteBackupUseCase)
+	return _c
+}
+
+func (_c *MockCreateRemoteBackupUseCase_WithIncremental_Call) RunAndReturn(run func(fullIntervalDays int) CreateRemoteBackupUseCase) *MockCreateRemoteBackupUseCase_WithIncremental_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// WithRecorder provides a mock function for the type MockCreateRemoteBackupUseCase
+func (_mock *MockCreateRemoteBackupUseCase) WithRecorder(recorder RunRecorder) CreateRemoteBackupUseCase {
+	ret := _mock.Called(recorder)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WithRecorder")
+	}
+
+	var r0 CreateRemoteBackupUseCase
+	if returnFunc, ok := ret.Get(0).(func(RunRecorder) CreateRemoteBackupUseCase); ok {
+		r0 = returnFunc(recorder)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(CreateRemoteBackupUseCase)
+		}
+	}
+	return r0
+}
+
+// MockCreateRemoteBackupUseCase_WithRecorder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WithRecorder'
+type MockCreateRemoteBackupUseCase_WithRecorder_Call struct {
+	*mock.Call
+}
+
+// WithRecorder is a helper method to define mock.On call
+//   - recorder RunRecorder
+func (_e *MockCreateRemoteBackupUseCase_Expecter) WithRecorder(recorder interface{}) *MockCreateRemoteBackupUseCase_WithRecorder_Call {
+	return &MockCreateRemoteBackupUseCase_WithRecorder_Call{Call: _e.mock.On("WithRecorder", recorder)}
+}
+
+func (_c *MockCreateRemoteBackupUseCase_WithRecorder_Call) Run(run func(recorder RunRecorder)) *MockCreateRemoteBackupUseCase_WithRecorder_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 RunRecorder
+		if args[0] != nil {
+			arg0 = args[0].(RunRecorder)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCreateRemoteBackupUseCase_WithRecorder_Call) Return(createRemoteBackupUseCase CreateRemoteBackupUseCase) *MockCreateRemoteBackupUseCase_WithRecorder_Call {
+	_c.Call.Return(createRemoteBackupUseCase)
+	return _c
+}
+
+func (_c *MockCreateRemoteBackupUseCase_WithRecorder_Call) RunAndReturn(run func(recorder RunRecorder) CreateRemoteBackupUseCase) *MockCreateRemoteBackupUseCase_WithRecorder_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// WithSplitRepositories provides a mock function for the type MockCreateRemoteBackupUseCase
+func (_mock *MockCreateRemoteBackupUseCase) WithSplitRepositories(splitRepositories bool) CreateRemoteBackupUseCase {
+	ret := _mock.Called(splitRepositories)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WithSplitRepositories")
+	}
+
+	var r0 CreateRemoteBackupUseCase
+	if returnFunc, ok := ret.Get(0).(func(bool) CreateRemoteBackupUseCase); ok {
+		r0 = returnFunc(splitRepositories)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(CreateRemoteBackupUseCase)
+		}
+	}
+	return r0
+}
+
+// MockCreateRemoteBackupUseCase_WithSplitRepositories_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WithSplitRepositories'
+type MockCreateRemoteBackupUseCase_WithSplitRepositories_Call struct {
+	*mock.Call
+}
+
+// WithSplitRepositories is a helper method to define mock.On call
+//   - splitRepositories bool
+func (_e *MockCreateRemoteBackupUseCase_Expecter) WithSplitRepositories(splitRepositories interface{}) *MockCreateRemoteBackupUseCase_WithSplitRepositories_Call {
+	return &MockCreateRemoteBackupUseCase_WithSplitRepositories_Call{Call: _e.mock.On("WithSplitRepositories", splitRepositories)}
+}
+
+func (_c *MockCreateRemoteBackupUseCase_WithSplitRepositories_Call) Run(run func(splitRepositories bool)) *MockCreateRemoteBackupUseCase_WithSplitRepositories_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 bool
+		if args[0] != nil {
+			arg0 = args[0].(bool)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCreateRemoteBackupUseCase_WithSplitRepositories_Call) Return(createRemoteBackupUseCase CreateRemoteBackupUseCase) *MockCreateRemoteBackupUseCase_WithSplitRepositories_Call {
+	_c.Call.Return(createRemoteBackupUseCase)
+	return _c
+}
+
+func (_c *MockCreateRemoteBackupUseCase_WithSplitRepositories_Call) RunAndReturn(run func(splitRepositories bool) CreateRemoteBackupUseCase) *MockCreateRemoteBackupUseCase_WithSplitRepositories_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockGetOrganizationArchiveUrlUseCase creates a new instance of MockGetOrganizationArchiveUrlUseCase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockGetOrganizationArchiveUrlUseCase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockGetOrganizationArchiveUrlUseCase {
+	mock := &MockGetOrganizationArchiveUrlUseCase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockGetOrganizationArchiveUrlUseCase is an autogenerated mock type for the GetOrganizationArchiveUrlUseCase type
+type MockGetOrganizationArchiveUrlUseCase struct {
+	mock.Mock
+}
+
+type MockGetOrganizationArchiveUrlUseCase_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockGetOrganizationArchiveUrlUseCase) EXPECT() *MockGetOrganizationArchiveUrlUseCase_Expecter {
+	return &MockGetOrganizationArchiveUrlUseCase_Expecter{mock: &_m.Mock}
+}
+
+// Do provides a mock function for the type MockGetOrganizationArchiveUrlUseCase
+func (_mock *MockGetOrganizationArchiveUrlUseCase) Do(ctx context.Context, organization string, organizationID int64) (string, error) {
+	ret := _mock.Called(ctx, organization, organizationID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Do")
+	}
+
+	var r0 string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int64) (string, error)); ok {
+		return returnFunc(ctx, organization, organizationID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int64) string); ok {
+		r0 = returnFunc(ctx, organization, organizationID)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, int64) error); ok {
+		r1 = returnFunc(ctx, organization, organizationID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockGetOrganizationArchiveUrlUseCase_Do_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Do'
+type MockGetOrganizationArchiveUrlUseCase_Do_Call struct {
+	*mock.Call
+}
+
+// Do is a helper method to define mock.On call
+//   - ctx context.Context
+//   - organization string
+//   - organizationID int64
+func (_e *MockGetOrganizationArchiveUrlUseCase_Expecter) Do(ctx interface{}, organization interface{}, organizationID interface{}) *MockGetOrganizationArchiveUrlUseCase_Do_Call {
+	return &MockGetOrganizationArchiveUrlUseCase_Do_Call{Call: _e.mock.On("Do", ctx, organization, organizationID)}
+}
+
+func (_c *MockGetOrganizationArchiveUrlUseCase_Do_Call) Run(run func(ctx context.Context, organization string, organizationID int64)) *MockGetOrganizationArchiveUrlUseCase_Do_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 int64
+		if args[2] != nil {
+			arg2 = args[2].(int64)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockGetOrganizationArchiveUrlUseCase_Do_Call) Return(s string, err error) *MockGetOrganizationArchiveUrlUseCase_Do_Call {
+	_c.Call.Return(s, err)
+	return _c
+}
+
+func (_c *MockGetOrganizationArchiveUrlUseCase_Do_Call) RunAndReturn(run func(ctx context.Context, organization string, organizationID int64) (string, error)) *MockGetOrganizationArchiveUrlUseCase_Do_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// WithDurationOptions provides a mock function for the type MockGetOrganizationArchiveUrlUseCase
+func (_mock *MockGetOrganizationArchiveUrlUseCase) WithDurationOptions(timeout time.Duration, ticker time.Duration) GetOrganizationArchiveUrlUseCase {
+	ret := _mock.Called(timeout, ticker)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WithDurationOptions")
+	}
+
+	var r0 GetOrganizationArchiveUrlUseCase
+	if returnFunc, ok := ret.Get(0).(func(time.Duration, time.Duration) GetOrganizationArchiveUrlUseCase); ok {
+		r0 = returnFunc(timeout, ticker)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(GetOrganizationArchiveUrlUseCase)
+		}
+	}
+	return r0
+}
+
+// MockGetOrganizationArchiveUrlUseCase_WithDurationOptions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WithDurationOptions'
+type MockGetOrganizationArchiveUrlUseCase_WithDurationOptions_Call struct {
+	*mock.Call
+}
+
+// WithDurationOptions is a helper method to define mock.On call
+//   - timeout time.Duration
+//   - ticker time.Duration
+func (_e *MockGetOrganizationArchiveUrlUseCase_Expecter) WithDurationOptions(timeout interface{}, ticker interface{}) *MockGetOrganizationArchiveUrlUseCase_WithDurationOptions_Call {
+	return &MockGetOrganizationArchiveUrlUseCase_WithDurationOptions_Call{Call: _e.mock.On("WithDurationOptions", timeout, ticker)}
+}
+
+func (_c *MockGetOrganizationArchiveUrlUseCase_WithDurationOptions_Call) Run(run func(timeout time.Duration, ticker time.Duration)) *MockGetOrganizationArchiveUrlUseCase_WithDurationOptions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 time.Duration
+		if args[0] != nil {
+			arg0 = args[0].(time.Duration)
+		}
+		var arg1 time.Duration
+		if args[1] != nil {
+			arg1 = args[1].(time.Duration)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockGetOrganizationArchiveUrlUseCase_WithDurationOptions_Call) Return(getOrganizationArchiveUrlUseCase GetOrganizationArchiveUrlUseCase) *MockGetOrganizationArchiveUrlUseCase_WithDurationOptions_Call {
+	_c.Call.Return(getOrganizationArchiveUrlUseCase)
+	return _c
+}
+
+func (_c *MockGetOrganizationArchiveUrlUseCase_WithDurationOptions_Call) RunAndReturn(run func(timeout time.Duration, ticker time.Duration) GetOrganizationArchiveUrlUseCase) *MockGetOrganizationArchiveUrlUseCase_WithDurationOptions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockListRepositoriesUseCase creates a new instance of MockListRepositoriesUseCase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockListRepositoriesUseCase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockListRepositoriesUseCase {
+	mock := &MockListRepositoriesUseCase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockListRepositoriesUseCase is an autogenerated mock type for the ListRepositoriesUseCase type
+type MockListRepositoriesUseCase struct {
+	mock.Mock
+}
+
+type MockListRepositoriesUseCase_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockListRepositoriesUseCase) EXPECT() *MockListRepositoriesUseCase_Expecter {
+	return &MockListRepositoriesUseCase_Expecter{mock: &_m.Mock}
+}
+
+// Do provides a mock function for the type MockListRepositoriesUseCase
+func (_mock *MockListRepositoriesUseCase) Do(ctx context.Context, organization string) ([]github0.Repository, error) {
+	ret := _mock.Called(ctx, organization)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Do")
+	}
+
+	var r0 []github0.Repository
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) ([]github0.Repository, error)); ok {
+		return returnFunc(ctx, organization)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) []github0.Repository); ok {
+		r0 = returnFunc(ctx, organization)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]github0.Repository)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, organization)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockListRepositoriesUseCase_Do_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Do'
+type MockListRepositoriesUseCase_Do_Call struct {
+	*mock.Call
+}
+
+// Do is a helper method to define mock.On call
+//   - ctx context.Context
+//   - organization string
+func (_e *MockListRepositoriesUseCase_Expecter) Do(ctx interface{}, organization interface{}) *MockListRepositoriesUseCase_Do_Call {
+	return &MockListRepositoriesUseCase_Do_Call{Call: _e.mock.On("Do", ctx, organization)}
+}
+
+func (_c *MockListRepositoriesUseCase_Do_Call) Run(run func(ctx context.Context, organization string)) *MockListRepositoriesUseCase_Do_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockListRepositoriesUseCase_Do_Call) Return(repositorys []github0.Repository, err error) *MockListRepositoriesUseCase_Do_Call {
+	_c.Call.Return(repositorys, err)
+	return _c
+}
+
+func (_c *MockListRepositoriesUseCase_Do_Call) RunAndReturn(run func(ctx context.Context, organization string) ([]github0.Repository, error)) *MockListRepositoriesUseCase_Do_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// WithFilter provides a mock function for the type MockListRepositoriesUseCase
+func (_mock *MockListRepositoriesUseCase) WithFilter(filter RepositoryFilter) ListRepositoriesUseCase {
+	ret := _mock.Called(filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WithFilter")
+	}
+
+	var r0 ListRepositoriesUseCase
+	if returnFunc, ok := ret.Get(0).(func(RepositoryFilter) ListRepositoriesUseCase); ok {
+		r0 = returnFunc(filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(ListRepositoriesUseCase)
+		}
+	}
+	return r0
+}
+
+// MockListRepositoriesUseCase_WithFilter_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WithFilter'
+type MockListRepositoriesUseCase_WithFilter_Call struct {
+	*mock.Call
+}
+
+// WithFilter is a helper method to define mock.On call
+//   - filter RepositoryFilter
+func (_e *MockListRepositoriesUseCase_Expecter) WithFilter(filter interface{}) *MockListRepositoriesUseCase_WithFilter_Call {
+	return &MockListRepositoriesUseCase_WithFilter_Call{Call: _e.mock.On("WithFilter", filter)}
+}
+
+func (_c *MockListRepositoriesUseCase_WithFilter_Call) Run(run func(filter RepositoryFilter)) *MockListRepositoriesUseCase_WithFilter_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 RepositoryFilter
+		if args[0] != nil {
+			arg0 = args[0].(RepositoryFilter)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockListRepositoriesUseCase_WithFilter_Call) Return(listRepositoriesUseCase ListRepositoriesUseCase) *MockListRepositoriesUseCase_WithFilter_Call {
+	_c.Call.Return(listRepositoriesUseCase)
+	return _c
+}
+
+func (_c *MockListRepositoriesUseCase_WithFilter_Call) RunAndReturn(run func(filter RepositoryFilter) ListRepositoriesUseCase) *MockListRepositoriesUseCase_WithFilter_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// WithRecorder provides a mock function for the type MockListRepositoriesUseCase
+func (_mock *MockListRepositoriesUseCase) WithRecorder(recorder RunRecorder) ListRepositoriesUseCase {
+	ret := _mock.Called(recorder)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WithRecorder")
+	}
+
+	var r0 ListRepositoriesUseCase
+	if returnFunc, ok := ret.Get(0).(func(RunRecorder) ListRepositoriesUseCase); ok {
+		r0 = returnFunc(recorder)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(ListRepositoriesUseCase)
+		}
+	}
+	return r0
+}
+
+// MockListRepositoriesUseCase_WithRecorder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WithRecorder'
+type MockListRepositoriesUseCase_WithRecorder_Call struct {
+	*mock.Call
+}
+
+// WithRecorder is a helper method to define mock.On call
+//   - recorder RunRecorder
+func (_e *MockListRepositoriesUseCase_Expecter) WithRecorder(recorder interface{}) *MockListRepositoriesUseCase_WithRecorder_Call {
+	return &MockListRepositoriesUseCase_WithRecorder_Call{Call: _e.mock.On("WithRecorder", recorder)}
+}
+
+func (_c *MockListRepositoriesUseCase_WithRecorder_Call) Run(run func(recorder RunRecorder)) *MockListRepositoriesUseCase_WithRecorder_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 RunRecorder
+		if args[0] != nil {
+			arg0 = args[0].(RunRecorder)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockListRepositoriesUseCase_WithRecorder_Call) Return(listRepositoriesUseCase ListRepositoriesUseCase) *MockListRepositoriesUseCase_WithRecorder_Call {
+	_c.Call.Return(listRepositoriesUseCase)
+	return _c
+}
+
+func (_c *MockListRepositoriesUseCase_WithRecorder_Call) RunAndReturn(run func(recorder RunRecorder) ListRepositoriesUseCase) *MockListRepositoriesUseCase_WithRecorder_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockMigrationStateStore creates a new instance of MockMigrationStateStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockMigrationStateStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockMigrationStateStore {
+	mock := &MockMigrationStateStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockMigrationStateStore is an autogenerated mock type for the MigrationStateStore type
+type MockMigrationStateStore struct {
+	mock.Mock
+}
+
+type MockMigrationStateStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockMigrationStateStore) EXPECT() *MockMigrationStateStore_Expecter {
+	return &MockMigrationStateStore_Expecter{mock: &_m.Mock}
+}
+
+// Delete provides a mock function for the type MockMigrationStateStore
+func (_mock *MockMigrationStateStore) Delete(ctx context.Context, organization string) error {
+	ret := _mock.Called(ctx, organization)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, organization)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockMigrationStateStore_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockMigrationStateStore_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - organization string
+func (_e *MockMigrationStateStore_Expecter) Delete(ctx interface{}, organization interface{}) *MockMigrationStateStore_Delete_Call {
+	return &MockMigrationStateStore_Delete_Call{Call: _e.mock.On("Delete", ctx, organization)}
+}
+
+func (_c *MockMigrationStateStore_Delete_Call) Run(run func(ctx context.Context, organization string)) *MockMigrationStateStore_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockMigrationStateStore_Delete_Call) Return(err error) *MockMigrationStateStore_Delete_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockMigrationStateStore_Delete_Call) RunAndReturn(run func(ctx context.Context, organization string) error) *MockMigrationStateStore_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Load provides a mock function for the type MockMigrationStateStore
+func (_mock *MockMigrationStateStore) Load(ctx context.Context, organization string) (MigrationState, error) {
+	ret := _mock.Called(ctx, organization)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Load")
+	}
+
+	var r0 MigrationState
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (MigrationState, error)); ok {
+		return returnFunc(ctx, organization)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) MigrationState); ok {
+		r0 = returnFunc(ctx, organization)
+	} else {
+		r0 = ret.Get(0).(MigrationState)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, organization)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockMigrationStateStore_Load_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Load'
+type MockMigrationStateStore_Load_Call struct {
+	*mock.Call
+}
+
+// Load is a helper method to define mock.On call
+//   - ctx context.Context
+//   - organization string
+func (_e *MockMigrationStateStore_Expecter) Load(ctx interface{}, organization interface{}) *MockMigrationStateStore_Load_Call {
+	return &MockMigrationStateStore_Load_Call{Call: _e.mock.On("Load", ctx, organization)}
+}
+
+func (_c *MockMigrationStateStore_Load_Call) Run(run func(ctx context.Context, organization string)) *MockMigrationStateStore_Load_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockMigrationStateStore_Load_Call) Return(migrationState MigrationState, err error) *MockMigrationStateStore_Load_Call {
+	_c.Call.Return(migrationState, err)
+	return _c
+}
+
+func (_c *MockMigrationStateStore_Load_Call) RunAndReturn(run func(ctx context.Context, organization string) (MigrationState, error)) *MockMigrationStateStore_Load_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Save provides a mock function for the type MockMigrationStateStore
+func (_mock *MockMigrationStateStore) Save(ctx context.Context, state MigrationState) error {
+	ret := _mock.Called(ctx, state)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, MigrationState) error); ok {
+		r0 = returnFunc(ctx, state)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockMigrationStateStore_Save_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Save'
+type MockMigrationStateStore_Save_Call struct {
+	*mock.Call
+}
+
+// Save is a helper method to define mock.On call
+//   - ctx context.Context
+//   - state MigrationState
+func (_e *MockMigrationStateStore_Expecter) Save(ctx interface{}, state interface{}) *MockMigrationStateStore_Save_Call {
+	return &MockMigrationStateStore_Save_Call{Call: _e.mock.On("Save", ctx, state)}
+}
+
+func (_c *MockMigrationStateStore_Save_Call) Run(run func(ctx context.Context, state MigrationState)) *MockMigrationStateStore_Save_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 MigrationState
+		if args[1] != nil {
+			arg1 = args[1].(MigrationState)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockMigrationStateStore_Save_Call) Return(err error) *MockMigrationStateStore_Save_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockMigrationStateStore_Save_Call) RunAndReturn(run func(ctx context.Context, state MigrationState) error) *MockMigrationStateStore_Save_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockPruneBackupsUseCase creates a new instance of MockPruneBackupsUseCase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockPruneBackupsUseCase(t interface {
 	mock.TestingT
 	Cleanup(func())
-}) *MockCreateBackupUseCase {
-	mock := &MockCreateBackupUseCase{}
+}) *MockPruneBackupsUseCase {
+	mock := &MockPruneBackupsUseCase{}
 	mock.Mock.Test(t)
 
 	t.Cleanup(func() { mock.AssertExpectations(t) })
@@ -26,59 +1714,60 @@ func NewMockCreateBackupUseCase(t interface {
 	return mock
 }
 
-// MockCreateBackupUseCase is an autogenerated mock type for the CreateBackupUseCase type
-type MockCreateBackupUseCase struct {
+// MockPruneBackupsUseCase is an autogenerated mock type for the PruneBackupsUseCase type
+type MockPruneBackupsUseCase struct {
 	mock.Mock
 }
 
-type MockCreateBackupUseCase_Expecter struct {
+type MockPruneBackupsUseCase_Expecter struct {
 	mock *mock.Mock
 }
 
-func (_m *MockCreateBackupUseCase) EXPECT() *MockCreateBackupUseCase_Expecter {
-	return &MockCreateBackupUseCase_Expecter{mock: &_m.Mock}
+func (_m *MockPruneBackupsUseCase) EXPECT() *MockPruneBackupsUseCase_Expecter {
+	return &MockPruneBackupsUseCase_Expecter{mock: &_m.Mock}
 }
 
-// Do provides a mock function for the type MockCreateBackupUseCase
-func (_mock *MockCreateBackupUseCase) Do(ctx context.Context, organization string, saveBackupFunc SaveBackupFunc) (string, error) {
-	ret := _mock.Called(ctx, organization, saveBackupFunc)
+// Do provides a mock function for the type MockPruneBackupsUseCase
+func (_mock *MockPruneBackupsUseCase) Do(ctx context.Context, organization string, policy RetentionPolicy, dryRun bool) (PruneReport, error) {
+	ret := _mock.Called(ctx, organization, policy, dryRun)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Do")
 	}
 
-	var r0 string
+	var r0 PruneReport
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string, SaveBackupFunc) (string, error)); ok {
-		return returnFunc(ctx, organization, saveBackupFunc)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, RetentionPolicy, bool) (PruneReport, error)); ok {
+		return returnFunc(ctx, organization, policy, dryRun)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string, SaveBackupFunc) string); ok {
-		r0 = returnFunc(ctx, organization, saveBackupFunc)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, RetentionPolicy, bool) PruneReport); ok {
+		r0 = returnFunc(ctx, organization, policy, dryRun)
 	} else {
-		r0 = ret.Get(0).(string)
+		r0 = ret.Get(0).(PruneReport)
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, string, SaveBackupFunc) error); ok {
-		r1 = returnFunc(ctx, organization, saveBackupFunc)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, RetentionPolicy, bool) error); ok {
+		r1 = returnFunc(ctx, organization, policy, dryRun)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockCreateBackupUseCase_Do_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Do'
-type MockCreateBackupUseCase_Do_Call struct {
+// MockPruneBackupsUseCase_Do_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Do'
+type MockPruneBackupsUseCase_Do_Call struct {
 	*mock.Call
 }
 
 // Do is a helper method to define mock.On call
 //   - ctx context.Context
 //   - organization string
-//   - saveBackupFunc SaveBackupFunc
-func (_e *MockCreateBackupUseCase_Expecter) Do(ctx interface{}, organization interface{}, saveBackupFunc interface{}) *MockCreateBackupUseCase_Do_Call {
-	return &MockCreateBackupUseCase_Do_Call{Call: _e.mock.On("Do", ctx, organization, saveBackupFunc)}
+//   - policy RetentionPolicy
+//   - dryRun bool
+func (_e *MockPruneBackupsUseCase_Expecter) Do(ctx interface{}, organization interface{}, policy interface{}, dryRun interface{}) *MockPruneBackupsUseCase_Do_Call {
+	return &MockPruneBackupsUseCase_Do_Call{Call: _e.mock.On("Do", ctx, organization, policy, dryRun)}
 }
 
-func (_c *MockCreateBackupUseCase_Do_Call) Run(run func(ctx context.Context, organization string, saveBackupFunc SaveBackupFunc)) *MockCreateBackupUseCase_Do_Call {
+func (_c *MockPruneBackupsUseCase_Do_Call) Run(run func(ctx context.Context, organization string, policy RetentionPolicy, dryRun bool)) *MockPruneBackupsUseCase_Do_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -88,64 +1777,69 @@ func (_c *MockCreateBackupUseCase_Do_Call) Run(run func(ctx context.Context, org
 		if args[1] != nil {
 			arg1 = args[1].(string)
 		}
-		var arg2 SaveBackupFunc
+		var arg2 RetentionPolicy
 		if args[2] != nil {
-			arg2 = args[2].(SaveBackupFunc)
+			arg2 = args[2].(RetentionPolicy)
+		}
+		var arg3 bool
+		if args[3] != nil {
+			arg3 = args[3].(bool)
 		}
 		run(
 			arg0,
 			arg1,
 			arg2,
+			arg3,
 		)
 	})
 	return _c
 }
 
-func (_c *MockCreateBackupUseCase_Do_Call) Return(s string, err error) *MockCreateBackupUseCase_Do_Call {
-	_c.Call.Return(s, err)
+func (_c *MockPruneBackupsUseCase_Do_Call) Return(pruneReport PruneReport, err error) *MockPruneBackupsUseCase_Do_Call {
+	_c.Call.Return(pruneReport, err)
 	return _c
 }
 
-func (_c *MockCreateBackupUseCase_Do_Call) RunAndReturn(run func(ctx context.Context, organization string, saveBackupFunc SaveBackupFunc) (string, error)) *MockCreateBackupUseCase_Do_Call {
+func (_c *MockPruneBackupsUseCase_Do_Call) RunAndReturn(run func(ctx context.Context, organization string, policy RetentionPolicy, dryRun bool) (PruneReport, error)) *MockPruneBackupsUseCase_Do_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// WithPollingInterval provides a mock function for the type MockCreateBackupUseCase
-func (_mock *MockCreateBackupUseCase) WithPollingInterval(interval time.Duration) CreateBackupUseCase {
-	ret := _mock.Called(interval)
+// WithCatalog provides a mock function for the type MockPruneBackupsUseCase
+func (_mock *MockPruneBackupsUseCase) WithCatalog(catalog CatalogStore) PruneBackupsUseCase {
+	ret := _mock.Called(catalog)
 
 	if len(ret) == 0 {
-		panic("no return value specified for WithPollingInterval")
+		panic("no return value specified for WithCatalog")
 	}
 
-	var r0 CreateBackupUseCase
-	if returnFunc, ok := ret.Get(0).(func(time.Duration) CreateBackupUseCase); ok {
-		r0 = returnFunc(interval)
+	var r0 PruneBackupsUseCase
+	if returnFunc, ok := ret.Get(0).(func(CatalogStore) PruneBackupsUseCase); ok {
+		r0 = returnFunc(catalog)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(CreateBackupUseCase)
+			r0 = ret.Get(0).(PruneBackupsUseCase)
 		}
 	}
 	return r0
 }
 
-// MockCreateBackupUseCase_WithPollingInterval_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WithPollingInterval'
-type MockCreateBackupUseCase_WithPollingInterval_Call struct {
+// MockPruneBackupsUseCase_WithCatalog_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WithCatalog'
+type MockPruneBackupsUseCase_WithCatalog_Call struct {
 	*mock.Call
 }
 
-// WithPollingInterval is a helper method to define mock.On call
-//   - interval time.Duration
-func (_e *MockCreateBackupUseCase_Expecter) WithPollingInterval(interval interface{}) *MockCreateBackupUseCase_WithPollingInterval_Call {
-	return &MockCreateBackupUseCase_WithPollingInterval_Call{Call: _e.mock.On("WithPollingInterval", interval)}
+// WithCatalog is a helper method to define mock.On call
+//   - catalog CatalogStore
+func (_e *MockPruneBackupsUseCase_Expecter) WithCatalog(catalog interface{}) *MockPruneBackupsUseCase_WithCatalog_Call {
+	return &MockPruneBackupsUseCase_WithCatalog_Call{Call: _e.mock.On("WithCatalog", catalog)}
 }
 
-func (_c *MockCreateBackupUseCase_WithPollingInterval_Call) Run(run func(interval time.Duration)) *MockCreateBackupUseCase_WithPollingInterval_Call {
+func (_c *MockPruneBackupsUseCase_WithCatalog_Call) Run(run func(catalog CatalogStore)) *MockPruneBackupsUseCase_WithCatalog_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 time.Duration
+		var arg0 CatalogStore
 		if args[0] != nil {
-			arg0 = args[0].(time.Duration)
+			arg0 = args[0].(CatalogStore)
 		}
 		run(
 			arg0,
@@ -154,23 +1848,23 @@ func (_c *MockCreateBackupUseCase_WithPollingInterval_Call) Run(run func(interva
 	return _c
 }
 
-func (_c *MockCreateBackupUseCase_WithPollingInterval_Call) Return(createBackupUseCase CreateBackupUseCase) *MockCreateBackupUseCase_WithPollingInterval_Call {
-	_c.Call.Return(createBackupUseCase)
+func (_c *MockPruneBackupsUseCase_WithCatalog_Call) Return(pruneBackupsUseCase PruneBackupsUseCase) *MockPruneBackupsUseCase_WithCatalog_Call {
+	_c.Call.Return(pruneBackupsUseCase)
 	return _c
 }
 
-func (_c *MockCreateBackupUseCase_WithPollingInterval_Call) RunAndReturn(run func(interval time.Duration) CreateBackupUseCase) *MockCreateBackupUseCase_WithPollingInterval_Call {
+func (_c *MockPruneBackupsUseCase_WithCatalog_Call) RunAndReturn(run func(catalog CatalogStore) PruneBackupsUseCase) *MockPruneBackupsUseCase_WithCatalog_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// NewMockCreateLocalBackupUseCase creates a new instance of MockCreateLocalBackupUseCase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// NewMockRestoreBackupUseCase creates a new instance of MockRestoreBackupUseCase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
-func NewMockCreateLocalBackupUseCase(t interface {
+func NewMockRestoreBackupUseCase(t interface {
 	mock.TestingT
 	Cleanup(func())
-}) *MockCreateLocalBackupUseCase {
-	mock := &MockCreateLocalBackupUseCase{}
+}) *MockRestoreBackupUseCase {
+	mock := &MockRestoreBackupUseCase{}
 	mock.Mock.Test(t)
 
 	t.Cleanup(func() { mock.AssertExpectations(t) })
@@ -178,98 +1872,106 @@ func NewMockCreateLocalBackupUseCase(t interface {
 	return mock
 }
 
-// MockCreateLocalBackupUseCase is an autogenerated mock type for the CreateLocalBackupUseCase type
-type MockCreateLocalBackupUseCase struct {
+// MockRestoreBackupUseCase is an autogenerated mock type for the RestoreBackupUseCase type
+type MockRestoreBackupUseCase struct {
 	mock.Mock
 }
 
-type MockCreateLocalBackupUseCase_Expecter struct {
+type MockRestoreBackupUseCase_Expecter struct {
 	mock *mock.Mock
 }
 
-func (_m *MockCreateLocalBackupUseCase) EXPECT() *MockCreateLocalBackupUseCase_Expecter {
-	return &MockCreateLocalBackupUseCase_Expecter{mock: &_m.Mock}
+func (_m *MockRestoreBackupUseCase) EXPECT() *MockRestoreBackupUseCase_Expecter {
+	return &MockRestoreBackupUseCase_Expecter{mock: &_m.Mock}
 }
 
-// Do provides a mock function for the type MockCreateLocalBackupUseCase
-func (_mock *MockCreateLocalBackupUseCase) Do(ctx context.Context, organization string, backupPath string) (string, error) {
-	ret := _mock.Called(ctx, organization, backupPath)
+// Do provides a mock function for the type MockRestoreBackupUseCase
+func (_mock *MockRestoreBackupUseCase) Do(ctx context.Context, reader io.Reader, organization string, dryRun bool) ([]string, error) {
+	ret := _mock.Called(ctx, reader, organization, dryRun)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Do")
 	}
 
-	var r0 string
+	var r0 []string
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) (string, error)); ok {
-		return returnFunc(ctx, organization, backupPath)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, io.Reader, string, bool) ([]string, error)); ok {
+		return returnFunc(ctx, reader, organization, dryRun)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) string); ok {
-		r0 = returnFunc(ctx, organization, backupPath)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, io.Reader, string, bool) []string); ok {
+		r0 = returnFunc(ctx, reader, organization, dryRun)
 	} else {
-		r0 = ret.Get(0).(string)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = returnFunc(ctx, organization, backupPath)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, io.Reader, string, bool) error); ok {
+		r1 = returnFunc(ctx, reader, organization, dryRun)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockCreateLocalBackupUseCase_Do_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Do'
-type MockCreateLocalBackupUseCase_Do_Call struct {
+// MockRestoreBackupUseCase_Do_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Do'
+type MockRestoreBackupUseCase_Do_Call struct {
 	*mock.Call
 }
 
 // Do is a helper method to define mock.On call
 //   - ctx context.Context
+//   - reader io.Reader
 //   - organization string
-//   - backupPath string
-func (_e *MockCreateLocalBackupUseCase_Expecter) Do(ctx interface{}, organization interface{}, backupPath interface{}) *MockCreateLocalBackupUseCase_Do_Call {
-	return &MockCreateLocalBackupUseCase_Do_Call{Call: _e.mock.On("Do", ctx, organization, backupPath)}
+//   - dryRun bool
+func (_e *MockRestoreBackupUseCase_Expecter) Do(ctx interface{}, reader interface{}, organization interface{}, dryRun interface{}) *MockRestoreBackupUseCase_Do_Call {
+	return &MockRestoreBackupUseCase_Do_Call{Call: _e.mock.On("Do", ctx, reader, organization, dryRun)}
 }
 
-func (_c *MockCreateLocalBackupUseCase_Do_Call) Run(run func(ctx context.Context, organization string, backupPath string)) *MockCreateLocalBackupUseCase_Do_Call {
+func (_c *MockRestoreBackupUseCase_Do_Call) Run(run func(ctx context.Context, reader io.Reader, organization string, dryRun bool)) *MockRestoreBackupUseCase_Do_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 string
+		var arg1 io.Reader
 		if args[1] != nil {
-			arg1 = args[1].(string)
+			arg1 = args[1].(io.Reader)
 		}
 		var arg2 string
 		if args[2] != nil {
 			arg2 = args[2].(string)
 		}
+		var arg3 bool
+		if args[3] != nil {
+			arg3 = args[3].(bool)
+		}
 		run(
 			arg0,
 			arg1,
 			arg2,
+			arg3,
 		)
 	})
 	return _c
 }
 
-func (_c *MockCreateLocalBackupUseCase_Do_Call) Return(s string, err error) *MockCreateLocalBackupUseCase_Do_Call {
-	_c.Call.Return(s, err)
+func (_c *MockRestoreBackupUseCase_Do_Call) Return(ss []string, err error) *MockRestoreBackupUseCase_Do_Call {
+	_c.Call.Return(ss, err)
 	return _c
 }
 
-func (_c *MockCreateLocalBackupUseCase_Do_Call) RunAndReturn(run func(ctx context.Context, organization string, backupPath string) (string, error)) *MockCreateLocalBackupUseCase_Do_Call {
+func (_c *MockRestoreBackupUseCase_Do_Call) RunAndReturn(run func(ctx context.Context, reader io.Reader, organization string, dryRun bool) ([]string, error)) *MockRestoreBackupUseCase_Do_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// NewMockCreateRemoteBackupUseCase creates a new instance of MockCreateRemoteBackupUseCase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// NewMockRunRecorder creates a new instance of MockRunRecorder. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
-func NewMockCreateRemoteBackupUseCase(t interface {
+func NewMockRunRecorder(t interface {
 	mock.TestingT
 	Cleanup(func())
-}) *MockCreateRemoteBackupUseCase {
-	mock := &MockCreateRemoteBackupUseCase{}
+}) *MockRunRecorder {
+	mock := &MockRunRecorder{}
 	mock.Mock.Test(t)
 
 	t.Cleanup(func() { mock.AssertExpectations(t) })
@@ -277,66 +1979,46 @@ func NewMockCreateRemoteBackupUseCase(t interface {
 	return mock
 }
 
-// MockCreateRemoteBackupUseCase is an autogenerated mock type for the CreateRemoteBackupUseCase type
-type MockCreateRemoteBackupUseCase struct {
+// MockRunRecorder is an autogenerated mock type for the RunRecorder type
+type MockRunRecorder struct {
 	mock.Mock
 }
 
-type MockCreateRemoteBackupUseCase_Expecter struct {
+type MockRunRecorder_Expecter struct {
 	mock *mock.Mock
 }
 
-func (_m *MockCreateRemoteBackupUseCase) EXPECT() *MockCreateRemoteBackupUseCase_Expecter {
-	return &MockCreateRemoteBackupUseCase_Expecter{mock: &_m.Mock}
+func (_m *MockRunRecorder) EXPECT() *MockRunRecorder_Expecter {
+	return &MockRunRecorder_Expecter{mock: &_m.Mock}
 }
 
-// Do provides a mock function for the type MockCreateRemoteBackupUseCase
-func (_mock *MockCreateRemoteBackupUseCase) Do(ctx context.Context, organization string) (string, error) {
-	ret := _mock.Called(ctx, organization)
-
-	if len(ret) == 0 {
-		panic("no return value specified for Do")
-	}
-
-	var r0 string
-	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
-		return returnFunc(ctx, organization)
-	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) string); ok {
-		r0 = returnFunc(ctx, organization)
-	} else {
-		r0 = ret.Get(0).(string)
-	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = returnFunc(ctx, organization)
-	} else {
-		r1 = ret.Error(1)
-	}
-	return r0, r1
+// RecordArchive provides a mock function for the type MockRunRecorder
+func (_mock *MockRunRecorder) RecordArchive(organization string, archive ArchiveReport) {
+	_mock.Called(organization, archive)
+	return
 }
 
-// MockCreateRemoteBackupUseCase_Do_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Do'
-type MockCreateRemoteBackupUseCase_Do_Call struct {
+// MockRunRecorder_RecordArchive_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecordArchive'
+type MockRunRecorder_RecordArchive_Call struct {
 	*mock.Call
 }
 
-// Do is a helper method to define mock.On call
-//   - ctx context.Context
+// RecordArchive is a helper method to define mock.On call
 //   - organization string
-func (_e *MockCreateRemoteBackupUseCase_Expecter) Do(ctx interface{}, organization interface{}) *MockCreateRemoteBackupUseCase_Do_Call {
-	return &MockCreateRemoteBackupUseCase_Do_Call{Call: _e.mock.On("Do", ctx, organization)}
+//   - archive ArchiveReport
+func (_e *MockRunRecorder_Expecter) RecordArchive(organization interface{}, archive interface{}) *MockRunRecorder_RecordArchive_Call {
+	return &MockRunRecorder_RecordArchive_Call{Call: _e.mock.On("RecordArchive", organization, archive)}
 }
 
-func (_c *MockCreateRemoteBackupUseCase_Do_Call) Run(run func(ctx context.Context, organization string)) *MockCreateRemoteBackupUseCase_Do_Call {
+func (_c *MockRunRecorder_RecordArchive_Call) Run(run func(organization string, archive ArchiveReport)) *MockRunRecorder_RecordArchive_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 context.Context
+		var arg0 string
 		if args[0] != nil {
-			arg0 = args[0].(context.Context)
+			arg0 = args[0].(string)
 		}
-		var arg1 string
+		var arg1 ArchiveReport
 		if args[1] != nil {
-			arg1 = args[1].(string)
+			arg1 = args[1].(ArchiveReport)
 		}
 		run(
 			arg0,
@@ -346,181 +2028,231 @@ func (_c *MockCreateRemoteBackupUseCase_Do_Call) Run(run func(ctx context.Contex
 	return _c
 }
 
-func (_c *MockCreateRemoteBackupUseCase_Do_Call) Return(s string, err error) *MockCreateRemoteBackupUseCase_Do_Call {
-	_c.Call.Return(s, err)
+func (_c *MockRunRecorder_RecordArchive_Call) Return() *MockRunRecorder_RecordArchive_Call {
+	_c.Call.Return()
 	return _c
 }
 
-func (_c *MockCreateRemoteBackupUseCase_Do_Call) RunAndReturn(run func(ctx context.Context, organization string) (string, error)) *MockCreateRemoteBackupUseCase_Do_Call {
-	_c.Call.Return(run)
+func (_c *MockRunRecorder_RecordArchive_Call) RunAndReturn(run func(organization string, archive ArchiveReport)) *MockRunRecorder_RecordArchive_Call {
+	_c.Run(run)
 	return _c
 }
 
-// NewMockGetOrganizationArchiveUrlUseCase creates a new instance of MockGetOrganizationArchiveUrlUseCase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
-// The first argument is typically a *testing.T value.
-func NewMockGetOrganizationArchiveUrlUseCase(t interface {
-	mock.TestingT
-	Cleanup(func())
-}) *MockGetOrganizationArchiveUrlUseCase {
-	mock := &MockGetOrganizationArchiveUrlUseCase{}
-	mock.Mock.Test(t)
+// RecordExcludedRepository provides a mock function for the type MockRunRecorder
+func (_mock *MockRunRecorder) RecordExcludedRepository(organization string, repository ExcludedRepository) {
+	_mock.Called(organization, repository)
+	return
+}
 
-	t.Cleanup(func() { mock.AssertExpectations(t) })
+// MockRunRecorder_RecordExcludedRepository_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecordExcludedRepository'
+type MockRunRecorder_RecordExcludedRepository_Call struct {
+	*mock.Call
+}
 
-	return mock
+// RecordExcludedRepository is a helper method to define mock.On call
+//   - organization string
+//   - repository ExcludedRepository
+func (_e *MockRunRecorder_Expecter) RecordExcludedRepository(organization interface{}, repository interface{}) *MockRunRecorder_RecordExcludedRepository_Call {
+	return &MockRunRecorder_RecordExcludedRepository_Call{Call: _e.mock.On("RecordExcludedRepository", organization, repository)}
 }
 
-// MockGetOrganizationArchiveUrlUseCase is an autogenerated mock type for the GetOrganizationArchiveUrlUseCase type
-type MockGetOrganizationArchiveUrlUseCase struct {
-	mock.Mock
+func (_c *MockRunRecorder_RecordExcludedRepository_Call) Run(run func(organization string, repository ExcludedRepository)) *MockRunRecorder_RecordExcludedRepository_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		var arg1 ExcludedRepository
+		if args[1] != nil {
+			arg1 = args[1].(ExcludedRepository)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
 }
 
-type MockGetOrganizationArchiveUrlUseCase_Expecter struct {
-	mock *mock.Mock
+func (_c *MockRunRecorder_RecordExcludedRepository_Call) Return() *MockRunRecorder_RecordExcludedRepository_Call {
+	_c.Call.Return()
+	return _c
 }
 
-func (_m *MockGetOrganizationArchiveUrlUseCase) EXPECT() *MockGetOrganizationArchiveUrlUseCase_Expecter {
-	return &MockGetOrganizationArchiveUrlUseCase_Expecter{mock: &_m.Mock}
+func (_c *MockRunRecorder_RecordExcludedRepository_Call) RunAndReturn(run func(organization string, repository ExcludedRepository)) *MockRunRecorder_RecordExcludedRepository_Call {
+	_c.Run(run)
+	return _c
 }
 
-// Do provides a mock function for the type MockGetOrganizationArchiveUrlUseCase
-func (_mock *MockGetOrganizationArchiveUrlUseCase) Do(ctx context.Context, organization string, organizationID int64) (string, error) {
-	ret := _mock.Called(ctx, organization, organizationID)
+// RecordMigrationState provides a mock function for the type MockRunRecorder
+func (_mock *MockRunRecorder) RecordMigrationState(organization string, archive BackupArchive, state string) {
+	_mock.Called(organization, archive, state)
+	return
+}
 
-	if len(ret) == 0 {
-		panic("no return value specified for Do")
-	}
+// MockRunRecorder_RecordMigrationState_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecordMigrationState'
+type MockRunRecorder_RecordMigrationState_Call struct {
+	*mock.Call
+}
 
-	var r0 string
-	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int64) (string, error)); ok {
-		return returnFunc(ctx, organization, organizationID)
-	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int64) string); ok {
-		r0 = returnFunc(ctx, organization, organizationID)
-	} else {
-		r0 = ret.Get(0).(string)
-	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, string, int64) error); ok {
-		r1 = returnFunc(ctx, organization, organizationID)
-	} else {
-		r1 = ret.Error(1)
-	}
-	return r0, r1
+// RecordMigrationState is a helper method to define mock.On call
+//   - organization string
+//   - archive BackupArchive
+//   - state string
+func (_e *MockRunRecorder_Expecter) RecordMigrationState(organization interface{}, archive interface{}, state interface{}) *MockRunRecorder_RecordMigrationState_Call {
+	return &MockRunRecorder_RecordMigrationState_Call{Call: _e.mock.On("RecordMigrationState", organization, archive, state)}
 }
 
-// MockGetOrganizationArchiveUrlUseCase_Do_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Do'
-type MockGetOrganizationArchiveUrlUseCase_Do_Call struct {
+func (_c *MockRunRecorder_RecordMigrationState_Call) Run(run func(organization string, archive BackupArchive, state string)) *MockRunRecorder_RecordMigrationState_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		var arg1 BackupArchive
+		if args[1] != nil {
+			arg1 = args[1].(BackupArchive)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRunRecorder_RecordMigrationState_Call) Return() *MockRunRecorder_RecordMigrationState_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockRunRecorder_RecordMigrationState_Call) RunAndReturn(run func(organization string, archive BackupArchive, state string)) *MockRunRecorder_RecordMigrationState_Call {
+	_c.Run(run)
+	return _c
+}
+
+// RecordPhase provides a mock function for the type MockRunRecorder
+func (_mock *MockRunRecorder) RecordPhase(organization string, phase string, duration time.Duration, err error) {
+	_mock.Called(organization, phase, duration, err)
+	return
+}
+
+// MockRunRecorder_RecordPhase_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecordPhase'
+type MockRunRecorder_RecordPhase_Call struct {
 	*mock.Call
 }
 
-// Do is a helper method to define mock.On call
-//   - ctx context.Context
+// RecordPhase is a helper method to define mock.On call
 //   - organization string
-//   - organizationID int64
-func (_e *MockGetOrganizationArchiveUrlUseCase_Expecter) Do(ctx interface{}, organization interface{}, organizationID interface{}) *MockGetOrganizationArchiveUrlUseCase_Do_Call {
-	return &MockGetOrganizationArchiveUrlUseCase_Do_Call{Call: _e.mock.On("Do", ctx, organization, organizationID)}
+//   - phase string
+//   - duration time.Duration
+//   - err error
+func (_e *MockRunRecorder_Expecter) RecordPhase(organization interface{}, phase interface{}, duration interface{}, err interface{}) *MockRunRecorder_RecordPhase_Call {
+	return &MockRunRecorder_RecordPhase_Call{Call: _e.mock.On("RecordPhase", organization, phase, duration, err)}
 }
 
-func (_c *MockGetOrganizationArchiveUrlUseCase_Do_Call) Run(run func(ctx context.Context, organization string, organizationID int64)) *MockGetOrganizationArchiveUrlUseCase_Do_Call {
+func (_c *MockRunRecorder_RecordPhase_Call) Run(run func(organization string, phase string, duration time.Duration, err error)) *MockRunRecorder_RecordPhase_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 context.Context
+		var arg0 string
 		if args[0] != nil {
-			arg0 = args[0].(context.Context)
+			arg0 = args[0].(string)
 		}
 		var arg1 string
 		if args[1] != nil {
 			arg1 = args[1].(string)
 		}
-		var arg2 int64
+		var arg2 time.Duration
 		if args[2] != nil {
-			arg2 = args[2].(int64)
+			arg2 = args[2].(time.Duration)
+		}
+		var arg3 error
+		if args[3] != nil {
+			arg3 = args[3].(error)
 		}
 		run(
 			arg0,
 			arg1,
 			arg2,
+			arg3,
 		)
 	})
 	return _c
 }
 
-func (_c *MockGetOrganizationArchiveUrlUseCase_Do_Call) Return(s string, err error) *MockGetOrganizationArchiveUrlUseCase_Do_Call {
-	_c.Call.Return(s, err)
+func (_c *MockRunRecorder_RecordPhase_Call) Return() *MockRunRecorder_RecordPhase_Call {
+	_c.Call.Return()
 	return _c
 }
 
-func (_c *MockGetOrganizationArchiveUrlUseCase_Do_Call) RunAndReturn(run func(ctx context.Context, organization string, organizationID int64) (string, error)) *MockGetOrganizationArchiveUrlUseCase_Do_Call {
-	_c.Call.Return(run)
+func (_c *MockRunRecorder_RecordPhase_Call) RunAndReturn(run func(organization string, phase string, duration time.Duration, err error)) *MockRunRecorder_RecordPhase_Call {
+	_c.Run(run)
 	return _c
 }
 
-// WithDurationOptions provides a mock function for the type MockGetOrganizationArchiveUrlUseCase
-func (_mock *MockGetOrganizationArchiveUrlUseCase) WithDurationOptions(timeout time.Duration, ticker time.Duration) GetOrganizationArchiveUrlUseCase {
-	ret := _mock.Called(timeout, ticker)
-
-	if len(ret) == 0 {
-		panic("no return value specified for WithDurationOptions")
-	}
-
-	var r0 GetOrganizationArchiveUrlUseCase
-	if returnFunc, ok := ret.Get(0).(func(time.Duration, time.Duration) GetOrganizationArchiveUrlUseCase); ok {
-		r0 = returnFunc(timeout, ticker)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(GetOrganizationArchiveUrlUseCase)
-		}
-	}
-	return r0
+// RecordPlan provides a mock function for the type MockRunRecorder
+func (_mock *MockRunRecorder) RecordPlan(organization string, backupType string, base string) {
+	_mock.Called(organization, backupType, base)
+	return
 }
 
-// MockGetOrganizationArchiveUrlUseCase_WithDurationOptions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WithDurationOptions'
-type MockGetOrganizationArchiveUrlUseCase_WithDurationOptions_Call struct {
+// MockRunRecorder_RecordPlan_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecordPlan'
+type MockRunRecorder_RecordPlan_Call struct {
 	*mock.Call
 }
 
-// WithDurationOptions is a helper method to define mock.On call
-//   - timeout time.Duration
-//   - ticker time.Duration
-func (_e *MockGetOrganizationArchiveUrlUseCase_Expecter) WithDurationOptions(timeout interface{}, ticker interface{}) *MockGetOrganizationArchiveUrlUseCase_WithDurationOptions_Call {
-	return &MockGetOrganizationArchiveUrlUseCase_WithDurationOptions_Call{Call: _e.mock.On("WithDurationOptions", timeout, ticker)}
+// RecordPlan is a helper method to define mock.On call
+//   - organization string
+//   - backupType string
+//   - base string
+func (_e *MockRunRecorder_Expecter) RecordPlan(organization interface{}, backupType interface{}, base interface{}) *MockRunRecorder_RecordPlan_Call {
+	return &MockRunRecorder_RecordPlan_Call{Call: _e.mock.On("RecordPlan", organization, backupType, base)}
 }
 
-func (_c *MockGetOrganizationArchiveUrlUseCase_WithDurationOptions_Call) Run(run func(timeout time.Duration, ticker time.Duration)) *MockGetOrganizationArchiveUrlUseCase_WithDurationOptions_Call {
+func (_c *MockRunRecorder_RecordPlan_Call) Run(run func(organization string, backupType string, base string)) *MockRunRecorder_RecordPlan_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 time.Duration
+		var arg0 string
 		if args[0] != nil {
-			arg0 = args[0].(time.Duration)
+			arg0 = args[0].(string)
 		}
-		var arg1 time.Duration
+		var arg1 string
 		if args[1] != nil {
-			arg1 = args[1].(time.Duration)
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
 		}
 		run(
 			arg0,
 			arg1,
+			arg2,
 		)
 	})
 	return _c
 }
 
-func (_c *MockGetOrganizationArchiveUrlUseCase_WithDurationOptions_Call) Return(getOrganizationArchiveUrlUseCase GetOrganizationArchiveUrlUseCase) *MockGetOrganizationArchiveUrlUseCase_WithDurationOptions_Call {
-	_c.Call.Return(getOrganizationArchiveUrlUseCase)
+func (_c *MockRunRecorder_RecordPlan_Call) Return() *MockRunRecorder_RecordPlan_Call {
+	_c.Call.Return()
 	return _c
 }
 
-func (_c *MockGetOrganizationArchiveUrlUseCase_WithDurationOptions_Call) RunAndReturn(run func(timeout time.Duration, ticker time.Duration) GetOrganizationArchiveUrlUseCase) *MockGetOrganizationArchiveUrlUseCase_WithDurationOptions_Call {
-	_c.Call.Return(run)
+func (_c *MockRunRecorder_RecordPlan_Call) RunAndReturn(run func(organization string, backupType string, base string)) *MockRunRecorder_RecordPlan_Call {
+	_c.Run(run)
 	return _c
 }
 
-// NewMockListPrivateReposUseCase creates a new instance of MockListPrivateReposUseCase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// NewMockVerifyBackupUseCase creates a new instance of MockVerifyBackupUseCase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
-func NewMockListPrivateReposUseCase(t interface {
+func NewMockVerifyBackupUseCase(t interface {
 	mock.TestingT
 	Cleanup(func())
-}) *MockListPrivateReposUseCase {
-	mock := &MockListPrivateReposUseCase{}
+}) *MockVerifyBackupUseCase {
+	mock := &MockVerifyBackupUseCase{}
 	mock.Mock.Test(t)
 
 	t.Cleanup(func() { mock.AssertExpectations(t) })
@@ -528,83 +2260,87 @@ func NewMockListPrivateReposUseCase(t interface {
 	return mock
 }
 
-// MockListPrivateReposUseCase is an autogenerated mock type for the ListPrivateReposUseCase type
-type MockListPrivateReposUseCase struct {
+// MockVerifyBackupUseCase is an autogenerated mock type for the VerifyBackupUseCase type
+type MockVerifyBackupUseCase struct {
 	mock.Mock
 }
 
-type MockListPrivateReposUseCase_Expecter struct {
+type MockVerifyBackupUseCase_Expecter struct {
 	mock *mock.Mock
 }
 
-func (_m *MockListPrivateReposUseCase) EXPECT() *MockListPrivateReposUseCase_Expecter {
-	return &MockListPrivateReposUseCase_Expecter{mock: &_m.Mock}
+func (_m *MockVerifyBackupUseCase) EXPECT() *MockVerifyBackupUseCase_Expecter {
+	return &MockVerifyBackupUseCase_Expecter{mock: &_m.Mock}
 }
 
-// Do provides a mock function for the type MockListPrivateReposUseCase
-func (_mock *MockListPrivateReposUseCase) Do(ctx context.Context, organization string) ([]github.Repository, error) {
-	ret := _mock.Called(ctx, organization)
+// Do provides a mock function for the type MockVerifyBackupUseCase
+func (_mock *MockVerifyBackupUseCase) Do(ctx context.Context, manifest io.Reader, archive io.Reader) (IntegrityManifest, error) {
+	ret := _mock.Called(ctx, manifest, archive)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Do")
 	}
 
-	var r0 []github.Repository
+	var r0 IntegrityManifest
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) ([]github.Repository, error)); ok {
-		return returnFunc(ctx, organization)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, io.Reader, io.Reader) (IntegrityManifest, error)); ok {
+		return returnFunc(ctx, manifest, archive)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) []github.Repository); ok {
-		r0 = returnFunc(ctx, organization)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, io.Reader, io.Reader) IntegrityManifest); ok {
+		r0 = returnFunc(ctx, manifest, archive)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]github.Repository)
-		}
+		r0 = ret.Get(0).(IntegrityManifest)
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = returnFunc(ctx, organization)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, io.Reader, io.Reader) error); ok {
+		r1 = returnFunc(ctx, manifest, archive)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockListPrivateReposUseCase_Do_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Do'
-type MockListPrivateReposUseCase_Do_Call struct {
+// MockVerifyBackupUseCase_Do_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Do'
+type MockVerifyBackupUseCase_Do_Call struct {
 	*mock.Call
 }
 
 // Do is a helper method to define mock.On call
 //   - ctx context.Context
-//   - organization string
-func (_e *MockListPrivateReposUseCase_Expecter) Do(ctx interface{}, organization interface{}) *MockListPrivateReposUseCase_Do_Call {
-	return &MockListPrivateReposUseCase_Do_Call{Call: _e.mock.On("Do", ctx, organization)}
+//   - manifest io.Reader
+//   - archive io.Reader
+func (_e *MockVerifyBackupUseCase_Expecter) Do(ctx interface{}, manifest interface{}, archive interface{}) *MockVerifyBackupUseCase_Do_Call {
+	return &MockVerifyBackupUseCase_Do_Call{Call: _e.mock.On("Do", ctx, manifest, archive)}
 }
 
-func (_c *MockListPrivateReposUseCase_Do_Call) Run(run func(ctx context.Context, organization string)) *MockListPrivateReposUseCase_Do_Call {
+func (_c *MockVerifyBackupUseCase_Do_Call) Run(run func(ctx context.Context, manifest io.Reader, archive io.Reader)) *MockVerifyBackupUseCase_Do_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 string
+		var arg1 io.Reader
 		if args[1] != nil {
-			arg1 = args[1].(string)
+			arg1 = args[1].(io.Reader)
+		}
+		var arg2 io.Reader
+		if args[2] != nil {
+			arg2 = args[2].(io.Reader)
 		}
 		run(
 			arg0,
 			arg1,
+			arg2,
 		)
 	})
 	return _c
 }
 
-func (_c *MockListPrivateReposUseCase_Do_Call) Return(repositorys []github.Repository, err error) *MockListPrivateReposUseCase_Do_Call {
-	_c.Call.Return(repositorys, err)
+func (_c *MockVerifyBackupUseCase_Do_Call) Return(integrityManifest IntegrityManifest, err error) *MockVerifyBackupUseCase_Do_Call {
+	_c.Call.Return(integrityManifest, err)
 	return _c
 }
 
-func (_c *MockListPrivateReposUseCase_Do_Call) RunAndReturn(run func(ctx context.Context, organization string) ([]github.Repository, error)) *MockListPrivateReposUseCase_Do_Call {
+func (_c *MockVerifyBackupUseCase_Do_Call) RunAndReturn(run func(ctx context.Context, manifest io.Reader, archive io.Reader) (IntegrityManifest, error)) *MockVerifyBackupUseCase_Do_Call {
 	_c.Call.Return(run)
 	return _c
 }
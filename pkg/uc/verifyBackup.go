@@ -0,0 +1,44 @@
+package uc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var ErrIntegrityMismatch = errors.New("archive does not match its integrity manifest")
+
+type VerifyBackupUseCase interface {
+	// Do reads the archive again and checks its checksum and size against its integrity manifest
+	Do(ctx context.Context, manifest io.Reader, archive io.Reader) (IntegrityManifest, error)
+}
+
+type verifyBackupUseCase struct{}
+
+func NewVerifyBackupUseCase() VerifyBackupUseCase {
+	return &verifyBackupUseCase{}
+}
+
+func (uc *verifyBackupUseCase) Do(_ context.Context, manifestReader io.Reader, archive io.Reader) (IntegrityManifest, error) {
+	var manifest IntegrityManifest
+	if err := json.NewDecoder(manifestReader).Decode(&manifest); err != nil {
+		return manifest, fmt.Errorf("failed to read integrity manifest: %w", err)
+	}
+
+	checksum := newChecksumReader(archive)
+	if _, err := io.Copy(io.Discard, checksum); err != nil {
+		return manifest, fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	if checksum.size != manifest.Size {
+		return manifest, fmt.Errorf("%w: size is %d bytes, expected %d", ErrIntegrityMismatch, checksum.size, manifest.Size)
+	}
+
+	if checksum.SHA256() != manifest.SHA256 {
+		return manifest, fmt.Errorf("%w: SHA-256 is %s, expected %s", ErrIntegrityMismatch, checksum.SHA256(), manifest.SHA256)
+	}
+
+	return manifest, nil
+}
@@ -0,0 +1,69 @@
+package uc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyBackupUseCase(t *testing.T) {
+	manifest := `{
+		"archive": "2025-07-23-kumojin-migration.tar.gz",
+		"organization": "kumojin",
+		"sha256": "b10c4854966ae4b7549a4f1bf964eb09d76b2a9510d543acb81d50c9bbb6e88d",
+		"size": 20,
+		"repositories": ["repo1"]
+	}`
+
+	tests := []struct {
+		name          string
+		manifest      string
+		archive       string
+		expectedError error
+	}{
+		{
+			name:     "intact archive",
+			manifest: manifest,
+			archive:  "mock archive content",
+		},
+		{
+			name:          "truncated archive",
+			manifest:      manifest,
+			archive:       "mock archive",
+			expectedError: ErrIntegrityMismatch,
+		},
+		{
+			name:          "corrupted archive",
+			manifest:      manifest,
+			archive:       "mock archive CONTENT",
+			expectedError: ErrIntegrityMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCase := NewVerifyBackupUseCase()
+
+			result, err := useCase.Do(context.Background(), strings.NewReader(tt.manifest), strings.NewReader(tt.archive))
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "2025-07-23-kumojin-migration.tar.gz", result.Archive)
+			assert.Equal(t, []string{"repo1"}, result.Repositories)
+		})
+	}
+}
+
+func TestVerifyBackupUseCase_InvalidManifest(t *testing.T) {
+	useCase := NewVerifyBackupUseCase()
+
+	_, err := useCase.Do(context.Background(), strings.NewReader("not json"), strings.NewReader("archive"))
+
+	assert.ErrorContains(t, err, "failed to read integrity manifest")
+}